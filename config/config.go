@@ -2,18 +2,142 @@ package config
 
 import (
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
 
 type Config struct {
-	Host       string `yaml:"host"`
-	Port       string `yaml:"port"`
-	User       string `yaml:"user"`
-	Password   string `yaml:"password"`
-	Name       string `yaml:"name"`
-	JWTSecret  string `yaml:"jwt_secret"`
-	ServerPort string `yaml:"server_port"`
+	Host         string         `yaml:"host"`
+	Port         string         `yaml:"port"`
+	User         string         `yaml:"user"`
+	Password     string         `yaml:"password"`
+	Name         string         `yaml:"name"`
+	JWTSecret    string         `yaml:"jwt_secret"`
+	ServerPort   string         `yaml:"server_port"`
+	PublicURL    string         `yaml:"public_url"`
+	MagicLinkTTL time.Duration  `yaml:"magic_link_ttl"`
+	WebAuthn     WebAuthnConfig `yaml:"webauthn"`
+	Lockout      LockoutConfig  `yaml:"lockout"`
+	OIDC         OIDCConfig     `yaml:"oidc"`
+	AdminUserIDs []string       `yaml:"admin_user_ids"`
+	// DevTokens exposes GET /auth/tokens, which mints tokens for any user
+	// without authentication. Never enable it outside local development.
+	DevTokens bool `yaml:"dev_tokens"`
+	// AccountDeletionGrace is how long a deleted account can be restored
+	// before its data is purged.
+	AccountDeletionGrace time.Duration  `yaml:"account_deletion_grace"`
+	Sessions             SessionsConfig `yaml:"sessions"`
+	Cleanup              CleanupConfig  `yaml:"cleanup"`
+	GeoIP                GeoIPConfig    `yaml:"geoip"`
+	IPChange             IPChangeConfig `yaml:"ip_change"`
+
+	FederatedProviders []FederatedProviderConfig `yaml:"federated_providers"`
+	LDAP               LDAPConfig                `yaml:"ldap"`
+	Tenants            []TenantConfig            `yaml:"tenants"`
+}
+
+type WebAuthnConfig struct {
+	RPID          string   `yaml:"rp_id"`
+	RPDisplayName string   `yaml:"rp_display_name"`
+	RPOrigins     []string `yaml:"rp_origins"`
+}
+
+type LockoutConfig struct {
+	DelayThreshold  int           `yaml:"delay_threshold"`
+	BaseDelay       time.Duration `yaml:"base_delay"`
+	MaxDelay        time.Duration `yaml:"max_delay"`
+	LockThreshold   int           `yaml:"lock_threshold"`
+	LockDuration    time.Duration `yaml:"lock_duration"`
+	IPLockThreshold int           `yaml:"ip_lock_threshold"`
+	Window          time.Duration `yaml:"window"`
+}
+
+// SessionsConfig caps the sessions of a user. MaxPerUser 0 means no cap;
+// LimitPolicy is evict_oldest, evict_lru or reject. Sessions not refreshed
+// for IdleTimeout end, 0 means they only end when they expire.
+type SessionsConfig struct {
+	MaxPerUser  int           `yaml:"max_per_user"`
+	LimitPolicy string        `yaml:"limit_policy"`
+	IdleTimeout time.Duration `yaml:"idle_timeout"`
+}
+
+// CleanupConfig sets how often the background jobs run, 0 disables a job.
+// Rows are deleted BatchSize at a time.
+type CleanupConfig struct {
+	TokensInterval   time.Duration `yaml:"tokens_interval"`
+	CodesInterval    time.Duration `yaml:"codes_interval"`
+	AccountsInterval time.Duration `yaml:"accounts_interval"`
+	BatchSize        int           `yaml:"batch_size"`
+}
+
+// GeoIPConfig points to MaxMind DB files, GeoLite2-City for the country and
+// city and GeoLite2-ASN for the network. Without them sessions are not
+// located. The files are checked for updates every ReloadInterval.
+type GeoIPConfig struct {
+	CityDB         string        `yaml:"city_db"`
+	ASNDB          string        `yaml:"asn_db"`
+	ReloadInterval time.Duration `yaml:"reload_interval"`
+}
+
+// IPChangeConfig is what happens when a session is refreshed from a new IP:
+// Policy is allow, notify, step_up or deny. Moves within the same /24 or /64
+// network, AS or country (when enabled) and to AllowedNetworks are allowed.
+type IPChangeConfig struct {
+	Policy          string   `yaml:"policy"`
+	SameSubnet      bool     `yaml:"same_subnet"`
+	SameASN         bool     `yaml:"same_asn"`
+	SameCountry     bool     `yaml:"same_country"`
+	AllowedNetworks []string `yaml:"allowed_networks"`
+}
+
+type OIDCConfig struct {
+	// SigningKeyFile is a PEM encoded RSA private key for ID tokens. Without
+	// it a key is generated on startup and tokens do not survive a restart.
+	SigningKeyFile string `yaml:"signing_key_file"`
+}
+
+// FederatedProviderConfig is an upstream OpenID Connect provider. The client
+// secret can be set with FEDERATED_<NAME>_CLIENT_SECRET instead of the file.
+type FederatedProviderConfig struct {
+	Name         string   `yaml:"name"`
+	Issuer       string   `yaml:"issuer"`
+	ClientID     string   `yaml:"client_id"`
+	ClientSecret string   `yaml:"client_secret"`
+	Scopes       []string `yaml:"scopes"`
+	TrustEmail   bool     `yaml:"trust_email"`
+}
+
+// TenantConfig is a customer hosted on the deployment, chosen by one of its
+// hosts, the X-Tenant-ID header or a /t/<id> path prefix. The JWT secret can
+// be set with TENANT_<ID>_JWT_SECRET; without it tokens are signed with
+// JWT_SECRET. Zero token lifetimes fall back to the defaults.
+type TenantConfig struct {
+	ID              string        `yaml:"id"`
+	Hosts           []string      `yaml:"hosts"`
+	JWTSecret       string        `yaml:"jwt_secret"`
+	AccessTokenTTL  time.Duration `yaml:"access_token_ttl"`
+	RefreshTokenTTL time.Duration `yaml:"refresh_token_ttl"`
+}
+
+// LDAPConfig is the directory used for password logins of the configured
+// email domains. LDAP logins are disabled when URL is empty. Filters get the
+// escaped login (user filter) or user DN (group filter) in place of %s.
+type LDAPConfig struct {
+	URL          string            `yaml:"url"`
+	StartTLS     bool              `yaml:"start_tls"`
+	BindDN       string            `yaml:"bind_dn"`
+	BindPassword string            `yaml:"bind_password"`
+	UserBaseDN   string            `yaml:"user_base_dn"`
+	UserFilter   string            `yaml:"user_filter"`
+	GroupBaseDN  string            `yaml:"group_base_dn"`
+	GroupFilter  string            `yaml:"group_filter"`
+	IDAttribute  string            `yaml:"id_attribute"`
+	GroupRoles   map[string]string `yaml:"group_roles"`
+	Domains      []string          `yaml:"domains"`
+	Timeout      time.Duration     `yaml:"timeout"`
 }
 
 func Load() (*Config, error) {
@@ -33,6 +157,66 @@ func Load() (*Config, error) {
 	cfg.Name = getEnv("NAME", cfg.Name, "auth_service")
 	cfg.JWTSecret = getEnv("JWT_SECRET", cfg.JWTSecret, "")
 	cfg.ServerPort = getEnv("SERVER_PORT", cfg.ServerPort, "8081")
+	cfg.PublicURL = getEnv("PUBLIC_URL", cfg.PublicURL, "http://localhost:"+cfg.ServerPort)
+	cfg.MagicLinkTTL = getEnvDuration("MAGIC_LINK_TTL", cfg.MagicLinkTTL, 15*time.Minute)
+
+	cfg.WebAuthn.RPID = getEnv("WEBAUTHN_RP_ID", cfg.WebAuthn.RPID, "localhost")
+	cfg.WebAuthn.RPDisplayName = getEnv("WEBAUTHN_RP_DISPLAY_NAME", cfg.WebAuthn.RPDisplayName, "Auth Service")
+	cfg.WebAuthn.RPOrigins = getEnvList("WEBAUTHN_RP_ORIGINS", cfg.WebAuthn.RPOrigins, []string{"http://localhost:8081"})
+
+	cfg.Lockout.DelayThreshold = getEnvInt("LOCKOUT_DELAY_THRESHOLD", cfg.Lockout.DelayThreshold, 3)
+	cfg.Lockout.BaseDelay = getEnvDuration("LOCKOUT_BASE_DELAY", cfg.Lockout.BaseDelay, time.Second)
+	cfg.Lockout.MaxDelay = getEnvDuration("LOCKOUT_MAX_DELAY", cfg.Lockout.MaxDelay, time.Minute)
+	cfg.Lockout.LockThreshold = getEnvInt("LOCKOUT_LOCK_THRESHOLD", cfg.Lockout.LockThreshold, 10)
+	cfg.Lockout.LockDuration = getEnvDuration("LOCKOUT_LOCK_DURATION", cfg.Lockout.LockDuration, 15*time.Minute)
+	cfg.Lockout.IPLockThreshold = getEnvInt("LOCKOUT_IP_LOCK_THRESHOLD", cfg.Lockout.IPLockThreshold, 50)
+	cfg.Lockout.Window = getEnvDuration("LOCKOUT_WINDOW", cfg.Lockout.Window, time.Hour)
+
+	cfg.OIDC.SigningKeyFile = getEnv("OIDC_SIGNING_KEY_FILE", cfg.OIDC.SigningKeyFile, "")
+
+	cfg.AdminUserIDs = getEnvList("ADMIN_USER_IDS", cfg.AdminUserIDs, nil)
+	cfg.DevTokens = getEnvBool("DEV_TOKENS", cfg.DevTokens)
+	cfg.AccountDeletionGrace = getEnvDuration("ACCOUNT_DELETION_GRACE", cfg.AccountDeletionGrace, 30*24*time.Hour)
+
+	cfg.Sessions.MaxPerUser = getEnvInt("SESSIONS_MAX_PER_USER", cfg.Sessions.MaxPerUser, 0)
+	cfg.Sessions.LimitPolicy = getEnv("SESSIONS_LIMIT_POLICY", cfg.Sessions.LimitPolicy, "evict_oldest")
+	cfg.Sessions.IdleTimeout = getEnvDuration("SESSIONS_IDLE_TIMEOUT", cfg.Sessions.IdleTimeout, 0)
+
+	cfg.Cleanup.TokensInterval = getEnvDuration("CLEANUP_TOKENS_INTERVAL", cfg.Cleanup.TokensInterval, 10*time.Minute)
+	cfg.Cleanup.CodesInterval = getEnvDuration("CLEANUP_CODES_INTERVAL", cfg.Cleanup.CodesInterval, 5*time.Minute)
+	cfg.Cleanup.AccountsInterval = getEnvDuration("CLEANUP_ACCOUNTS_INTERVAL", cfg.Cleanup.AccountsInterval, time.Hour)
+	cfg.Cleanup.BatchSize = getEnvInt("CLEANUP_BATCH_SIZE", cfg.Cleanup.BatchSize, 500)
+
+	cfg.GeoIP.CityDB = getEnv("GEOIP_CITY_DB", cfg.GeoIP.CityDB, "")
+	cfg.GeoIP.ASNDB = getEnv("GEOIP_ASN_DB", cfg.GeoIP.ASNDB, "")
+	cfg.GeoIP.ReloadInterval = getEnvDuration("GEOIP_RELOAD_INTERVAL", cfg.GeoIP.ReloadInterval, time.Minute)
+
+	cfg.IPChange.Policy = getEnv("IP_CHANGE_POLICY", cfg.IPChange.Policy, "notify")
+	cfg.IPChange.SameSubnet = getEnvBool("IP_CHANGE_SAME_SUBNET", cfg.IPChange.SameSubnet)
+	cfg.IPChange.SameASN = getEnvBool("IP_CHANGE_SAME_ASN", cfg.IPChange.SameASN)
+	cfg.IPChange.SameCountry = getEnvBool("IP_CHANGE_SAME_COUNTRY", cfg.IPChange.SameCountry)
+	cfg.IPChange.AllowedNetworks = getEnvList("IP_CHANGE_ALLOWED_NETWORKS", cfg.IPChange.AllowedNetworks, nil)
+
+	cfg.LDAP.URL = getEnv("LDAP_URL", cfg.LDAP.URL, "")
+	cfg.LDAP.BindDN = getEnv("LDAP_BIND_DN", cfg.LDAP.BindDN, "")
+	cfg.LDAP.BindPassword = getEnv("LDAP_BIND_PASSWORD", cfg.LDAP.BindPassword, "")
+	cfg.LDAP.UserBaseDN = getEnv("LDAP_USER_BASE_DN", cfg.LDAP.UserBaseDN, "")
+	cfg.LDAP.UserFilter = getEnv("LDAP_USER_FILTER", cfg.LDAP.UserFilter, "(&(objectClass=person)(mail=%s))")
+	cfg.LDAP.GroupBaseDN = getEnv("LDAP_GROUP_BASE_DN", cfg.LDAP.GroupBaseDN, cfg.LDAP.UserBaseDN)
+	cfg.LDAP.GroupFilter = getEnv("LDAP_GROUP_FILTER", cfg.LDAP.GroupFilter, "(&(objectClass=groupOfNames)(member=%s))")
+	cfg.LDAP.IDAttribute = getEnv("LDAP_ID_ATTRIBUTE", cfg.LDAP.IDAttribute, "entryUUID")
+	cfg.LDAP.Domains = getEnvList("LDAP_DOMAINS", cfg.LDAP.Domains, nil)
+	cfg.LDAP.Timeout = getEnvDuration("LDAP_TIMEOUT", cfg.LDAP.Timeout, 5*time.Second)
+
+	for i, provider := range cfg.FederatedProviders {
+		envName := "FEDERATED_" + strings.ToUpper(strings.ReplaceAll(provider.Name, "-", "_")) + "_CLIENT_SECRET"
+		cfg.FederatedProviders[i].ClientSecret = getEnv(envName, provider.ClientSecret, "")
+	}
+
+	for i, tenant := range cfg.Tenants {
+		envName := "TENANT_" + strings.ToUpper(strings.ReplaceAll(tenant.ID, "-", "_")) + "_JWT_SECRET"
+		cfg.Tenants[i].JWTSecret = getEnv(envName, tenant.JWTSecret, "")
+	}
 
 	return cfg, nil
 }
@@ -46,3 +230,52 @@ func getEnv(key, current, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+func getEnvList(key string, current, defaultValue []string) []string {
+	if value, exist := os.LookupEnv(key); exist {
+		var items []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		return items
+	}
+	if len(current) > 0 {
+		return current
+	}
+	return defaultValue
+}
+
+func getEnvInt(key string, current, defaultValue int) int {
+	if value, exist := os.LookupEnv(key); exist {
+		if parsed, err := strconv.Atoi(value); err == nil {
+			return parsed
+		}
+	}
+	if current != 0 {
+		return current
+	}
+	return defaultValue
+}
+
+func getEnvBool(key string, current bool) bool {
+	if value, exist := os.LookupEnv(key); exist {
+		if parsed, err := strconv.ParseBool(value); err == nil {
+			return parsed
+		}
+	}
+	return current
+}
+
+func getEnvDuration(key string, current, defaultValue time.Duration) time.Duration {
+	if value, exist := os.LookupEnv(key); exist {
+		if parsed, err := time.ParseDuration(value); err == nil {
+			return parsed
+		}
+	}
+	if current != 0 {
+		return current
+	}
+	return defaultValue
+}
@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"errors"
+	"net"
+	"net/http"
+
+	"github.com/auth-service/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type AccountHandler struct {
+	accountService services.AccountServiceInterface
+}
+
+func NewAccountHandler(accountService services.AccountServiceInterface) *AccountHandler {
+	return &AccountHandler{accountService: accountService}
+}
+
+// Export returns the personal data of the current user as a JSON download.
+func (h *AccountHandler) Export(c *gin.Context) {
+	export, err := h.accountService.Export(c.Request.Context(), c.GetString("user_id"), net.ParseIP(c.ClientIP()))
+	if err != nil {
+		if errors.Is(err, services.ErrUserNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to export account"})
+		}
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.Header("Content-Disposition", `attachment; filename="account-export.json"`)
+	c.JSON(http.StatusOK, export)
+}
+
+// Delete schedules the account of the current user for deletion and logs
+// it out everywhere.
+func (h *AccountHandler) Delete(c *gin.Context) {
+	deleteAfter, err := h.accountService.ScheduleDeletion(c.Request.Context(), c.GetString("user_id"), net.ParseIP(c.ClientIP()))
+	if err != nil {
+		if errors.Is(err, services.ErrUserNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete account"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"status": "scheduled", "delete_after": deleteAfter})
+}
+
+func (h *AccountHandler) CancelDeletion(c *gin.Context) {
+	err := h.accountService.CancelDeletion(c.Request.Context(), c.GetString("user_id"), net.ParseIP(c.ClientIP()))
+	if err != nil {
+		if errors.Is(err, services.ErrNoDeletionScheduled) {
+			c.JSON(http.StatusConflict, gin.H{"error": "account deletion is not scheduled"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to cancel deletion"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "cancelled"})
+}
@@ -0,0 +1,70 @@
+package handlers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/auth-service/internal/handlers"
+	"github.com/auth-service/internal/models"
+	"github.com/auth-service/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccountHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAccounts := services.NewMockAccountServiceInterface(ctrl)
+	handler := handlers.NewAccountHandler(mockAccounts)
+
+	t.Run("Export", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("POST", "/api/me/export", nil)
+		c.Request.RemoteAddr = "192.168.1.1:1234"
+		c.Set("user_id", "user1")
+
+		mockAccounts.EXPECT().Export(gomock.Any(), "user1", gomock.Any()).
+			Return(&services.AccountExport{Profile: &models.User{ID: "user1"}}, nil)
+
+		handler.Export(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Header().Get("Content-Disposition"), "attachment")
+		assert.Contains(t, w.Body.String(), `"profile":{"id":"user1"`)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("DELETE", "/api/me", nil)
+		c.Request.RemoteAddr = "192.168.1.1:1234"
+		c.Set("user_id", "user1")
+
+		mockAccounts.EXPECT().ScheduleDeletion(gomock.Any(), "user1", gomock.Any()).
+			Return(time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC), nil)
+
+		handler.Delete(c)
+
+		assert.Equal(t, http.StatusAccepted, w.Code)
+		assert.Contains(t, w.Body.String(), `"delete_after":"2030-01-01T00:00:00Z"`)
+	})
+
+	t.Run("CancelDeletion when nothing is scheduled", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("POST", "/api/me/cancel-deletion", nil)
+		c.Request.RemoteAddr = "192.168.1.1:1234"
+		c.Set("user_id", "user1")
+
+		mockAccounts.EXPECT().CancelDeletion(gomock.Any(), "user1", gomock.Any()).Return(services.ErrNoDeletionScheduled)
+
+		handler.CancelDeletion(c)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+}
@@ -0,0 +1,29 @@
+package handlers
+
+import (
+	"net"
+	"net/http"
+
+	"github.com/auth-service/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type AdminHandler struct {
+	lockoutService services.LockoutServiceInterface
+}
+
+func NewAdminHandler(lockoutService services.LockoutServiceInterface) *AdminHandler {
+	return &AdminHandler{lockoutService: lockoutService}
+}
+
+func (h *AdminHandler) UnlockUser(c *gin.Context) {
+	userID := c.Param("id")
+	adminID := c.GetString("user_id")
+
+	if err := h.lockoutService.Unlock(c.Request.Context(), userID, adminID, net.ParseIP(c.ClientIP())); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to unlock user"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "unlocked"})
+}
@@ -0,0 +1,38 @@
+package handlers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/auth-service/internal/handlers"
+	"github.com/auth-service/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdminHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockLockout := services.NewMockLockoutServiceInterface(ctrl)
+	handler := handlers.NewAdminHandler(mockLockout)
+
+	t.Run("UnlockUser", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("POST", "/admin/users/user1/unlock", nil)
+		c.Request.RemoteAddr = "192.168.1.1:1234"
+		c.Params = gin.Params{{Key: "id", Value: "user1"}}
+		c.Set("user_id", "admin1")
+
+		mockLockout.EXPECT().
+			Unlock(gomock.Any(), "user1", "admin1", gomock.Any()).
+			Return(nil)
+
+		handler.UnlockUser(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+}
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/auth-service/internal/models"
+	"github.com/auth-service/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type APIKeyHandler struct {
+	apiKeyService services.APIKeyServiceInterface
+}
+
+func NewAPIKeyHandler(apiKeyService services.APIKeyServiceInterface) *APIKeyHandler {
+	return &APIKeyHandler{apiKeyService: apiKeyService}
+}
+
+type createAPIKeyRequest struct {
+	Name   string   `json:"name" binding:"required"`
+	Scopes []string `json:"scopes"`
+	// ExpiresAt is optional, keys without it do not expire.
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+type apiKeyResponse struct {
+	*models.APIKey
+	Key string `json:"api_key"`
+}
+
+// CreateKey issues a key for the current user. The full key is only shown in
+// this response.
+func (h *APIKeyHandler) CreateKey(c *gin.Context) {
+	var req createAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request format"})
+		return
+	}
+
+	key, rawKey, err := h.apiKeyService.Create(
+		c.Request.Context(), c.GetString("user_id"), req.Name, req.Scopes, req.ExpiresAt, net.ParseIP(c.ClientIP()),
+	)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidAPIKeyRequest) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create API key"})
+		}
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusCreated, apiKeyResponse{APIKey: key, Key: rawKey})
+}
+
+func (h *APIKeyHandler) ListKeys(c *gin.Context) {
+	keys, err := h.apiKeyService.List(c.Request.Context(), c.GetString("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list API keys"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"api_keys": keys})
+}
+
+func (h *APIKeyHandler) RevokeKey(c *gin.Context) {
+	err := h.apiKeyService.Revoke(c.Request.Context(), c.GetString("user_id"), c.Param("id"), net.ParseIP(c.ClientIP()))
+	if err != nil {
+		if errors.Is(err, services.ErrAPIKeyNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "API key not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to revoke API key"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "revoked"})
+}
@@ -0,0 +1,94 @@
+package handlers_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/auth-service/internal/handlers"
+	"github.com/auth-service/internal/models"
+	"github.com/auth-service/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIKeyHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockKeys := services.NewMockAPIKeyServiceInterface(ctrl)
+	handler := handlers.NewAPIKeyHandler(mockKeys)
+
+	t.Run("CreateKey", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("POST", "/api/keys", bytes.NewBufferString(
+			`{"name": "CI", "scopes": ["profile:read"], "expires_at": "2030-01-01T00:00:00Z"}`,
+		))
+		c.Request.RemoteAddr = "192.168.1.1:1234"
+		c.Set("user_id", "user1")
+
+		mockKeys.EXPECT().
+			Create(gomock.Any(), "user1", "CI", []string{"profile:read"}, gomock.Not(gomock.Nil()), gomock.Any()).
+			Return(&models.APIKey{ID: "key1", Prefix: "0123abcd4567", SecretHash: "hash"}, "0123abcd4567.secret", nil)
+
+		handler.CreateKey(c)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+		assert.Contains(t, w.Body.String(), `"api_key":"0123abcd4567.secret"`)
+		assert.NotContains(t, w.Body.String(), "hash")
+	})
+
+	t.Run("CreateKey invalid request", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("POST", "/api/keys", bytes.NewBufferString(`{"name": "CI", "scopes": ["a b"]}`))
+		c.Request.RemoteAddr = "192.168.1.1:1234"
+		c.Set("user_id", "user1")
+
+		mockKeys.EXPECT().
+			Create(gomock.Any(), "user1", "CI", []string{"a b"}, nil, gomock.Any()).
+			Return(nil, "", services.ErrInvalidAPIKeyRequest)
+
+		handler.CreateKey(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("ListKeys", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/api/keys", nil)
+		c.Set("user_id", "user1")
+
+		mockKeys.EXPECT().
+			List(gomock.Any(), "user1").
+			Return([]models.APIKey{{ID: "key1", Name: "CI", SecretHash: "hash"}}, nil)
+
+		handler.ListKeys(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"name":"CI"`)
+		assert.NotContains(t, w.Body.String(), "hash")
+	})
+
+	t.Run("RevokeKey unknown key", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("DELETE", "/api/keys/missing", nil)
+		c.Request.RemoteAddr = "192.168.1.1:1234"
+		c.Params = gin.Params{{Key: "id", Value: "missing"}}
+		c.Set("user_id", "user1")
+
+		mockKeys.EXPECT().
+			Revoke(gomock.Any(), "user1", "missing", gomock.Any()).
+			Return(services.ErrAPIKeyNotFound)
+
+		handler.RevokeKey(c)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
@@ -1,8 +1,12 @@
 package handlers
 
 import (
+	"errors"
+	"log"
+	"math"
 	"net"
 	"net/http"
+	"strconv"
 
 	"github.com/auth-service/internal/services"
 	"github.com/gin-gonic/gin"
@@ -35,19 +39,75 @@ func (h *AuthHandler) GenerateTokens(c *gin.Context) {
 
 	tokens, err := h.authService.GenerateTokens(c.Request.Context(), userID, ip)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeIssueError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, tokens)
 }
 
+// Logout ends the session of the access token of the request.
 func (h *AuthHandler) Logout(c *gin.Context) {
 	userID := c.GetString("user_id")
-	err := h.authService.RevokeAllTokens(c.Request.Context(), userID)
-	if err != nil {
-		c.JSON(500, gin.H{"error": "logout failed"})
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	if err := h.authService.Logout(c.Request.Context(), userID, accessTokenRef(c)); err != nil {
+		log.Printf("Logout failed for user %s: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "logout failed"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "logged out"})
+}
+
+// LogoutAll ends every session of the user.
+func (h *AuthHandler) LogoutAll(c *gin.Context) {
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	if err := h.authService.LogoutAll(c.Request.Context(), userID, accessTokenRef(c)); err != nil {
+		log.Printf("Logout from all sessions failed for user %s: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "logout failed"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "logged out"})
+}
+
+func accessTokenRef(c *gin.Context) services.AccessTokenRef {
+	return services.AccessTokenRef{
+		ID:        c.GetString("token_id"),
+		SessionID: c.GetString("session_id"),
+		ExpiresAt: c.GetTime("expires_at"),
+	}
+}
+
+// writeLockedError answers with 429 and Retry-After when err is a lockout.
+func writeLockedError(c *gin.Context, err error) bool {
+	var locked *services.LockedError
+	if !errors.As(err, &locked) {
+		return false
+	}
+
+	retryAfter := int(math.Ceil(locked.RetryAfter.Seconds()))
+	c.Header("Retry-After", strconv.Itoa(retryAfter))
+	c.JSON(http.StatusTooManyRequests, gin.H{
+		"error":       "too many failed attempts",
+		"retry_after": retryAfter,
+	})
+	return true
+}
+
+// writeIssueError answers a failed token issue, with 409 when the login was
+// refused because the user has too many sessions.
+func writeIssueError(c *gin.Context, err error) {
+	if errors.Is(err, services.ErrSessionLimit) {
+		c.JSON(http.StatusConflict, gin.H{"error": "too many active sessions"})
 		return
 	}
-	c.JSON(200, gin.H{"status": "logged out"})
+	c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 }
@@ -0,0 +1,240 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"html/template"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/auth-service/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	authorizeCSRFCookie = "oauth_csrf"
+	authorizeCookiePath = "/oauth/authorize"
+)
+
+var authorizePage = template.Must(template.New("authorize").Parse(`<!DOCTYPE html>
+<html lang="ru">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Вход</title>
+</head>
+<body>
+{{if .Error}}<p role="alert">{{.Error}}</p>{{end}}
+{{if .Client}}
+<h1>Вход в {{.Client}}</h1>
+{{if .Scopes}}<p>Приложение запрашивает доступ:</p>
+<ul>{{range .Scopes}}<li>{{.}}</li>{{end}}</ul>{{end}}
+<form method="post" action="authorize">
+{{range $name, $value := .Params}}<input type="hidden" name="{{$name}}" value="{{$value}}">
+{{end}}<input type="hidden" name="csrf_token" value="{{.CSRF}}">
+<label>Email <input type="email" name="email" value="{{.Email}}" autocomplete="username" required></label>
+<label>Пароль <input type="password" name="password" autocomplete="current-password" required></label>
+<button type="submit" name="action" value="allow">Разрешить</button>
+<button type="submit" name="action" value="deny" formnovalidate>Отклонить</button>
+</form>
+{{end}}
+</body>
+</html>
+`))
+
+type authorizePageData struct {
+	Client string
+	Scopes []string
+	Params map[string]string
+	CSRF   string
+	Email  string
+	Error  string
+}
+
+type AuthorizeHandler struct {
+	oauthService  services.OAuthServiceInterface
+	authenticator services.Authenticator
+	secureCookies bool
+}
+
+func NewAuthorizeHandler(
+	oauthService services.OAuthServiceInterface,
+	authenticator services.Authenticator,
+	secureCookies bool,
+) *AuthorizeHandler {
+	return &AuthorizeHandler{
+		oauthService:  oauthService,
+		authenticator: authenticator,
+		secureCookies: secureCookies,
+	}
+}
+
+// Authorize validates an authorization request and shows the login and
+// consent page.
+func (h *AuthorizeHandler) Authorize(c *gin.Context) {
+	auth, ok := h.validate(c, c.Query)
+	if !ok {
+		return
+	}
+
+	csrf, err := generateCSRFToken()
+	if err != nil {
+		h.renderPage(c, http.StatusInternalServerError, authorizePageData{Error: "Внутренняя ошибка, попробуйте позже"})
+		return
+	}
+	// The page posts back to the path it was served on, tenant prefix
+	// included, so the cookie is scoped to that path.
+	path := services.TenantPath(c.Request.Context(), authorizeCookiePath)
+	c.SetSameSite(http.SameSiteStrictMode)
+	c.SetCookie(authorizeCSRFCookie, csrf, int(time.Hour.Seconds()), path, "", h.secureCookies, true)
+
+	h.renderForm(c, http.StatusOK, auth, csrf, "", "")
+}
+
+// Decide handles the submitted login and consent form and redirects back to
+// the client with an authorization code or an error.
+func (h *AuthorizeHandler) Decide(c *gin.Context) {
+	auth, ok := h.validate(c, c.PostForm)
+	if !ok {
+		return
+	}
+
+	csrf, _ := c.Cookie(authorizeCSRFCookie)
+	if csrf == "" || subtle.ConstantTimeCompare([]byte(csrf), []byte(c.PostForm("csrf_token"))) != 1 {
+		h.renderPage(c, http.StatusForbidden, authorizePageData{Error: "Сессия входа устарела, начните вход заново"})
+		return
+	}
+
+	if c.PostForm("action") != "allow" {
+		h.clearCSRFCookie(c)
+		c.Redirect(http.StatusFound, auth.ErrorRedirect(&services.OAuthError{Code: services.OAuthAccessDenied}))
+		return
+	}
+
+	ip := net.ParseIP(c.ClientIP())
+	email := c.PostForm("email")
+	user, err := h.authenticator.Authenticate(c.Request.Context(), email, c.PostForm("password"), ip)
+	if err != nil {
+		var locked *services.LockedError
+		switch {
+		case errors.As(err, &locked):
+			h.renderForm(c, http.StatusTooManyRequests, auth, csrf, email, "Слишком много неудачных попыток, попробуйте позже")
+		case errors.Is(err, services.ErrInvalidCredentials):
+			h.renderForm(c, http.StatusUnauthorized, auth, csrf, email, "Неверный email или пароль")
+		default:
+			log.Printf("Authorization login failed for client %s: %v", auth.Client.ClientID, err)
+			h.renderForm(c, http.StatusInternalServerError, auth, csrf, email, "Внутренняя ошибка, попробуйте позже")
+		}
+		return
+	}
+
+	redirect, err := h.oauthService.IssueCode(c.Request.Context(), auth, services.Authentication{
+		UserID:  user.ID,
+		Time:    time.Now(),
+		Methods: []string{services.AMRPassword},
+	}, ip)
+	if err != nil {
+		log.Printf("Failed to issue authorization code for client %s: %v", auth.Client.ClientID, err)
+		c.Redirect(http.StatusFound, auth.ErrorRedirect(&services.OAuthError{Code: services.OAuthServerError}))
+		return
+	}
+
+	h.clearCSRFCookie(c)
+	c.Redirect(http.StatusFound, redirect)
+}
+
+// validate checks the authorization request read with param. Errors that
+// cannot be sent to a trusted redirect URI are shown on the page.
+func (h *AuthorizeHandler) validate(c *gin.Context, param func(string) string) (*services.Authorization, bool) {
+	auth, err := h.oauthService.ValidateAuthorization(c.Request.Context(), services.AuthorizeRequest{
+		ResponseType:        param("response_type"),
+		ClientID:            param("client_id"),
+		RedirectURI:         param("redirect_uri"),
+		Scope:               param("scope"),
+		State:               param("state"),
+		Nonce:               param("nonce"),
+		CodeChallenge:       param("code_challenge"),
+		CodeChallengeMethod: param("code_challenge_method"),
+	})
+	if err == nil {
+		return auth, true
+	}
+
+	var oauthErr *services.OAuthError
+	switch {
+	case !errors.As(err, &oauthErr):
+		log.Printf("Failed to validate authorization request: %v", err)
+		h.renderPage(c, http.StatusInternalServerError, authorizePageData{Error: "Внутренняя ошибка, попробуйте позже"})
+	case auth == nil:
+		h.renderPage(c, http.StatusBadRequest, authorizePageData{Error: "Некорректный запрос авторизации: " + oauthErr.Description})
+	default:
+		c.Redirect(http.StatusFound, auth.ErrorRedirect(oauthErr))
+	}
+	return nil, false
+}
+
+func (h *AuthorizeHandler) renderForm(c *gin.Context, status int, auth *services.Authorization, csrf, email, message string) {
+	params := map[string]string{
+		"response_type":         "code",
+		"client_id":             auth.Client.ClientID,
+		"redirect_uri":          auth.RedirectURI,
+		"scope":                 auth.Scope,
+		"state":                 auth.State,
+		"nonce":                 auth.Nonce,
+		"code_challenge":        auth.CodeChallenge,
+		"code_challenge_method": services.CodeChallengeMethodS256,
+	}
+	for name, value := range params {
+		if value == "" {
+			delete(params, name)
+		}
+	}
+
+	h.renderPage(c, status, authorizePageData{
+		Client: auth.Client.Name,
+		Scopes: strings.Fields(auth.Scope),
+		Params: params,
+		CSRF:   csrf,
+		Email:  email,
+		Error:  message,
+	})
+}
+
+func (h *AuthorizeHandler) renderPage(c *gin.Context, status int, data authorizePageData) {
+	renderHTML(c, status, authorizePage, data)
+}
+
+// renderHTML renders a login page that must not be cached or framed.
+func renderHTML(c *gin.Context, status int, page *template.Template, data interface{}) {
+	var buf bytes.Buffer
+	if err := page.Execute(&buf, data); err != nil {
+		log.Printf("Failed to render %s page: %v", page.Name(), err)
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.Header("X-Frame-Options", "DENY")
+	c.Header("Content-Security-Policy", "default-src 'none'; frame-ancestors 'none'")
+	c.Data(status, "text/html; charset=utf-8", buf.Bytes())
+}
+
+func (h *AuthorizeHandler) clearCSRFCookie(c *gin.Context) {
+	c.SetSameSite(http.SameSiteStrictMode)
+	path := services.TenantPath(c.Request.Context(), authorizeCookiePath)
+	c.SetCookie(authorizeCSRFCookie, "", -1, path, "", h.secureCookies, true)
+}
+
+func generateCSRFToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,194 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/auth-service/internal/handlers"
+	"github.com/auth-service/internal/models"
+	"github.com/auth-service/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthorizeHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockOAuth := services.NewMockOAuthServiceInterface(ctrl)
+	mockAuthenticator := services.NewMockAuthenticator(ctrl)
+	handler := handlers.NewAuthorizeHandler(mockOAuth, mockAuthenticator, false)
+
+	auth := &services.Authorization{
+		Client:        &models.OAuthClient{ClientID: "spa", Name: "Web <app>"},
+		RedirectURI:   "https://app.example/callback",
+		Scope:         "openid profile",
+		State:         "xyz",
+		CodeChallenge: "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM",
+	}
+
+	form := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {"spa"},
+		"redirect_uri":          {"https://app.example/callback"},
+		"scope":                 {"openid profile"},
+		"state":                 {"xyz"},
+		"code_challenge":        {auth.CodeChallenge},
+		"code_challenge_method": {"S256"},
+		"csrf_token":            {"csrf"},
+		"email":                 {"user@example.com"},
+		"password":              {"secret"},
+		"action":                {"allow"},
+	}
+
+	submit := func(values url.Values, csrfCookie string) (*httptest.ResponseRecorder, *gin.Context) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("POST", "/oauth/authorize", bytes.NewBufferString(values.Encode()))
+		c.Request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		c.Request.RemoteAddr = "192.168.1.1:1234"
+		if csrfCookie != "" {
+			c.Request.AddCookie(&http.Cookie{Name: "oauth_csrf", Value: csrfCookie})
+		}
+		return w, c
+	}
+
+	t.Run("Login page", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/oauth/authorize?"+form.Encode(), nil)
+
+		mockOAuth.EXPECT().
+			ValidateAuthorization(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ interface{}, req services.AuthorizeRequest) (*services.Authorization, error) {
+				assert.Equal(t, "spa", req.ClientID)
+				assert.Equal(t, "S256", req.CodeChallengeMethod)
+				return auth, nil
+			})
+
+		handler.Authorize(c)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "DENY", w.Header().Get("X-Frame-Options"))
+		assert.Contains(t, w.Header().Get("Set-Cookie"), "oauth_csrf=")
+		assert.Contains(t, w.Body.String(), "Web &lt;app&gt;")
+		assert.Contains(t, w.Body.String(), `name="code_challenge" value="E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"`)
+	})
+
+	t.Run("Login page of a path tenant", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/oauth/authorize?"+form.Encode(), nil)
+		ctx := services.WithTenantPath(services.WithTenant(c.Request.Context(), "acme"), "/t/acme")
+		c.Request = c.Request.WithContext(ctx)
+
+		mockOAuth.EXPECT().ValidateAuthorization(gomock.Any(), gomock.Any()).Return(auth, nil)
+
+		handler.Authorize(c)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Header().Get("Set-Cookie"), "Path=/t/acme/oauth/authorize")
+		assert.Contains(t, w.Body.String(), `action="authorize"`)
+	})
+
+	t.Run("Unknown client is not redirected", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/oauth/authorize?client_id=evil", nil)
+
+		mockOAuth.EXPECT().
+			ValidateAuthorization(gomock.Any(), gomock.Any()).
+			Return(nil, &services.OAuthError{Code: services.OAuthInvalidRequest, Description: "unknown client"})
+
+		handler.Authorize(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Empty(t, w.Header().Get("Location"))
+	})
+
+	t.Run("Allow", func(t *testing.T) {
+		w, c := submit(form, "csrf")
+
+		mockOAuth.EXPECT().ValidateAuthorization(gomock.Any(), gomock.Any()).Return(auth, nil)
+		mockAuthenticator.EXPECT().
+			Authenticate(gomock.Any(), "user@example.com", "secret", gomock.Any()).
+			Return(&models.User{ID: "user1"}, nil)
+		mockOAuth.EXPECT().
+			IssueCode(gomock.Any(), auth, gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ *services.Authorization, authn services.Authentication, _ net.IP) (string, error) {
+				assert.Equal(t, "user1", authn.UserID)
+				assert.Equal(t, []string{services.AMRPassword}, authn.Methods)
+				return "https://app.example/callback?code=abc&state=xyz", nil
+			})
+
+		handler.Decide(c)
+
+		assert.Equal(t, http.StatusFound, c.Writer.Status())
+		assert.Equal(t, "https://app.example/callback?code=abc&state=xyz", w.Header().Get("Location"))
+	})
+
+	t.Run("Deny", func(t *testing.T) {
+		values := url.Values{}
+		for key, value := range form {
+			values[key] = value
+		}
+		values.Set("action", "deny")
+		w, c := submit(values, "csrf")
+
+		mockOAuth.EXPECT().ValidateAuthorization(gomock.Any(), gomock.Any()).Return(auth, nil)
+
+		handler.Decide(c)
+
+		assert.Equal(t, http.StatusFound, c.Writer.Status())
+		assert.Equal(t, "https://app.example/callback?error=access_denied&state=xyz", w.Header().Get("Location"))
+	})
+
+	t.Run("Wrong password", func(t *testing.T) {
+		w, c := submit(form, "csrf")
+
+		mockOAuth.EXPECT().ValidateAuthorization(gomock.Any(), gomock.Any()).Return(auth, nil)
+		mockAuthenticator.EXPECT().
+			Authenticate(gomock.Any(), "user@example.com", "secret", gomock.Any()).
+			Return(nil, services.ErrInvalidCredentials)
+
+		handler.Decide(c)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Contains(t, w.Body.String(), `value="user@example.com"`)
+	})
+
+	t.Run("Missing CSRF cookie", func(t *testing.T) {
+		w, c := submit(form, "")
+
+		mockOAuth.EXPECT().ValidateAuthorization(gomock.Any(), gomock.Any()).Return(auth, nil)
+
+		handler.Decide(c)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("Code cannot be stored", func(t *testing.T) {
+		w, c := submit(form, "csrf")
+
+		mockOAuth.EXPECT().ValidateAuthorization(gomock.Any(), gomock.Any()).Return(auth, nil)
+		mockAuthenticator.EXPECT().
+			Authenticate(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(&models.User{ID: "user1"}, nil)
+		mockOAuth.EXPECT().
+			IssueCode(gomock.Any(), auth, gomock.Any(), gomock.Any()).
+			Return("", errors.New("db is down"))
+
+		handler.Decide(c)
+
+		assert.Equal(t, http.StatusFound, c.Writer.Status())
+		assert.Contains(t, w.Header().Get("Location"), "error=server_error")
+	})
+}
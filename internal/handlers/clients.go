@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/auth-service/internal/models"
+	"github.com/auth-service/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type ClientHandler struct {
+	clientService services.ClientServiceInterface
+}
+
+func NewClientHandler(clientService services.ClientServiceInterface) *ClientHandler {
+	return &ClientHandler{clientService: clientService}
+}
+
+type createClientRequest struct {
+	Name         string   `json:"name" binding:"required"`
+	Public       bool     `json:"public"`
+	GrantTypes   []string `json:"grant_types" binding:"required"`
+	RedirectURIs []string `json:"redirect_uris"`
+	Scopes       []string `json:"scopes"`
+	// Lifetimes in seconds, 0 means the service default.
+	AccessTokenTTL  int `json:"access_token_ttl"`
+	RefreshTokenTTL int `json:"refresh_token_ttl"`
+	// MaxSessions caps the sessions of a user with the client, 0 means
+	// no cap.
+	MaxSessions int `json:"max_sessions"`
+	// IdleTimeout in seconds ends sessions with the client that were not
+	// refreshed for that long, 0 means the service default.
+	IdleTimeout int `json:"idle_timeout"`
+	// IPChangePolicy is allow, notify, step_up or deny for sessions with
+	// the client, empty for the service default.
+	IPChangePolicy string `json:"ip_change_policy"`
+}
+
+type clientResponse struct {
+	*models.OAuthClient
+	ClientSecret string `json:"client_secret,omitempty"`
+}
+
+// CreateClient registers a client. The secret is only shown in this
+// response.
+func (h *ClientHandler) CreateClient(c *gin.Context) {
+	var req createClientRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request format"})
+		return
+	}
+
+	client, secret, err := h.clientService.CreateClient(c.Request.Context(), services.ClientRegistration{
+		Name:            req.Name,
+		Public:          req.Public,
+		GrantTypes:      req.GrantTypes,
+		RedirectURIs:    req.RedirectURIs,
+		Scopes:          req.Scopes,
+		AccessTokenTTL:  time.Duration(req.AccessTokenTTL) * time.Second,
+		RefreshTokenTTL: time.Duration(req.RefreshTokenTTL) * time.Second,
+		MaxSessions:     req.MaxSessions,
+		IdleTimeout:     time.Duration(req.IdleTimeout) * time.Second,
+		IPChangePolicy:  req.IPChangePolicy,
+	}, c.GetString("user_id"), net.ParseIP(c.ClientIP()))
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidRegistration) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create client"})
+		}
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusCreated, clientResponse{OAuthClient: client, ClientSecret: secret})
+}
+
+func (h *ClientHandler) RotateSecret(c *gin.Context) {
+	clientID := c.Param("client_id")
+
+	secret, err := h.clientService.RotateSecret(c.Request.Context(), clientID, c.GetString("user_id"), net.ParseIP(c.ClientIP()))
+	if err != nil {
+		h.writeError(c, err, "failed to rotate client secret")
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, gin.H{"client_id": clientID, "client_secret": secret})
+}
+
+func (h *ClientHandler) DisableClient(c *gin.Context) {
+	clientID := c.Param("client_id")
+
+	if err := h.clientService.DisableClient(c.Request.Context(), clientID, c.GetString("user_id"), net.ParseIP(c.ClientIP())); err != nil {
+		h.writeError(c, err, "failed to disable client")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "disabled"})
+}
+
+func (h *ClientHandler) writeError(c *gin.Context, err error, message string) {
+	if errors.Is(err, services.ErrClientNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "client not found"})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+}
@@ -0,0 +1,84 @@
+package handlers_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/auth-service/internal/handlers"
+	"github.com/auth-service/internal/models"
+	"github.com/auth-service/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockClients := services.NewMockClientServiceInterface(ctrl)
+	handler := handlers.NewClientHandler(mockClients)
+
+	t.Run("CreateClient", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("POST", "/admin/clients", bytes.NewBufferString(
+			`{"name": "billing", "grant_types": ["client_credentials"], "scopes": ["invoices:read"], "access_token_ttl": 300}`,
+		))
+		c.Request.RemoteAddr = "192.168.1.1:1234"
+		c.Set("user_id", "admin1")
+
+		mockClients.EXPECT().
+			CreateClient(gomock.Any(), services.ClientRegistration{
+				Name:           "billing",
+				GrantTypes:     []string{"client_credentials"},
+				Scopes:         []string{"invoices:read"},
+				AccessTokenTTL: 5 * time.Minute,
+			}, "admin1", gomock.Any()).
+			Return(&models.OAuthClient{ClientID: "client1", SecretHash: "hash"}, "secret", nil)
+
+		handler.CreateClient(c)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+		assert.Contains(t, w.Body.String(), `"client_secret":"secret"`)
+		assert.NotContains(t, w.Body.String(), "hash")
+	})
+
+	t.Run("RotateSecret unknown client", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("POST", "/admin/clients/missing/secret", nil)
+		c.Request.RemoteAddr = "192.168.1.1:1234"
+		c.Params = gin.Params{{Key: "client_id", Value: "missing"}}
+		c.Set("user_id", "admin1")
+
+		mockClients.EXPECT().
+			RotateSecret(gomock.Any(), "missing", "admin1", gomock.Any()).
+			Return("", services.ErrClientNotFound)
+
+		handler.RotateSecret(c)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("DisableClient", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("POST", "/admin/clients/client1/disable", nil)
+		c.Request.RemoteAddr = "192.168.1.1:1234"
+		c.Params = gin.Params{{Key: "client_id", Value: "client1"}}
+		c.Set("user_id", "admin1")
+
+		mockClients.EXPECT().
+			DisableClient(gomock.Any(), "client1", "admin1", gomock.Any()).
+			Return(nil)
+
+		handler.DisableClient(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+}
@@ -0,0 +1,196 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"errors"
+	"html/template"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/auth-service/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	deviceCSRFCookie = "device_csrf"
+	deviceCookiePath = "/oauth/device"
+)
+
+var devicePage = template.Must(template.New("device").Parse(`<!DOCTYPE html>
+<html lang="ru">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Подключение устройства</title>
+</head>
+<body>
+{{if .Error}}<p role="alert">{{.Error}}</p>{{end}}
+{{if .Done}}<p>{{.Done}}</p>
+{{else if .Client}}
+<h1>Вход в {{.Client}} на устройстве</h1>
+<p>Убедитесь, что на устройстве показан код <strong>{{.UserCode}}</strong>.</p>
+{{if .Scopes}}<p>Приложение запрашивает доступ:</p>
+<ul>{{range .Scopes}}<li>{{.}}</li>{{end}}</ul>{{end}}
+<form method="post" action="device">
+<input type="hidden" name="user_code" value="{{.UserCode}}">
+<input type="hidden" name="csrf_token" value="{{.CSRF}}">
+<label>Email <input type="email" name="email" value="{{.Email}}" autocomplete="username" required></label>
+<label>Пароль <input type="password" name="password" autocomplete="current-password" required></label>
+<button type="submit" name="action" value="allow">Разрешить</button>
+<button type="submit" name="action" value="deny">Отклонить</button>
+</form>
+{{else}}
+<h1>Подключение устройства</h1>
+<form method="get" action="device">
+<label>Код с экрана устройства <input type="text" name="user_code" value="{{.UserCode}}" autocomplete="off" autocapitalize="characters" required></label>
+<button type="submit">Продолжить</button>
+</form>
+{{end}}
+</body>
+</html>
+`))
+
+type devicePageData struct {
+	Client   string
+	Scopes   []string
+	UserCode string
+	CSRF     string
+	Email    string
+	Error    string
+	Done     string
+}
+
+// DeviceHandler serves the verification page of the device flow, where the
+// user enters the code shown on the device, logs in and approves it.
+type DeviceHandler struct {
+	oauthService  services.OAuthServiceInterface
+	authenticator services.Authenticator
+	secureCookies bool
+}
+
+func NewDeviceHandler(
+	oauthService services.OAuthServiceInterface,
+	authenticator services.Authenticator,
+	secureCookies bool,
+) *DeviceHandler {
+	return &DeviceHandler{
+		oauthService:  oauthService,
+		authenticator: authenticator,
+		secureCookies: secureCookies,
+	}
+}
+
+// Verify asks for the user code and, once it is known, shows the client
+// with the login form.
+func (h *DeviceHandler) Verify(c *gin.Context) {
+	userCode := c.Query("user_code")
+	if userCode == "" {
+		renderHTML(c, http.StatusOK, devicePage, devicePageData{})
+		return
+	}
+
+	verification, ok := h.lookup(c, userCode)
+	if !ok {
+		return
+	}
+
+	csrf, err := generateCSRFToken()
+	if err != nil {
+		renderHTML(c, http.StatusInternalServerError, devicePage, devicePageData{Error: "Внутренняя ошибка, попробуйте позже"})
+		return
+	}
+	// The page posts back to the path it was served on, tenant prefix
+	// included, so the cookie is scoped to that path.
+	path := services.TenantPath(c.Request.Context(), deviceCookiePath)
+	c.SetSameSite(http.SameSiteStrictMode)
+	c.SetCookie(deviceCSRFCookie, csrf, int(time.Hour.Seconds()), path, "", h.secureCookies, true)
+
+	h.renderForm(c, http.StatusOK, verification, csrf, "", "")
+}
+
+// Decide handles the submitted login form and approves or denies the
+// device. Both need the user to log in, so that only the user who owns the
+// code can turn the device away.
+func (h *DeviceHandler) Decide(c *gin.Context) {
+	verification, ok := h.lookup(c, c.PostForm("user_code"))
+	if !ok {
+		return
+	}
+
+	csrf, _ := c.Cookie(deviceCSRFCookie)
+	if csrf == "" || subtle.ConstantTimeCompare([]byte(csrf), []byte(c.PostForm("csrf_token"))) != 1 {
+		renderHTML(c, http.StatusForbidden, devicePage, devicePageData{Error: "Сессия входа устарела, введите код заново"})
+		return
+	}
+
+	ip := net.ParseIP(c.ClientIP())
+	email := c.PostForm("email")
+	user, err := h.authenticator.Authenticate(c.Request.Context(), email, c.PostForm("password"), ip)
+	if err != nil {
+		var locked *services.LockedError
+		switch {
+		case errors.As(err, &locked):
+			h.renderForm(c, http.StatusTooManyRequests, verification, csrf, email, "Слишком много неудачных попыток, попробуйте позже")
+		case errors.Is(err, services.ErrInvalidCredentials):
+			h.renderForm(c, http.StatusUnauthorized, verification, csrf, email, "Неверный email или пароль")
+		default:
+			log.Printf("Device login failed for client %s: %v", verification.Client.ClientID, err)
+			h.renderForm(c, http.StatusInternalServerError, verification, csrf, email, "Внутренняя ошибка, попробуйте позже")
+		}
+		return
+	}
+
+	if c.PostForm("action") != "allow" {
+		h.decide(c, verification.UserCode, user.ID, false, ip, "Подключение устройства отклонено")
+		return
+	}
+	h.decide(c, verification.UserCode, user.ID, true, ip, "Устройство подключено, вернитесь к нему")
+}
+
+func (h *DeviceHandler) decide(c *gin.Context, userCode, userID string, allow bool, ip net.IP, done string) {
+	if err := h.oauthService.DecideDevice(c.Request.Context(), userCode, userID, allow, ip); err != nil {
+		if errors.Is(err, services.ErrInvalidUserCode) {
+			renderHTML(c, http.StatusBadRequest, devicePage, devicePageData{Error: "Код неверный или устарел"})
+		} else {
+			log.Printf("Failed to decide device code: %v", err)
+			renderHTML(c, http.StatusInternalServerError, devicePage, devicePageData{Error: "Внутренняя ошибка, попробуйте позже"})
+		}
+		return
+	}
+
+	path := services.TenantPath(c.Request.Context(), deviceCookiePath)
+	c.SetSameSite(http.SameSiteStrictMode)
+	c.SetCookie(deviceCSRFCookie, "", -1, path, "", h.secureCookies, true)
+	renderHTML(c, http.StatusOK, devicePage, devicePageData{Done: done})
+}
+
+// lookup finds the pending authorization of the user code and shows the
+// code form again when there is none.
+func (h *DeviceHandler) lookup(c *gin.Context, userCode string) (*services.DeviceVerification, bool) {
+	verification, err := h.oauthService.LookupDeviceCode(c.Request.Context(), userCode)
+	if err == nil {
+		return verification, true
+	}
+
+	if errors.Is(err, services.ErrInvalidUserCode) {
+		renderHTML(c, http.StatusBadRequest, devicePage, devicePageData{UserCode: userCode, Error: "Код неверный или устарел"})
+	} else {
+		log.Printf("Failed to look up device code: %v", err)
+		renderHTML(c, http.StatusInternalServerError, devicePage, devicePageData{Error: "Внутренняя ошибка, попробуйте позже"})
+	}
+	return nil, false
+}
+
+func (h *DeviceHandler) renderForm(c *gin.Context, status int, verification *services.DeviceVerification, csrf, email, message string) {
+	renderHTML(c, status, devicePage, devicePageData{
+		Client:   verification.Client.Name,
+		Scopes:   strings.Fields(verification.Scope),
+		UserCode: verification.UserCode,
+		CSRF:     csrf,
+		Email:    email,
+		Error:    message,
+	})
+}
@@ -0,0 +1,166 @@
+package handlers_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/auth-service/internal/handlers"
+	"github.com/auth-service/internal/models"
+	"github.com/auth-service/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeviceHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockOAuth := services.NewMockOAuthServiceInterface(ctrl)
+	mockAuthenticator := services.NewMockAuthenticator(ctrl)
+	handler := handlers.NewDeviceHandler(mockOAuth, mockAuthenticator, false)
+
+	verification := &services.DeviceVerification{
+		UserCode: "WDJB-MJHT",
+		Client:   &models.OAuthClient{ClientID: "cli", Name: "CLI"},
+		Scope:    "invoices:read",
+	}
+
+	submit := func(values url.Values) (*httptest.ResponseRecorder, *gin.Context) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("POST", "/oauth/device", bytes.NewBufferString(values.Encode()))
+		c.Request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		c.Request.RemoteAddr = "192.168.1.1:1234"
+		c.Request.AddCookie(&http.Cookie{Name: "device_csrf", Value: "csrf"})
+		return w, c
+	}
+
+	t.Run("Code form", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/oauth/device", nil)
+
+		handler.Verify(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `name="user_code"`)
+	})
+
+	t.Run("Login page for a known code", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/oauth/device?user_code=wdjbmjht", nil)
+
+		mockOAuth.EXPECT().LookupDeviceCode(gomock.Any(), "wdjbmjht").Return(verification, nil)
+
+		handler.Verify(c)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "WDJB-MJHT")
+		assert.Contains(t, w.Body.String(), "invoices:read")
+		assert.Contains(t, w.Header().Get("Set-Cookie"), "device_csrf=")
+	})
+
+	t.Run("Login page of a path tenant", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/oauth/device?user_code=wdjbmjht", nil)
+		ctx := services.WithTenantPath(services.WithTenant(c.Request.Context(), "acme"), "/t/acme")
+		c.Request = c.Request.WithContext(ctx)
+
+		mockOAuth.EXPECT().LookupDeviceCode(gomock.Any(), "wdjbmjht").Return(verification, nil)
+
+		handler.Verify(c)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Header().Get("Set-Cookie"), "Path=/t/acme/oauth/device")
+		assert.Contains(t, w.Body.String(), `action="device"`)
+	})
+
+	t.Run("Unknown code", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/oauth/device?user_code=XXXX", nil)
+
+		mockOAuth.EXPECT().LookupDeviceCode(gomock.Any(), "XXXX").Return(nil, services.ErrInvalidUserCode)
+
+		handler.Verify(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Approve", func(t *testing.T) {
+		w, c := submit(url.Values{
+			"user_code":  {"WDJB-MJHT"},
+			"csrf_token": {"csrf"},
+			"email":      {"user@example.com"},
+			"password":   {"secret"},
+			"action":     {"allow"},
+		})
+
+		mockOAuth.EXPECT().LookupDeviceCode(gomock.Any(), "WDJB-MJHT").Return(verification, nil)
+		mockAuthenticator.EXPECT().Authenticate(gomock.Any(), "user@example.com", "secret", gomock.Any()).
+			Return(&models.User{ID: "user1"}, nil)
+		mockOAuth.EXPECT().DecideDevice(gomock.Any(), "WDJB-MJHT", "user1", true, gomock.Any()).Return(nil)
+
+		handler.Decide(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "Устройство подключено")
+	})
+
+	t.Run("Deny needs a login", func(t *testing.T) {
+		w, c := submit(url.Values{
+			"user_code":  {"WDJB-MJHT"},
+			"csrf_token": {"csrf"},
+			"action":     {"deny"},
+		})
+
+		mockOAuth.EXPECT().LookupDeviceCode(gomock.Any(), "WDJB-MJHT").Return(verification, nil)
+		mockAuthenticator.EXPECT().Authenticate(gomock.Any(), "", "", gomock.Any()).
+			Return(nil, services.ErrInvalidCredentials)
+
+		handler.Decide(c)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("Deny", func(t *testing.T) {
+		w, c := submit(url.Values{
+			"user_code":  {"WDJB-MJHT"},
+			"csrf_token": {"csrf"},
+			"email":      {"user@example.com"},
+			"password":   {"secret"},
+			"action":     {"deny"},
+		})
+
+		mockOAuth.EXPECT().LookupDeviceCode(gomock.Any(), "WDJB-MJHT").Return(verification, nil)
+		mockAuthenticator.EXPECT().Authenticate(gomock.Any(), "user@example.com", "secret", gomock.Any()).
+			Return(&models.User{ID: "user1"}, nil)
+		mockOAuth.EXPECT().DecideDevice(gomock.Any(), "WDJB-MJHT", "user1", false, gomock.Any()).Return(nil)
+
+		handler.Decide(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "Подключение устройства отклонено")
+	})
+
+	t.Run("Wrong CSRF token", func(t *testing.T) {
+		w, c := submit(url.Values{
+			"user_code":  {"WDJB-MJHT"},
+			"csrf_token": {"other"},
+			"action":     {"allow"},
+		})
+
+		mockOAuth.EXPECT().LookupDeviceCode(gomock.Any(), "WDJB-MJHT").Return(verification, nil)
+
+		handler.Decide(c)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
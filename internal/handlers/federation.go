@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/auth-service/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	federatedStateCookie = "federated_state"
+	federatedCookiePath  = "/auth/federated"
+	federatedCookieTTL   = 10 * time.Minute
+)
+
+type FederationHandler struct {
+	federationService services.FederationServiceInterface
+	authService       services.AuthServiceInterface
+	secureCookies     bool
+}
+
+func NewFederationHandler(
+	federationService services.FederationServiceInterface,
+	authService services.AuthServiceInterface,
+	secureCookies bool,
+) *FederationHandler {
+	return &FederationHandler{
+		federationService: federationService,
+		authService:       authService,
+		secureCookies:     secureCookies,
+	}
+}
+
+// Begin redirects the browser to the upstream provider. The state is also
+// kept in a cookie, so the callback is accepted only in the same browser.
+func (h *FederationHandler) Begin(c *gin.Context) {
+	provider := c.Param("provider")
+
+	redirect, state, err := h.federationService.Begin(c.Request.Context(), provider)
+	if err != nil {
+		if errors.Is(err, services.ErrUnknownProvider) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "unknown identity provider"})
+		} else {
+			log.Printf("Failed to start federated login with %s: %v", provider, err)
+			c.JSON(http.StatusBadGateway, gin.H{"error": "identity provider is unavailable"})
+		}
+		return
+	}
+
+	h.setStateCookie(c, state, int(federatedCookieTTL.Seconds()))
+	c.Redirect(http.StatusFound, redirect)
+}
+
+func (h *FederationHandler) Callback(c *gin.Context) {
+	provider := c.Param("provider")
+	ip := net.ParseIP(c.ClientIP())
+	if ip == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid IP address"})
+		return
+	}
+
+	if upstreamErr := c.Query("error"); upstreamErr != "" {
+		h.setStateCookie(c, "", -1)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "federated login failed", "upstream_error": upstreamErr})
+		return
+	}
+
+	state := c.Query("state")
+	cookie, _ := c.Cookie(federatedStateCookie)
+	if state == "" || subtle.ConstantTimeCompare([]byte(cookie), []byte(state)) != 1 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid federated login state"})
+		return
+	}
+
+	user, err := h.federationService.Complete(c.Request.Context(), provider, state, c.Query("code"), ip)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrUnknownProvider):
+			c.JSON(http.StatusNotFound, gin.H{"error": "unknown identity provider"})
+		case errors.Is(err, services.ErrFederatedEmailInUse):
+			c.JSON(http.StatusConflict, gin.H{"error": "email belongs to another account"})
+		case errors.Is(err, services.ErrFederatedLogin):
+			log.Printf("Federated login with %s rejected: %v", provider, err)
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "federated login failed"})
+		default:
+			log.Printf("Federated login with %s failed: %v", provider, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to complete federated login"})
+		}
+		return
+	}
+
+	tokens, err := h.authService.GenerateTokens(c.Request.Context(), user.ID, ip)
+	if err != nil {
+		writeIssueError(c, err)
+		return
+	}
+
+	h.setStateCookie(c, "", -1)
+	c.JSON(http.StatusOK, tokens)
+}
+
+func (h *FederationHandler) setStateCookie(c *gin.Context, value string, maxAge int) {
+	// Lax, because the callback is a top-level navigation from the provider.
+	c.SetSameSite(http.SameSiteLaxMode)
+	path := services.TenantURL(c.Request.Context(), "") + federatedCookiePath
+	c.SetCookie(federatedStateCookie, value, maxAge, path, "", h.secureCookies, true)
+}
@@ -0,0 +1,112 @@
+package handlers_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/auth-service/internal/handlers"
+	"github.com/auth-service/internal/models"
+	"github.com/auth-service/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFederationHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockFederation := services.NewMockFederationServiceInterface(ctrl)
+	mockAuth := services.NewMockAuthServiceInterface(ctrl)
+	handler := handlers.NewFederationHandler(mockFederation, mockAuth, false)
+
+	newRequest := func(path, provider string) (*httptest.ResponseRecorder, *gin.Context) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", path, nil)
+		c.Request.RemoteAddr = "192.168.1.1:1234"
+		c.Params = gin.Params{{Key: "provider", Value: provider}}
+		return w, c
+	}
+
+	t.Run("Begin", func(t *testing.T) {
+		w, c := newRequest("/auth/federated/corp", "corp")
+
+		mockFederation.EXPECT().
+			Begin(gomock.Any(), "corp").
+			Return("https://idp.example/authorize?state=state1", "state1", nil)
+
+		handler.Begin(c)
+
+		assert.Equal(t, http.StatusFound, c.Writer.Status())
+		assert.Equal(t, "https://idp.example/authorize?state=state1", w.Header().Get("Location"))
+		assert.Contains(t, w.Header().Get("Set-Cookie"), "federated_state=state1")
+		assert.Contains(t, w.Header().Get("Set-Cookie"), "HttpOnly")
+	})
+
+	t.Run("Begin with unknown provider", func(t *testing.T) {
+		w, c := newRequest("/auth/federated/other", "other")
+
+		mockFederation.EXPECT().Begin(gomock.Any(), "other").Return("", "", services.ErrUnknownProvider)
+
+		handler.Begin(c)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("Callback", func(t *testing.T) {
+		w, c := newRequest("/auth/federated/corp/callback?state=state1&code=code1", "corp")
+		c.Request.AddCookie(&http.Cookie{Name: "federated_state", Value: "state1"})
+
+		mockFederation.EXPECT().
+			Complete(gomock.Any(), "corp", "state1", "code1", gomock.Any()).
+			Return(&models.User{ID: "user1"}, nil)
+		mockAuth.EXPECT().
+			GenerateTokens(gomock.Any(), "user1", gomock.Any()).
+			Return(&models.TokenPair{AccessToken: "access", RefreshToken: "refresh"}, nil)
+
+		handler.Callback(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"access_token":"access"`)
+	})
+
+	t.Run("Callback from another browser", func(t *testing.T) {
+		w, c := newRequest("/auth/federated/corp/callback?state=state1&code=code1", "corp")
+		c.Request.AddCookie(&http.Cookie{Name: "federated_state", Value: "state2"})
+
+		handler.Callback(c)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("Callback with upstream error", func(t *testing.T) {
+		w, c := newRequest("/auth/federated/corp/callback?error=access_denied&state=state1", "corp")
+
+		handler.Callback(c)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Contains(t, w.Body.String(), "access_denied")
+	})
+
+	t.Run("Callback errors", func(t *testing.T) {
+		tests := map[error]int{
+			services.ErrFederatedEmailInUse:                              http.StatusConflict,
+			fmt.Errorf("%w: nonce mismatch", services.ErrFederatedLogin): http.StatusUnauthorized,
+			fmt.Errorf("db is down"):                                     http.StatusInternalServerError,
+		}
+		for err, status := range tests {
+			w, c := newRequest("/auth/federated/corp/callback?state=state1&code=code1", "corp")
+			c.Request.AddCookie(&http.Cookie{Name: "federated_state", Value: "state1"})
+
+			mockFederation.EXPECT().Complete(gomock.Any(), "corp", "state1", "code1", gomock.Any()).Return(nil, err)
+
+			handler.Callback(c)
+
+			assert.Equal(t, status, w.Code, err.Error())
+			assert.NotContains(t, w.Body.String(), "db is down")
+		}
+	})
+}
@@ -2,9 +2,11 @@ package handlers_test
 
 import (
 	"bytes"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/auth-service/internal/handlers"
 	"github.com/auth-service/internal/models"
@@ -60,5 +62,103 @@ func TestAuthHandler(t *testing.T) {
 			handler.RefreshTokens(c)
 			assert.Equal(t, http.StatusOK, w.Code)
 		})
+
+		t.Run("Locked out", func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("POST", "/refresh", bytes.NewBufferString(
+				`{"user_id": "user1", "refresh_token": "token"}`,
+			))
+			c.Request.RemoteAddr = "192.168.1.1:1234"
+
+			mockAuth.EXPECT().
+				RefreshTokens(gomock.Any(), "user1", "token", gomock.Any()).
+				Return(nil, &services.LockedError{RetryAfter: 1500 * time.Millisecond})
+
+			handler.RefreshTokens(c)
+			assert.Equal(t, http.StatusTooManyRequests, w.Code)
+			assert.Equal(t, "2", w.Header().Get("Retry-After"))
+		})
+
+		t.Run("Idle session", func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("POST", "/refresh", bytes.NewBufferString(
+				`{"user_id": "user1", "refresh_token": "token"}`,
+			))
+			c.Request.RemoteAddr = "192.168.1.1:1234"
+
+			mockAuth.EXPECT().
+				RefreshTokens(gomock.Any(), "user1", "token", gomock.Any()).
+				Return(nil, services.ErrSessionIdleTimeout)
+
+			handler.RefreshTokens(c)
+			assert.Equal(t, http.StatusUnauthorized, w.Code)
+			assert.JSONEq(t, `{"error": "session_idle_timeout"}`, w.Body.String())
+		})
+
+		t.Run("IP change needs step-up", func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("POST", "/refresh", bytes.NewBufferString(
+				`{"user_id": "user1", "refresh_token": "token"}`,
+			))
+			c.Request.RemoteAddr = "192.168.1.1:1234"
+
+			mockAuth.EXPECT().
+				RefreshTokens(gomock.Any(), "user1", "token", gomock.Any()).
+				Return(nil, &services.StepUpError{Challenge: "challenge"})
+
+			handler.RefreshTokens(c)
+			assert.Equal(t, http.StatusUnauthorized, w.Code)
+			assert.JSONEq(t, `{"error": "step_up_required", "mfa_token": "challenge"}`, w.Body.String())
+		})
+	})
+	t.Run("Logout", func(t *testing.T) {
+		expiresAt := time.Now().Add(time.Minute)
+		newContext := func() (*httptest.ResponseRecorder, *gin.Context) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("POST", "/auth/logout", nil)
+			c.Set("user_id", "user1")
+			c.Set("session_id", "session1")
+			c.Set("token_id", "jti1")
+			c.Set("expires_at", expiresAt)
+			return w, c
+		}
+		token := services.AccessTokenRef{ID: "jti1", SessionID: "session1", ExpiresAt: expiresAt}
+
+		t.Run("Ends the current session", func(t *testing.T) {
+			w, c := newContext()
+			mockAuth.EXPECT().Logout(gomock.Any(), "user1", token).Return(nil)
+
+			handler.Logout(c)
+			assert.Equal(t, http.StatusOK, w.Code)
+		})
+
+		t.Run("Everywhere", func(t *testing.T) {
+			w, c := newContext()
+			mockAuth.EXPECT().LogoutAll(gomock.Any(), "user1", token).Return(nil)
+
+			handler.LogoutAll(c)
+			assert.Equal(t, http.StatusOK, w.Code)
+		})
+
+		t.Run("Failure", func(t *testing.T) {
+			w, c := newContext()
+			mockAuth.EXPECT().Logout(gomock.Any(), "user1", token).Return(errors.New("db down"))
+
+			handler.Logout(c)
+			assert.Equal(t, http.StatusInternalServerError, w.Code)
+		})
+
+		t.Run("Without a token", func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("POST", "/auth/logout", nil)
+
+			handler.Logout(c)
+			assert.Equal(t, http.StatusUnauthorized, w.Code)
+		})
 	})
 }
@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"errors"
+	"net"
+	"net/http"
+
+	"github.com/auth-service/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type ImpersonationHandler struct {
+	impersonationService services.ImpersonationServiceInterface
+}
+
+func NewImpersonationHandler(impersonationService services.ImpersonationServiceInterface) *ImpersonationHandler {
+	return &ImpersonationHandler{impersonationService: impersonationService}
+}
+
+type impersonateRequest struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+// Impersonate issues the admin an access token for the user in the path.
+func (h *ImpersonationHandler) Impersonate(c *gin.Context) {
+	var req impersonateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "reason is required"})
+		return
+	}
+
+	pair, err := h.impersonationService.Impersonate(c.Request.Context(), c.GetString("user_id"), c.Param("id"), req.Reason, net.ParseIP(c.ClientIP()))
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrImpersonationReason):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrUserNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		case errors.Is(err, services.ErrImpersonationForbidden):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to impersonate user"})
+		}
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, pair)
+}
@@ -0,0 +1,64 @@
+package handlers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/auth-service/internal/handlers"
+	"github.com/auth-service/internal/models"
+	"github.com/auth-service/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImpersonationHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockImpersonation := services.NewMockImpersonationServiceInterface(ctrl)
+	handler := handlers.NewImpersonationHandler(mockImpersonation)
+
+	newContext := func(body string) (*gin.Context, *httptest.ResponseRecorder) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("POST", "/admin/users/user1/impersonate", strings.NewReader(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Request.RemoteAddr = "192.168.1.1:1234"
+		c.Params = gin.Params{{Key: "id", Value: "user1"}}
+		c.Set("user_id", "admin1")
+		return c, w
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		c, w := newContext(`{"reason":"ticket 42"}`)
+		mockImpersonation.EXPECT().Impersonate(gomock.Any(), "admin1", "user1", "ticket 42", gomock.Any()).
+			Return(&models.TokenPair{AccessToken: "access", ExpiresIn: 600}, nil)
+
+		handler.Impersonate(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"access_token":"access"`)
+		assert.NotContains(t, w.Body.String(), "refresh_token")
+	})
+
+	t.Run("Missing reason", func(t *testing.T) {
+		c, w := newContext(`{}`)
+
+		handler.Impersonate(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Privileged user", func(t *testing.T) {
+		c, w := newContext(`{"reason":"ticket 42"}`)
+		mockImpersonation.EXPECT().Impersonate(gomock.Any(), "admin1", "user1", "ticket 42", gomock.Any()).
+			Return(nil, services.ErrImpersonationForbidden)
+
+		handler.Impersonate(c)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
@@ -0,0 +1,21 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/auth-service/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type JobHandler struct {
+	jobs services.JobStatsProvider
+}
+
+func NewJobHandler(jobs services.JobStatsProvider) *JobHandler {
+	return &JobHandler{jobs: jobs}
+}
+
+// ListJobs returns the metrics of the background jobs.
+func (h *JobHandler) ListJobs(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"jobs": h.jobs.Stats()})
+}
@@ -0,0 +1,32 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/auth-service/internal/handlers"
+	"github.com/auth-service/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJobHandler(t *testing.T) {
+	manager := services.NewJobManager()
+	manager.Add(services.Job{Name: "expired_tokens", Interval: time.Minute, Run: func(ctx context.Context) (int, error) {
+		return 0, nil
+	}})
+	handler := handlers.NewJobHandler(manager)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/admin/jobs", nil)
+
+	handler.ListJobs(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"name":"expired_tokens"`)
+	assert.Contains(t, w.Body.String(), `"runs":0`)
+}
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net"
+	"net/http"
+
+	"github.com/auth-service/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type LoginHandler struct {
+	authenticator services.RealmAuthenticator
+	authService   services.AuthServiceInterface
+	mfaService    services.MFAServiceInterface
+}
+
+func NewLoginHandler(
+	authenticator services.RealmAuthenticator,
+	authService services.AuthServiceInterface,
+	mfaService services.MFAServiceInterface,
+) *LoginHandler {
+	return &LoginHandler{
+		authenticator: authenticator,
+		authService:   authService,
+		mfaService:    mfaService,
+	}
+}
+
+type loginRequest struct {
+	Login    string `json:"login" binding:"required"`
+	Password string `json:"password" binding:"required"`
+	// Realm is optional, by default it is chosen by the email domain.
+	Realm string `json:"realm"`
+}
+
+// Login checks the password with the backend of the user's realm and issues
+// a token pair carrying the roles the backend reported. Users that need a
+// second factor get an mfa_token for /auth/mfa/recovery instead.
+func (h *LoginHandler) Login(c *gin.Context) {
+	var req loginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request format"})
+		return
+	}
+
+	ip := net.ParseIP(c.ClientIP())
+	if ip == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid IP address"})
+		return
+	}
+
+	user, err := h.authenticator.AuthenticateRealm(c.Request.Context(), req.Realm, req.Login, req.Password, ip)
+	if err != nil {
+		if writeLockedError(c, err) {
+			return
+		}
+		switch {
+		case errors.Is(err, services.ErrUnknownRealm):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "unknown realm"})
+		case errors.Is(err, services.ErrInvalidCredentials):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid login or password"})
+		default:
+			log.Printf("Password login failed in realm %q: %v", req.Realm, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to verify password"})
+		}
+		return
+	}
+
+	challenge, err := h.mfaService.Challenge(c.Request.Context(), user.ID, user.Roles)
+	if err != nil {
+		log.Printf("Failed to check second factor of user %s: %v", user.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to verify password"})
+		return
+	}
+	if challenge != "" {
+		c.Header("Cache-Control", "no-store")
+		c.JSON(http.StatusOK, gin.H{"mfa_required": true, "mfa_token": challenge})
+		return
+	}
+
+	tokens, err := h.authService.IssueTokens(c.Request.Context(), services.TokenGrant{
+		UserID: user.ID,
+		Roles:  user.Roles,
+		IP:     ip,
+	})
+	if err != nil {
+		writeIssueError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
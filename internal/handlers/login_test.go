@@ -0,0 +1,121 @@
+package handlers_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/auth-service/internal/handlers"
+	"github.com/auth-service/internal/models"
+	"github.com/auth-service/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoginHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRealms := services.NewMockRealmAuthenticator(ctrl)
+	mockAuth := services.NewMockAuthServiceInterface(ctrl)
+	mockMFA := services.NewMockMFAServiceInterface(ctrl)
+	handler := handlers.NewLoginHandler(mockRealms, mockAuth, mockMFA)
+
+	newRequest := func(body string) (*httptest.ResponseRecorder, *gin.Context) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("POST", "/auth/login", bytes.NewBufferString(body))
+		c.Request.RemoteAddr = "192.168.1.1:1234"
+		return w, c
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		w, c := newRequest(`{"login": "ivan@corp.example", "password": "secret"}`)
+
+		mockRealms.EXPECT().
+			AuthenticateRealm(gomock.Any(), "", "ivan@corp.example", "secret", gomock.Any()).
+			Return(&models.User{ID: "user1", Roles: []string{"admin"}}, nil)
+		mockMFA.EXPECT().Challenge(gomock.Any(), "user1", []string{"admin"}).Return("", nil)
+		mockAuth.EXPECT().
+			IssueTokens(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ interface{}, grant services.TokenGrant) (*models.TokenPair, error) {
+				assert.Equal(t, "user1", grant.UserID)
+				assert.Equal(t, []string{"admin"}, grant.Roles)
+				return &models.TokenPair{AccessToken: "access", RefreshToken: "refresh"}, nil
+			})
+
+		handler.Login(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		tests := map[string]struct {
+			err    error
+			status int
+		}{
+			"Invalid credentials": {services.ErrInvalidCredentials, http.StatusUnauthorized},
+			"Unknown realm":       {services.ErrUnknownRealm, http.StatusBadRequest},
+			"Locked":              {&services.LockedError{RetryAfter: time.Minute}, http.StatusTooManyRequests},
+			"Directory down":      {errors.New("failed to connect to LDAP"), http.StatusInternalServerError},
+		}
+		for name, tt := range tests {
+			t.Run(name, func(t *testing.T) {
+				w, c := newRequest(`{"login": "ivan", "password": "secret", "realm": "ldap"}`)
+
+				mockRealms.EXPECT().
+					AuthenticateRealm(gomock.Any(), "ldap", "ivan", "secret", gomock.Any()).
+					Return(nil, tt.err)
+
+				handler.Login(c)
+
+				assert.Equal(t, tt.status, w.Code)
+				assert.NotContains(t, w.Body.String(), "LDAP")
+			})
+		}
+	})
+
+	t.Run("Session limit", func(t *testing.T) {
+		w, c := newRequest(`{"login": "ivan@corp.example", "password": "secret"}`)
+
+		mockRealms.EXPECT().
+			AuthenticateRealm(gomock.Any(), "", "ivan@corp.example", "secret", gomock.Any()).
+			Return(&models.User{ID: "user1"}, nil)
+		mockMFA.EXPECT().Challenge(gomock.Any(), "user1", gomock.Any()).Return("", nil)
+		mockAuth.EXPECT().
+			IssueTokens(gomock.Any(), gomock.Any()).
+			Return(nil, fmt.Errorf("%w: the limit is 5", services.ErrSessionLimit))
+
+		handler.Login(c)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("Second factor required", func(t *testing.T) {
+		w, c := newRequest(`{"login": "ivan@corp.example", "password": "secret"}`)
+
+		mockRealms.EXPECT().
+			AuthenticateRealm(gomock.Any(), "", "ivan@corp.example", "secret", gomock.Any()).
+			Return(&models.User{ID: "user1"}, nil)
+		mockMFA.EXPECT().Challenge(gomock.Any(), "user1", gomock.Any()).Return("challenge", nil)
+
+		handler.Login(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"mfa_token":"challenge"`)
+		assert.NotContains(t, w.Body.String(), "access_token")
+	})
+
+	t.Run("Missing password", func(t *testing.T) {
+		w, c := newRequest(`{"login": "ivan"}`)
+
+		handler.Login(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/auth-service/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	magicLinkNonceCookie = "magic_link_nonce"
+	magicLinkCookiePath  = "/auth/magic-link"
+)
+
+type MagicLinkHandler struct {
+	magicLinkService services.MagicLinkServiceInterface
+	authService      services.AuthServiceInterface
+	cookieTTL        time.Duration
+	secureCookies    bool
+}
+
+func NewMagicLinkHandler(
+	magicLinkService services.MagicLinkServiceInterface,
+	authService services.AuthServiceInterface,
+	cookieTTL time.Duration,
+	secureCookies bool,
+) *MagicLinkHandler {
+	return &MagicLinkHandler{
+		magicLinkService: magicLinkService,
+		authService:      authService,
+		cookieTTL:        cookieTTL,
+		secureCookies:    secureCookies,
+	}
+}
+
+type magicLinkRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type magicLinkCodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// RequestLink always answers 202 so that the response does not reveal
+// whether the address belongs to an account.
+func (h *MagicLinkHandler) RequestLink(c *gin.Context) {
+	var req magicLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request format"})
+		return
+	}
+
+	nonce, err := h.magicLinkService.Request(c.Request.Context(), req.Email, net.ParseIP(c.ClientIP()))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to send login link"})
+		return
+	}
+
+	h.setNonceCookie(c, nonce, int(h.cookieTTL.Seconds()))
+	c.JSON(http.StatusAccepted, gin.H{"status": "sent"})
+}
+
+func (h *MagicLinkHandler) Callback(c *gin.Context) {
+	nonce, _ := c.Cookie(magicLinkNonceCookie)
+	h.login(c, func(ip net.IP) (string, error) {
+		return h.magicLinkService.VerifyLink(c.Request.Context(), c.Query("token"), nonce, ip, c.Request.UserAgent())
+	})
+}
+
+func (h *MagicLinkHandler) VerifyCode(c *gin.Context) {
+	var req magicLinkCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request format"})
+		return
+	}
+
+	nonce, _ := c.Cookie(magicLinkNonceCookie)
+	h.login(c, func(ip net.IP) (string, error) {
+		return h.magicLinkService.VerifyCode(c.Request.Context(), req.Code, nonce, ip, c.Request.UserAgent())
+	})
+}
+
+func (h *MagicLinkHandler) login(c *gin.Context, verify func(ip net.IP) (string, error)) {
+	ip := net.ParseIP(c.ClientIP())
+	if ip == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid IP address"})
+		return
+	}
+
+	userID, err := verify(ip)
+	if err != nil {
+		if writeLockedError(c, err) {
+			return
+		}
+		if errors.Is(err, services.ErrInvalidLoginCode) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid or expired login link"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to verify login link"})
+		}
+		return
+	}
+
+	tokens, err := h.authService.GenerateTokens(c.Request.Context(), userID, ip)
+	if err != nil {
+		writeIssueError(c, err)
+		return
+	}
+
+	h.setNonceCookie(c, "", -1)
+	c.JSON(http.StatusOK, tokens)
+}
+
+// setNonceCookie scopes the nonce to the magic link routes of the tenant,
+// under the same prefix as the link sent by email.
+func (h *MagicLinkHandler) setNonceCookie(c *gin.Context, value string, maxAge int) {
+	path := services.TenantURL(c.Request.Context(), "") + magicLinkCookiePath
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(magicLinkNonceCookie, value, maxAge, path, "", h.secureCookies, true)
+}
@@ -0,0 +1,121 @@
+package handlers_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/auth-service/internal/handlers"
+	"github.com/auth-service/internal/models"
+	"github.com/auth-service/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMagicLinkHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockMagicLink := services.NewMockMagicLinkServiceInterface(ctrl)
+	mockAuth := services.NewMockAuthServiceInterface(ctrl)
+	handler := handlers.NewMagicLinkHandler(mockMagicLink, mockAuth, 15*time.Minute, false)
+
+	t.Run("RequestLink", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("POST", "/auth/magic-link", bytes.NewBufferString(
+			`{"email": "user@example.com"}`,
+		))
+		c.Request.RemoteAddr = "192.168.1.1:1234"
+
+		mockMagicLink.EXPECT().
+			Request(gomock.Any(), "user@example.com", gomock.Any()).
+			Return("nonce-1", nil)
+
+		handler.RequestLink(c)
+
+		assert.Equal(t, http.StatusAccepted, w.Code)
+		assert.Contains(t, w.Header().Get("Set-Cookie"), "magic_link_nonce=nonce-1")
+		assert.Contains(t, w.Header().Get("Set-Cookie"), "HttpOnly")
+	})
+
+	t.Run("RequestLink in a tenant", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("POST", "/auth/magic-link", bytes.NewBufferString(
+			`{"email": "user@example.com"}`,
+		))
+		c.Request = c.Request.WithContext(services.WithTenant(c.Request.Context(), "acme"))
+		c.Request.RemoteAddr = "192.168.1.1:1234"
+
+		mockMagicLink.EXPECT().
+			Request(gomock.Any(), "user@example.com", gomock.Any()).
+			Return("nonce-1", nil)
+
+		handler.RequestLink(c)
+
+		assert.Equal(t, http.StatusAccepted, w.Code)
+		assert.Contains(t, w.Header().Get("Set-Cookie"), "Path=/t/acme/auth/magic-link")
+	})
+
+	t.Run("Callback", func(t *testing.T) {
+		t.Run("Success", func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", "/auth/magic-link/callback?token=abc", nil)
+			c.Request.RemoteAddr = "192.168.1.1:1234"
+			c.Request.Header.Set("User-Agent", "Firefox")
+			c.Request.AddCookie(&http.Cookie{Name: "magic_link_nonce", Value: "nonce-1"})
+
+			mockMagicLink.EXPECT().
+				VerifyLink(gomock.Any(), "abc", "nonce-1", gomock.Any(), "Firefox").
+				Return("user1", nil)
+			mockAuth.EXPECT().
+				GenerateTokens(gomock.Any(), "user1", gomock.Any()).
+				Return(&models.TokenPair{AccessToken: "access", RefreshToken: "refresh"}, nil)
+
+			handler.Callback(c)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+		})
+
+		t.Run("Invalid link", func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", "/auth/magic-link/callback?token=abc", nil)
+			c.Request.RemoteAddr = "192.168.1.1:1234"
+
+			mockMagicLink.EXPECT().
+				VerifyLink(gomock.Any(), "abc", "", gomock.Any(), gomock.Any()).
+				Return("", services.ErrInvalidLoginCode)
+
+			handler.Callback(c)
+
+			assert.Equal(t, http.StatusUnauthorized, w.Code)
+		})
+	})
+
+	t.Run("VerifyCode", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("POST", "/auth/magic-link/verify", bytes.NewBufferString(
+			`{"code": "123456"}`,
+		))
+		c.Request.RemoteAddr = "192.168.1.1:1234"
+		c.Request.AddCookie(&http.Cookie{Name: "magic_link_nonce", Value: "nonce-1"})
+
+		mockMagicLink.EXPECT().
+			VerifyCode(gomock.Any(), "123456", "nonce-1", gomock.Any(), gomock.Any()).
+			Return("user1", nil)
+		mockAuth.EXPECT().
+			GenerateTokens(gomock.Any(), "user1", gomock.Any()).
+			Return(&models.TokenPair{AccessToken: "access", RefreshToken: "refresh"}, nil)
+
+		handler.VerifyCode(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+}
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"errors"
+	"net"
+	"net/http"
+
+	"github.com/auth-service/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type MFAHandler struct {
+	mfaService  services.MFAServiceInterface
+	authService services.AuthServiceInterface
+}
+
+func NewMFAHandler(mfaService services.MFAServiceInterface, authService services.AuthServiceInterface) *MFAHandler {
+	return &MFAHandler{
+		mfaService:  mfaService,
+		authService: authService,
+	}
+}
+
+type recoveryCodeRequest struct {
+	// MFAToken is the challenge returned by /auth/login.
+	MFAToken string `json:"mfa_token" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
+
+func (h *MFAHandler) GenerateRecoveryCodes(c *gin.Context) {
+	userID := c.GetString("user_id")
+	ip := net.ParseIP(c.ClientIP())
+
+	codes, err := h.mfaService.GenerateRecoveryCodes(c.Request.Context(), userID, ip)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate recovery codes"})
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusCreated, gin.H{"recovery_codes": codes})
+}
+
+// VerifyRecoveryCode completes a password login that needs a second factor
+// with a recovery code and issues a token pair for the user. For a step-up
+// challenge from /auth/refresh the held session is continued instead.
+func (h *MFAHandler) VerifyRecoveryCode(c *gin.Context) {
+	var req recoveryCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request format"})
+		return
+	}
+
+	ip := net.ParseIP(c.ClientIP())
+	if ip == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid IP address"})
+		return
+	}
+
+	login, err := h.mfaService.VerifyRecoveryCode(c.Request.Context(), req.MFAToken, req.Code, ip)
+	if err != nil {
+		if writeLockedError(c, err) {
+			return
+		}
+		switch {
+		case errors.Is(err, services.ErrInvalidMFAChallenge):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid or expired mfa_token"})
+		case errors.Is(err, services.ErrInvalidRecoveryCode):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid recovery code"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to verify recovery code"})
+		}
+		return
+	}
+
+	if login.SessionID != "" {
+		tokens, err := h.authService.CompleteStepUp(c.Request.Context(), login.UserID, login.SessionID, ip)
+		if err != nil {
+			switch {
+			case errors.Is(err, services.ErrSessionNotFound):
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "session not found"})
+			case errors.Is(err, services.ErrSessionIdleTimeout):
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "session_idle_timeout"})
+			case errors.Is(err, services.ErrSessionClientDisabled):
+				c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			default:
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to continue session"})
+			}
+			return
+		}
+		c.JSON(http.StatusOK, tokens)
+		return
+	}
+
+	tokens, err := h.authService.IssueTokens(c.Request.Context(), services.TokenGrant{
+		UserID: login.UserID,
+		Roles:  login.Roles,
+		IP:     ip,
+	})
+	if err != nil {
+		writeIssueError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
@@ -0,0 +1,118 @@
+package handlers_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/auth-service/internal/handlers"
+	"github.com/auth-service/internal/models"
+	"github.com/auth-service/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMFAHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockMFA := services.NewMockMFAServiceInterface(ctrl)
+	mockAuth := services.NewMockAuthServiceInterface(ctrl)
+	handler := handlers.NewMFAHandler(mockMFA, mockAuth)
+
+	t.Run("GenerateRecoveryCodes", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("POST", "/api/mfa/recovery-codes", nil)
+		c.Request.RemoteAddr = "192.168.1.1:1234"
+		c.Set("user_id", "user1")
+
+		mockMFA.EXPECT().
+			GenerateRecoveryCodes(gomock.Any(), "user1", gomock.Any()).
+			Return([]string{"aaaaa-bbbbb"}, nil)
+
+		handler.GenerateRecoveryCodes(c)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+		assert.Contains(t, w.Body.String(), "aaaaa-bbbbb")
+	})
+
+	t.Run("VerifyRecoveryCode", func(t *testing.T) {
+		t.Run("Valid code", func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("POST", "/auth/mfa/recovery", bytes.NewBufferString(
+				`{"mfa_token": "challenge", "code": "aaaaa-bbbbb"}`,
+			))
+			c.Request.RemoteAddr = "192.168.1.1:1234"
+
+			mockMFA.EXPECT().
+				VerifyRecoveryCode(gomock.Any(), "challenge", "aaaaa-bbbbb", gomock.Any()).
+				Return(&services.MFALogin{UserID: "user1", Roles: []string{"admin"}}, nil)
+			mockAuth.EXPECT().
+				IssueTokens(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ interface{}, grant services.TokenGrant) (*models.TokenPair, error) {
+					assert.Equal(t, "user1", grant.UserID)
+					assert.Equal(t, []string{"admin"}, grant.Roles)
+					return &models.TokenPair{AccessToken: "access", RefreshToken: "refresh"}, nil
+				})
+
+			handler.VerifyRecoveryCode(c)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+		})
+
+		t.Run("Invalid code", func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("POST", "/auth/mfa/recovery", bytes.NewBufferString(
+				`{"mfa_token": "challenge", "code": "wrong"}`,
+			))
+			c.Request.RemoteAddr = "192.168.1.1:1234"
+
+			mockMFA.EXPECT().
+				VerifyRecoveryCode(gomock.Any(), "challenge", "wrong", gomock.Any()).
+				Return(nil, services.ErrInvalidRecoveryCode)
+
+			handler.VerifyRecoveryCode(c)
+
+			assert.Equal(t, http.StatusUnauthorized, w.Code)
+		})
+
+		t.Run("Step-up", func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("POST", "/auth/mfa/recovery", bytes.NewBufferString(
+				`{"mfa_token": "step-up", "code": "aaaaa-bbbbb"}`,
+			))
+			c.Request.RemoteAddr = "192.168.1.1:1234"
+
+			mockMFA.EXPECT().
+				VerifyRecoveryCode(gomock.Any(), "step-up", "aaaaa-bbbbb", gomock.Any()).
+				Return(&services.MFALogin{UserID: "user1", SessionID: "session1"}, nil)
+			mockAuth.EXPECT().
+				CompleteStepUp(gomock.Any(), "user1", "session1", gomock.Any()).
+				Return(&models.TokenPair{AccessToken: "access", RefreshToken: "refresh"}, nil)
+
+			handler.VerifyRecoveryCode(c)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+		})
+
+		t.Run("Missing challenge", func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("POST", "/auth/mfa/recovery", bytes.NewBufferString(
+				`{"user_id": "user1", "code": "aaaaa-bbbbb"}`,
+			))
+			c.Request.RemoteAddr = "192.168.1.1:1234"
+
+			handler.VerifyRecoveryCode(c)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	})
+}
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+
+	"github.com/auth-service/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type OAuthHandler struct {
+	oauthService services.OAuthServiceInterface
+}
+
+func NewOAuthHandler(oauthService services.OAuthServiceInterface) *OAuthHandler {
+	return &OAuthHandler{oauthService: oauthService}
+}
+
+// Token is the OAuth 2.0 token endpoint. Clients authenticate with HTTP Basic
+// (client_secret_basic) or with client_id and client_secret in the form body
+// (client_secret_post), but not both. Public clients send client_id only.
+func (h *OAuthHandler) Token(c *gin.Context) {
+	c.Header("Cache-Control", "no-store")
+	c.Header("Pragma", "no-cache")
+
+	req := services.TokenRequest{
+		GrantType:    c.PostForm("grant_type"),
+		Scope:        c.PostForm("scope"),
+		Code:         c.PostForm("code"),
+		RedirectURI:  c.PostForm("redirect_uri"),
+		CodeVerifier: c.PostForm("code_verifier"),
+		DeviceCode:   c.PostForm("device_code"),
+		IP:           net.ParseIP(c.ClientIP()),
+	}
+
+	var basic, ok bool
+	req.ClientID, req.ClientSecret, basic, ok = readClientCredentials(c)
+	if !ok {
+		return
+	}
+
+	tokens, err := h.oauthService.Token(c.Request.Context(), req)
+	if err != nil {
+		writeTokenError(c, err, req.ClientID, basic)
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+// DeviceAuthorization is the device authorization endpoint of RFC 8628.
+// Clients authenticate the same way as at the token endpoint.
+func (h *OAuthHandler) DeviceAuthorization(c *gin.Context) {
+	c.Header("Cache-Control", "no-store")
+	c.Header("Pragma", "no-cache")
+
+	clientID, clientSecret, basic, ok := readClientCredentials(c)
+	if !ok {
+		return
+	}
+
+	auth, err := h.oauthService.DeviceAuthorization(c.Request.Context(), clientID, clientSecret, c.PostForm("scope"))
+	if err != nil {
+		writeTokenError(c, err, clientID, basic)
+		return
+	}
+
+	c.JSON(http.StatusOK, auth)
+}
+
+// readClientCredentials reads client_secret_basic or client_secret_post
+// credentials and reports a request that uses both.
+func readClientCredentials(c *gin.Context) (clientID, clientSecret string, basic, ok bool) {
+	username, password, basic := c.Request.BasicAuth()
+	if !basic {
+		return c.PostForm("client_id"), c.PostForm("client_secret"), false, true
+	}
+
+	if c.PostForm("client_secret") != "" {
+		writeOAuthError(c, http.StatusBadRequest, services.OAuthInvalidRequest, "multiple client authentication methods")
+		return "", "", true, false
+	}
+	// client_secret_basic credentials are form-urlencoded, RFC 6749 section 2.3.1.
+	clientID, errID := url.QueryUnescape(username)
+	clientSecret, errSecret := url.QueryUnescape(password)
+	if errID != nil || errSecret != nil {
+		writeOAuthError(c, http.StatusBadRequest, services.OAuthInvalidRequest, "malformed client credentials")
+		return "", "", true, false
+	}
+	return clientID, clientSecret, true, true
+}
+
+func writeTokenError(c *gin.Context, err error, clientID string, basic bool) {
+	var oauthErr *services.OAuthError
+	if !errors.As(err, &oauthErr) {
+		log.Printf("Token endpoint error for client %s: %v", clientID, err)
+		writeOAuthError(c, http.StatusInternalServerError, services.OAuthServerError, "")
+		return
+	}
+
+	status := http.StatusBadRequest
+	if oauthErr.Code == services.OAuthInvalidClient {
+		status = http.StatusUnauthorized
+		if basic {
+			c.Header("WWW-Authenticate", `Basic realm="oauth"`)
+		}
+	}
+	writeOAuthError(c, status, oauthErr.Code, oauthErr.Description)
+}
+
+func writeOAuthError(c *gin.Context, status int, code, description string) {
+	body := gin.H{"error": code}
+	if description != "" {
+		body["error_description"] = description
+	}
+	c.JSON(status, body)
+}
@@ -0,0 +1,128 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/auth-service/internal/handlers"
+	"github.com/auth-service/internal/models"
+	"github.com/auth-service/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOAuthHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockOAuth := services.NewMockOAuthServiceInterface(ctrl)
+	handler := handlers.NewOAuthHandler(mockOAuth)
+
+	newRequest := func(form string) (*httptest.ResponseRecorder, *gin.Context) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("POST", "/oauth/token", bytes.NewBufferString(form))
+		c.Request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		c.Request.RemoteAddr = "192.168.1.1:1234"
+		return w, c
+	}
+
+	t.Run("client_secret_basic", func(t *testing.T) {
+		w, c := newRequest("grant_type=client_credentials&scope=invoices%3Aread")
+		c.Request.SetBasicAuth("client1", "s%2Fecret")
+
+		mockOAuth.EXPECT().
+			Token(gomock.Any(), services.TokenRequest{
+				GrantType:    "client_credentials",
+				ClientID:     "client1",
+				ClientSecret: "s/ecret",
+				Scope:        "invoices:read",
+				IP:           net.ParseIP("192.168.1.1"),
+			}).
+			Return(&models.TokenPair{AccessToken: "access", TokenType: "Bearer", ExpiresIn: 900}, nil)
+
+		handler.Token(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+
+		var body map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		assert.Equal(t, "access", body["access_token"])
+		assert.NotContains(t, body, "refresh_token")
+	})
+
+	t.Run("client_secret_post", func(t *testing.T) {
+		w, c := newRequest("grant_type=client_credentials&client_id=client1&client_secret=secret")
+
+		mockOAuth.EXPECT().
+			Token(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ interface{}, req services.TokenRequest) (*models.TokenPair, error) {
+				assert.Equal(t, "client1", req.ClientID)
+				assert.Equal(t, "secret", req.ClientSecret)
+				return &models.TokenPair{AccessToken: "access"}, nil
+			})
+
+		handler.Token(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("Both authentication methods", func(t *testing.T) {
+		w, c := newRequest("grant_type=client_credentials&client_secret=secret")
+		c.Request.SetBasicAuth("client1", "secret")
+
+		handler.Token(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), services.OAuthInvalidRequest)
+	})
+
+	t.Run("Invalid client", func(t *testing.T) {
+		w, c := newRequest("grant_type=client_credentials")
+		c.Request.SetBasicAuth("client1", "wrong")
+
+		mockOAuth.EXPECT().
+			Token(gomock.Any(), gomock.Any()).
+			Return(nil, &services.OAuthError{Code: services.OAuthInvalidClient})
+
+		handler.Token(c)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.NotEmpty(t, w.Header().Get("WWW-Authenticate"))
+		assert.JSONEq(t, `{"error": "invalid_client"}`, w.Body.String())
+	})
+
+	t.Run("Invalid scope", func(t *testing.T) {
+		w, c := newRequest("grant_type=client_credentials&client_id=client1&client_secret=secret&scope=admin")
+
+		mockOAuth.EXPECT().
+			Token(gomock.Any(), gomock.Any()).
+			Return(nil, &services.OAuthError{Code: services.OAuthInvalidScope, Description: "scope admin is not allowed"})
+
+		handler.Token(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "scope admin is not allowed")
+	})
+
+	t.Run("Internal error", func(t *testing.T) {
+		w, c := newRequest("grant_type=client_credentials&client_id=client1&client_secret=secret")
+
+		mockOAuth.EXPECT().
+			Token(gomock.Any(), gomock.Any()).
+			Return(nil, errors.New("db is down"))
+
+		handler.Token(c)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.NotContains(t, w.Body.String(), "db is down")
+	})
+}
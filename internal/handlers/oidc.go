@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/auth-service/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type OIDCHandler struct {
+	userInfoService services.UserInfoServiceInterface
+	issuer          string
+	keySet          services.JSONWebKeySet
+}
+
+func NewOIDCHandler(userInfoService services.UserInfoServiceInterface, issuer string, keySet services.JSONWebKeySet) *OIDCHandler {
+	return &OIDCHandler{
+		userInfoService: userInfoService,
+		issuer:          strings.TrimRight(issuer, "/"),
+		keySet:          keySet,
+	}
+}
+
+// Discovery serves the OpenID Provider metadata, OIDC Discovery section 3.
+func (h *OIDCHandler) Discovery(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"issuer":                                h.issuer,
+		"authorization_endpoint":                h.issuer + "/oauth/authorize",
+		"token_endpoint":                        h.issuer + "/oauth/token",
+		"userinfo_endpoint":                     h.issuer + "/userinfo",
+		"device_authorization_endpoint":         h.issuer + "/oauth/device_authorization",
+		"jwks_uri":                              h.issuer + "/.well-known/jwks.json",
+		"response_types_supported":              []string{"code"},
+		"grant_types_supported":                 []string{services.GrantAuthorizationCode, services.GrantClientCredentials, services.GrantDeviceCode},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"scopes_supported":                      []string{services.ScopeOpenID, services.ScopeProfile, services.ScopeEmail},
+		"token_endpoint_auth_methods_supported": []string{"client_secret_basic", "client_secret_post", "none"},
+		"code_challenge_methods_supported":      []string{services.CodeChallengeMethodS256},
+		"claims_supported": []string{
+			"sub", "iss", "aud", "exp", "iat", "nonce", "auth_time", "acr", "amr",
+			"name", "preferred_username", "updated_at", "picture", "locale", "zoneinfo",
+			"email", "email_verified",
+		},
+	})
+}
+
+func (h *OIDCHandler) JWKS(c *gin.Context) {
+	c.JSON(http.StatusOK, h.keySet)
+}
+
+// UserInfo returns the claims of the token's user released by its scope.
+// The token has to be issued with the openid scope.
+func (h *OIDCHandler) UserInfo(c *gin.Context) {
+	scope := c.GetString("scope")
+	if !containsScope(scope, services.ScopeOpenID) {
+		c.Header("WWW-Authenticate", `Bearer error="insufficient_scope", scope="openid"`)
+		c.JSON(http.StatusForbidden, gin.H{"error": "insufficient_scope"})
+		return
+	}
+
+	claims, err := h.userInfoService.UserInfo(c.Request.Context(), c.GetString("user_id"), scope)
+	if err != nil {
+		if errors.Is(err, services.ErrUserNotFound) {
+			c.Header("WWW-Authenticate", `Bearer error="invalid_token"`)
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid_token"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get user info"})
+		}
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, claims)
+}
+
+func containsScope(scope, want string) bool {
+	for _, s := range strings.Fields(scope) {
+		if s == want {
+			return true
+		}
+	}
+	return false
+}
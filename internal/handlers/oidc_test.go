@@ -0,0 +1,92 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/auth-service/internal/handlers"
+	"github.com/auth-service/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOIDCHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserInfo := services.NewMockUserInfoServiceInterface(ctrl)
+	keySet := services.JSONWebKeySet{Keys: []services.JSONWebKey{{KeyType: "RSA", KeyID: "key1"}}}
+	handler := handlers.NewOIDCHandler(mockUserInfo, "https://auth.example/", keySet)
+
+	newRequest := func(method, path string) (*httptest.ResponseRecorder, *gin.Context) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(method, path, nil)
+		return w, c
+	}
+
+	t.Run("Discovery", func(t *testing.T) {
+		w, c := newRequest("GET", "/.well-known/openid-configuration")
+
+		handler.Discovery(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var body map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		assert.Equal(t, "https://auth.example", body["issuer"])
+		assert.Equal(t, "https://auth.example/userinfo", body["userinfo_endpoint"])
+		assert.Equal(t, "https://auth.example/.well-known/jwks.json", body["jwks_uri"])
+		assert.Equal(t, []interface{}{"S256"}, body["code_challenge_methods_supported"])
+	})
+
+	t.Run("JWKS", func(t *testing.T) {
+		w, c := newRequest("GET", "/.well-known/jwks.json")
+
+		handler.JWKS(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"kid":"key1"`)
+	})
+
+	t.Run("UserInfo", func(t *testing.T) {
+		w, c := newRequest("GET", "/userinfo")
+		c.Set("user_id", "user1")
+		c.Set("scope", "openid email")
+
+		mockUserInfo.EXPECT().
+			UserInfo(gomock.Any(), "user1", "openid email").
+			Return(map[string]interface{}{"sub": "user1", "email": "user@example.com"}, nil)
+
+		handler.UserInfo(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"sub": "user1", "email": "user@example.com"}`, w.Body.String())
+	})
+
+	t.Run("UserInfo without openid scope", func(t *testing.T) {
+		w, c := newRequest("GET", "/userinfo")
+		c.Set("user_id", "user1")
+		c.Set("scope", "invoices:read")
+
+		handler.UserInfo(c)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Contains(t, w.Header().Get("WWW-Authenticate"), `error="insufficient_scope"`)
+	})
+
+	t.Run("UserInfo for deleted user", func(t *testing.T) {
+		w, c := newRequest("POST", "/userinfo")
+		c.Set("user_id", "gone")
+		c.Set("scope", "openid")
+
+		mockUserInfo.EXPECT().UserInfo(gomock.Any(), "gone", "openid").Return(nil, services.ErrUserNotFound)
+
+		handler.UserInfo(c)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
@@ -0,0 +1,236 @@
+package handlers
+
+import (
+	"errors"
+	"net"
+	"net/http"
+
+	"github.com/auth-service/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type OrganizationHandler struct {
+	orgService  services.OrganizationServiceInterface
+	authService services.AuthServiceInterface
+}
+
+func NewOrganizationHandler(orgService services.OrganizationServiceInterface, authService services.AuthServiceInterface) *OrganizationHandler {
+	return &OrganizationHandler{orgService: orgService, authService: authService}
+}
+
+type createOrganizationRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+type inviteRequest struct {
+	Email string `json:"email" binding:"required"`
+	Role  string `json:"role" binding:"required"`
+}
+
+type acceptInvitationRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type changeMemberRoleRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+type createTeamRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+func (h *OrganizationHandler) CreateOrganization(c *gin.Context) {
+	var req createOrganizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request format"})
+		return
+	}
+
+	org, err := h.orgService.Create(c.Request.Context(), c.GetString("user_id"), req.Name, net.ParseIP(c.ClientIP()))
+	if err != nil {
+		h.writeError(c, err, "failed to create organization")
+		return
+	}
+
+	c.JSON(http.StatusCreated, org)
+}
+
+func (h *OrganizationHandler) ListOrganizations(c *gin.Context) {
+	orgs, err := h.orgService.ListForUser(c.Request.Context(), c.GetString("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list organizations"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"organizations": orgs})
+}
+
+// Switch issues a token pair for an organization of the current user. The
+// refresh token keeps the organization for later refreshes.
+func (h *OrganizationHandler) Switch(c *gin.Context) {
+	userID := c.GetString("user_id")
+	if _, err := h.orgService.Membership(c.Request.Context(), c.Param("org_id"), userID); err != nil {
+		h.writeError(c, err, "failed to switch organization")
+		return
+	}
+
+	tokens, err := h.authService.SwitchOrganization(c.Request.Context(), userID, c.GetString("session_id"),
+		c.Param("org_id"), net.ParseIP(c.ClientIP()))
+	if errors.Is(err, services.ErrSessionNotFound) || errors.Is(err, services.ErrSessionClientDisabled) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "session has ended, sign in again"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to generate tokens"})
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+func (h *OrganizationHandler) ListMembers(c *gin.Context) {
+	members, err := h.orgService.Members(c.Request.Context(), c.Param("org_id"), c.GetString("user_id"))
+	if err != nil {
+		h.writeError(c, err, "failed to list members")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"members": members})
+}
+
+func (h *OrganizationHandler) Invite(c *gin.Context) {
+	var req inviteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request format"})
+		return
+	}
+
+	invitation, err := h.orgService.Invite(
+		c.Request.Context(), c.Param("org_id"), c.GetString("user_id"), req.Email, req.Role, net.ParseIP(c.ClientIP()),
+	)
+	if err != nil {
+		h.writeError(c, err, "failed to invite member")
+		return
+	}
+
+	c.JSON(http.StatusCreated, invitation)
+}
+
+func (h *OrganizationHandler) AcceptInvitation(c *gin.Context) {
+	var req acceptInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request format"})
+		return
+	}
+
+	member, err := h.orgService.Accept(c.Request.Context(), c.GetString("user_id"), req.Token, net.ParseIP(c.ClientIP()))
+	if err != nil {
+		h.writeError(c, err, "failed to accept invitation")
+		return
+	}
+
+	c.JSON(http.StatusOK, member)
+}
+
+func (h *OrganizationHandler) ChangeMemberRole(c *gin.Context) {
+	var req changeMemberRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request format"})
+		return
+	}
+
+	err := h.orgService.ChangeRole(
+		c.Request.Context(), c.Param("org_id"), c.GetString("user_id"), c.Param("user_id"), req.Role, net.ParseIP(c.ClientIP()),
+	)
+	if err != nil {
+		h.writeError(c, err, "failed to change member role")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "updated"})
+}
+
+func (h *OrganizationHandler) RemoveMember(c *gin.Context) {
+	err := h.orgService.RemoveMember(
+		c.Request.Context(), c.Param("org_id"), c.GetString("user_id"), c.Param("user_id"), net.ParseIP(c.ClientIP()),
+	)
+	if err != nil {
+		h.writeError(c, err, "failed to remove member")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "removed"})
+}
+
+func (h *OrganizationHandler) CreateTeam(c *gin.Context) {
+	var req createTeamRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request format"})
+		return
+	}
+
+	team, err := h.orgService.CreateTeam(c.Request.Context(), c.Param("org_id"), c.GetString("user_id"), req.Name, net.ParseIP(c.ClientIP()))
+	if err != nil {
+		h.writeError(c, err, "failed to create team")
+		return
+	}
+
+	c.JSON(http.StatusCreated, team)
+}
+
+func (h *OrganizationHandler) ListTeams(c *gin.Context) {
+	teams, err := h.orgService.ListTeams(c.Request.Context(), c.Param("org_id"), c.GetString("user_id"))
+	if err != nil {
+		h.writeError(c, err, "failed to list teams")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"teams": teams})
+}
+
+func (h *OrganizationHandler) AddTeamMember(c *gin.Context) {
+	err := h.orgService.AddTeamMember(
+		c.Request.Context(), c.Param("org_id"), c.Param("team_id"), c.GetString("user_id"), c.Param("user_id"), net.ParseIP(c.ClientIP()),
+	)
+	if err != nil {
+		h.writeError(c, err, "failed to add team member")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "added"})
+}
+
+func (h *OrganizationHandler) RemoveTeamMember(c *gin.Context) {
+	err := h.orgService.RemoveTeamMember(
+		c.Request.Context(), c.Param("org_id"), c.Param("team_id"), c.GetString("user_id"), c.Param("user_id"), net.ParseIP(c.ClientIP()),
+	)
+	if err != nil {
+		h.writeError(c, err, "failed to remove team member")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "removed"})
+}
+
+func (h *OrganizationHandler) writeError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrNotOrgMember):
+		c.JSON(http.StatusNotFound, gin.H{"error": "organization or member not found"})
+	case errors.Is(err, services.ErrTeamNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "team not found"})
+	case errors.Is(err, services.ErrOrgForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": "not allowed in the organization"})
+	case errors.Is(err, services.ErrLastOwner):
+		c.JSON(http.StatusConflict, gin.H{"error": "organization must keep an owner"})
+	case errors.Is(err, services.ErrAlreadyMember):
+		c.JSON(http.StatusConflict, gin.H{"error": "already a member of the organization"})
+	case errors.Is(err, services.ErrTeamExists):
+		c.JSON(http.StatusConflict, gin.H{"error": "team already exists"})
+	case errors.Is(err, services.ErrInvalidInvitation):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid or expired invitation"})
+	case errors.Is(err, services.ErrInvalidOrganization):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
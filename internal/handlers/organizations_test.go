@@ -0,0 +1,129 @@
+package handlers_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/auth-service/internal/handlers"
+	"github.com/auth-service/internal/models"
+	"github.com/auth-service/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrganizationHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockOrgs := services.NewMockOrganizationServiceInterface(ctrl)
+	mockAuth := services.NewMockAuthServiceInterface(ctrl)
+	handler := handlers.NewOrganizationHandler(mockOrgs, mockAuth)
+
+	t.Run("Switch issues tokens for the organization", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("POST", "/api/orgs/org1/switch", nil)
+		c.Request.RemoteAddr = "192.168.1.1:1234"
+		c.Params = gin.Params{{Key: "org_id", Value: "org1"}}
+		c.Set("user_id", "user1")
+		c.Set("session_id", "session1")
+
+		mockOrgs.EXPECT().Membership(gomock.Any(), "org1", "user1").
+			Return(&models.OrganizationMember{OrgID: "org1", UserID: "user1", Role: services.OrgRoleMember}, nil)
+		mockAuth.EXPECT().
+			SwitchOrganization(gomock.Any(), "user1", "session1", "org1", gomock.Any()).
+			Return(&models.TokenPair{AccessToken: "access", RefreshToken: "refresh"}, nil)
+
+		handler.Switch(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"access_token":"access"`)
+	})
+
+	t.Run("Switch without a session", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("POST", "/api/orgs/org1/switch", nil)
+		c.Request.RemoteAddr = "192.168.1.1:1234"
+		c.Params = gin.Params{{Key: "org_id", Value: "org1"}}
+		c.Set("user_id", "user1")
+
+		mockOrgs.EXPECT().Membership(gomock.Any(), "org1", "user1").
+			Return(&models.OrganizationMember{OrgID: "org1", UserID: "user1", Role: services.OrgRoleMember}, nil)
+		mockAuth.EXPECT().
+			SwitchOrganization(gomock.Any(), "user1", "", "org1", gomock.Any()).
+			Return(nil, services.ErrSessionNotFound)
+
+		handler.Switch(c)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("Switch to an organization of someone else", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("POST", "/api/orgs/org2/switch", nil)
+		c.Request.RemoteAddr = "192.168.1.1:1234"
+		c.Params = gin.Params{{Key: "org_id", Value: "org2"}}
+		c.Set("user_id", "user1")
+
+		mockOrgs.EXPECT().Membership(gomock.Any(), "org2", "user1").Return(nil, services.ErrNotOrgMember)
+
+		handler.Switch(c)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("Invite", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("POST", "/api/orgs/org1/invitations", bytes.NewBufferString(
+			`{"email": "new@example.com", "role": "member"}`,
+		))
+		c.Request.RemoteAddr = "192.168.1.1:1234"
+		c.Params = gin.Params{{Key: "org_id", Value: "org1"}}
+		c.Set("user_id", "user1")
+
+		mockOrgs.EXPECT().
+			Invite(gomock.Any(), "org1", "user1", "new@example.com", "member", gomock.Any()).
+			Return(&models.OrganizationInvitation{ID: "inv1", TokenHash: "secret-hash"}, nil)
+
+		handler.Invite(c)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.NotContains(t, w.Body.String(), "secret-hash")
+	})
+
+	t.Run("RemoveMember last owner", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("DELETE", "/api/orgs/org1/members/user1", nil)
+		c.Request.RemoteAddr = "192.168.1.1:1234"
+		c.Params = gin.Params{{Key: "org_id", Value: "org1"}, {Key: "user_id", Value: "user1"}}
+		c.Set("user_id", "user1")
+
+		mockOrgs.EXPECT().RemoveMember(gomock.Any(), "org1", "user1", "user1", gomock.Any()).Return(services.ErrLastOwner)
+
+		handler.RemoveMember(c)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("ChangeMemberRole forbidden", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("PUT", "/api/orgs/org1/members/user2", bytes.NewBufferString(`{"role": "owner"}`))
+		c.Request.RemoteAddr = "192.168.1.1:1234"
+		c.Params = gin.Params{{Key: "org_id", Value: "org1"}, {Key: "user_id", Value: "user2"}}
+		c.Set("user_id", "user3")
+
+		mockOrgs.EXPECT().ChangeRole(gomock.Any(), "org1", "user3", "user2", "owner", gomock.Any()).Return(services.ErrOrgForbidden)
+
+		handler.ChangeMemberRole(c)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
@@ -1,9 +1,11 @@
 package handlers
 
 import (
+	"errors"
 	"net"
 	"net/http"
 
+	"github.com/auth-service/internal/services"
 	"github.com/gin-gonic/gin"
 )
 
@@ -32,6 +34,24 @@ func (h *AuthHandler) RefreshTokens(c *gin.Context) {
 	)
 
 	if err != nil {
+		if writeLockedError(c, err) {
+			return
+		}
+		if errors.Is(err, services.ErrSessionIdleTimeout) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "session_idle_timeout"})
+			return
+		}
+		var stepUp *services.StepUpError
+		if errors.As(err, &stepUp) {
+			c.Header("Cache-Control", "no-store")
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error(), "mfa_token": stepUp.Challenge})
+			return
+		}
+		if errors.Is(err, services.ErrIPChangeDenied) ||
+			errors.Is(err, services.ErrSessionClientDisabled) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
 		errorMsg := "failed to refresh tokens"
 		if err.Error() == "refresh token not found in DB" {
 			c.JSON(http.StatusNotFound, gin.H{"error": errorMsg + ": token not found"})
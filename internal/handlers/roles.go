@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"errors"
+	"net"
+	"net/http"
+
+	"github.com/auth-service/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type RoleHandler struct {
+	rbacService services.RBACServiceInterface
+}
+
+func NewRoleHandler(rbacService services.RBACServiceInterface) *RoleHandler {
+	return &RoleHandler{rbacService: rbacService}
+}
+
+type createRoleRequest struct {
+	Name        string   `json:"name" binding:"required"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+}
+
+type updateRoleRequest struct {
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+	// ForceRefresh makes the users of the role refresh their access tokens.
+	ForceRefresh bool `json:"force_refresh"`
+}
+
+type setUserRolesRequest struct {
+	Roles        []string `json:"roles" binding:"required"`
+	ForceRefresh bool     `json:"force_refresh"`
+}
+
+func (h *RoleHandler) ListRoles(c *gin.Context) {
+	roles, err := h.rbacService.ListRoles(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list roles"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"roles": roles})
+}
+
+func (h *RoleHandler) ListPermissions(c *gin.Context) {
+	permissions, err := h.rbacService.ListPermissions(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list permissions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"permissions": permissions})
+}
+
+func (h *RoleHandler) CreateRole(c *gin.Context) {
+	var req createRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request format"})
+		return
+	}
+
+	role, err := h.rbacService.CreateRole(
+		c.Request.Context(), req.Name, req.Description, req.Permissions, c.GetString("user_id"), net.ParseIP(c.ClientIP()),
+	)
+	if err != nil {
+		h.writeError(c, err, "failed to create role")
+		return
+	}
+
+	c.JSON(http.StatusCreated, role)
+}
+
+func (h *RoleHandler) UpdateRole(c *gin.Context) {
+	var req updateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request format"})
+		return
+	}
+
+	role, err := h.rbacService.UpdateRole(
+		c.Request.Context(), c.Param("id"), req.Description, req.Permissions, req.ForceRefresh,
+		c.GetString("user_id"), net.ParseIP(c.ClientIP()),
+	)
+	if err != nil {
+		h.writeError(c, err, "failed to update role")
+		return
+	}
+
+	c.JSON(http.StatusOK, role)
+}
+
+func (h *RoleHandler) DeleteRole(c *gin.Context) {
+	forceRefresh := c.Query("force_refresh") == "true"
+
+	err := h.rbacService.DeleteRole(c.Request.Context(), c.Param("id"), forceRefresh, c.GetString("user_id"), net.ParseIP(c.ClientIP()))
+	if err != nil {
+		h.writeError(c, err, "failed to delete role")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "deleted"})
+}
+
+func (h *RoleHandler) GetUserRoles(c *gin.Context) {
+	roles, err := h.rbacService.GetUserRoles(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get user roles"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"roles": roles})
+}
+
+func (h *RoleHandler) SetUserRoles(c *gin.Context) {
+	var req setUserRolesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request format"})
+		return
+	}
+
+	roles, err := h.rbacService.SetUserRoles(
+		c.Request.Context(), c.Param("id"), req.Roles, req.ForceRefresh, c.GetString("user_id"), net.ParseIP(c.ClientIP()),
+	)
+	if err != nil {
+		h.writeError(c, err, "failed to set user roles")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"roles": roles})
+}
+
+func (h *RoleHandler) writeError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrRoleNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "role not found"})
+	case errors.Is(err, services.ErrRoleExists):
+		c.JSON(http.StatusConflict, gin.H{"error": "role already exists"})
+	case errors.Is(err, services.ErrInvalidRole):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
@@ -0,0 +1,129 @@
+package handlers_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/auth-service/internal/handlers"
+	"github.com/auth-service/internal/models"
+	"github.com/auth-service/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoleHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRBAC := services.NewMockRBACServiceInterface(ctrl)
+	handler := handlers.NewRoleHandler(mockRBAC)
+
+	t.Run("CreateRole", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("POST", "/admin/roles", bytes.NewBufferString(
+			`{"name": "support", "permissions": ["users:read"]}`,
+		))
+		c.Request.RemoteAddr = "192.168.1.1:1234"
+		c.Set("user_id", "admin1")
+
+		mockRBAC.EXPECT().
+			CreateRole(gomock.Any(), "support", "", []string{"users:read"}, "admin1", gomock.Any()).
+			Return(&models.Role{ID: "role1", Name: "support", Permissions: []string{"users:read"}}, nil)
+
+		handler.CreateRole(c)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Contains(t, w.Body.String(), `"id":"role1"`)
+	})
+
+	t.Run("CreateRole duplicate", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("POST", "/admin/roles", bytes.NewBufferString(`{"name": "support"}`))
+		c.Request.RemoteAddr = "192.168.1.1:1234"
+		c.Set("user_id", "admin1")
+
+		mockRBAC.EXPECT().
+			CreateRole(gomock.Any(), "support", "", nil, "admin1", gomock.Any()).
+			Return(nil, services.ErrRoleExists)
+
+		handler.CreateRole(c)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("UpdateRole unknown role", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("PUT", "/admin/roles/missing", bytes.NewBufferString(`{"permissions": []}`))
+		c.Request.RemoteAddr = "192.168.1.1:1234"
+		c.Params = gin.Params{{Key: "id", Value: "missing"}}
+		c.Set("user_id", "admin1")
+
+		mockRBAC.EXPECT().
+			UpdateRole(gomock.Any(), "missing", "", []string{}, false, "admin1", gomock.Any()).
+			Return(nil, services.ErrRoleNotFound)
+
+		handler.UpdateRole(c)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("DeleteRole with forced refresh", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("DELETE", "/admin/roles/role1?force_refresh=true", nil)
+		c.Request.RemoteAddr = "192.168.1.1:1234"
+		c.Params = gin.Params{{Key: "id", Value: "role1"}}
+		c.Set("user_id", "admin1")
+
+		mockRBAC.EXPECT().
+			DeleteRole(gomock.Any(), "role1", true, "admin1", gomock.Any()).
+			Return(nil)
+
+		handler.DeleteRole(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("SetUserRoles", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("PUT", "/admin/users/user1/roles", bytes.NewBufferString(
+			`{"roles": ["support"], "force_refresh": true}`,
+		))
+		c.Request.RemoteAddr = "192.168.1.1:1234"
+		c.Params = gin.Params{{Key: "id", Value: "user1"}}
+		c.Set("user_id", "admin1")
+
+		mockRBAC.EXPECT().
+			SetUserRoles(gomock.Any(), "user1", []string{"support"}, true, "admin1", gomock.Any()).
+			Return([]models.Role{{Name: "support"}}, nil)
+
+		handler.SetUserRoles(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"name":"support"`)
+	})
+
+	t.Run("SetUserRoles unknown role", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("PUT", "/admin/users/user1/roles", bytes.NewBufferString(`{"roles": ["missing"]}`))
+		c.Request.RemoteAddr = "192.168.1.1:1234"
+		c.Params = gin.Params{{Key: "id", Value: "user1"}}
+		c.Set("user_id", "admin1")
+
+		mockRBAC.EXPECT().
+			SetUserRoles(gomock.Any(), "user1", []string{"missing"}, false, "admin1", gomock.Any()).
+			Return(nil, services.ErrInvalidRole)
+
+		handler.SetUserRoles(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+
+	"github.com/auth-service/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type SessionHandler struct {
+	sessionService services.SessionServiceInterface
+}
+
+func NewSessionHandler(sessionService services.SessionServiceInterface) *SessionHandler {
+	return &SessionHandler{sessionService: sessionService}
+}
+
+// ListSessions returns a page of the active sessions of the current user.
+func (h *SessionHandler) ListSessions(c *gin.Context) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "page must be a positive number"})
+		return
+	}
+	perPage, err := strconv.Atoi(c.DefaultQuery("per_page", strconv.Itoa(services.DefaultSessionsPerPage)))
+	if err != nil || perPage < 1 || perPage > services.MaxSessionsPerPage {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "per_page must be between 1 and " + strconv.Itoa(services.MaxSessionsPerPage)})
+		return
+	}
+
+	sessions, err := h.sessionService.List(c.Request.Context(), c.GetString("user_id"), c.GetString("session_id"), page, perPage)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list sessions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, sessions)
+}
+
+func (h *SessionHandler) RevokeSession(c *gin.Context) {
+	err := h.sessionService.Revoke(c.Request.Context(), c.GetString("user_id"), c.Param("id"), net.ParseIP(c.ClientIP()))
+	if err != nil {
+		if errors.Is(err, services.ErrSessionNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "session not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to revoke session"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "revoked"})
+}
@@ -0,0 +1,64 @@
+package handlers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/auth-service/internal/handlers"
+	"github.com/auth-service/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSessionHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockSessions := services.NewMockSessionServiceInterface(ctrl)
+	handler := handlers.NewSessionHandler(mockSessions)
+
+	t.Run("ListSessions", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/api/sessions?page=2&per_page=5", nil)
+		c.Set("user_id", "user1")
+		c.Set("session_id", "session1")
+
+		mockSessions.EXPECT().List(gomock.Any(), "user1", "session1", 2, 5).
+			Return(&services.SessionPage{Sessions: []services.Session{{ID: "session1", Current: true}}, Total: 6, Page: 2, PerPage: 5}, nil)
+
+		handler.ListSessions(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"current":true`)
+		assert.Contains(t, w.Body.String(), `"total":6`)
+	})
+
+	t.Run("ListSessions with invalid page size", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/api/sessions?per_page=1000", nil)
+		c.Set("user_id", "user1")
+
+		handler.ListSessions(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("RevokeSession of another user", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("DELETE", "/api/sessions/session2", nil)
+		c.Request.RemoteAddr = "192.168.1.1:1234"
+		c.Params = gin.Params{{Key: "id", Value: "session2"}}
+		c.Set("user_id", "user1")
+
+		mockSessions.EXPECT().Revoke(gomock.Any(), "user1", "session2", gomock.Any()).Return(services.ErrSessionNotFound)
+
+		handler.RevokeSession(c)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
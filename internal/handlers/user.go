@@ -1,8 +1,98 @@
 package handlers
 
-import "github.com/gin-gonic/gin"
+import (
+	"errors"
+	"net"
+	"net/http"
 
-func (h *AuthHandler) GetUserData(c *gin.Context) {
-	userID := c.GetString("user_id")
-	c.JSON(200, gin.H{"user_id": userID, "data": "secure_content"})
+	"github.com/auth-service/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type UserHandler struct {
+	profileService services.ProfileServiceInterface
+}
+
+func NewUserHandler(profileService services.ProfileServiceInterface) *UserHandler {
+	return &UserHandler{profileService: profileService}
+}
+
+// updateProfileRequest changes the fields present in the request, null and
+// missing fields are left as they are.
+type updateProfileRequest struct {
+	Name      *string `json:"name"`
+	Email     *string `json:"email"`
+	Locale    *string `json:"locale"`
+	Timezone  *string `json:"timezone"`
+	AvatarURL *string `json:"avatar_url"`
+}
+
+// GetProfile returns the profile of the authenticated user.
+func (h *UserHandler) GetProfile(c *gin.Context) {
+	user, err := h.profileService.Get(c.Request.Context(), c.GetString("user_id"))
+	if err != nil {
+		h.writeError(c, err, "failed to get user")
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// UpdateProfile changes the profile of the authenticated user. A new email
+// is only applied after it has been confirmed from both addresses.
+func (h *UserHandler) UpdateProfile(c *gin.Context) {
+	var req updateProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request format"})
+		return
+	}
+
+	user, change, err := h.profileService.Update(c.Request.Context(), c.GetString("user_id"), services.ProfileUpdate{
+		Name:      req.Name,
+		Email:     req.Email,
+		Locale:    req.Locale,
+		Timezone:  req.Timezone,
+		AvatarURL: req.AvatarURL,
+	}, net.ParseIP(c.ClientIP()))
+	if err != nil {
+		h.writeError(c, err, "failed to update profile")
+		return
+	}
+
+	if change != nil {
+		c.JSON(http.StatusAccepted, gin.H{"user": user, "pending_email": change.NewEmail})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"user": user})
+}
+
+// ConfirmEmail follows a confirmation link sent for an email change.
+func (h *UserHandler) ConfirmEmail(c *gin.Context) {
+	change, applied, err := h.profileService.ConfirmEmailChange(c.Request.Context(), c.Query("token"), net.ParseIP(c.ClientIP()))
+	if err != nil {
+		h.writeError(c, err, "failed to confirm email")
+		return
+	}
+
+	if !applied {
+		c.JSON(http.StatusOK, gin.H{"status": "pending", "email": change.NewEmail})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "changed", "email": change.NewEmail})
+}
+
+func (h *UserHandler) writeError(c *gin.Context, err error, message string) {
+	var validationErr *services.ValidationError
+	switch {
+	case errors.As(err, &validationErr):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validation failed", "fields": validationErr.Fields})
+	case errors.Is(err, services.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+	case errors.Is(err, services.ErrInvalidEmailChange):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid or expired confirmation"})
+	case errors.Is(err, services.ErrEmailTaken):
+		c.JSON(http.StatusConflict, gin.H{"error": "email is already in use"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
 }
@@ -0,0 +1,128 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/auth-service/internal/handlers"
+	"github.com/auth-service/internal/models"
+	"github.com/auth-service/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockProfile := services.NewMockProfileServiceInterface(ctrl)
+	handler := handlers.NewUserHandler(mockProfile)
+
+	t.Run("GetProfile", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/api/me", nil)
+		c.Set("user_id", "user1")
+
+		mockProfile.EXPECT().Get(gomock.Any(), "user1").
+			Return(&models.User{ID: "user1", Email: "user@example.com", PasswordHash: "hash", Locale: "ru-RU"}, nil)
+
+		handler.GetProfile(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"locale":"ru-RU"`)
+		assert.NotContains(t, w.Body.String(), "hash")
+	})
+
+	t.Run("UpdateProfile changes only the sent fields", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("PATCH", "/api/me", bytes.NewBufferString(`{"name": "Ivan", "timezone": "Europe/Moscow"}`))
+		c.Request.RemoteAddr = "192.168.1.1:1234"
+		c.Set("user_id", "user1")
+
+		mockProfile.EXPECT().Update(gomock.Any(), "user1", gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ interface{}, _ string, update services.ProfileUpdate, _ interface{}) (*models.User, *models.EmailChange, error) {
+				require.NotNil(t, update.Name)
+				assert.Equal(t, "Ivan", *update.Name)
+				assert.Equal(t, "Europe/Moscow", *update.Timezone)
+				assert.Nil(t, update.Email)
+				assert.Nil(t, update.Locale)
+				return &models.User{ID: "user1", Name: "Ivan"}, nil, nil
+			})
+
+		handler.UpdateProfile(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("UpdateProfile with a new email waits for confirmation", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("PATCH", "/api/me", bytes.NewBufferString(`{"email": "new@example.com"}`))
+		c.Request.RemoteAddr = "192.168.1.1:1234"
+		c.Set("user_id", "user1")
+
+		mockProfile.EXPECT().Update(gomock.Any(), "user1", gomock.Any(), gomock.Any()).
+			Return(&models.User{ID: "user1", Email: "old@example.com"}, &models.EmailChange{NewEmail: "new@example.com"}, nil)
+
+		handler.UpdateProfile(c)
+
+		assert.Equal(t, http.StatusAccepted, w.Code)
+		assert.Contains(t, w.Body.String(), `"pending_email":"new@example.com"`)
+	})
+
+	t.Run("UpdateProfile validation errors", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("PATCH", "/api/me", bytes.NewBufferString(`{"locale": "not a locale"}`))
+		c.Request.RemoteAddr = "192.168.1.1:1234"
+		c.Set("user_id", "user1")
+
+		mockProfile.EXPECT().Update(gomock.Any(), "user1", gomock.Any(), gomock.Any()).
+			Return(nil, nil, &services.ValidationError{Fields: map[string]string{"locale": "must be a BCP 47 language tag"}})
+
+		handler.UpdateProfile(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		var body struct {
+			Fields map[string]string `json:"fields"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		assert.Contains(t, body.Fields, "locale")
+	})
+
+	t.Run("ConfirmEmail", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/auth/email/confirm?token=abc", nil)
+		c.Request.RemoteAddr = "192.168.1.1:1234"
+
+		mockProfile.EXPECT().ConfirmEmailChange(gomock.Any(), "abc", gomock.Any()).
+			Return(&models.EmailChange{NewEmail: "new@example.com"}, true, nil)
+
+		handler.ConfirmEmail(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `"status":"changed"`)
+	})
+
+	t.Run("ConfirmEmail expired", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/auth/email/confirm?token=old", nil)
+		c.Request.RemoteAddr = "192.168.1.1:1234"
+
+		mockProfile.EXPECT().ConfirmEmailChange(gomock.Any(), "old", gomock.Any()).
+			Return(nil, false, services.ErrInvalidEmailChange)
+
+		handler.ConfirmEmail(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
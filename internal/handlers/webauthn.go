@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"errors"
+	"net"
+	"net/http"
+
+	"github.com/auth-service/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type WebAuthnHandler struct {
+	webAuthnService services.WebAuthnServiceInterface
+	authService     services.AuthServiceInterface
+}
+
+func NewWebAuthnHandler(webAuthnService services.WebAuthnServiceInterface, authService services.AuthServiceInterface) *WebAuthnHandler {
+	return &WebAuthnHandler{
+		webAuthnService: webAuthnService,
+		authService:     authService,
+	}
+}
+
+type webauthnLoginRequest struct {
+	UserID string `json:"user_id"`
+}
+
+func (h *WebAuthnHandler) BeginRegistration(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	options, sessionID, err := h.webAuthnService.BeginRegistration(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to begin registration"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"session_id": sessionID, "options": options})
+}
+
+// FinishRegistration expects the PublicKeyCredential produced by
+// navigator.credentials.create() as the body and the session_id returned by
+// BeginRegistration as a query parameter.
+func (h *WebAuthnHandler) FinishRegistration(c *gin.Context) {
+	userID := c.GetString("user_id")
+
+	body, err := c.GetRawData()
+	if err != nil || len(body) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request format"})
+		return
+	}
+
+	credential, err := h.webAuthnService.FinishRegistration(
+		c.Request.Context(),
+		userID,
+		c.Query("session_id"),
+		body,
+		net.ParseIP(c.ClientIP()),
+	)
+	if err != nil {
+		writeWebAuthnError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"id":            credential.ID,
+		"credential_id": base64.RawURLEncoding.EncodeToString(credential.CredentialID),
+		"transports":    credential.Transports,
+	})
+}
+
+// BeginLogin accepts an optional user_id; without it a discoverable
+// credential (passkey) login is started.
+func (h *WebAuthnHandler) BeginLogin(c *gin.Context) {
+	var req webauthnLoginRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request format"})
+			return
+		}
+	}
+
+	options, sessionID, err := h.webAuthnService.BeginLogin(c.Request.Context(), req.UserID)
+	if err != nil {
+		if errors.Is(err, services.ErrWebAuthnNoCredentials) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "no passkeys registered"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to begin login"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"session_id": sessionID, "options": options})
+}
+
+func (h *WebAuthnHandler) FinishLogin(c *gin.Context) {
+	ip := net.ParseIP(c.ClientIP())
+	if ip == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid IP address"})
+		return
+	}
+
+	body, err := c.GetRawData()
+	if err != nil || len(body) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request format"})
+		return
+	}
+
+	userID, err := h.webAuthnService.FinishLogin(c.Request.Context(), c.Query("session_id"), body, ip)
+	if err != nil {
+		writeWebAuthnError(c, err)
+		return
+	}
+
+	tokens, err := h.authService.GenerateTokens(c.Request.Context(), userID, ip)
+	if err != nil {
+		writeIssueError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+func writeWebAuthnError(c *gin.Context, err error) {
+	if writeLockedError(c, err) {
+		return
+	}
+	switch {
+	case errors.Is(err, services.ErrWebAuthnSessionNotFound):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "webauthn session not found or expired"})
+	case errors.Is(err, services.ErrWebAuthnVerification), errors.Is(err, services.ErrWebAuthnCloneDetected):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "webauthn verification failed"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "webauthn ceremony failed"})
+	}
+}
@@ -0,0 +1,125 @@
+package handlers_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/auth-service/internal/handlers"
+	"github.com/auth-service/internal/models"
+	"github.com/auth-service/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebAuthnHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockWebAuthn := services.NewMockWebAuthnServiceInterface(ctrl)
+	mockAuth := services.NewMockAuthServiceInterface(ctrl)
+	handler := handlers.NewWebAuthnHandler(mockWebAuthn, mockAuth)
+
+	t.Run("BeginRegistration", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("POST", "/auth/webauthn/register/begin", nil)
+		c.Set("user_id", "user1")
+
+		mockWebAuthn.EXPECT().
+			BeginRegistration(gomock.Any(), "user1").
+			Return(&protocol.CredentialCreation{}, "session-1", nil)
+
+		handler.BeginRegistration(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), "session-1")
+	})
+
+	t.Run("FinishRegistration", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("POST", "/auth/webauthn/register/finish?session_id=session-1",
+			bytes.NewBufferString(`{"id": "cred"}`))
+		c.Request.RemoteAddr = "192.168.1.1:1234"
+		c.Set("user_id", "user1")
+
+		mockWebAuthn.EXPECT().
+			FinishRegistration(gomock.Any(), "user1", "session-1", []byte(`{"id": "cred"}`), gomock.Any()).
+			Return(&models.WebAuthnCredential{ID: "id-1", CredentialID: []byte("cred")}, nil)
+
+		handler.FinishRegistration(c)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+	})
+
+	t.Run("FinishLogin", func(t *testing.T) {
+		t.Run("Success", func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("POST", "/auth/webauthn/login/finish?session_id=session-2",
+				bytes.NewBufferString(`{"id": "cred"}`))
+			c.Request.RemoteAddr = "192.168.1.1:1234"
+
+			mockWebAuthn.EXPECT().
+				FinishLogin(gomock.Any(), "session-2", gomock.Any(), gomock.Any()).
+				Return("user1", nil)
+			mockAuth.EXPECT().
+				GenerateTokens(gomock.Any(), "user1", gomock.Any()).
+				Return(&models.TokenPair{AccessToken: "access", RefreshToken: "refresh"}, nil)
+
+			handler.FinishLogin(c)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+		})
+
+		t.Run("Verification failed", func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("POST", "/auth/webauthn/login/finish?session_id=session-3",
+				bytes.NewBufferString(`{"id": "cred"}`))
+			c.Request.RemoteAddr = "192.168.1.1:1234"
+
+			mockWebAuthn.EXPECT().
+				FinishLogin(gomock.Any(), "session-3", gomock.Any(), gomock.Any()).
+				Return("", services.ErrWebAuthnVerification)
+
+			handler.FinishLogin(c)
+
+			assert.Equal(t, http.StatusUnauthorized, w.Code)
+		})
+
+		t.Run("Repeated failures are locked out", func(t *testing.T) {
+			finish := func() *httptest.ResponseRecorder {
+				w := httptest.NewRecorder()
+				c, _ := gin.CreateTestContext(w)
+				c.Request = httptest.NewRequest("POST", "/auth/webauthn/login/finish?session_id=session-4",
+					bytes.NewBufferString(`{"id": "cred"}`))
+				c.Request.RemoteAddr = "192.168.1.1:1234"
+				handler.FinishLogin(c)
+				return w
+			}
+
+			gomock.InOrder(
+				mockWebAuthn.EXPECT().
+					FinishLogin(gomock.Any(), "session-4", gomock.Any(), gomock.Any()).
+					Return("", services.ErrWebAuthnVerification).
+					Times(3),
+				mockWebAuthn.EXPECT().
+					FinishLogin(gomock.Any(), "session-4", gomock.Any(), gomock.Any()).
+					Return("", &services.LockedError{RetryAfter: 90 * time.Second}),
+			)
+
+			for i := 0; i < 3; i++ {
+				assert.Equal(t, http.StatusUnauthorized, finish().Code)
+			}
+			w := finish()
+			assert.Equal(t, http.StatusTooManyRequests, w.Code)
+			assert.Equal(t, "90", w.Header().Get("Retry-After"))
+		})
+	})
+}
@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/auth-service/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// RequirePermission lets through only users whose roles grant the
+// permission. It has to run after JWTValidator.
+func RequirePermission(accessProvider services.AccessProvider, permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		access, ok := currentAccess(c, accessProvider)
+		if !ok {
+			return
+		}
+		if !access.Can(permission) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "permission " + permission + " required"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// RejectStaleTokens makes clients refresh access tokens that were issued
+// before the roles of the user changed. Requests with API keys are let
+// through, keys carry no roles.
+func RejectStaleTokens(accessProvider services.AccessProvider) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString("api_key_id") != "" {
+			c.Next()
+			return
+		}
+		if _, ok := currentAccess(c, accessProvider); ok {
+			c.Next()
+		}
+	}
+}
+
+// RejectImpersonation keeps admins acting as a user away from the
+// credentials and personal data of the user. It has to run after
+// JWTValidator.
+func RejectImpersonation() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString("actor_id") != "" {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "not allowed while impersonating a user"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// currentAccess loads the access of the authenticated user and aborts the
+// request when it cannot be loaded or the token is stale.
+func currentAccess(c *gin.Context, accessProvider services.AccessProvider) (*services.Access, bool) {
+	access, err := accessProvider.Access(c.Request.Context(), c.GetString("user_id"))
+	if err != nil {
+		log.Printf("Failed to load access of user %s: %v", c.GetString("user_id"), err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "failed to check permissions"})
+		return nil, false
+	}
+
+	if !access.TokensNotBefore.IsZero() && c.GetTime("issued_at").Before(access.TokensNotBefore) {
+		c.Header("WWW-Authenticate", `Bearer error="invalid_token", error_description="roles changed, refresh the token"`)
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "token is outdated, refresh it"})
+		return nil, false
+	}
+	return access, true
+}
@@ -1,7 +1,10 @@
 package middleware
 
 import (
+	"errors"
 	"log"
+	"net"
+	"slices"
 	"strings"
 
 	"github.com/auth-service/internal/services"
@@ -20,15 +23,131 @@ func JWTValidator(tokenService *services.TokenService) gin.HandlerFunc {
 			tokenString = tokenString[7:]
 		}
 
-		claims, err := tokenService.ParseAccessToken(tokenString)
+		validateJWT(c, tokenService, tokenString)
+	}
+}
+
+// CredentialsValidator accepts an access token like JWTValidator or an API
+// key sent as "Authorization: ApiKey <prefix.secret>", and sets the same
+// context values for both. Routes it guards should check the scope of keys
+// with RequireAPIKeyScope.
+func CredentialsValidator(tokenService *services.TokenService, apiKeys services.APIKeyServiceInterface) gin.HandlerFunc {
+	jwtValidator := JWTValidator(tokenService)
+
+	return func(c *gin.Context) {
+		rawKey, ok := strings.CutPrefix(c.GetHeader("Authorization"), "ApiKey ")
+		if !ok {
+			jwtValidator(c)
+			return
+		}
+
+		key, err := apiKeys.Authenticate(c.Request.Context(), strings.TrimSpace(rawKey), net.ParseIP(c.ClientIP()))
 		if err != nil {
-			log.Printf("JWT validation failed: %v", err)
-			c.AbortWithStatusJSON(401, gin.H{"error": "Invalid token: " + err.Error()})
+			if errors.Is(err, services.ErrInvalidAPIKey) {
+				c.AbortWithStatusJSON(401, gin.H{"error": "Invalid API key"})
+			} else {
+				log.Printf("API key validation failed: %v", err)
+				c.AbortWithStatusJSON(500, gin.H{"error": "failed to validate API key"})
+			}
+			return
+		}
+
+		c.Set("user_id", key.UserID)
+		c.Set("tenant_id", key.TenantID)
+		c.Set("api_key_id", key.ID)
+		c.Set("scope", strings.Join(key.Scopes, " "))
+		c.Next()
+	}
+}
+
+// RequireAPIKeyScope lets through requests with an API key only when the key
+// has the scope. Requests with an access token are let through. It has to
+// run after CredentialsValidator.
+func RequireAPIKeyScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString("api_key_id") != "" && !slices.Contains(strings.Fields(c.GetString("scope")), scope) {
+			c.AbortWithStatusJSON(403, gin.H{"error": "API key scope " + scope + " required"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// RejectClientTokens keeps access tokens issued to OAuth clients away from
+// the routes that change the account or its credentials: a client acts
+// within its scope, not as the user. It has to run after JWTValidator.
+func RejectClientTokens() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString("client_id") != "" {
+			c.AbortWithStatusJSON(403, gin.H{"error": "not allowed with a token issued to a client"})
 			return
 		}
+		c.Next()
+	}
+}
 
-		c.Set("user_id", claims.UserID)
-		c.Set("ip", claims.IP)
+// CaptureDevice puts the user agent of the request and the device name from
+// the X-Device-Name header into its context, so they are stored with the
+// sessions issued for the request.
+func CaptureDevice() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := services.WithUserAgent(c.Request.Context(), c.Request.UserAgent())
+		ctx = services.WithDeviceName(ctx, c.GetHeader("X-Device-Name"))
+		c.Request = c.Request.WithContext(ctx)
 		c.Next()
 	}
 }
+
+func validateJWT(c *gin.Context, tokenService *services.TokenService, tokenString string) {
+	claims, err := tokenService.ParseAccessToken(tokenString)
+	if err != nil {
+		log.Printf("JWT validation failed: %v", err)
+		c.AbortWithStatusJSON(401, gin.H{"error": "Invalid token: " + err.Error()})
+		return
+	}
+
+	// Tokens issued to clients on their own behalf carry no user.
+	if claims.UserID == "" {
+		c.AbortWithStatusJSON(401, gin.H{"error": "Invalid token: no user"})
+		return
+	}
+
+	revoked, err := tokenService.IsRevoked(c.Request.Context(), claims)
+	if err != nil {
+		log.Printf("Failed to check revocation of token of user %s: %v", claims.UserID, err)
+		c.AbortWithStatusJSON(500, gin.H{"error": "failed to check token"})
+		return
+	}
+	if revoked {
+		c.AbortWithStatusJSON(401, gin.H{"error": "Invalid token: revoked"})
+		return
+	}
+
+	tenantID := claims.TenantID
+	if tenantID == "" {
+		tenantID = services.DefaultTenant
+	}
+	if tenantID != services.TenantFromContext(c.Request.Context()) {
+		c.AbortWithStatusJSON(401, gin.H{"error": "Invalid token: issued for another tenant"})
+		return
+	}
+
+	c.Set("user_id", claims.UserID)
+	c.Set("tenant_id", tenantID)
+	c.Set("ip", claims.IP)
+	c.Set("client_id", claims.ClientID)
+	c.Set("scope", claims.Scope)
+	c.Set("roles", claims.Roles)
+	c.Set("session_id", claims.SessionID)
+	c.Set("token_id", claims.ID)
+	if claims.Actor != nil {
+		c.Set("actor_id", claims.Actor.Subject)
+	}
+	if claims.IssuedAt != nil {
+		c.Set("issued_at", claims.IssuedAt.Time)
+	}
+	if claims.ExpiresAt != nil {
+		c.Set("expires_at", claims.ExpiresAt.Time)
+	}
+	c.Next()
+}
@@ -0,0 +1,85 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/auth-service/internal/middleware"
+	"github.com/auth-service/internal/models"
+	"github.com/auth-service/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIKeyScopes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAPIKeys := services.NewMockAPIKeyServiceInterface(ctrl)
+	router := gin.New()
+	router.GET("/api/me",
+		middleware.CredentialsValidator(services.NewTokenService("test-secret"), mockAPIKeys),
+		middleware.RequireAPIKeyScope(services.ScopeProfileRead),
+		func(c *gin.Context) {
+			c.JSON(http.StatusOK, gin.H{"user_id": c.GetString("user_id"), "tenant_id": c.GetString("tenant_id")})
+		})
+
+	request := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/api/me", nil)
+		req.Header.Set("Authorization", "ApiKey abc.secret")
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Key with the scope", func(t *testing.T) {
+		mockAPIKeys.EXPECT().Authenticate(gomock.Any(), "abc.secret", gomock.Any()).
+			Return(&models.APIKey{ID: "key1", TenantID: services.DefaultTenant, UserID: "user1", Scopes: []string{services.ScopeProfileRead}}, nil)
+
+		w := request()
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"user_id": "user1", "tenant_id": "default"}`, w.Body.String())
+	})
+
+	t.Run("Key without the scope", func(t *testing.T) {
+		mockAPIKeys.EXPECT().Authenticate(gomock.Any(), "abc.secret", gomock.Any()).
+			Return(&models.APIKey{ID: "key1", TenantID: services.DefaultTenant, UserID: "user1", Scopes: []string{}}, nil)
+
+		w := request()
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
+
+func TestRejectClientTokens(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tokenSvc := services.NewTokenService("test-secret")
+	router := gin.New()
+	router.DELETE("/api/me", middleware.JWTValidator(tokenSvc), middleware.RejectClientTokens(), func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+
+	request := func(claims services.TokenClaims) *httptest.ResponseRecorder {
+		token, err := tokenSvc.SignAccessToken(claims, time.Minute)
+		require.NoError(t, err)
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodDelete, "/api/me", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("Token of the user", func(t *testing.T) {
+		w := request(services.TokenClaims{UserID: "user1"})
+		assert.Equal(t, http.StatusNoContent, w.Code)
+	})
+
+	t.Run("Token of a client", func(t *testing.T) {
+		w := request(services.TokenClaims{UserID: "user1", ClientID: "spa", Scope: "openid profile"})
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
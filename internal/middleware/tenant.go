@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/auth-service/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// ResolveTenant puts the tenant of the request into its context before it is
+// routed and strips the /t/<tenant> prefix, so the same routes serve every
+// tenant.
+func ResolveTenant(tenants *services.Tenants, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tenant, path, err := tenants.Resolve(r.Host, r.Header.Get(services.TenantHeader), r.URL.Path)
+		if err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(map[string]string{"error": "unknown tenant"})
+			return
+		}
+
+		ctx := services.WithTenant(r.Context(), tenant.ID)
+		if path != r.URL.Path {
+			ctx = services.WithTenantPath(ctx, strings.TrimSuffix(r.URL.Path, path))
+			r.URL.Path = path
+			r.URL.RawPath = ""
+		}
+		r = r.WithContext(ctx)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// RequireTenant lets through only requests of the given tenant, for settings
+// shared by the whole deployment.
+func RequireTenant(tenantID string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if services.TenantFromContext(c.Request.Context()) != tenantID {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "not available for this tenant"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireTenantUser hides users of other tenants from routes with the user
+// ID in the "id" parameter.
+func RequireTenantUser(membership services.TenantMembership) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		owned, err := membership.OwnsUser(c.Request.Context(), c.Param("id"))
+		if err != nil {
+			log.Printf("Failed to check tenant of user %s: %v", c.Param("id"), err)
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "failed to get user"})
+			return
+		}
+		if !owned {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "user not found"})
+			return
+		}
+		c.Next()
+	}
+}
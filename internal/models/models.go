@@ -4,14 +4,330 @@ import "time"
 
 type TokenPair struct {
 	AccessToken  string `json:"access_token"`
-	RefreshToken string `json:"refresh_token"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	TokenType    string `json:"token_type,omitempty"`
+	ExpiresIn    int    `json:"expires_in,omitempty"`
+	Scope        string `json:"scope,omitempty"`
+	IDToken      string `json:"id_token,omitempty"`
 }
 
+// RefreshToken is a session of a user. The ID stays the same when the token
+// is rotated. IP, Location and the device fields are recorded at login,
+// LastIP, LastLocation and LastUsedAt on every refresh. DeviceName is the
+// name the client gave the device.
 type RefreshToken struct {
+	ID        string `json:"id"`
+	TenantID  string `json:"tenant_id"`
+	UserID    string `json:"user_id"`
+	TokenHash string `json:"token_hash"`
+	IP        string `json:"ip"`
+	LastIP    string `json:"last_ip,omitempty"`
+	// Location and LastLocation are looked up in the GeoIP database and
+	// stay empty when it is not configured.
+	Location     GeoLocation `json:"location"`
+	LastLocation GeoLocation `json:"last_location"`
+	UserAgent    string      `json:"user_agent,omitempty"`
+	DeviceName   string      `json:"device_name,omitempty"`
+	Device       string      `json:"device,omitempty"`
+	OS           string      `json:"os,omitempty"`
+	Browser      string      `json:"browser,omitempty"`
+	ClientID     string      `json:"client_id,omitempty"`
+	Scope        string      `json:"scope,omitempty"`
+	Roles        []string    `json:"roles,omitempty"`
+	OrgID        string      `json:"org_id,omitempty"`
+	// IdleTimeout overrides the global idle timeout of the session.
+	IdleTimeout time.Duration `json:"idle_timeout,omitempty"`
+	// IPChangePolicy overrides the global IP change mode of the session.
+	IPChangePolicy string `json:"ip_change_policy,omitempty"`
+	// AccessTokenTTL and RefreshTokenTTL are the lifetimes the session was
+	// opened with, zero means the defaults.
+	AccessTokenTTL  time.Duration `json:"access_token_ttl,omitempty"`
+	RefreshTokenTTL time.Duration `json:"refresh_token_ttl,omitempty"`
+	CreatedAt       time.Time     `json:"created_at"`
+	LastUsedAt      *time.Time    `json:"last_used_at,omitempty"`
+	ExpiresAt       time.Time     `json:"expires_at"`
+}
+
+// GeoLocation is where an IP address is, as far as the GeoIP database knows.
+// Country is the ISO 3166-1 code, ASN the autonomous system of the network.
+type GeoLocation struct {
+	Country string `json:"country,omitempty"`
+	City    string `json:"city,omitempty"`
+	ASN     uint32 `json:"asn,omitempty"`
+	ASOrg   string `json:"as_org,omitempty"`
+}
+
+func (l GeoLocation) IsZero() bool {
+	return l == GeoLocation{}
+}
+
+type RecoveryCode struct {
+	ID        string     `json:"id"`
+	TenantID  string     `json:"tenant_id"`
+	UserID    string     `json:"user_id"`
+	CodeHash  string     `json:"code_hash"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+type AuditEvent struct {
+	ID        string            `json:"id"`
+	UserID    string            `json:"user_id"`
+	Type      string            `json:"event_type"`
+	IP        string            `json:"ip"`
+	Details   map[string]string `json:"details,omitempty"`
+	CreatedAt time.Time         `json:"created_at"`
+}
+
+type WebAuthnCredential struct {
+	ID              string     `json:"id"`
+	TenantID        string     `json:"tenant_id"`
+	UserID          string     `json:"user_id"`
+	CredentialID    []byte     `json:"credential_id"`
+	PublicKey       []byte     `json:"-"`
+	AttestationType string     `json:"attestation_type"`
+	AAGUID          []byte     `json:"aaguid"`
+	SignCount       uint32     `json:"sign_count"`
+	Transports      []string   `json:"transports"`
+	BackupEligible  bool       `json:"backup_eligible"`
+	BackupState     bool       `json:"backup_state"`
+	CreatedAt       time.Time  `json:"created_at"`
+	LastUsedAt      *time.Time `json:"last_used_at,omitempty"`
+}
+
+// WebAuthnSession keeps the challenge of an unfinished registration or login
+// ceremony between its begin and finish requests.
+type WebAuthnSession struct {
 	ID        string    `json:"id"`
 	UserID    string    `json:"user_id"`
-	TokenHash string    `json:"token_hash"`
-	IP        string    `json:"ip"`
+	Ceremony  string    `json:"ceremony"`
+	Data      []byte    `json:"data"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// AuthFailure counts failed credential attempts for one account or IP.
+type AuthFailure struct {
+	Scope        string     `json:"scope"`
+	Key          string     `json:"key"`
+	Failures     int        `json:"failures"`
+	LastFailedAt time.Time  `json:"last_failed_at"`
+	LockedUntil  *time.Time `json:"locked_until,omitempty"`
+}
+
+// User is an account. Roles are not loaded with the user: they are set by
+// the authenticator that verified the user, e.g. from directory groups.
+type User struct {
+	ID            string     `json:"id"`
+	TenantID      string     `json:"tenant_id"`
+	Email         string     `json:"email"`
+	PasswordHash  string     `json:"-"`
+	Name          string     `json:"name"`
+	EmailVerified bool       `json:"email_verified"`
+	Locale        string     `json:"locale"`
+	Timezone      string     `json:"timezone"`
+	AvatarURL     string     `json:"avatar_url"`
+	Roles         []string   `json:"roles,omitempty"`
+	DeleteAfter   *time.Time `json:"delete_after,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// SecurityAlert is a security notification sent to a user.
+type SecurityAlert struct {
+	ID        string    `json:"id"`
+	UserID    string    `json:"user_id"`
+	Message   string    `json:"message"`
 	CreatedAt time.Time `json:"created_at"`
+}
+
+// EmailChange is a pending change of the email of a user. It is applied once
+// both addresses have confirmed it, only hashes of the tokens are stored.
+type EmailChange struct {
+	ID             string     `json:"id"`
+	UserID         string     `json:"user_id"`
+	NewEmail       string     `json:"new_email"`
+	OldTokenHash   string     `json:"-"`
+	NewTokenHash   string     `json:"-"`
+	OldConfirmedAt *time.Time `json:"old_confirmed_at,omitempty"`
+	NewConfirmedAt *time.Time `json:"new_confirmed_at,omitempty"`
+	ExpiresAt      time.Time  `json:"expires_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// LoginCode is a pending passwordless login: a magic link and a 6-digit
+// code bound to the browser session that requested them.
+type LoginCode struct {
+	ID        string     `json:"id"`
+	TenantID  string     `json:"tenant_id"`
+	UserID    string     `json:"user_id"`
+	CodeHash  string     `json:"code_hash"`
+	NonceHash string     `json:"nonce_hash"`
+	Attempts  int        `json:"attempts"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+}
+
+// OAuthClient is a registered client application. Public clients (single-page
+// and mobile apps) have no secret and must use PKCE. Zero token lifetimes
+// fall back to the service defaults.
+type OAuthClient struct {
+	ID              string        `json:"id"`
+	ClientID        string        `json:"client_id"`
+	Name            string        `json:"name"`
+	SecretHash      string        `json:"-"`
+	Public          bool          `json:"public"`
+	GrantTypes      []string      `json:"grant_types"`
+	RedirectURIs    []string      `json:"redirect_uris"`
+	Scopes          []string      `json:"scopes"`
+	AccessTokenTTL  time.Duration `json:"access_token_ttl"`
+	RefreshTokenTTL time.Duration `json:"refresh_token_ttl"`
+	MaxSessions     int           `json:"max_sessions"`
+	IdleTimeout     time.Duration `json:"idle_timeout"`
+	// IPChangePolicy is the IP change mode of sessions with the client,
+	// empty for the service default.
+	IPChangePolicy string     `json:"ip_change_policy,omitempty"`
+	DisabledAt     *time.Time `json:"disabled_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// AuthorizationCode is a single-use code issued by the authorization
+// endpoint. Only the SHA-256 of the code is stored. AMR lists the methods the
+// user authenticated with (RFC 8176).
+type AuthorizationCode struct {
+	ID            string    `json:"id"`
+	TenantID      string    `json:"tenant_id"`
+	CodeHash      string    `json:"code_hash"`
+	ClientID      string    `json:"client_id"`
+	UserID        string    `json:"user_id"`
+	RedirectURI   string    `json:"redirect_uri"`
+	Scope         string    `json:"scope"`
+	Nonce         string    `json:"nonce"`
+	CodeChallenge string    `json:"code_challenge"`
+	AMR           []string  `json:"amr"`
+	AuthTime      time.Time `json:"auth_time"`
+	CreatedAt     time.Time `json:"created_at"`
+	ExpiresAt     time.Time `json:"expires_at"`
+}
+
+// Statuses of a device code.
+const (
+	DeviceCodePending  = "pending"
+	DeviceCodeApproved = "approved"
+	DeviceCodeDenied   = "denied"
+)
+
+// DeviceCode is a pending device authorization (RFC 8628). The device polls
+// with the device code, of which only the SHA-256 is stored, while the user
+// approves the user code in a browser. UserID is set once the user decided.
+type DeviceCode struct {
+	ID             string     `json:"id"`
+	TenantID       string     `json:"tenant_id"`
+	DeviceCodeHash string     `json:"device_code_hash"`
+	UserCode       string     `json:"user_code"`
+	ClientID       string     `json:"client_id"`
+	Scope          string     `json:"scope"`
+	Status         string     `json:"status"`
+	UserID         string     `json:"user_id,omitempty"`
+	LastPolledAt   *time.Time `json:"last_polled_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	ExpiresAt      time.Time  `json:"expires_at"`
+}
+
+// FederatedIdentity links an account to a user of an upstream OpenID
+// provider, identified by the provider's issuer and subject.
+type FederatedIdentity struct {
+	ID        string    `json:"id"`
+	TenantID  string    `json:"tenant_id"`
+	UserID    string    `json:"user_id"`
+	Provider  string    `json:"provider"`
+	Issuer    string    `json:"issuer"`
+	Subject   string    `json:"subject"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// FederatedLogin is a login redirected to an upstream provider and waiting
+// for its callback. Only the SHA-256 of the state is stored.
+type FederatedLogin struct {
+	StateHash    string    `json:"state_hash"`
+	TenantID     string    `json:"tenant_id"`
+	Provider     string    `json:"provider"`
+	Nonce        string    `json:"nonce"`
+	CodeVerifier string    `json:"code_verifier"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+// APIKey is a long-lived credential of a user for scripts and CI jobs, sent
+// as "prefix.secret". The prefix is public and finds the key, only the
+// SHA-256 of the secret is stored.
+type APIKey struct {
+	ID         string     `json:"id"`
+	TenantID   string     `json:"tenant_id"`
+	UserID     string     `json:"user_id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	SecretHash string     `json:"-"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	LastUsedIP string     `json:"last_used_ip,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// Role is a named set of permissions assigned to users by administrators.
+type Role struct {
+	ID          string    `json:"id"`
+	TenantID    string    `json:"tenant_id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Permissions []string  `json:"permissions"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type Permission struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// Organization groups users of a tenant. Role is the role of the user the
+// organization was listed for.
+type Organization struct {
+	ID        string    `json:"id"`
+	TenantID  string    `json:"tenant_id"`
+	Name      string    `json:"name"`
+	Role      string    `json:"role,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// OrganizationMember is a user in an organization with their role there and
+// the IDs of the organization's teams they are in.
+type OrganizationMember struct {
+	OrgID     string    `json:"org_id"`
+	UserID    string    `json:"user_id"`
+	Email     string    `json:"email,omitempty"`
+	Role      string    `json:"role"`
+	Teams     []string  `json:"teams"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// OrganizationInvitation lets the owner of the email join an organization.
+// Only the SHA-256 of the invitation token is stored.
+type OrganizationInvitation struct {
+	ID        string    `json:"id"`
+	OrgID     string    `json:"org_id"`
+	Email     string    `json:"email"`
+	Role      string    `json:"role"`
+	TokenHash string    `json:"-"`
+	InvitedBy string    `json:"invited_by"`
 	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type Team struct {
+	ID        string    `json:"id"`
+	OrgID     string    `json:"org_id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
 }
@@ -0,0 +1,171 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/auth-service/internal/models"
+)
+
+// DeletedUserID replaces the user ID of the audit events of purged accounts.
+const DeletedUserID = "deleted"
+
+// personalDataTables hold rows of a user that are deleted with the account,
+// users itself is deleted last.
+var personalDataTables = []string{
+	"refresh_tokens",
+	"mfa_recovery_codes",
+	"webauthn_credentials",
+	"webauthn_sessions",
+	"login_codes",
+	"authorization_codes",
+	"device_codes",
+	"federated_identities",
+	"api_keys",
+	"user_roles",
+	"token_cutoffs",
+	"team_members",
+	"organization_members",
+	"email_changes",
+	"security_alerts",
+}
+
+func (p *Postgres) SaveSecurityAlert(ctx context.Context, alert *models.SecurityAlert) error {
+	err := p.db.QueryRowContext(
+		context.WithoutCancel(ctx),
+		`INSERT INTO security_alerts (user_id, message)
+		VALUES ($1, $2)
+		RETURNING id, created_at`,
+		alert.UserID, alert.Message,
+	).Scan(&alert.ID, &alert.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save security alert: %w", err)
+	}
+	return nil
+}
+
+func (p *Postgres) ListSecurityAlerts(ctx context.Context, userID string) ([]models.SecurityAlert, error) {
+	rows, err := p.db.QueryContext(ctx,
+		`SELECT id, user_id, message, created_at
+		FROM security_alerts
+		WHERE user_id = $1
+		ORDER BY created_at`,
+		userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list security alerts: %w", err)
+	}
+	defer rows.Close()
+
+	var alerts []models.SecurityAlert
+	for rows.Next() {
+		var alert models.SecurityAlert
+		if err := rows.Scan(&alert.ID, &alert.UserID, &alert.Message, &alert.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan security alert: %w", err)
+		}
+		alerts = append(alerts, alert)
+	}
+	return alerts, rows.Err()
+}
+
+// ScheduleUserDeletion marks the account for deletion and revokes the
+// refresh tokens and API keys of the user.
+func (p *Postgres) ScheduleUserDeletion(ctx context.Context, userID string, deleteAfter time.Time) error {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx,
+		`UPDATE users SET delete_after = $2, updated_at = NOW() WHERE id = $1`,
+		userID, deleteAfter)
+	if err != nil {
+		return fmt.Errorf("failed to schedule user deletion: %w", err)
+	}
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return ErrNotFound
+	}
+	for _, table := range []string{"refresh_tokens", "api_keys"} {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM `+table+` WHERE user_id = $1`, userID); err != nil {
+			return fmt.Errorf("failed to revoke %s: %w", table, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit user deletion: %w", err)
+	}
+	return nil
+}
+
+func (p *Postgres) CancelUserDeletion(ctx context.Context, userID string) error {
+	result, err := p.db.ExecContext(ctx,
+		`UPDATE users SET delete_after = NULL, updated_at = NOW() WHERE id = $1 AND delete_after IS NOT NULL`,
+		userID)
+	if err != nil {
+		return fmt.Errorf("failed to cancel user deletion: %w", err)
+	}
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// ListUsersDueForDeletion returns up to limit users whose grace period ended
+// before now.
+func (p *Postgres) ListUsersDueForDeletion(ctx context.Context, now time.Time, limit int) ([]string, error) {
+	rows, err := p.db.QueryContext(ctx,
+		`SELECT id FROM users WHERE delete_after IS NOT NULL AND delete_after <= $1 ORDER BY delete_after LIMIT $2`,
+		now, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list users due for deletion: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan user id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// PurgeUser anonymizes the audit events of a user and deletes the account
+// with all of its personal data.
+func (p *Postgres) PurgeUser(ctx context.Context, userID string) error {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx,
+		`UPDATE audit_events SET user_id = $2, ip = '', details = '{}' WHERE user_id = $1`,
+		userID, DeletedUserID)
+	if err != nil {
+		return fmt.Errorf("failed to anonymize audit events: %w", err)
+	}
+	_, err = tx.ExecContext(ctx,
+		`DELETE FROM organization_invitations
+		WHERE email = (SELECT email FROM users WHERE id = $1) OR invited_by = $1`,
+		userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete organization invitations: %w", err)
+	}
+	for _, table := range personalDataTables {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM `+table+` WHERE user_id = $1`, userID); err != nil {
+			return fmt.Errorf("failed to delete %s: %w", table, err)
+		}
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM users WHERE id = $1`, userID); err != nil {
+		return fmt.Errorf("failed to delete user: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit user purge: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/auth-service/internal/models"
+	"github.com/lib/pq"
+)
+
+const apiKeyColumns = `id, tenant_id, user_id, name, prefix, secret_hash, scopes, expires_at, last_used_at,
+	COALESCE(last_used_ip, ''), created_at`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func (p *Postgres) CreateAPIKey(ctx context.Context, key *models.APIKey) error {
+	err := p.db.QueryRowContext(ctx,
+		`INSERT INTO api_keys (user_id, name, prefix, secret_hash, scopes, expires_at, tenant_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at`,
+		key.UserID,
+		key.Name,
+		key.Prefix,
+		key.SecretHash,
+		pq.Array(key.Scopes),
+		key.ExpiresAt,
+		key.TenantID,
+	).Scan(&key.ID, &key.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create API key for user %s: %w", key.UserID, err)
+	}
+	return nil
+}
+
+func (p *Postgres) GetAPIKeyByPrefix(ctx context.Context, prefix string) (*models.APIKey, error) {
+	key, err := scanAPIKey(p.db.QueryRowContext(ctx,
+		`SELECT `+apiKeyColumns+` FROM api_keys WHERE prefix = $1`, prefix))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get API key: %w", err)
+	}
+	return key, nil
+}
+
+func (p *Postgres) ListAPIKeys(ctx context.Context, userID string) ([]models.APIKey, error) {
+	rows, err := p.db.QueryContext(ctx,
+		`SELECT `+apiKeyColumns+` FROM api_keys WHERE user_id = $1 ORDER BY created_at`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get API keys: %w", err)
+	}
+	defer rows.Close()
+
+	var keys []models.APIKey
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan API key: %w", err)
+		}
+		keys = append(keys, *key)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating API keys: %w", err)
+	}
+	return keys, nil
+}
+
+func (p *Postgres) DeleteAPIKey(ctx context.Context, userID, id string) error {
+	result, err := p.db.ExecContext(ctx,
+		`DELETE FROM api_keys WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete API key: %w", err)
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (p *Postgres) TouchAPIKey(ctx context.Context, id, ip string, usedAt time.Time) error {
+	_, err := p.db.ExecContext(ctx,
+		`UPDATE api_keys SET last_used_at = $2, last_used_ip = $3 WHERE id = $1`,
+		id, usedAt, ip)
+	if err != nil {
+		return fmt.Errorf("failed to update API key usage: %w", err)
+	}
+	return nil
+}
+
+func scanAPIKey(row rowScanner) (*models.APIKey, error) {
+	var key models.APIKey
+	var expiresAt, lastUsedAt sql.NullTime
+	err := row.Scan(
+		&key.ID,
+		&key.TenantID,
+		&key.UserID,
+		&key.Name,
+		&key.Prefix,
+		&key.SecretHash,
+		pq.Array(&key.Scopes),
+		&expiresAt,
+		&lastUsedAt,
+		&key.LastUsedIP,
+		&key.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if expiresAt.Valid {
+		key.ExpiresAt = &expiresAt.Time
+	}
+	if lastUsedAt.Valid {
+		key.LastUsedAt = &lastUsedAt.Time
+	}
+	return &key, nil
+}
@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/auth-service/internal/models"
+)
+
+func (p *Postgres) SaveAuditEvent(ctx context.Context, event *models.AuditEvent) error {
+	details := event.Details
+	if details == nil {
+		details = map[string]string{}
+	}
+	data, err := json.Marshal(details)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit details: %w", err)
+	}
+
+	err = p.db.QueryRowContext(
+		context.WithoutCancel(ctx),
+		`INSERT INTO audit_events (user_id, event_type, ip, details)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at`,
+		event.UserID,
+		event.Type,
+		event.IP,
+		data,
+	).Scan(&event.ID, &event.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save audit event %s: %w", event.Type, err)
+	}
+	return nil
+}
+
+// ListAuditEvents returns the audit trail of a user, oldest first.
+func (p *Postgres) ListAuditEvents(ctx context.Context, userID string) ([]models.AuditEvent, error) {
+	rows, err := p.db.QueryContext(ctx,
+		`SELECT id, user_id, event_type, ip, details, created_at
+		FROM audit_events
+		WHERE user_id = $1
+		ORDER BY created_at`,
+		userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []models.AuditEvent
+	for rows.Next() {
+		var event models.AuditEvent
+		var details []byte
+		if err := rows.Scan(&event.ID, &event.UserID, &event.Type, &event.IP, &details, &event.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan audit event: %w", err)
+		}
+		if err := json.Unmarshal(details, &event.Details); err != nil {
+			return nil, fmt.Errorf("failed to decode audit details: %w", err)
+		}
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
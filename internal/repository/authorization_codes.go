@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/auth-service/internal/models"
+	"github.com/lib/pq"
+)
+
+func (p *Postgres) SaveAuthorizationCode(ctx context.Context, code *models.AuthorizationCode) error {
+	err := p.db.QueryRowContext(ctx,
+		`INSERT INTO authorization_codes
+			(tenant_id, code_hash, client_id, user_id, redirect_uri, scope, nonce, code_challenge, amr, auth_time, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id, created_at`,
+		code.TenantID,
+		code.CodeHash,
+		code.ClientID,
+		code.UserID,
+		code.RedirectURI,
+		code.Scope,
+		code.Nonce,
+		code.CodeChallenge,
+		pq.Array(code.AMR),
+		code.AuthTime,
+		code.ExpiresAt,
+	).Scan(&code.ID, &code.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save authorization code for client %s: %w", code.ClientID, err)
+	}
+	return nil
+}
+
+// TakeAuthorizationCode deletes and returns an authorization code, so every
+// code can be exchanged only once. Expired codes are returned as well and
+// have to be rejected by the caller.
+func (p *Postgres) TakeAuthorizationCode(ctx context.Context, codeHash string) (*models.AuthorizationCode, error) {
+	var code models.AuthorizationCode
+	err := p.db.QueryRowContext(ctx,
+		`DELETE FROM authorization_codes
+		WHERE code_hash = $1
+		RETURNING id, tenant_id, code_hash, client_id, user_id, redirect_uri, scope, nonce, code_challenge,
+			amr, auth_time, created_at, expires_at`,
+		codeHash).Scan(
+		&code.ID,
+		&code.TenantID,
+		&code.CodeHash,
+		&code.ClientID,
+		&code.UserID,
+		&code.RedirectURI,
+		&code.Scope,
+		&code.Nonce,
+		&code.CodeChallenge,
+		pq.Array(&code.AMR),
+		&code.AuthTime,
+		&code.CreatedAt,
+		&code.ExpiresAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get authorization code: %w", err)
+	}
+	return &code, nil
+}
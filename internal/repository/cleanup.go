@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// DeleteExpiredRefreshTokens deletes up to limit refresh tokens that expired
+// before now and returns how many were deleted.
+func (p *Postgres) DeleteExpiredRefreshTokens(ctx context.Context, now time.Time, limit int) (int, error) {
+	return p.deleteBatch(ctx, "refresh_tokens", "id", "expires_at < $1", now, limit)
+}
+
+// DeleteExpiredRevokedTokens deletes denylist entries of access tokens that
+// have expired by now and are rejected by their exp claim anyway.
+func (p *Postgres) DeleteExpiredRevokedTokens(ctx context.Context, now time.Time, limit int) (int, error) {
+	return p.deleteBatch(ctx, "revoked_access_tokens", "jti", "expires_at < $1", now, limit)
+}
+
+// DeleteStaleLoginCodes deletes magic link codes that expired or were used.
+func (p *Postgres) DeleteStaleLoginCodes(ctx context.Context, now time.Time, limit int) (int, error) {
+	return p.deleteBatch(ctx, "login_codes", "id", "expires_at < $1 OR used_at IS NOT NULL", now, limit)
+}
+
+func (p *Postgres) DeleteExpiredAuthorizationCodes(ctx context.Context, now time.Time, limit int) (int, error) {
+	return p.deleteBatch(ctx, "authorization_codes", "id", "expires_at < $1", now, limit)
+}
+
+func (p *Postgres) DeleteExpiredDeviceCodes(ctx context.Context, now time.Time, limit int) (int, error) {
+	return p.deleteBatch(ctx, "device_codes", "id", "expires_at < $1", now, limit)
+}
+
+func (p *Postgres) DeleteExpiredWebAuthnSessions(ctx context.Context, now time.Time, limit int) (int, error) {
+	return p.deleteBatch(ctx, "webauthn_sessions", "id", "expires_at < $1", now, limit)
+}
+
+func (p *Postgres) DeleteExpiredEmailChanges(ctx context.Context, now time.Time, limit int) (int, error) {
+	return p.deleteBatch(ctx, "email_changes", "id", "expires_at < $1", now, limit)
+}
+
+// deleteBatch deletes up to limit rows of the table that match condition,
+// where $1 is now. Table, key and condition are constants of this package.
+func (p *Postgres) deleteBatch(ctx context.Context, table, key, condition string, now time.Time, limit int) (int, error) {
+	result, err := p.db.ExecContext(ctx,
+		`DELETE FROM `+table+` WHERE `+key+` IN (SELECT `+key+` FROM `+table+` WHERE `+condition+` LIMIT $2)`,
+		now, limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to clean up %s: %w", table, err)
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to count deleted %s: %w", table, err)
+	}
+	return int(deleted), nil
+}
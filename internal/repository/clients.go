@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/auth-service/internal/models"
+	"github.com/lib/pq"
+)
+
+func (p *Postgres) CreateClient(ctx context.Context, client *models.OAuthClient) error {
+	err := p.db.QueryRowContext(ctx,
+		`INSERT INTO oauth_clients
+			(client_id, name, secret_hash, public, grant_types, redirect_uris, scopes,
+			 access_token_ttl, refresh_token_ttl, max_sessions, idle_timeout, ip_change_policy)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		RETURNING id, created_at, updated_at`,
+		client.ClientID,
+		client.Name,
+		client.SecretHash,
+		client.Public,
+		pq.Array(client.GrantTypes),
+		pq.Array(client.RedirectURIs),
+		pq.Array(client.Scopes),
+		int(client.AccessTokenTTL.Seconds()),
+		int(client.RefreshTokenTTL.Seconds()),
+		client.MaxSessions,
+		int(client.IdleTimeout.Seconds()),
+		client.IPChangePolicy,
+	).Scan(&client.ID, &client.CreatedAt, &client.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create client %s: %w", client.ClientID, err)
+	}
+	return nil
+}
+
+func (p *Postgres) GetClient(ctx context.Context, clientID string) (*models.OAuthClient, error) {
+	var (
+		client          models.OAuthClient
+		accessTokenTTL  int
+		refreshTokenTTL int
+		idleTimeout     int
+		disabledAt      sql.NullTime
+	)
+	err := p.db.QueryRowContext(ctx,
+		`SELECT id, client_id, name, secret_hash, public, grant_types, redirect_uris, scopes,
+			access_token_ttl, refresh_token_ttl, max_sessions, idle_timeout, ip_change_policy,
+			disabled_at, created_at, updated_at
+		FROM oauth_clients
+		WHERE client_id = $1`,
+		clientID).Scan(
+		&client.ID,
+		&client.ClientID,
+		&client.Name,
+		&client.SecretHash,
+		&client.Public,
+		pq.Array(&client.GrantTypes),
+		pq.Array(&client.RedirectURIs),
+		pq.Array(&client.Scopes),
+		&accessTokenTTL,
+		&refreshTokenTTL,
+		&client.MaxSessions,
+		&idleTimeout,
+		&client.IPChangePolicy,
+		&disabledAt,
+		&client.CreatedAt,
+		&client.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get client: %w", err)
+	}
+
+	client.AccessTokenTTL = time.Duration(accessTokenTTL) * time.Second
+	client.RefreshTokenTTL = time.Duration(refreshTokenTTL) * time.Second
+	client.IdleTimeout = time.Duration(idleTimeout) * time.Second
+	if disabledAt.Valid {
+		client.DisabledAt = &disabledAt.Time
+	}
+	return &client, nil
+}
+
+// UpdateClientSecret replaces the secret of a confidential client. Public
+// clients have no secret and are reported as not found.
+func (p *Postgres) UpdateClientSecret(ctx context.Context, clientID, secretHash string) error {
+	return p.updateClient(ctx,
+		`UPDATE oauth_clients SET secret_hash = $2, updated_at = NOW() WHERE client_id = $1 AND NOT public`,
+		clientID, secretHash)
+}
+
+func (p *Postgres) DisableClient(ctx context.Context, clientID string) error {
+	return p.updateClient(ctx,
+		`UPDATE oauth_clients SET disabled_at = COALESCE(disabled_at, NOW()), updated_at = NOW() WHERE client_id = $1`,
+		clientID)
+}
+
+func (p *Postgres) updateClient(ctx context.Context, query string, args ...interface{}) error {
+	res, err := p.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to update client: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to update client: %w", err)
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/auth-service/internal/models"
+)
+
+const deviceCodeColumns = `id, tenant_id, device_code_hash, user_code, client_id, scope, status, COALESCE(user_id, ''),
+	last_polled_at, created_at, expires_at`
+
+// SaveDeviceCode stores a new device authorization. It returns ErrDuplicate
+// when the user code is already taken.
+func (p *Postgres) SaveDeviceCode(ctx context.Context, code *models.DeviceCode) error {
+	if code.Status == "" {
+		code.Status = models.DeviceCodePending
+	}
+	err := p.db.QueryRowContext(ctx,
+		`INSERT INTO device_codes (tenant_id, device_code_hash, user_code, client_id, scope, status, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at`,
+		code.TenantID,
+		code.DeviceCodeHash,
+		code.UserCode,
+		code.ClientID,
+		code.Scope,
+		code.Status,
+		code.ExpiresAt,
+	).Scan(&code.ID, &code.CreatedAt)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrDuplicate
+		}
+		return fmt.Errorf("failed to save device code for client %s: %w", code.ClientID, err)
+	}
+	return nil
+}
+
+func (p *Postgres) GetDeviceCodeByUserCode(ctx context.Context, userCode string) (*models.DeviceCode, error) {
+	code, err := scanDeviceCode(p.db.QueryRowContext(ctx,
+		`SELECT `+deviceCodeColumns+` FROM device_codes WHERE user_code = $1`, userCode))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get device code: %w", err)
+	}
+	return code, nil
+}
+
+// DecideDeviceCode records the decision of the user. Only pending codes
+// that have not expired can be decided, ErrNotFound is returned otherwise.
+func (p *Postgres) DecideDeviceCode(ctx context.Context, id, userID, status string) error {
+	result, err := p.db.ExecContext(ctx,
+		`UPDATE device_codes SET status = $3, user_id = NULLIF($2, '')
+		WHERE id = $1 AND status = 'pending' AND expires_at > NOW()`,
+		id, userID, status)
+	if err != nil {
+		return fmt.Errorf("failed to decide device code: %w", err)
+	}
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// PollDeviceCode records a poll of the device and returns the code as it
+// was before, so LastPolledAt is the time of the previous poll.
+func (p *Postgres) PollDeviceCode(ctx context.Context, deviceCodeHash string, now time.Time) (*models.DeviceCode, error) {
+	code, err := scanDeviceCode(p.db.QueryRowContext(ctx,
+		`UPDATE device_codes d SET last_polled_at = $2
+		FROM (SELECT `+deviceCodeColumns+` FROM device_codes WHERE device_code_hash = $1 FOR UPDATE) old
+		WHERE d.id = old.id
+		RETURNING old.*`,
+		deviceCodeHash, now))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to poll device code: %w", err)
+	}
+	return code, nil
+}
+
+// DeleteDeviceCode returns ErrNotFound when the code is already gone, so an
+// approved code can be exchanged only once.
+func (p *Postgres) DeleteDeviceCode(ctx context.Context, id string) error {
+	result, err := p.db.ExecContext(ctx, `DELETE FROM device_codes WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete device code: %w", err)
+	}
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func scanDeviceCode(row rowScanner) (*models.DeviceCode, error) {
+	var code models.DeviceCode
+	err := row.Scan(
+		&code.ID,
+		&code.TenantID,
+		&code.DeviceCodeHash,
+		&code.UserCode,
+		&code.ClientID,
+		&code.Scope,
+		&code.Status,
+		&code.UserID,
+		&code.LastPolledAt,
+		&code.CreatedAt,
+		&code.ExpiresAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &code, nil
+}
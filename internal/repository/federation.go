@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/auth-service/internal/models"
+)
+
+func (p *Postgres) SaveFederatedLogin(ctx context.Context, login *models.FederatedLogin) error {
+	_, err := p.db.ExecContext(ctx,
+		`INSERT INTO federated_logins (state_hash, tenant_id, provider, nonce, code_verifier, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)`,
+		login.StateHash, login.TenantID, login.Provider, login.Nonce, login.CodeVerifier, login.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("failed to save federated login: %w", err)
+	}
+	return nil
+}
+
+// TakeFederatedLogin deletes and returns a pending login, so every state can
+// be used only once. Expired logins have to be rejected by the caller.
+func (p *Postgres) TakeFederatedLogin(ctx context.Context, stateHash string) (*models.FederatedLogin, error) {
+	var login models.FederatedLogin
+	err := p.db.QueryRowContext(ctx,
+		`DELETE FROM federated_logins
+		WHERE state_hash = $1
+		RETURNING state_hash, tenant_id, provider, nonce, code_verifier, expires_at`,
+		stateHash).Scan(
+		&login.StateHash,
+		&login.TenantID,
+		&login.Provider,
+		&login.Nonce,
+		&login.CodeVerifier,
+		&login.ExpiresAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get federated login: %w", err)
+	}
+	return &login, nil
+}
+
+func (p *Postgres) GetFederatedIdentity(ctx context.Context, tenantID, issuer, subject string) (*models.FederatedIdentity, error) {
+	var identity models.FederatedIdentity
+	err := p.db.QueryRowContext(ctx,
+		`SELECT id, tenant_id, user_id, provider, issuer, subject, created_at
+		FROM federated_identities
+		WHERE tenant_id = $1 AND issuer = $2 AND subject = $3`,
+		tenantID, issuer, subject).Scan(
+		&identity.ID,
+		&identity.TenantID,
+		&identity.UserID,
+		&identity.Provider,
+		&identity.Issuer,
+		&identity.Subject,
+		&identity.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get federated identity: %w", err)
+	}
+	return &identity, nil
+}
+
+func (p *Postgres) LinkFederatedIdentity(ctx context.Context, identity *models.FederatedIdentity) error {
+	return linkFederatedIdentity(ctx, p.db, identity)
+}
+
+// CreateFederatedUser creates a user together with the federated identity
+// it signed in with.
+func (p *Postgres) CreateFederatedUser(ctx context.Context, user *models.User, identity *models.FederatedIdentity) error {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRowContext(ctx,
+		`INSERT INTO users (tenant_id, email, name, email_verified)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at, updated_at`,
+		user.TenantID, strings.ToLower(user.Email), user.Name, user.EmailVerified,
+	).Scan(&user.ID, &user.CreatedAt, &user.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create user: %w", err)
+	}
+
+	identity.UserID = user.ID
+	identity.TenantID = user.TenantID
+	if err := linkFederatedIdentity(ctx, tx, identity); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit federated user: %w", err)
+	}
+	return nil
+}
+
+type queryRower interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+func linkFederatedIdentity(ctx context.Context, db queryRower, identity *models.FederatedIdentity) error {
+	err := db.QueryRowContext(ctx,
+		`INSERT INTO federated_identities (tenant_id, user_id, provider, issuer, subject)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at`,
+		identity.TenantID, identity.UserID, identity.Provider, identity.Issuer, identity.Subject,
+	).Scan(&identity.ID, &identity.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to link federated identity: %w", err)
+	}
+	return nil
+}
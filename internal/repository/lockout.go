@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/auth-service/internal/models"
+)
+
+// GetAuthFailure returns the failure counter for the key. A key without
+// recorded failures yields a zero counter rather than an error.
+func (p *Postgres) GetAuthFailure(ctx context.Context, scope, key string) (*models.AuthFailure, error) {
+	failure := models.AuthFailure{Scope: scope, Key: key}
+	var lockedUntil sql.NullTime
+
+	err := p.db.QueryRowContext(ctx,
+		`SELECT failures, last_failed_at, locked_until
+		FROM auth_failures
+		WHERE scope = $1 AND key = $2`,
+		scope, key).Scan(
+		&failure.Failures,
+		&failure.LastFailedAt,
+		&lockedUntil)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return &failure, nil
+		}
+		return nil, fmt.Errorf("failed to get auth failures: %w", err)
+	}
+
+	if lockedUntil.Valid {
+		failure.LockedUntil = &lockedUntil.Time
+	}
+	return &failure, nil
+}
+
+// RecordAuthFailure increments the counter. Counters whose last failure is
+// older than window start over from one.
+func (p *Postgres) RecordAuthFailure(ctx context.Context, scope, key string, window time.Duration) (*models.AuthFailure, error) {
+	failure := models.AuthFailure{Scope: scope, Key: key}
+	var lockedUntil sql.NullTime
+
+	err := p.db.QueryRowContext(
+		context.WithoutCancel(ctx),
+		`INSERT INTO auth_failures (scope, key, failures, last_failed_at)
+		VALUES ($1, $2, 1, NOW())
+		ON CONFLICT (scope, key) DO UPDATE SET
+			failures = CASE
+				WHEN auth_failures.last_failed_at < NOW() - make_interval(secs => $3) THEN 1
+				ELSE auth_failures.failures + 1
+			END,
+			last_failed_at = NOW()
+		RETURNING failures, last_failed_at, locked_until`,
+		scope, key, window.Seconds()).Scan(
+		&failure.Failures,
+		&failure.LastFailedAt,
+		&lockedUntil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record auth failure: %w", err)
+	}
+
+	if lockedUntil.Valid {
+		failure.LockedUntil = &lockedUntil.Time
+	}
+	return &failure, nil
+}
+
+func (p *Postgres) LockAuthFailure(ctx context.Context, scope, key string, until time.Time) error {
+	_, err := p.db.ExecContext(ctx,
+		`UPDATE auth_failures SET locked_until = $3 WHERE scope = $1 AND key = $2`,
+		scope, key, until)
+	if err != nil {
+		return fmt.Errorf("failed to lock %s %s: %w", scope, key, err)
+	}
+	return nil
+}
+
+func (p *Postgres) ClearAuthFailures(ctx context.Context, scope, key string) error {
+	_, err := p.db.ExecContext(ctx,
+		`DELETE FROM auth_failures WHERE scope = $1 AND key = $2`,
+		scope, key)
+	if err != nil {
+		return fmt.Errorf("failed to clear auth failures: %w", err)
+	}
+	return nil
+}
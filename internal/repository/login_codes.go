@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/auth-service/internal/models"
+)
+
+const loginCodeColumns = `id, tenant_id, user_id, code_hash, nonce_hash, attempts, created_at, expires_at, used_at`
+
+func (p *Postgres) SaveLoginCode(ctx context.Context, code *models.LoginCode) error {
+	err := p.db.QueryRowContext(ctx,
+		`INSERT INTO login_codes (tenant_id, user_id, code_hash, nonce_hash, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at`,
+		code.TenantID,
+		code.UserID,
+		code.CodeHash,
+		code.NonceHash,
+		code.ExpiresAt,
+	).Scan(&code.ID, &code.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save login code for user %s: %w", code.UserID, err)
+	}
+	return nil
+}
+
+func (p *Postgres) GetLoginCode(ctx context.Context, id string) (*models.LoginCode, error) {
+	return p.getLoginCode(ctx, `WHERE id = $1`, id)
+}
+
+func (p *Postgres) GetLoginCodeByNonce(ctx context.Context, nonceHash string) (*models.LoginCode, error) {
+	return p.getLoginCode(ctx, `WHERE nonce_hash = $1`, nonceHash)
+}
+
+func (p *Postgres) getLoginCode(ctx context.Context, where string, arg string) (*models.LoginCode, error) {
+	var (
+		code   models.LoginCode
+		usedAt sql.NullTime
+	)
+	err := p.db.QueryRowContext(ctx,
+		`SELECT `+loginCodeColumns+` FROM login_codes `+where, arg).Scan(
+		&code.ID,
+		&code.TenantID,
+		&code.UserID,
+		&code.CodeHash,
+		&code.NonceHash,
+		&code.Attempts,
+		&code.CreatedAt,
+		&code.ExpiresAt,
+		&usedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get login code: %w", err)
+	}
+
+	if usedAt.Valid {
+		code.UsedAt = &usedAt.Time
+	}
+	return &code, nil
+}
+
+func (p *Postgres) IncrementLoginCodeAttempts(ctx context.Context, id string) error {
+	_, err := p.db.ExecContext(ctx,
+		`UPDATE login_codes SET attempts = attempts + 1 WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to update login code: %w", err)
+	}
+	return nil
+}
+
+// ConsumeLoginCode marks the code used. It returns ErrNotFound when the code
+// was already used, which makes both the link and the code single-use.
+func (p *Postgres) ConsumeLoginCode(ctx context.Context, id string) error {
+	res, err := p.db.ExecContext(ctx,
+		`UPDATE login_codes SET used_at = NOW() WHERE id = $1 AND used_at IS NULL`, id)
+	if err != nil {
+		return fmt.Errorf("failed to consume login code: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to consume login code: %w", err)
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
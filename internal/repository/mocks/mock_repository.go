@@ -1,5 +1,5 @@
 // Code generated by MockGen. DO NOT EDIT.
-// Source: github.com/auth-service/internal/repository (interfaces: Repository)
+// Source: github.com/auth-service/internal/repository (interfaces: Repository,RecoveryCodeRepository,AuditRepository,WebAuthnRepository,LockoutRepository,UserRepository,LoginCodeRepository,ClientRepository,AuthorizationCodeRepository,DeviceCodeRepository,FederationRepository,APIKeyRepository,RoleRepository,TokenCutoffRepository,RevokedTokenRepository,OrganizationRepository,EmailChangeRepository,SecurityAlertRepository,AccountRepository,CleanupRepository)
 
 // Package mocks is a generated GoMock package.
 package mocks
@@ -7,6 +7,7 @@ package mocks
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	models "github.com/auth-service/internal/models"
 	gomock "github.com/golang/mock/gomock"
@@ -35,6 +36,62 @@ func (m *MockRepository) EXPECT() *MockRepositoryMockRecorder {
 	return m.recorder
 }
 
+// AddOrganizationMember mocks base method.
+func (m *MockRepository) AddOrganizationMember(arg0 context.Context, arg1 *models.OrganizationMember) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddOrganizationMember", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddOrganizationMember indicates an expected call of AddOrganizationMember.
+func (mr *MockRepositoryMockRecorder) AddOrganizationMember(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddOrganizationMember", reflect.TypeOf((*MockRepository)(nil).AddOrganizationMember), arg0, arg1)
+}
+
+// AddTeamMember mocks base method.
+func (m *MockRepository) AddTeamMember(arg0 context.Context, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddTeamMember", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddTeamMember indicates an expected call of AddTeamMember.
+func (mr *MockRepositoryMockRecorder) AddTeamMember(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddTeamMember", reflect.TypeOf((*MockRepository)(nil).AddTeamMember), arg0, arg1, arg2)
+}
+
+// CancelUserDeletion mocks base method.
+func (m *MockRepository) CancelUserDeletion(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelUserDeletion", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CancelUserDeletion indicates an expected call of CancelUserDeletion.
+func (mr *MockRepositoryMockRecorder) CancelUserDeletion(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelUserDeletion", reflect.TypeOf((*MockRepository)(nil).CancelUserDeletion), arg0, arg1)
+}
+
+// ClearAuthFailures mocks base method.
+func (m *MockRepository) ClearAuthFailures(arg0 context.Context, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClearAuthFailures", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ClearAuthFailures indicates an expected call of ClearAuthFailures.
+func (mr *MockRepositoryMockRecorder) ClearAuthFailures(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClearAuthFailures", reflect.TypeOf((*MockRepository)(nil).ClearAuthFailures), arg0, arg1, arg2)
+}
+
 // Close mocks base method.
 func (m *MockRepository) Close() error {
 	m.ctrl.T.Helper()
@@ -49,6 +106,309 @@ func (mr *MockRepositoryMockRecorder) Close() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockRepository)(nil).Close))
 }
 
+// ConfirmEmailChange mocks base method.
+func (m *MockRepository) ConfirmEmailChange(arg0 context.Context, arg1 string, arg2 bool) (*models.EmailChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConfirmEmailChange", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.EmailChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConfirmEmailChange indicates an expected call of ConfirmEmailChange.
+func (mr *MockRepositoryMockRecorder) ConfirmEmailChange(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfirmEmailChange", reflect.TypeOf((*MockRepository)(nil).ConfirmEmailChange), arg0, arg1, arg2)
+}
+
+// ConsumeLoginCode mocks base method.
+func (m *MockRepository) ConsumeLoginCode(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConsumeLoginCode", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ConsumeLoginCode indicates an expected call of ConsumeLoginCode.
+func (mr *MockRepositoryMockRecorder) ConsumeLoginCode(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsumeLoginCode", reflect.TypeOf((*MockRepository)(nil).ConsumeLoginCode), arg0, arg1)
+}
+
+// CountOrganizationOwners mocks base method.
+func (m *MockRepository) CountOrganizationOwners(arg0 context.Context, arg1 string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountOrganizationOwners", arg0, arg1)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountOrganizationOwners indicates an expected call of CountOrganizationOwners.
+func (mr *MockRepositoryMockRecorder) CountOrganizationOwners(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountOrganizationOwners", reflect.TypeOf((*MockRepository)(nil).CountOrganizationOwners), arg0, arg1)
+}
+
+// CreateAPIKey mocks base method.
+func (m *MockRepository) CreateAPIKey(arg0 context.Context, arg1 *models.APIKey) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAPIKey", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateAPIKey indicates an expected call of CreateAPIKey.
+func (mr *MockRepositoryMockRecorder) CreateAPIKey(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAPIKey", reflect.TypeOf((*MockRepository)(nil).CreateAPIKey), arg0, arg1)
+}
+
+// CreateClient mocks base method.
+func (m *MockRepository) CreateClient(arg0 context.Context, arg1 *models.OAuthClient) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateClient", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateClient indicates an expected call of CreateClient.
+func (mr *MockRepositoryMockRecorder) CreateClient(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateClient", reflect.TypeOf((*MockRepository)(nil).CreateClient), arg0, arg1)
+}
+
+// CreateFederatedUser mocks base method.
+func (m *MockRepository) CreateFederatedUser(arg0 context.Context, arg1 *models.User, arg2 *models.FederatedIdentity) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateFederatedUser", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateFederatedUser indicates an expected call of CreateFederatedUser.
+func (mr *MockRepositoryMockRecorder) CreateFederatedUser(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateFederatedUser", reflect.TypeOf((*MockRepository)(nil).CreateFederatedUser), arg0, arg1, arg2)
+}
+
+// CreateOrganization mocks base method.
+func (m *MockRepository) CreateOrganization(arg0 context.Context, arg1 *models.Organization, arg2 *models.OrganizationMember) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOrganization", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateOrganization indicates an expected call of CreateOrganization.
+func (mr *MockRepositoryMockRecorder) CreateOrganization(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOrganization", reflect.TypeOf((*MockRepository)(nil).CreateOrganization), arg0, arg1, arg2)
+}
+
+// CreateRole mocks base method.
+func (m *MockRepository) CreateRole(arg0 context.Context, arg1 *models.Role) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateRole", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateRole indicates an expected call of CreateRole.
+func (mr *MockRepositoryMockRecorder) CreateRole(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRole", reflect.TypeOf((*MockRepository)(nil).CreateRole), arg0, arg1)
+}
+
+// CreateTeam mocks base method.
+func (m *MockRepository) CreateTeam(arg0 context.Context, arg1 *models.Team) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTeam", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateTeam indicates an expected call of CreateTeam.
+func (mr *MockRepositoryMockRecorder) CreateTeam(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTeam", reflect.TypeOf((*MockRepository)(nil).CreateTeam), arg0, arg1)
+}
+
+// DecideDeviceCode mocks base method.
+func (m *MockRepository) DecideDeviceCode(arg0 context.Context, arg1, arg2, arg3 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DecideDeviceCode", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DecideDeviceCode indicates an expected call of DecideDeviceCode.
+func (mr *MockRepositoryMockRecorder) DecideDeviceCode(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DecideDeviceCode", reflect.TypeOf((*MockRepository)(nil).DecideDeviceCode), arg0, arg1, arg2, arg3)
+}
+
+// DeleteAPIKey mocks base method.
+func (m *MockRepository) DeleteAPIKey(arg0 context.Context, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAPIKey", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAPIKey indicates an expected call of DeleteAPIKey.
+func (mr *MockRepositoryMockRecorder) DeleteAPIKey(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAPIKey", reflect.TypeOf((*MockRepository)(nil).DeleteAPIKey), arg0, arg1, arg2)
+}
+
+// DeleteDeviceCode mocks base method.
+func (m *MockRepository) DeleteDeviceCode(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteDeviceCode", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteDeviceCode indicates an expected call of DeleteDeviceCode.
+func (mr *MockRepositoryMockRecorder) DeleteDeviceCode(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteDeviceCode", reflect.TypeOf((*MockRepository)(nil).DeleteDeviceCode), arg0, arg1)
+}
+
+// DeleteEmailChange mocks base method.
+func (m *MockRepository) DeleteEmailChange(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteEmailChange", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteEmailChange indicates an expected call of DeleteEmailChange.
+func (mr *MockRepositoryMockRecorder) DeleteEmailChange(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteEmailChange", reflect.TypeOf((*MockRepository)(nil).DeleteEmailChange), arg0, arg1)
+}
+
+// DeleteExpiredAuthorizationCodes mocks base method.
+func (m *MockRepository) DeleteExpiredAuthorizationCodes(arg0 context.Context, arg1 time.Time, arg2 int) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpiredAuthorizationCodes", arg0, arg1, arg2)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteExpiredAuthorizationCodes indicates an expected call of DeleteExpiredAuthorizationCodes.
+func (mr *MockRepositoryMockRecorder) DeleteExpiredAuthorizationCodes(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpiredAuthorizationCodes", reflect.TypeOf((*MockRepository)(nil).DeleteExpiredAuthorizationCodes), arg0, arg1, arg2)
+}
+
+// DeleteExpiredDeviceCodes mocks base method.
+func (m *MockRepository) DeleteExpiredDeviceCodes(arg0 context.Context, arg1 time.Time, arg2 int) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpiredDeviceCodes", arg0, arg1, arg2)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteExpiredDeviceCodes indicates an expected call of DeleteExpiredDeviceCodes.
+func (mr *MockRepositoryMockRecorder) DeleteExpiredDeviceCodes(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpiredDeviceCodes", reflect.TypeOf((*MockRepository)(nil).DeleteExpiredDeviceCodes), arg0, arg1, arg2)
+}
+
+// DeleteExpiredEmailChanges mocks base method.
+func (m *MockRepository) DeleteExpiredEmailChanges(arg0 context.Context, arg1 time.Time, arg2 int) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpiredEmailChanges", arg0, arg1, arg2)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteExpiredEmailChanges indicates an expected call of DeleteExpiredEmailChanges.
+func (mr *MockRepositoryMockRecorder) DeleteExpiredEmailChanges(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpiredEmailChanges", reflect.TypeOf((*MockRepository)(nil).DeleteExpiredEmailChanges), arg0, arg1, arg2)
+}
+
+// DeleteExpiredRefreshTokens mocks base method.
+func (m *MockRepository) DeleteExpiredRefreshTokens(arg0 context.Context, arg1 time.Time, arg2 int) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpiredRefreshTokens", arg0, arg1, arg2)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteExpiredRefreshTokens indicates an expected call of DeleteExpiredRefreshTokens.
+func (mr *MockRepositoryMockRecorder) DeleteExpiredRefreshTokens(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpiredRefreshTokens", reflect.TypeOf((*MockRepository)(nil).DeleteExpiredRefreshTokens), arg0, arg1, arg2)
+}
+
+// DeleteExpiredRevokedTokens mocks base method.
+func (m *MockRepository) DeleteExpiredRevokedTokens(arg0 context.Context, arg1 time.Time, arg2 int) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpiredRevokedTokens", arg0, arg1, arg2)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteExpiredRevokedTokens indicates an expected call of DeleteExpiredRevokedTokens.
+func (mr *MockRepositoryMockRecorder) DeleteExpiredRevokedTokens(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpiredRevokedTokens", reflect.TypeOf((*MockRepository)(nil).DeleteExpiredRevokedTokens), arg0, arg1, arg2)
+}
+
+// DeleteExpiredWebAuthnSessions mocks base method.
+func (m *MockRepository) DeleteExpiredWebAuthnSessions(arg0 context.Context, arg1 time.Time, arg2 int) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpiredWebAuthnSessions", arg0, arg1, arg2)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteExpiredWebAuthnSessions indicates an expected call of DeleteExpiredWebAuthnSessions.
+func (mr *MockRepositoryMockRecorder) DeleteExpiredWebAuthnSessions(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpiredWebAuthnSessions", reflect.TypeOf((*MockRepository)(nil).DeleteExpiredWebAuthnSessions), arg0, arg1, arg2)
+}
+
+// DeleteIdleRefreshTokens mocks base method.
+func (m *MockRepository) DeleteIdleRefreshTokens(arg0 context.Context, arg1 time.Duration, arg2 time.Time, arg3 int) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteIdleRefreshTokens", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteIdleRefreshTokens indicates an expected call of DeleteIdleRefreshTokens.
+func (mr *MockRepositoryMockRecorder) DeleteIdleRefreshTokens(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteIdleRefreshTokens", reflect.TypeOf((*MockRepository)(nil).DeleteIdleRefreshTokens), arg0, arg1, arg2, arg3)
+}
+
+// DeleteOrganizationInvitation mocks base method.
+func (m *MockRepository) DeleteOrganizationInvitation(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteOrganizationInvitation", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteOrganizationInvitation indicates an expected call of DeleteOrganizationInvitation.
+func (mr *MockRepositoryMockRecorder) DeleteOrganizationInvitation(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteOrganizationInvitation", reflect.TypeOf((*MockRepository)(nil).DeleteOrganizationInvitation), arg0, arg1)
+}
+
 // DeleteRefreshToken mocks base method.
 func (m *MockRepository) DeleteRefreshToken(arg0 context.Context, arg1 string) error {
 	m.ctrl.T.Helper()
@@ -63,45 +423,2801 @@ func (mr *MockRepositoryMockRecorder) DeleteRefreshToken(arg0, arg1 interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRefreshToken", reflect.TypeOf((*MockRepository)(nil).DeleteRefreshToken), arg0, arg1)
 }
 
-// GetRefreshTokensByUser mocks base method.
-func (m *MockRepository) GetRefreshTokensByUser(arg0 context.Context, arg1 string) ([]models.RefreshToken, error) {
+// DeleteRole mocks base method.
+func (m *MockRepository) DeleteRole(arg0 context.Context, arg1, arg2 string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetRefreshTokensByUser", arg0, arg1)
-	ret0, _ := ret[0].([]models.RefreshToken)
+	ret := m.ctrl.Call(m, "DeleteRole", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteRole indicates an expected call of DeleteRole.
+func (mr *MockRepositoryMockRecorder) DeleteRole(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRole", reflect.TypeOf((*MockRepository)(nil).DeleteRole), arg0, arg1, arg2)
+}
+
+// DeleteStaleLoginCodes mocks base method.
+func (m *MockRepository) DeleteStaleLoginCodes(arg0 context.Context, arg1 time.Time, arg2 int) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteStaleLoginCodes", arg0, arg1, arg2)
+	ret0, _ := ret[0].(int)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetRefreshTokensByUser indicates an expected call of GetRefreshTokensByUser.
-func (mr *MockRepositoryMockRecorder) GetRefreshTokensByUser(arg0, arg1 interface{}) *gomock.Call {
+// DeleteStaleLoginCodes indicates an expected call of DeleteStaleLoginCodes.
+func (mr *MockRepositoryMockRecorder) DeleteStaleLoginCodes(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRefreshTokensByUser", reflect.TypeOf((*MockRepository)(nil).GetRefreshTokensByUser), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteStaleLoginCodes", reflect.TypeOf((*MockRepository)(nil).DeleteStaleLoginCodes), arg0, arg1, arg2)
 }
 
-// RevokeAllTokens mocks base method.
-func (m *MockRepository) RevokeAllTokens(arg0 context.Context, arg1 string) error {
+// DisableClient mocks base method.
+func (m *MockRepository) DisableClient(arg0 context.Context, arg1 string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "RevokeAllTokens", arg0, arg1)
+	ret := m.ctrl.Call(m, "DisableClient", arg0, arg1)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// RevokeAllTokens indicates an expected call of RevokeAllTokens.
-func (mr *MockRepositoryMockRecorder) RevokeAllTokens(arg0, arg1 interface{}) *gomock.Call {
+// DisableClient indicates an expected call of DisableClient.
+func (mr *MockRepositoryMockRecorder) DisableClient(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAllTokens", reflect.TypeOf((*MockRepository)(nil).RevokeAllTokens), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisableClient", reflect.TypeOf((*MockRepository)(nil).DisableClient), arg0, arg1)
 }
 
-// SaveRefreshToken mocks base method.
-func (m *MockRepository) SaveRefreshToken(arg0 context.Context, arg1, arg2, arg3 string) error {
+// GetAPIKeyByPrefix mocks base method.
+func (m *MockRepository) GetAPIKeyByPrefix(arg0 context.Context, arg1 string) (*models.APIKey, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SaveRefreshToken", arg0, arg1, arg2, arg3)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "GetAPIKeyByPrefix", arg0, arg1)
+	ret0, _ := ret[0].(*models.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// SaveRefreshToken indicates an expected call of SaveRefreshToken.
-func (mr *MockRepositoryMockRecorder) SaveRefreshToken(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+// GetAPIKeyByPrefix indicates an expected call of GetAPIKeyByPrefix.
+func (mr *MockRepositoryMockRecorder) GetAPIKeyByPrefix(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAPIKeyByPrefix", reflect.TypeOf((*MockRepository)(nil).GetAPIKeyByPrefix), arg0, arg1)
+}
+
+// GetAuthFailure mocks base method.
+func (m *MockRepository) GetAuthFailure(arg0 context.Context, arg1, arg2 string) (*models.AuthFailure, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAuthFailure", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.AuthFailure)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAuthFailure indicates an expected call of GetAuthFailure.
+func (mr *MockRepositoryMockRecorder) GetAuthFailure(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuthFailure", reflect.TypeOf((*MockRepository)(nil).GetAuthFailure), arg0, arg1, arg2)
+}
+
+// GetClient mocks base method.
+func (m *MockRepository) GetClient(arg0 context.Context, arg1 string) (*models.OAuthClient, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetClient", arg0, arg1)
+	ret0, _ := ret[0].(*models.OAuthClient)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetClient indicates an expected call of GetClient.
+func (mr *MockRepositoryMockRecorder) GetClient(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetClient", reflect.TypeOf((*MockRepository)(nil).GetClient), arg0, arg1)
+}
+
+// GetDeviceCodeByUserCode mocks base method.
+func (m *MockRepository) GetDeviceCodeByUserCode(arg0 context.Context, arg1 string) (*models.DeviceCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeviceCodeByUserCode", arg0, arg1)
+	ret0, _ := ret[0].(*models.DeviceCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDeviceCodeByUserCode indicates an expected call of GetDeviceCodeByUserCode.
+func (mr *MockRepositoryMockRecorder) GetDeviceCodeByUserCode(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeviceCodeByUserCode", reflect.TypeOf((*MockRepository)(nil).GetDeviceCodeByUserCode), arg0, arg1)
+}
+
+// GetEmailChangeByToken mocks base method.
+func (m *MockRepository) GetEmailChangeByToken(arg0 context.Context, arg1 string) (*models.EmailChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEmailChangeByToken", arg0, arg1)
+	ret0, _ := ret[0].(*models.EmailChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEmailChangeByToken indicates an expected call of GetEmailChangeByToken.
+func (mr *MockRepositoryMockRecorder) GetEmailChangeByToken(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEmailChangeByToken", reflect.TypeOf((*MockRepository)(nil).GetEmailChangeByToken), arg0, arg1)
+}
+
+// GetFederatedIdentity mocks base method.
+func (m *MockRepository) GetFederatedIdentity(arg0 context.Context, arg1, arg2, arg3 string) (*models.FederatedIdentity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFederatedIdentity", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*models.FederatedIdentity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFederatedIdentity indicates an expected call of GetFederatedIdentity.
+func (mr *MockRepositoryMockRecorder) GetFederatedIdentity(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFederatedIdentity", reflect.TypeOf((*MockRepository)(nil).GetFederatedIdentity), arg0, arg1, arg2, arg3)
+}
+
+// GetLoginCode mocks base method.
+func (m *MockRepository) GetLoginCode(arg0 context.Context, arg1 string) (*models.LoginCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLoginCode", arg0, arg1)
+	ret0, _ := ret[0].(*models.LoginCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLoginCode indicates an expected call of GetLoginCode.
+func (mr *MockRepositoryMockRecorder) GetLoginCode(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLoginCode", reflect.TypeOf((*MockRepository)(nil).GetLoginCode), arg0, arg1)
+}
+
+// GetLoginCodeByNonce mocks base method.
+func (m *MockRepository) GetLoginCodeByNonce(arg0 context.Context, arg1 string) (*models.LoginCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLoginCodeByNonce", arg0, arg1)
+	ret0, _ := ret[0].(*models.LoginCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLoginCodeByNonce indicates an expected call of GetLoginCodeByNonce.
+func (mr *MockRepositoryMockRecorder) GetLoginCodeByNonce(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLoginCodeByNonce", reflect.TypeOf((*MockRepository)(nil).GetLoginCodeByNonce), arg0, arg1)
+}
+
+// GetOrganization mocks base method.
+func (m *MockRepository) GetOrganization(arg0 context.Context, arg1, arg2 string) (*models.Organization, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrganization", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.Organization)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrganization indicates an expected call of GetOrganization.
+func (mr *MockRepositoryMockRecorder) GetOrganization(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrganization", reflect.TypeOf((*MockRepository)(nil).GetOrganization), arg0, arg1, arg2)
+}
+
+// GetOrganizationInvitation mocks base method.
+func (m *MockRepository) GetOrganizationInvitation(arg0 context.Context, arg1 string) (*models.OrganizationInvitation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrganizationInvitation", arg0, arg1)
+	ret0, _ := ret[0].(*models.OrganizationInvitation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrganizationInvitation indicates an expected call of GetOrganizationInvitation.
+func (mr *MockRepositoryMockRecorder) GetOrganizationInvitation(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrganizationInvitation", reflect.TypeOf((*MockRepository)(nil).GetOrganizationInvitation), arg0, arg1)
+}
+
+// GetOrganizationMember mocks base method.
+func (m *MockRepository) GetOrganizationMember(arg0 context.Context, arg1, arg2 string) (*models.OrganizationMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrganizationMember", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.OrganizationMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrganizationMember indicates an expected call of GetOrganizationMember.
+func (mr *MockRepositoryMockRecorder) GetOrganizationMember(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrganizationMember", reflect.TypeOf((*MockRepository)(nil).GetOrganizationMember), arg0, arg1, arg2)
+}
+
+// GetRefreshTokenByID mocks base method.
+func (m *MockRepository) GetRefreshTokenByID(arg0 context.Context, arg1, arg2 string) (*models.RefreshToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRefreshTokenByID", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.RefreshToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRefreshTokenByID indicates an expected call of GetRefreshTokenByID.
+func (mr *MockRepositoryMockRecorder) GetRefreshTokenByID(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRefreshTokenByID", reflect.TypeOf((*MockRepository)(nil).GetRefreshTokenByID), arg0, arg1, arg2)
+}
+
+// GetRefreshTokensByUser mocks base method.
+func (m *MockRepository) GetRefreshTokensByUser(arg0 context.Context, arg1, arg2 string) ([]models.RefreshToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRefreshTokensByUser", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]models.RefreshToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRefreshTokensByUser indicates an expected call of GetRefreshTokensByUser.
+func (mr *MockRepositoryMockRecorder) GetRefreshTokensByUser(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRefreshTokensByUser", reflect.TypeOf((*MockRepository)(nil).GetRefreshTokensByUser), arg0, arg1, arg2)
+}
+
+// GetRole mocks base method.
+func (m *MockRepository) GetRole(arg0 context.Context, arg1, arg2 string) (*models.Role, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRole", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.Role)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRole indicates an expected call of GetRole.
+func (mr *MockRepositoryMockRecorder) GetRole(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRole", reflect.TypeOf((*MockRepository)(nil).GetRole), arg0, arg1, arg2)
+}
+
+// GetTeam mocks base method.
+func (m *MockRepository) GetTeam(arg0 context.Context, arg1, arg2 string) (*models.Team, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTeam", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.Team)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTeam indicates an expected call of GetTeam.
+func (mr *MockRepositoryMockRecorder) GetTeam(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTeam", reflect.TypeOf((*MockRepository)(nil).GetTeam), arg0, arg1, arg2)
+}
+
+// GetTokenCutoff mocks base method.
+func (m *MockRepository) GetTokenCutoff(arg0 context.Context, arg1 string) (time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTokenCutoff", arg0, arg1)
+	ret0, _ := ret[0].(time.Time)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTokenCutoff indicates an expected call of GetTokenCutoff.
+func (mr *MockRepositoryMockRecorder) GetTokenCutoff(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTokenCutoff", reflect.TypeOf((*MockRepository)(nil).GetTokenCutoff), arg0, arg1)
+}
+
+// GetUnusedRecoveryCodes mocks base method.
+func (m *MockRepository) GetUnusedRecoveryCodes(arg0 context.Context, arg1, arg2 string) ([]models.RecoveryCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUnusedRecoveryCodes", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]models.RecoveryCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUnusedRecoveryCodes indicates an expected call of GetUnusedRecoveryCodes.
+func (mr *MockRepositoryMockRecorder) GetUnusedRecoveryCodes(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUnusedRecoveryCodes", reflect.TypeOf((*MockRepository)(nil).GetUnusedRecoveryCodes), arg0, arg1, arg2)
+}
+
+// GetUserByEmail mocks base method.
+func (m *MockRepository) GetUserByEmail(arg0 context.Context, arg1, arg2 string) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByEmail", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByEmail indicates an expected call of GetUserByEmail.
+func (mr *MockRepositoryMockRecorder) GetUserByEmail(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByEmail", reflect.TypeOf((*MockRepository)(nil).GetUserByEmail), arg0, arg1, arg2)
+}
+
+// GetUserByID mocks base method.
+func (m *MockRepository) GetUserByID(arg0 context.Context, arg1 string) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByID", arg0, arg1)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByID indicates an expected call of GetUserByID.
+func (mr *MockRepositoryMockRecorder) GetUserByID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByID", reflect.TypeOf((*MockRepository)(nil).GetUserByID), arg0, arg1)
+}
+
+// GetUserRoles mocks base method.
+func (m *MockRepository) GetUserRoles(arg0 context.Context, arg1 string) ([]models.Role, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserRoles", arg0, arg1)
+	ret0, _ := ret[0].([]models.Role)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserRoles indicates an expected call of GetUserRoles.
+func (mr *MockRepositoryMockRecorder) GetUserRoles(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserRoles", reflect.TypeOf((*MockRepository)(nil).GetUserRoles), arg0, arg1)
+}
+
+// GetWebAuthnCredentialsByUser mocks base method.
+func (m *MockRepository) GetWebAuthnCredentialsByUser(arg0 context.Context, arg1, arg2 string) ([]models.WebAuthnCredential, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebAuthnCredentialsByUser", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]models.WebAuthnCredential)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebAuthnCredentialsByUser indicates an expected call of GetWebAuthnCredentialsByUser.
+func (mr *MockRepositoryMockRecorder) GetWebAuthnCredentialsByUser(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebAuthnCredentialsByUser", reflect.TypeOf((*MockRepository)(nil).GetWebAuthnCredentialsByUser), arg0, arg1, arg2)
+}
+
+// IncrementLoginCodeAttempts mocks base method.
+func (m *MockRepository) IncrementLoginCodeAttempts(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IncrementLoginCodeAttempts", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// IncrementLoginCodeAttempts indicates an expected call of IncrementLoginCodeAttempts.
+func (mr *MockRepositoryMockRecorder) IncrementLoginCodeAttempts(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncrementLoginCodeAttempts", reflect.TypeOf((*MockRepository)(nil).IncrementLoginCodeAttempts), arg0, arg1)
+}
+
+// IsAccessTokenRevoked mocks base method.
+func (m *MockRepository) IsAccessTokenRevoked(arg0 context.Context, arg1 string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsAccessTokenRevoked", arg0, arg1)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsAccessTokenRevoked indicates an expected call of IsAccessTokenRevoked.
+func (mr *MockRepositoryMockRecorder) IsAccessTokenRevoked(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsAccessTokenRevoked", reflect.TypeOf((*MockRepository)(nil).IsAccessTokenRevoked), arg0, arg1)
+}
+
+// LinkFederatedIdentity mocks base method.
+func (m *MockRepository) LinkFederatedIdentity(arg0 context.Context, arg1 *models.FederatedIdentity) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LinkFederatedIdentity", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LinkFederatedIdentity indicates an expected call of LinkFederatedIdentity.
+func (mr *MockRepositoryMockRecorder) LinkFederatedIdentity(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LinkFederatedIdentity", reflect.TypeOf((*MockRepository)(nil).LinkFederatedIdentity), arg0, arg1)
+}
+
+// ListAPIKeys mocks base method.
+func (m *MockRepository) ListAPIKeys(arg0 context.Context, arg1 string) ([]models.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAPIKeys", arg0, arg1)
+	ret0, _ := ret[0].([]models.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAPIKeys indicates an expected call of ListAPIKeys.
+func (mr *MockRepositoryMockRecorder) ListAPIKeys(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAPIKeys", reflect.TypeOf((*MockRepository)(nil).ListAPIKeys), arg0, arg1)
+}
+
+// ListAuditEvents mocks base method.
+func (m *MockRepository) ListAuditEvents(arg0 context.Context, arg1 string) ([]models.AuditEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAuditEvents", arg0, arg1)
+	ret0, _ := ret[0].([]models.AuditEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAuditEvents indicates an expected call of ListAuditEvents.
+func (mr *MockRepositoryMockRecorder) ListAuditEvents(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAuditEvents", reflect.TypeOf((*MockRepository)(nil).ListAuditEvents), arg0, arg1)
+}
+
+// ListOrganizationMembers mocks base method.
+func (m *MockRepository) ListOrganizationMembers(arg0 context.Context, arg1 string) ([]models.OrganizationMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListOrganizationMembers", arg0, arg1)
+	ret0, _ := ret[0].([]models.OrganizationMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListOrganizationMembers indicates an expected call of ListOrganizationMembers.
+func (mr *MockRepositoryMockRecorder) ListOrganizationMembers(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListOrganizationMembers", reflect.TypeOf((*MockRepository)(nil).ListOrganizationMembers), arg0, arg1)
+}
+
+// ListPermissions mocks base method.
+func (m *MockRepository) ListPermissions(arg0 context.Context) ([]models.Permission, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPermissions", arg0)
+	ret0, _ := ret[0].([]models.Permission)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPermissions indicates an expected call of ListPermissions.
+func (mr *MockRepositoryMockRecorder) ListPermissions(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPermissions", reflect.TypeOf((*MockRepository)(nil).ListPermissions), arg0)
+}
+
+// ListRoles mocks base method.
+func (m *MockRepository) ListRoles(arg0 context.Context, arg1 string) ([]models.Role, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRoles", arg0, arg1)
+	ret0, _ := ret[0].([]models.Role)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListRoles indicates an expected call of ListRoles.
+func (mr *MockRepositoryMockRecorder) ListRoles(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRoles", reflect.TypeOf((*MockRepository)(nil).ListRoles), arg0, arg1)
+}
+
+// ListSecurityAlerts mocks base method.
+func (m *MockRepository) ListSecurityAlerts(arg0 context.Context, arg1 string) ([]models.SecurityAlert, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSecurityAlerts", arg0, arg1)
+	ret0, _ := ret[0].([]models.SecurityAlert)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSecurityAlerts indicates an expected call of ListSecurityAlerts.
+func (mr *MockRepositoryMockRecorder) ListSecurityAlerts(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSecurityAlerts", reflect.TypeOf((*MockRepository)(nil).ListSecurityAlerts), arg0, arg1)
+}
+
+// ListTeams mocks base method.
+func (m *MockRepository) ListTeams(arg0 context.Context, arg1 string) ([]models.Team, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTeams", arg0, arg1)
+	ret0, _ := ret[0].([]models.Team)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTeams indicates an expected call of ListTeams.
+func (mr *MockRepositoryMockRecorder) ListTeams(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTeams", reflect.TypeOf((*MockRepository)(nil).ListTeams), arg0, arg1)
+}
+
+// ListUserOrganizations mocks base method.
+func (m *MockRepository) ListUserOrganizations(arg0 context.Context, arg1, arg2 string) ([]models.Organization, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUserOrganizations", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]models.Organization)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUserOrganizations indicates an expected call of ListUserOrganizations.
+func (mr *MockRepositoryMockRecorder) ListUserOrganizations(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUserOrganizations", reflect.TypeOf((*MockRepository)(nil).ListUserOrganizations), arg0, arg1, arg2)
+}
+
+// ListUsersDueForDeletion mocks base method.
+func (m *MockRepository) ListUsersDueForDeletion(arg0 context.Context, arg1 time.Time, arg2 int) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUsersDueForDeletion", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUsersDueForDeletion indicates an expected call of ListUsersDueForDeletion.
+func (mr *MockRepositoryMockRecorder) ListUsersDueForDeletion(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUsersDueForDeletion", reflect.TypeOf((*MockRepository)(nil).ListUsersDueForDeletion), arg0, arg1, arg2)
+}
+
+// LockAuthFailure mocks base method.
+func (m *MockRepository) LockAuthFailure(arg0 context.Context, arg1, arg2 string, arg3 time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockAuthFailure", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LockAuthFailure indicates an expected call of LockAuthFailure.
+func (mr *MockRepositoryMockRecorder) LockAuthFailure(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockAuthFailure", reflect.TypeOf((*MockRepository)(nil).LockAuthFailure), arg0, arg1, arg2, arg3)
+}
+
+// MarkRecoveryCodeUsed mocks base method.
+func (m *MockRepository) MarkRecoveryCodeUsed(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkRecoveryCodeUsed", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkRecoveryCodeUsed indicates an expected call of MarkRecoveryCodeUsed.
+func (mr *MockRepositoryMockRecorder) MarkRecoveryCodeUsed(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkRecoveryCodeUsed", reflect.TypeOf((*MockRepository)(nil).MarkRecoveryCodeUsed), arg0, arg1)
+}
+
+// PollDeviceCode mocks base method.
+func (m *MockRepository) PollDeviceCode(arg0 context.Context, arg1 string, arg2 time.Time) (*models.DeviceCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PollDeviceCode", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.DeviceCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PollDeviceCode indicates an expected call of PollDeviceCode.
+func (mr *MockRepositoryMockRecorder) PollDeviceCode(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PollDeviceCode", reflect.TypeOf((*MockRepository)(nil).PollDeviceCode), arg0, arg1, arg2)
+}
+
+// PurgeUser mocks base method.
+func (m *MockRepository) PurgeUser(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeUser", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PurgeUser indicates an expected call of PurgeUser.
+func (mr *MockRepositoryMockRecorder) PurgeUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeUser", reflect.TypeOf((*MockRepository)(nil).PurgeUser), arg0, arg1)
+}
+
+// RecordAuthFailure mocks base method.
+func (m *MockRepository) RecordAuthFailure(arg0 context.Context, arg1, arg2 string, arg3 time.Duration) (*models.AuthFailure, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordAuthFailure", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*models.AuthFailure)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RecordAuthFailure indicates an expected call of RecordAuthFailure.
+func (mr *MockRepositoryMockRecorder) RecordAuthFailure(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordAuthFailure", reflect.TypeOf((*MockRepository)(nil).RecordAuthFailure), arg0, arg1, arg2, arg3)
+}
+
+// RemoveOrganizationMember mocks base method.
+func (m *MockRepository) RemoveOrganizationMember(arg0 context.Context, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveOrganizationMember", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveOrganizationMember indicates an expected call of RemoveOrganizationMember.
+func (mr *MockRepositoryMockRecorder) RemoveOrganizationMember(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveOrganizationMember", reflect.TypeOf((*MockRepository)(nil).RemoveOrganizationMember), arg0, arg1, arg2)
+}
+
+// RemoveTeamMember mocks base method.
+func (m *MockRepository) RemoveTeamMember(arg0 context.Context, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveTeamMember", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveTeamMember indicates an expected call of RemoveTeamMember.
+func (mr *MockRepositoryMockRecorder) RemoveTeamMember(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveTeamMember", reflect.TypeOf((*MockRepository)(nil).RemoveTeamMember), arg0, arg1, arg2)
+}
+
+// ReplaceRecoveryCodes mocks base method.
+func (m *MockRepository) ReplaceRecoveryCodes(arg0 context.Context, arg1, arg2 string, arg3 []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplaceRecoveryCodes", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReplaceRecoveryCodes indicates an expected call of ReplaceRecoveryCodes.
+func (mr *MockRepositoryMockRecorder) ReplaceRecoveryCodes(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplaceRecoveryCodes", reflect.TypeOf((*MockRepository)(nil).ReplaceRecoveryCodes), arg0, arg1, arg2, arg3)
+}
+
+// RevokeAccessToken mocks base method.
+func (m *MockRepository) RevokeAccessToken(arg0 context.Context, arg1 string, arg2 time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeAccessToken", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeAccessToken indicates an expected call of RevokeAccessToken.
+func (mr *MockRepositoryMockRecorder) RevokeAccessToken(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAccessToken", reflect.TypeOf((*MockRepository)(nil).RevokeAccessToken), arg0, arg1, arg2)
+}
+
+// RevokeAllTokens mocks base method.
+func (m *MockRepository) RevokeAllTokens(arg0 context.Context, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeAllTokens", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeAllTokens indicates an expected call of RevokeAllTokens.
+func (mr *MockRepositoryMockRecorder) RevokeAllTokens(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAllTokens", reflect.TypeOf((*MockRepository)(nil).RevokeAllTokens), arg0, arg1, arg2)
+}
+
+// RotateRefreshToken mocks base method.
+func (m *MockRepository) RotateRefreshToken(arg0 context.Context, arg1 string, arg2 *models.RefreshToken) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RotateRefreshToken", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RotateRefreshToken indicates an expected call of RotateRefreshToken.
+func (mr *MockRepositoryMockRecorder) RotateRefreshToken(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RotateRefreshToken", reflect.TypeOf((*MockRepository)(nil).RotateRefreshToken), arg0, arg1, arg2)
+}
+
+// SaveAuditEvent mocks base method.
+func (m *MockRepository) SaveAuditEvent(arg0 context.Context, arg1 *models.AuditEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveAuditEvent", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveAuditEvent indicates an expected call of SaveAuditEvent.
+func (mr *MockRepositoryMockRecorder) SaveAuditEvent(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveAuditEvent", reflect.TypeOf((*MockRepository)(nil).SaveAuditEvent), arg0, arg1)
+}
+
+// SaveAuthorizationCode mocks base method.
+func (m *MockRepository) SaveAuthorizationCode(arg0 context.Context, arg1 *models.AuthorizationCode) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveAuthorizationCode", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveAuthorizationCode indicates an expected call of SaveAuthorizationCode.
+func (mr *MockRepositoryMockRecorder) SaveAuthorizationCode(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveAuthorizationCode", reflect.TypeOf((*MockRepository)(nil).SaveAuthorizationCode), arg0, arg1)
+}
+
+// SaveDeviceCode mocks base method.
+func (m *MockRepository) SaveDeviceCode(arg0 context.Context, arg1 *models.DeviceCode) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveDeviceCode", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveDeviceCode indicates an expected call of SaveDeviceCode.
+func (mr *MockRepositoryMockRecorder) SaveDeviceCode(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveDeviceCode", reflect.TypeOf((*MockRepository)(nil).SaveDeviceCode), arg0, arg1)
+}
+
+// SaveEmailChange mocks base method.
+func (m *MockRepository) SaveEmailChange(arg0 context.Context, arg1 *models.EmailChange) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveEmailChange", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveEmailChange indicates an expected call of SaveEmailChange.
+func (mr *MockRepositoryMockRecorder) SaveEmailChange(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveEmailChange", reflect.TypeOf((*MockRepository)(nil).SaveEmailChange), arg0, arg1)
+}
+
+// SaveFederatedLogin mocks base method.
+func (m *MockRepository) SaveFederatedLogin(arg0 context.Context, arg1 *models.FederatedLogin) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveFederatedLogin", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveFederatedLogin indicates an expected call of SaveFederatedLogin.
+func (mr *MockRepositoryMockRecorder) SaveFederatedLogin(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveFederatedLogin", reflect.TypeOf((*MockRepository)(nil).SaveFederatedLogin), arg0, arg1)
+}
+
+// SaveLoginCode mocks base method.
+func (m *MockRepository) SaveLoginCode(arg0 context.Context, arg1 *models.LoginCode) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveLoginCode", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveLoginCode indicates an expected call of SaveLoginCode.
+func (mr *MockRepositoryMockRecorder) SaveLoginCode(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveLoginCode", reflect.TypeOf((*MockRepository)(nil).SaveLoginCode), arg0, arg1)
+}
+
+// SaveOrganizationInvitation mocks base method.
+func (m *MockRepository) SaveOrganizationInvitation(arg0 context.Context, arg1 *models.OrganizationInvitation) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveOrganizationInvitation", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveOrganizationInvitation indicates an expected call of SaveOrganizationInvitation.
+func (mr *MockRepositoryMockRecorder) SaveOrganizationInvitation(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveOrganizationInvitation", reflect.TypeOf((*MockRepository)(nil).SaveOrganizationInvitation), arg0, arg1)
+}
+
+// SaveRefreshToken mocks base method.
+func (m *MockRepository) SaveRefreshToken(arg0 context.Context, arg1 *models.RefreshToken) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveRefreshToken", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveRefreshToken indicates an expected call of SaveRefreshToken.
+func (mr *MockRepositoryMockRecorder) SaveRefreshToken(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveRefreshToken", reflect.TypeOf((*MockRepository)(nil).SaveRefreshToken), arg0, arg1)
+}
+
+// SaveSecurityAlert mocks base method.
+func (m *MockRepository) SaveSecurityAlert(arg0 context.Context, arg1 *models.SecurityAlert) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveSecurityAlert", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveSecurityAlert indicates an expected call of SaveSecurityAlert.
+func (mr *MockRepositoryMockRecorder) SaveSecurityAlert(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveSecurityAlert", reflect.TypeOf((*MockRepository)(nil).SaveSecurityAlert), arg0, arg1)
+}
+
+// SaveWebAuthnCredential mocks base method.
+func (m *MockRepository) SaveWebAuthnCredential(arg0 context.Context, arg1 *models.WebAuthnCredential) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveWebAuthnCredential", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveWebAuthnCredential indicates an expected call of SaveWebAuthnCredential.
+func (mr *MockRepositoryMockRecorder) SaveWebAuthnCredential(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveWebAuthnCredential", reflect.TypeOf((*MockRepository)(nil).SaveWebAuthnCredential), arg0, arg1)
+}
+
+// SaveWebAuthnSession mocks base method.
+func (m *MockRepository) SaveWebAuthnSession(arg0 context.Context, arg1 *models.WebAuthnSession) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveWebAuthnSession", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveWebAuthnSession indicates an expected call of SaveWebAuthnSession.
+func (mr *MockRepositoryMockRecorder) SaveWebAuthnSession(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveWebAuthnSession", reflect.TypeOf((*MockRepository)(nil).SaveWebAuthnSession), arg0, arg1)
+}
+
+// ScheduleUserDeletion mocks base method.
+func (m *MockRepository) ScheduleUserDeletion(arg0 context.Context, arg1 string, arg2 time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ScheduleUserDeletion", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ScheduleUserDeletion indicates an expected call of ScheduleUserDeletion.
+func (mr *MockRepositoryMockRecorder) ScheduleUserDeletion(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScheduleUserDeletion", reflect.TypeOf((*MockRepository)(nil).ScheduleUserDeletion), arg0, arg1, arg2)
+}
+
+// SetRoleTokenCutoff mocks base method.
+func (m *MockRepository) SetRoleTokenCutoff(arg0 context.Context, arg1 string, arg2 time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetRoleTokenCutoff", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetRoleTokenCutoff indicates an expected call of SetRoleTokenCutoff.
+func (mr *MockRepositoryMockRecorder) SetRoleTokenCutoff(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetRoleTokenCutoff", reflect.TypeOf((*MockRepository)(nil).SetRoleTokenCutoff), arg0, arg1, arg2)
+}
+
+// SetTokenCutoff mocks base method.
+func (m *MockRepository) SetTokenCutoff(arg0 context.Context, arg1 string, arg2 time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetTokenCutoff", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetTokenCutoff indicates an expected call of SetTokenCutoff.
+func (mr *MockRepositoryMockRecorder) SetTokenCutoff(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTokenCutoff", reflect.TypeOf((*MockRepository)(nil).SetTokenCutoff), arg0, arg1, arg2)
+}
+
+// SetUserRoles mocks base method.
+func (m *MockRepository) SetUserRoles(arg0 context.Context, arg1, arg2 string, arg3 []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetUserRoles", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetUserRoles indicates an expected call of SetUserRoles.
+func (mr *MockRepositoryMockRecorder) SetUserRoles(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetUserRoles", reflect.TypeOf((*MockRepository)(nil).SetUserRoles), arg0, arg1, arg2, arg3)
+}
+
+// TakeAuthorizationCode mocks base method.
+func (m *MockRepository) TakeAuthorizationCode(arg0 context.Context, arg1 string) (*models.AuthorizationCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TakeAuthorizationCode", arg0, arg1)
+	ret0, _ := ret[0].(*models.AuthorizationCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TakeAuthorizationCode indicates an expected call of TakeAuthorizationCode.
+func (mr *MockRepositoryMockRecorder) TakeAuthorizationCode(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TakeAuthorizationCode", reflect.TypeOf((*MockRepository)(nil).TakeAuthorizationCode), arg0, arg1)
+}
+
+// TakeFederatedLogin mocks base method.
+func (m *MockRepository) TakeFederatedLogin(arg0 context.Context, arg1 string) (*models.FederatedLogin, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TakeFederatedLogin", arg0, arg1)
+	ret0, _ := ret[0].(*models.FederatedLogin)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TakeFederatedLogin indicates an expected call of TakeFederatedLogin.
+func (mr *MockRepositoryMockRecorder) TakeFederatedLogin(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TakeFederatedLogin", reflect.TypeOf((*MockRepository)(nil).TakeFederatedLogin), arg0, arg1)
+}
+
+// TakeWebAuthnSession mocks base method.
+func (m *MockRepository) TakeWebAuthnSession(arg0 context.Context, arg1, arg2 string) (*models.WebAuthnSession, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TakeWebAuthnSession", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.WebAuthnSession)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TakeWebAuthnSession indicates an expected call of TakeWebAuthnSession.
+func (mr *MockRepositoryMockRecorder) TakeWebAuthnSession(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TakeWebAuthnSession", reflect.TypeOf((*MockRepository)(nil).TakeWebAuthnSession), arg0, arg1, arg2)
+}
+
+// TouchAPIKey mocks base method.
+func (m *MockRepository) TouchAPIKey(arg0 context.Context, arg1, arg2 string, arg3 time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TouchAPIKey", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TouchAPIKey indicates an expected call of TouchAPIKey.
+func (mr *MockRepositoryMockRecorder) TouchAPIKey(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TouchAPIKey", reflect.TypeOf((*MockRepository)(nil).TouchAPIKey), arg0, arg1, arg2, arg3)
+}
+
+// UpdateClientSecret mocks base method.
+func (m *MockRepository) UpdateClientSecret(arg0 context.Context, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateClientSecret", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateClientSecret indicates an expected call of UpdateClientSecret.
+func (mr *MockRepositoryMockRecorder) UpdateClientSecret(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateClientSecret", reflect.TypeOf((*MockRepository)(nil).UpdateClientSecret), arg0, arg1, arg2)
+}
+
+// UpdateOrganizationMemberRole mocks base method.
+func (m *MockRepository) UpdateOrganizationMemberRole(arg0 context.Context, arg1, arg2, arg3 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateOrganizationMemberRole", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateOrganizationMemberRole indicates an expected call of UpdateOrganizationMemberRole.
+func (mr *MockRepositoryMockRecorder) UpdateOrganizationMemberRole(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateOrganizationMemberRole", reflect.TypeOf((*MockRepository)(nil).UpdateOrganizationMemberRole), arg0, arg1, arg2, arg3)
+}
+
+// UpdateRole mocks base method.
+func (m *MockRepository) UpdateRole(arg0 context.Context, arg1 *models.Role) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateRole", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateRole indicates an expected call of UpdateRole.
+func (mr *MockRepositoryMockRecorder) UpdateRole(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRole", reflect.TypeOf((*MockRepository)(nil).UpdateRole), arg0, arg1)
+}
+
+// UpdateUserEmail mocks base method.
+func (m *MockRepository) UpdateUserEmail(arg0 context.Context, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserEmail", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateUserEmail indicates an expected call of UpdateUserEmail.
+func (mr *MockRepositoryMockRecorder) UpdateUserEmail(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserEmail", reflect.TypeOf((*MockRepository)(nil).UpdateUserEmail), arg0, arg1, arg2)
+}
+
+// UpdateUserProfile mocks base method.
+func (m *MockRepository) UpdateUserProfile(arg0 context.Context, arg1 *models.User) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserProfile", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateUserProfile indicates an expected call of UpdateUserProfile.
+func (mr *MockRepositoryMockRecorder) UpdateUserProfile(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserProfile", reflect.TypeOf((*MockRepository)(nil).UpdateUserProfile), arg0, arg1)
+}
+
+// UpdateWebAuthnCredentialUsage mocks base method.
+func (m *MockRepository) UpdateWebAuthnCredentialUsage(arg0 context.Context, arg1 string, arg2 uint32, arg3 bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateWebAuthnCredentialUsage", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateWebAuthnCredentialUsage indicates an expected call of UpdateWebAuthnCredentialUsage.
+func (mr *MockRepositoryMockRecorder) UpdateWebAuthnCredentialUsage(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWebAuthnCredentialUsage", reflect.TypeOf((*MockRepository)(nil).UpdateWebAuthnCredentialUsage), arg0, arg1, arg2, arg3)
+}
+
+// MockRecoveryCodeRepository is a mock of RecoveryCodeRepository interface.
+type MockRecoveryCodeRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockRecoveryCodeRepositoryMockRecorder
+}
+
+// MockRecoveryCodeRepositoryMockRecorder is the mock recorder for MockRecoveryCodeRepository.
+type MockRecoveryCodeRepositoryMockRecorder struct {
+	mock *MockRecoveryCodeRepository
+}
+
+// NewMockRecoveryCodeRepository creates a new mock instance.
+func NewMockRecoveryCodeRepository(ctrl *gomock.Controller) *MockRecoveryCodeRepository {
+	mock := &MockRecoveryCodeRepository{ctrl: ctrl}
+	mock.recorder = &MockRecoveryCodeRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRecoveryCodeRepository) EXPECT() *MockRecoveryCodeRepositoryMockRecorder {
+	return m.recorder
+}
+
+// GetUnusedRecoveryCodes mocks base method.
+func (m *MockRecoveryCodeRepository) GetUnusedRecoveryCodes(arg0 context.Context, arg1, arg2 string) ([]models.RecoveryCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUnusedRecoveryCodes", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]models.RecoveryCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUnusedRecoveryCodes indicates an expected call of GetUnusedRecoveryCodes.
+func (mr *MockRecoveryCodeRepositoryMockRecorder) GetUnusedRecoveryCodes(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUnusedRecoveryCodes", reflect.TypeOf((*MockRecoveryCodeRepository)(nil).GetUnusedRecoveryCodes), arg0, arg1, arg2)
+}
+
+// MarkRecoveryCodeUsed mocks base method.
+func (m *MockRecoveryCodeRepository) MarkRecoveryCodeUsed(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkRecoveryCodeUsed", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkRecoveryCodeUsed indicates an expected call of MarkRecoveryCodeUsed.
+func (mr *MockRecoveryCodeRepositoryMockRecorder) MarkRecoveryCodeUsed(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkRecoveryCodeUsed", reflect.TypeOf((*MockRecoveryCodeRepository)(nil).MarkRecoveryCodeUsed), arg0, arg1)
+}
+
+// ReplaceRecoveryCodes mocks base method.
+func (m *MockRecoveryCodeRepository) ReplaceRecoveryCodes(arg0 context.Context, arg1, arg2 string, arg3 []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplaceRecoveryCodes", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReplaceRecoveryCodes indicates an expected call of ReplaceRecoveryCodes.
+func (mr *MockRecoveryCodeRepositoryMockRecorder) ReplaceRecoveryCodes(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplaceRecoveryCodes", reflect.TypeOf((*MockRecoveryCodeRepository)(nil).ReplaceRecoveryCodes), arg0, arg1, arg2, arg3)
+}
+
+// MockAuditRepository is a mock of AuditRepository interface.
+type MockAuditRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuditRepositoryMockRecorder
+}
+
+// MockAuditRepositoryMockRecorder is the mock recorder for MockAuditRepository.
+type MockAuditRepositoryMockRecorder struct {
+	mock *MockAuditRepository
+}
+
+// NewMockAuditRepository creates a new mock instance.
+func NewMockAuditRepository(ctrl *gomock.Controller) *MockAuditRepository {
+	mock := &MockAuditRepository{ctrl: ctrl}
+	mock.recorder = &MockAuditRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAuditRepository) EXPECT() *MockAuditRepositoryMockRecorder {
+	return m.recorder
+}
+
+// ListAuditEvents mocks base method.
+func (m *MockAuditRepository) ListAuditEvents(arg0 context.Context, arg1 string) ([]models.AuditEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAuditEvents", arg0, arg1)
+	ret0, _ := ret[0].([]models.AuditEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAuditEvents indicates an expected call of ListAuditEvents.
+func (mr *MockAuditRepositoryMockRecorder) ListAuditEvents(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAuditEvents", reflect.TypeOf((*MockAuditRepository)(nil).ListAuditEvents), arg0, arg1)
+}
+
+// SaveAuditEvent mocks base method.
+func (m *MockAuditRepository) SaveAuditEvent(arg0 context.Context, arg1 *models.AuditEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveAuditEvent", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveAuditEvent indicates an expected call of SaveAuditEvent.
+func (mr *MockAuditRepositoryMockRecorder) SaveAuditEvent(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveAuditEvent", reflect.TypeOf((*MockAuditRepository)(nil).SaveAuditEvent), arg0, arg1)
+}
+
+// MockWebAuthnRepository is a mock of WebAuthnRepository interface.
+type MockWebAuthnRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockWebAuthnRepositoryMockRecorder
+}
+
+// MockWebAuthnRepositoryMockRecorder is the mock recorder for MockWebAuthnRepository.
+type MockWebAuthnRepositoryMockRecorder struct {
+	mock *MockWebAuthnRepository
+}
+
+// NewMockWebAuthnRepository creates a new mock instance.
+func NewMockWebAuthnRepository(ctrl *gomock.Controller) *MockWebAuthnRepository {
+	mock := &MockWebAuthnRepository{ctrl: ctrl}
+	mock.recorder = &MockWebAuthnRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWebAuthnRepository) EXPECT() *MockWebAuthnRepositoryMockRecorder {
+	return m.recorder
+}
+
+// GetWebAuthnCredentialsByUser mocks base method.
+func (m *MockWebAuthnRepository) GetWebAuthnCredentialsByUser(arg0 context.Context, arg1, arg2 string) ([]models.WebAuthnCredential, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebAuthnCredentialsByUser", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]models.WebAuthnCredential)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebAuthnCredentialsByUser indicates an expected call of GetWebAuthnCredentialsByUser.
+func (mr *MockWebAuthnRepositoryMockRecorder) GetWebAuthnCredentialsByUser(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebAuthnCredentialsByUser", reflect.TypeOf((*MockWebAuthnRepository)(nil).GetWebAuthnCredentialsByUser), arg0, arg1, arg2)
+}
+
+// SaveWebAuthnCredential mocks base method.
+func (m *MockWebAuthnRepository) SaveWebAuthnCredential(arg0 context.Context, arg1 *models.WebAuthnCredential) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveWebAuthnCredential", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveWebAuthnCredential indicates an expected call of SaveWebAuthnCredential.
+func (mr *MockWebAuthnRepositoryMockRecorder) SaveWebAuthnCredential(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveWebAuthnCredential", reflect.TypeOf((*MockWebAuthnRepository)(nil).SaveWebAuthnCredential), arg0, arg1)
+}
+
+// SaveWebAuthnSession mocks base method.
+func (m *MockWebAuthnRepository) SaveWebAuthnSession(arg0 context.Context, arg1 *models.WebAuthnSession) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveWebAuthnSession", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveWebAuthnSession indicates an expected call of SaveWebAuthnSession.
+func (mr *MockWebAuthnRepositoryMockRecorder) SaveWebAuthnSession(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveWebAuthnSession", reflect.TypeOf((*MockWebAuthnRepository)(nil).SaveWebAuthnSession), arg0, arg1)
+}
+
+// TakeWebAuthnSession mocks base method.
+func (m *MockWebAuthnRepository) TakeWebAuthnSession(arg0 context.Context, arg1, arg2 string) (*models.WebAuthnSession, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TakeWebAuthnSession", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.WebAuthnSession)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TakeWebAuthnSession indicates an expected call of TakeWebAuthnSession.
+func (mr *MockWebAuthnRepositoryMockRecorder) TakeWebAuthnSession(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TakeWebAuthnSession", reflect.TypeOf((*MockWebAuthnRepository)(nil).TakeWebAuthnSession), arg0, arg1, arg2)
+}
+
+// UpdateWebAuthnCredentialUsage mocks base method.
+func (m *MockWebAuthnRepository) UpdateWebAuthnCredentialUsage(arg0 context.Context, arg1 string, arg2 uint32, arg3 bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateWebAuthnCredentialUsage", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateWebAuthnCredentialUsage indicates an expected call of UpdateWebAuthnCredentialUsage.
+func (mr *MockWebAuthnRepositoryMockRecorder) UpdateWebAuthnCredentialUsage(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWebAuthnCredentialUsage", reflect.TypeOf((*MockWebAuthnRepository)(nil).UpdateWebAuthnCredentialUsage), arg0, arg1, arg2, arg3)
+}
+
+// MockLockoutRepository is a mock of LockoutRepository interface.
+type MockLockoutRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockLockoutRepositoryMockRecorder
+}
+
+// MockLockoutRepositoryMockRecorder is the mock recorder for MockLockoutRepository.
+type MockLockoutRepositoryMockRecorder struct {
+	mock *MockLockoutRepository
+}
+
+// NewMockLockoutRepository creates a new mock instance.
+func NewMockLockoutRepository(ctrl *gomock.Controller) *MockLockoutRepository {
+	mock := &MockLockoutRepository{ctrl: ctrl}
+	mock.recorder = &MockLockoutRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLockoutRepository) EXPECT() *MockLockoutRepositoryMockRecorder {
+	return m.recorder
+}
+
+// ClearAuthFailures mocks base method.
+func (m *MockLockoutRepository) ClearAuthFailures(arg0 context.Context, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClearAuthFailures", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ClearAuthFailures indicates an expected call of ClearAuthFailures.
+func (mr *MockLockoutRepositoryMockRecorder) ClearAuthFailures(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClearAuthFailures", reflect.TypeOf((*MockLockoutRepository)(nil).ClearAuthFailures), arg0, arg1, arg2)
+}
+
+// GetAuthFailure mocks base method.
+func (m *MockLockoutRepository) GetAuthFailure(arg0 context.Context, arg1, arg2 string) (*models.AuthFailure, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAuthFailure", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.AuthFailure)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAuthFailure indicates an expected call of GetAuthFailure.
+func (mr *MockLockoutRepositoryMockRecorder) GetAuthFailure(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuthFailure", reflect.TypeOf((*MockLockoutRepository)(nil).GetAuthFailure), arg0, arg1, arg2)
+}
+
+// LockAuthFailure mocks base method.
+func (m *MockLockoutRepository) LockAuthFailure(arg0 context.Context, arg1, arg2 string, arg3 time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockAuthFailure", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LockAuthFailure indicates an expected call of LockAuthFailure.
+func (mr *MockLockoutRepositoryMockRecorder) LockAuthFailure(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockAuthFailure", reflect.TypeOf((*MockLockoutRepository)(nil).LockAuthFailure), arg0, arg1, arg2, arg3)
+}
+
+// RecordAuthFailure mocks base method.
+func (m *MockLockoutRepository) RecordAuthFailure(arg0 context.Context, arg1, arg2 string, arg3 time.Duration) (*models.AuthFailure, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordAuthFailure", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*models.AuthFailure)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RecordAuthFailure indicates an expected call of RecordAuthFailure.
+func (mr *MockLockoutRepositoryMockRecorder) RecordAuthFailure(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordAuthFailure", reflect.TypeOf((*MockLockoutRepository)(nil).RecordAuthFailure), arg0, arg1, arg2, arg3)
+}
+
+// MockUserRepository is a mock of UserRepository interface.
+type MockUserRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockUserRepositoryMockRecorder
+}
+
+// MockUserRepositoryMockRecorder is the mock recorder for MockUserRepository.
+type MockUserRepositoryMockRecorder struct {
+	mock *MockUserRepository
+}
+
+// NewMockUserRepository creates a new mock instance.
+func NewMockUserRepository(ctrl *gomock.Controller) *MockUserRepository {
+	mock := &MockUserRepository{ctrl: ctrl}
+	mock.recorder = &MockUserRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockUserRepository) EXPECT() *MockUserRepositoryMockRecorder {
+	return m.recorder
+}
+
+// GetUserByEmail mocks base method.
+func (m *MockUserRepository) GetUserByEmail(arg0 context.Context, arg1, arg2 string) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByEmail", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByEmail indicates an expected call of GetUserByEmail.
+func (mr *MockUserRepositoryMockRecorder) GetUserByEmail(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByEmail", reflect.TypeOf((*MockUserRepository)(nil).GetUserByEmail), arg0, arg1, arg2)
+}
+
+// GetUserByID mocks base method.
+func (m *MockUserRepository) GetUserByID(arg0 context.Context, arg1 string) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByID", arg0, arg1)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByID indicates an expected call of GetUserByID.
+func (mr *MockUserRepositoryMockRecorder) GetUserByID(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByID", reflect.TypeOf((*MockUserRepository)(nil).GetUserByID), arg0, arg1)
+}
+
+// UpdateUserEmail mocks base method.
+func (m *MockUserRepository) UpdateUserEmail(arg0 context.Context, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserEmail", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateUserEmail indicates an expected call of UpdateUserEmail.
+func (mr *MockUserRepositoryMockRecorder) UpdateUserEmail(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserEmail", reflect.TypeOf((*MockUserRepository)(nil).UpdateUserEmail), arg0, arg1, arg2)
+}
+
+// UpdateUserProfile mocks base method.
+func (m *MockUserRepository) UpdateUserProfile(arg0 context.Context, arg1 *models.User) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserProfile", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateUserProfile indicates an expected call of UpdateUserProfile.
+func (mr *MockUserRepositoryMockRecorder) UpdateUserProfile(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserProfile", reflect.TypeOf((*MockUserRepository)(nil).UpdateUserProfile), arg0, arg1)
+}
+
+// MockLoginCodeRepository is a mock of LoginCodeRepository interface.
+type MockLoginCodeRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockLoginCodeRepositoryMockRecorder
+}
+
+// MockLoginCodeRepositoryMockRecorder is the mock recorder for MockLoginCodeRepository.
+type MockLoginCodeRepositoryMockRecorder struct {
+	mock *MockLoginCodeRepository
+}
+
+// NewMockLoginCodeRepository creates a new mock instance.
+func NewMockLoginCodeRepository(ctrl *gomock.Controller) *MockLoginCodeRepository {
+	mock := &MockLoginCodeRepository{ctrl: ctrl}
+	mock.recorder = &MockLoginCodeRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLoginCodeRepository) EXPECT() *MockLoginCodeRepositoryMockRecorder {
+	return m.recorder
+}
+
+// ConsumeLoginCode mocks base method.
+func (m *MockLoginCodeRepository) ConsumeLoginCode(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConsumeLoginCode", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ConsumeLoginCode indicates an expected call of ConsumeLoginCode.
+func (mr *MockLoginCodeRepositoryMockRecorder) ConsumeLoginCode(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsumeLoginCode", reflect.TypeOf((*MockLoginCodeRepository)(nil).ConsumeLoginCode), arg0, arg1)
+}
+
+// GetLoginCode mocks base method.
+func (m *MockLoginCodeRepository) GetLoginCode(arg0 context.Context, arg1 string) (*models.LoginCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLoginCode", arg0, arg1)
+	ret0, _ := ret[0].(*models.LoginCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLoginCode indicates an expected call of GetLoginCode.
+func (mr *MockLoginCodeRepositoryMockRecorder) GetLoginCode(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLoginCode", reflect.TypeOf((*MockLoginCodeRepository)(nil).GetLoginCode), arg0, arg1)
+}
+
+// GetLoginCodeByNonce mocks base method.
+func (m *MockLoginCodeRepository) GetLoginCodeByNonce(arg0 context.Context, arg1 string) (*models.LoginCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLoginCodeByNonce", arg0, arg1)
+	ret0, _ := ret[0].(*models.LoginCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLoginCodeByNonce indicates an expected call of GetLoginCodeByNonce.
+func (mr *MockLoginCodeRepositoryMockRecorder) GetLoginCodeByNonce(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLoginCodeByNonce", reflect.TypeOf((*MockLoginCodeRepository)(nil).GetLoginCodeByNonce), arg0, arg1)
+}
+
+// IncrementLoginCodeAttempts mocks base method.
+func (m *MockLoginCodeRepository) IncrementLoginCodeAttempts(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IncrementLoginCodeAttempts", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// IncrementLoginCodeAttempts indicates an expected call of IncrementLoginCodeAttempts.
+func (mr *MockLoginCodeRepositoryMockRecorder) IncrementLoginCodeAttempts(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncrementLoginCodeAttempts", reflect.TypeOf((*MockLoginCodeRepository)(nil).IncrementLoginCodeAttempts), arg0, arg1)
+}
+
+// SaveLoginCode mocks base method.
+func (m *MockLoginCodeRepository) SaveLoginCode(arg0 context.Context, arg1 *models.LoginCode) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveLoginCode", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveLoginCode indicates an expected call of SaveLoginCode.
+func (mr *MockLoginCodeRepositoryMockRecorder) SaveLoginCode(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveLoginCode", reflect.TypeOf((*MockLoginCodeRepository)(nil).SaveLoginCode), arg0, arg1)
+}
+
+// MockClientRepository is a mock of ClientRepository interface.
+type MockClientRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockClientRepositoryMockRecorder
+}
+
+// MockClientRepositoryMockRecorder is the mock recorder for MockClientRepository.
+type MockClientRepositoryMockRecorder struct {
+	mock *MockClientRepository
+}
+
+// NewMockClientRepository creates a new mock instance.
+func NewMockClientRepository(ctrl *gomock.Controller) *MockClientRepository {
+	mock := &MockClientRepository{ctrl: ctrl}
+	mock.recorder = &MockClientRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockClientRepository) EXPECT() *MockClientRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateClient mocks base method.
+func (m *MockClientRepository) CreateClient(arg0 context.Context, arg1 *models.OAuthClient) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateClient", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateClient indicates an expected call of CreateClient.
+func (mr *MockClientRepositoryMockRecorder) CreateClient(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateClient", reflect.TypeOf((*MockClientRepository)(nil).CreateClient), arg0, arg1)
+}
+
+// DisableClient mocks base method.
+func (m *MockClientRepository) DisableClient(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DisableClient", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DisableClient indicates an expected call of DisableClient.
+func (mr *MockClientRepositoryMockRecorder) DisableClient(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisableClient", reflect.TypeOf((*MockClientRepository)(nil).DisableClient), arg0, arg1)
+}
+
+// GetClient mocks base method.
+func (m *MockClientRepository) GetClient(arg0 context.Context, arg1 string) (*models.OAuthClient, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetClient", arg0, arg1)
+	ret0, _ := ret[0].(*models.OAuthClient)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetClient indicates an expected call of GetClient.
+func (mr *MockClientRepositoryMockRecorder) GetClient(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetClient", reflect.TypeOf((*MockClientRepository)(nil).GetClient), arg0, arg1)
+}
+
+// UpdateClientSecret mocks base method.
+func (m *MockClientRepository) UpdateClientSecret(arg0 context.Context, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateClientSecret", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateClientSecret indicates an expected call of UpdateClientSecret.
+func (mr *MockClientRepositoryMockRecorder) UpdateClientSecret(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateClientSecret", reflect.TypeOf((*MockClientRepository)(nil).UpdateClientSecret), arg0, arg1, arg2)
+}
+
+// MockAuthorizationCodeRepository is a mock of AuthorizationCodeRepository interface.
+type MockAuthorizationCodeRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuthorizationCodeRepositoryMockRecorder
+}
+
+// MockAuthorizationCodeRepositoryMockRecorder is the mock recorder for MockAuthorizationCodeRepository.
+type MockAuthorizationCodeRepositoryMockRecorder struct {
+	mock *MockAuthorizationCodeRepository
+}
+
+// NewMockAuthorizationCodeRepository creates a new mock instance.
+func NewMockAuthorizationCodeRepository(ctrl *gomock.Controller) *MockAuthorizationCodeRepository {
+	mock := &MockAuthorizationCodeRepository{ctrl: ctrl}
+	mock.recorder = &MockAuthorizationCodeRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAuthorizationCodeRepository) EXPECT() *MockAuthorizationCodeRepositoryMockRecorder {
+	return m.recorder
+}
+
+// SaveAuthorizationCode mocks base method.
+func (m *MockAuthorizationCodeRepository) SaveAuthorizationCode(arg0 context.Context, arg1 *models.AuthorizationCode) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveAuthorizationCode", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveAuthorizationCode indicates an expected call of SaveAuthorizationCode.
+func (mr *MockAuthorizationCodeRepositoryMockRecorder) SaveAuthorizationCode(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveAuthorizationCode", reflect.TypeOf((*MockAuthorizationCodeRepository)(nil).SaveAuthorizationCode), arg0, arg1)
+}
+
+// TakeAuthorizationCode mocks base method.
+func (m *MockAuthorizationCodeRepository) TakeAuthorizationCode(arg0 context.Context, arg1 string) (*models.AuthorizationCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TakeAuthorizationCode", arg0, arg1)
+	ret0, _ := ret[0].(*models.AuthorizationCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TakeAuthorizationCode indicates an expected call of TakeAuthorizationCode.
+func (mr *MockAuthorizationCodeRepositoryMockRecorder) TakeAuthorizationCode(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TakeAuthorizationCode", reflect.TypeOf((*MockAuthorizationCodeRepository)(nil).TakeAuthorizationCode), arg0, arg1)
+}
+
+// MockDeviceCodeRepository is a mock of DeviceCodeRepository interface.
+type MockDeviceCodeRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockDeviceCodeRepositoryMockRecorder
+}
+
+// MockDeviceCodeRepositoryMockRecorder is the mock recorder for MockDeviceCodeRepository.
+type MockDeviceCodeRepositoryMockRecorder struct {
+	mock *MockDeviceCodeRepository
+}
+
+// NewMockDeviceCodeRepository creates a new mock instance.
+func NewMockDeviceCodeRepository(ctrl *gomock.Controller) *MockDeviceCodeRepository {
+	mock := &MockDeviceCodeRepository{ctrl: ctrl}
+	mock.recorder = &MockDeviceCodeRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDeviceCodeRepository) EXPECT() *MockDeviceCodeRepositoryMockRecorder {
+	return m.recorder
+}
+
+// DecideDeviceCode mocks base method.
+func (m *MockDeviceCodeRepository) DecideDeviceCode(arg0 context.Context, arg1, arg2, arg3 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DecideDeviceCode", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DecideDeviceCode indicates an expected call of DecideDeviceCode.
+func (mr *MockDeviceCodeRepositoryMockRecorder) DecideDeviceCode(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DecideDeviceCode", reflect.TypeOf((*MockDeviceCodeRepository)(nil).DecideDeviceCode), arg0, arg1, arg2, arg3)
+}
+
+// DeleteDeviceCode mocks base method.
+func (m *MockDeviceCodeRepository) DeleteDeviceCode(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteDeviceCode", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteDeviceCode indicates an expected call of DeleteDeviceCode.
+func (mr *MockDeviceCodeRepositoryMockRecorder) DeleteDeviceCode(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteDeviceCode", reflect.TypeOf((*MockDeviceCodeRepository)(nil).DeleteDeviceCode), arg0, arg1)
+}
+
+// GetDeviceCodeByUserCode mocks base method.
+func (m *MockDeviceCodeRepository) GetDeviceCodeByUserCode(arg0 context.Context, arg1 string) (*models.DeviceCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeviceCodeByUserCode", arg0, arg1)
+	ret0, _ := ret[0].(*models.DeviceCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDeviceCodeByUserCode indicates an expected call of GetDeviceCodeByUserCode.
+func (mr *MockDeviceCodeRepositoryMockRecorder) GetDeviceCodeByUserCode(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeviceCodeByUserCode", reflect.TypeOf((*MockDeviceCodeRepository)(nil).GetDeviceCodeByUserCode), arg0, arg1)
+}
+
+// PollDeviceCode mocks base method.
+func (m *MockDeviceCodeRepository) PollDeviceCode(arg0 context.Context, arg1 string, arg2 time.Time) (*models.DeviceCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PollDeviceCode", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.DeviceCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PollDeviceCode indicates an expected call of PollDeviceCode.
+func (mr *MockDeviceCodeRepositoryMockRecorder) PollDeviceCode(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PollDeviceCode", reflect.TypeOf((*MockDeviceCodeRepository)(nil).PollDeviceCode), arg0, arg1, arg2)
+}
+
+// SaveDeviceCode mocks base method.
+func (m *MockDeviceCodeRepository) SaveDeviceCode(arg0 context.Context, arg1 *models.DeviceCode) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveDeviceCode", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveDeviceCode indicates an expected call of SaveDeviceCode.
+func (mr *MockDeviceCodeRepositoryMockRecorder) SaveDeviceCode(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveDeviceCode", reflect.TypeOf((*MockDeviceCodeRepository)(nil).SaveDeviceCode), arg0, arg1)
+}
+
+// MockFederationRepository is a mock of FederationRepository interface.
+type MockFederationRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockFederationRepositoryMockRecorder
+}
+
+// MockFederationRepositoryMockRecorder is the mock recorder for MockFederationRepository.
+type MockFederationRepositoryMockRecorder struct {
+	mock *MockFederationRepository
+}
+
+// NewMockFederationRepository creates a new mock instance.
+func NewMockFederationRepository(ctrl *gomock.Controller) *MockFederationRepository {
+	mock := &MockFederationRepository{ctrl: ctrl}
+	mock.recorder = &MockFederationRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFederationRepository) EXPECT() *MockFederationRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateFederatedUser mocks base method.
+func (m *MockFederationRepository) CreateFederatedUser(arg0 context.Context, arg1 *models.User, arg2 *models.FederatedIdentity) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateFederatedUser", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateFederatedUser indicates an expected call of CreateFederatedUser.
+func (mr *MockFederationRepositoryMockRecorder) CreateFederatedUser(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateFederatedUser", reflect.TypeOf((*MockFederationRepository)(nil).CreateFederatedUser), arg0, arg1, arg2)
+}
+
+// GetFederatedIdentity mocks base method.
+func (m *MockFederationRepository) GetFederatedIdentity(arg0 context.Context, arg1, arg2, arg3 string) (*models.FederatedIdentity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFederatedIdentity", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*models.FederatedIdentity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFederatedIdentity indicates an expected call of GetFederatedIdentity.
+func (mr *MockFederationRepositoryMockRecorder) GetFederatedIdentity(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFederatedIdentity", reflect.TypeOf((*MockFederationRepository)(nil).GetFederatedIdentity), arg0, arg1, arg2, arg3)
+}
+
+// LinkFederatedIdentity mocks base method.
+func (m *MockFederationRepository) LinkFederatedIdentity(arg0 context.Context, arg1 *models.FederatedIdentity) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LinkFederatedIdentity", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LinkFederatedIdentity indicates an expected call of LinkFederatedIdentity.
+func (mr *MockFederationRepositoryMockRecorder) LinkFederatedIdentity(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LinkFederatedIdentity", reflect.TypeOf((*MockFederationRepository)(nil).LinkFederatedIdentity), arg0, arg1)
+}
+
+// SaveFederatedLogin mocks base method.
+func (m *MockFederationRepository) SaveFederatedLogin(arg0 context.Context, arg1 *models.FederatedLogin) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveFederatedLogin", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveFederatedLogin indicates an expected call of SaveFederatedLogin.
+func (mr *MockFederationRepositoryMockRecorder) SaveFederatedLogin(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveFederatedLogin", reflect.TypeOf((*MockFederationRepository)(nil).SaveFederatedLogin), arg0, arg1)
+}
+
+// TakeFederatedLogin mocks base method.
+func (m *MockFederationRepository) TakeFederatedLogin(arg0 context.Context, arg1 string) (*models.FederatedLogin, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TakeFederatedLogin", arg0, arg1)
+	ret0, _ := ret[0].(*models.FederatedLogin)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TakeFederatedLogin indicates an expected call of TakeFederatedLogin.
+func (mr *MockFederationRepositoryMockRecorder) TakeFederatedLogin(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TakeFederatedLogin", reflect.TypeOf((*MockFederationRepository)(nil).TakeFederatedLogin), arg0, arg1)
+}
+
+// MockAPIKeyRepository is a mock of APIKeyRepository interface.
+type MockAPIKeyRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockAPIKeyRepositoryMockRecorder
+}
+
+// MockAPIKeyRepositoryMockRecorder is the mock recorder for MockAPIKeyRepository.
+type MockAPIKeyRepositoryMockRecorder struct {
+	mock *MockAPIKeyRepository
+}
+
+// NewMockAPIKeyRepository creates a new mock instance.
+func NewMockAPIKeyRepository(ctrl *gomock.Controller) *MockAPIKeyRepository {
+	mock := &MockAPIKeyRepository{ctrl: ctrl}
+	mock.recorder = &MockAPIKeyRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAPIKeyRepository) EXPECT() *MockAPIKeyRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateAPIKey mocks base method.
+func (m *MockAPIKeyRepository) CreateAPIKey(arg0 context.Context, arg1 *models.APIKey) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAPIKey", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateAPIKey indicates an expected call of CreateAPIKey.
+func (mr *MockAPIKeyRepositoryMockRecorder) CreateAPIKey(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAPIKey", reflect.TypeOf((*MockAPIKeyRepository)(nil).CreateAPIKey), arg0, arg1)
+}
+
+// DeleteAPIKey mocks base method.
+func (m *MockAPIKeyRepository) DeleteAPIKey(arg0 context.Context, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAPIKey", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAPIKey indicates an expected call of DeleteAPIKey.
+func (mr *MockAPIKeyRepositoryMockRecorder) DeleteAPIKey(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAPIKey", reflect.TypeOf((*MockAPIKeyRepository)(nil).DeleteAPIKey), arg0, arg1, arg2)
+}
+
+// GetAPIKeyByPrefix mocks base method.
+func (m *MockAPIKeyRepository) GetAPIKeyByPrefix(arg0 context.Context, arg1 string) (*models.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAPIKeyByPrefix", arg0, arg1)
+	ret0, _ := ret[0].(*models.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAPIKeyByPrefix indicates an expected call of GetAPIKeyByPrefix.
+func (mr *MockAPIKeyRepositoryMockRecorder) GetAPIKeyByPrefix(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAPIKeyByPrefix", reflect.TypeOf((*MockAPIKeyRepository)(nil).GetAPIKeyByPrefix), arg0, arg1)
+}
+
+// ListAPIKeys mocks base method.
+func (m *MockAPIKeyRepository) ListAPIKeys(arg0 context.Context, arg1 string) ([]models.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAPIKeys", arg0, arg1)
+	ret0, _ := ret[0].([]models.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAPIKeys indicates an expected call of ListAPIKeys.
+func (mr *MockAPIKeyRepositoryMockRecorder) ListAPIKeys(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAPIKeys", reflect.TypeOf((*MockAPIKeyRepository)(nil).ListAPIKeys), arg0, arg1)
+}
+
+// TouchAPIKey mocks base method.
+func (m *MockAPIKeyRepository) TouchAPIKey(arg0 context.Context, arg1, arg2 string, arg3 time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TouchAPIKey", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TouchAPIKey indicates an expected call of TouchAPIKey.
+func (mr *MockAPIKeyRepositoryMockRecorder) TouchAPIKey(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TouchAPIKey", reflect.TypeOf((*MockAPIKeyRepository)(nil).TouchAPIKey), arg0, arg1, arg2, arg3)
+}
+
+// MockRoleRepository is a mock of RoleRepository interface.
+type MockRoleRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockRoleRepositoryMockRecorder
+}
+
+// MockRoleRepositoryMockRecorder is the mock recorder for MockRoleRepository.
+type MockRoleRepositoryMockRecorder struct {
+	mock *MockRoleRepository
+}
+
+// NewMockRoleRepository creates a new mock instance.
+func NewMockRoleRepository(ctrl *gomock.Controller) *MockRoleRepository {
+	mock := &MockRoleRepository{ctrl: ctrl}
+	mock.recorder = &MockRoleRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRoleRepository) EXPECT() *MockRoleRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateRole mocks base method.
+func (m *MockRoleRepository) CreateRole(arg0 context.Context, arg1 *models.Role) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateRole", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateRole indicates an expected call of CreateRole.
+func (mr *MockRoleRepositoryMockRecorder) CreateRole(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRole", reflect.TypeOf((*MockRoleRepository)(nil).CreateRole), arg0, arg1)
+}
+
+// DeleteRole mocks base method.
+func (m *MockRoleRepository) DeleteRole(arg0 context.Context, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteRole", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteRole indicates an expected call of DeleteRole.
+func (mr *MockRoleRepositoryMockRecorder) DeleteRole(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRole", reflect.TypeOf((*MockRoleRepository)(nil).DeleteRole), arg0, arg1, arg2)
+}
+
+// GetRole mocks base method.
+func (m *MockRoleRepository) GetRole(arg0 context.Context, arg1, arg2 string) (*models.Role, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRole", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.Role)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRole indicates an expected call of GetRole.
+func (mr *MockRoleRepositoryMockRecorder) GetRole(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRole", reflect.TypeOf((*MockRoleRepository)(nil).GetRole), arg0, arg1, arg2)
+}
+
+// GetUserRoles mocks base method.
+func (m *MockRoleRepository) GetUserRoles(arg0 context.Context, arg1 string) ([]models.Role, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserRoles", arg0, arg1)
+	ret0, _ := ret[0].([]models.Role)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserRoles indicates an expected call of GetUserRoles.
+func (mr *MockRoleRepositoryMockRecorder) GetUserRoles(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserRoles", reflect.TypeOf((*MockRoleRepository)(nil).GetUserRoles), arg0, arg1)
+}
+
+// ListPermissions mocks base method.
+func (m *MockRoleRepository) ListPermissions(arg0 context.Context) ([]models.Permission, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPermissions", arg0)
+	ret0, _ := ret[0].([]models.Permission)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPermissions indicates an expected call of ListPermissions.
+func (mr *MockRoleRepositoryMockRecorder) ListPermissions(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPermissions", reflect.TypeOf((*MockRoleRepository)(nil).ListPermissions), arg0)
+}
+
+// ListRoles mocks base method.
+func (m *MockRoleRepository) ListRoles(arg0 context.Context, arg1 string) ([]models.Role, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRoles", arg0, arg1)
+	ret0, _ := ret[0].([]models.Role)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListRoles indicates an expected call of ListRoles.
+func (mr *MockRoleRepositoryMockRecorder) ListRoles(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRoles", reflect.TypeOf((*MockRoleRepository)(nil).ListRoles), arg0, arg1)
+}
+
+// SetUserRoles mocks base method.
+func (m *MockRoleRepository) SetUserRoles(arg0 context.Context, arg1, arg2 string, arg3 []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetUserRoles", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetUserRoles indicates an expected call of SetUserRoles.
+func (mr *MockRoleRepositoryMockRecorder) SetUserRoles(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetUserRoles", reflect.TypeOf((*MockRoleRepository)(nil).SetUserRoles), arg0, arg1, arg2, arg3)
+}
+
+// UpdateRole mocks base method.
+func (m *MockRoleRepository) UpdateRole(arg0 context.Context, arg1 *models.Role) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateRole", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateRole indicates an expected call of UpdateRole.
+func (mr *MockRoleRepositoryMockRecorder) UpdateRole(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRole", reflect.TypeOf((*MockRoleRepository)(nil).UpdateRole), arg0, arg1)
+}
+
+// MockTokenCutoffRepository is a mock of TokenCutoffRepository interface.
+type MockTokenCutoffRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockTokenCutoffRepositoryMockRecorder
+}
+
+// MockTokenCutoffRepositoryMockRecorder is the mock recorder for MockTokenCutoffRepository.
+type MockTokenCutoffRepositoryMockRecorder struct {
+	mock *MockTokenCutoffRepository
+}
+
+// NewMockTokenCutoffRepository creates a new mock instance.
+func NewMockTokenCutoffRepository(ctrl *gomock.Controller) *MockTokenCutoffRepository {
+	mock := &MockTokenCutoffRepository{ctrl: ctrl}
+	mock.recorder = &MockTokenCutoffRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTokenCutoffRepository) EXPECT() *MockTokenCutoffRepositoryMockRecorder {
+	return m.recorder
+}
+
+// GetTokenCutoff mocks base method.
+func (m *MockTokenCutoffRepository) GetTokenCutoff(arg0 context.Context, arg1 string) (time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTokenCutoff", arg0, arg1)
+	ret0, _ := ret[0].(time.Time)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTokenCutoff indicates an expected call of GetTokenCutoff.
+func (mr *MockTokenCutoffRepositoryMockRecorder) GetTokenCutoff(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTokenCutoff", reflect.TypeOf((*MockTokenCutoffRepository)(nil).GetTokenCutoff), arg0, arg1)
+}
+
+// SetRoleTokenCutoff mocks base method.
+func (m *MockTokenCutoffRepository) SetRoleTokenCutoff(arg0 context.Context, arg1 string, arg2 time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetRoleTokenCutoff", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetRoleTokenCutoff indicates an expected call of SetRoleTokenCutoff.
+func (mr *MockTokenCutoffRepositoryMockRecorder) SetRoleTokenCutoff(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetRoleTokenCutoff", reflect.TypeOf((*MockTokenCutoffRepository)(nil).SetRoleTokenCutoff), arg0, arg1, arg2)
+}
+
+// SetTokenCutoff mocks base method.
+func (m *MockTokenCutoffRepository) SetTokenCutoff(arg0 context.Context, arg1 string, arg2 time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetTokenCutoff", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetTokenCutoff indicates an expected call of SetTokenCutoff.
+func (mr *MockTokenCutoffRepositoryMockRecorder) SetTokenCutoff(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTokenCutoff", reflect.TypeOf((*MockTokenCutoffRepository)(nil).SetTokenCutoff), arg0, arg1, arg2)
+}
+
+// MockRevokedTokenRepository is a mock of RevokedTokenRepository interface.
+type MockRevokedTokenRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockRevokedTokenRepositoryMockRecorder
+}
+
+// MockRevokedTokenRepositoryMockRecorder is the mock recorder for MockRevokedTokenRepository.
+type MockRevokedTokenRepositoryMockRecorder struct {
+	mock *MockRevokedTokenRepository
+}
+
+// NewMockRevokedTokenRepository creates a new mock instance.
+func NewMockRevokedTokenRepository(ctrl *gomock.Controller) *MockRevokedTokenRepository {
+	mock := &MockRevokedTokenRepository{ctrl: ctrl}
+	mock.recorder = &MockRevokedTokenRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRevokedTokenRepository) EXPECT() *MockRevokedTokenRepositoryMockRecorder {
+	return m.recorder
+}
+
+// IsAccessTokenRevoked mocks base method.
+func (m *MockRevokedTokenRepository) IsAccessTokenRevoked(arg0 context.Context, arg1 string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsAccessTokenRevoked", arg0, arg1)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsAccessTokenRevoked indicates an expected call of IsAccessTokenRevoked.
+func (mr *MockRevokedTokenRepositoryMockRecorder) IsAccessTokenRevoked(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsAccessTokenRevoked", reflect.TypeOf((*MockRevokedTokenRepository)(nil).IsAccessTokenRevoked), arg0, arg1)
+}
+
+// RevokeAccessToken mocks base method.
+func (m *MockRevokedTokenRepository) RevokeAccessToken(arg0 context.Context, arg1 string, arg2 time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeAccessToken", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeAccessToken indicates an expected call of RevokeAccessToken.
+func (mr *MockRevokedTokenRepositoryMockRecorder) RevokeAccessToken(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAccessToken", reflect.TypeOf((*MockRevokedTokenRepository)(nil).RevokeAccessToken), arg0, arg1, arg2)
+}
+
+// MockOrganizationRepository is a mock of OrganizationRepository interface.
+type MockOrganizationRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockOrganizationRepositoryMockRecorder
+}
+
+// MockOrganizationRepositoryMockRecorder is the mock recorder for MockOrganizationRepository.
+type MockOrganizationRepositoryMockRecorder struct {
+	mock *MockOrganizationRepository
+}
+
+// NewMockOrganizationRepository creates a new mock instance.
+func NewMockOrganizationRepository(ctrl *gomock.Controller) *MockOrganizationRepository {
+	mock := &MockOrganizationRepository{ctrl: ctrl}
+	mock.recorder = &MockOrganizationRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOrganizationRepository) EXPECT() *MockOrganizationRepositoryMockRecorder {
+	return m.recorder
+}
+
+// AddOrganizationMember mocks base method.
+func (m *MockOrganizationRepository) AddOrganizationMember(arg0 context.Context, arg1 *models.OrganizationMember) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddOrganizationMember", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddOrganizationMember indicates an expected call of AddOrganizationMember.
+func (mr *MockOrganizationRepositoryMockRecorder) AddOrganizationMember(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddOrganizationMember", reflect.TypeOf((*MockOrganizationRepository)(nil).AddOrganizationMember), arg0, arg1)
+}
+
+// AddTeamMember mocks base method.
+func (m *MockOrganizationRepository) AddTeamMember(arg0 context.Context, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddTeamMember", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddTeamMember indicates an expected call of AddTeamMember.
+func (mr *MockOrganizationRepositoryMockRecorder) AddTeamMember(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddTeamMember", reflect.TypeOf((*MockOrganizationRepository)(nil).AddTeamMember), arg0, arg1, arg2)
+}
+
+// CountOrganizationOwners mocks base method.
+func (m *MockOrganizationRepository) CountOrganizationOwners(arg0 context.Context, arg1 string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountOrganizationOwners", arg0, arg1)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountOrganizationOwners indicates an expected call of CountOrganizationOwners.
+func (mr *MockOrganizationRepositoryMockRecorder) CountOrganizationOwners(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountOrganizationOwners", reflect.TypeOf((*MockOrganizationRepository)(nil).CountOrganizationOwners), arg0, arg1)
+}
+
+// CreateOrganization mocks base method.
+func (m *MockOrganizationRepository) CreateOrganization(arg0 context.Context, arg1 *models.Organization, arg2 *models.OrganizationMember) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOrganization", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateOrganization indicates an expected call of CreateOrganization.
+func (mr *MockOrganizationRepositoryMockRecorder) CreateOrganization(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOrganization", reflect.TypeOf((*MockOrganizationRepository)(nil).CreateOrganization), arg0, arg1, arg2)
+}
+
+// CreateTeam mocks base method.
+func (m *MockOrganizationRepository) CreateTeam(arg0 context.Context, arg1 *models.Team) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTeam", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateTeam indicates an expected call of CreateTeam.
+func (mr *MockOrganizationRepositoryMockRecorder) CreateTeam(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTeam", reflect.TypeOf((*MockOrganizationRepository)(nil).CreateTeam), arg0, arg1)
+}
+
+// DeleteOrganizationInvitation mocks base method.
+func (m *MockOrganizationRepository) DeleteOrganizationInvitation(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteOrganizationInvitation", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteOrganizationInvitation indicates an expected call of DeleteOrganizationInvitation.
+func (mr *MockOrganizationRepositoryMockRecorder) DeleteOrganizationInvitation(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteOrganizationInvitation", reflect.TypeOf((*MockOrganizationRepository)(nil).DeleteOrganizationInvitation), arg0, arg1)
+}
+
+// GetOrganization mocks base method.
+func (m *MockOrganizationRepository) GetOrganization(arg0 context.Context, arg1, arg2 string) (*models.Organization, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrganization", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.Organization)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrganization indicates an expected call of GetOrganization.
+func (mr *MockOrganizationRepositoryMockRecorder) GetOrganization(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrganization", reflect.TypeOf((*MockOrganizationRepository)(nil).GetOrganization), arg0, arg1, arg2)
+}
+
+// GetOrganizationInvitation mocks base method.
+func (m *MockOrganizationRepository) GetOrganizationInvitation(arg0 context.Context, arg1 string) (*models.OrganizationInvitation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrganizationInvitation", arg0, arg1)
+	ret0, _ := ret[0].(*models.OrganizationInvitation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrganizationInvitation indicates an expected call of GetOrganizationInvitation.
+func (mr *MockOrganizationRepositoryMockRecorder) GetOrganizationInvitation(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrganizationInvitation", reflect.TypeOf((*MockOrganizationRepository)(nil).GetOrganizationInvitation), arg0, arg1)
+}
+
+// GetOrganizationMember mocks base method.
+func (m *MockOrganizationRepository) GetOrganizationMember(arg0 context.Context, arg1, arg2 string) (*models.OrganizationMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrganizationMember", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.OrganizationMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrganizationMember indicates an expected call of GetOrganizationMember.
+func (mr *MockOrganizationRepositoryMockRecorder) GetOrganizationMember(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrganizationMember", reflect.TypeOf((*MockOrganizationRepository)(nil).GetOrganizationMember), arg0, arg1, arg2)
+}
+
+// GetTeam mocks base method.
+func (m *MockOrganizationRepository) GetTeam(arg0 context.Context, arg1, arg2 string) (*models.Team, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTeam", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.Team)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTeam indicates an expected call of GetTeam.
+func (mr *MockOrganizationRepositoryMockRecorder) GetTeam(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTeam", reflect.TypeOf((*MockOrganizationRepository)(nil).GetTeam), arg0, arg1, arg2)
+}
+
+// ListOrganizationMembers mocks base method.
+func (m *MockOrganizationRepository) ListOrganizationMembers(arg0 context.Context, arg1 string) ([]models.OrganizationMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListOrganizationMembers", arg0, arg1)
+	ret0, _ := ret[0].([]models.OrganizationMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListOrganizationMembers indicates an expected call of ListOrganizationMembers.
+func (mr *MockOrganizationRepositoryMockRecorder) ListOrganizationMembers(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListOrganizationMembers", reflect.TypeOf((*MockOrganizationRepository)(nil).ListOrganizationMembers), arg0, arg1)
+}
+
+// ListTeams mocks base method.
+func (m *MockOrganizationRepository) ListTeams(arg0 context.Context, arg1 string) ([]models.Team, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTeams", arg0, arg1)
+	ret0, _ := ret[0].([]models.Team)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTeams indicates an expected call of ListTeams.
+func (mr *MockOrganizationRepositoryMockRecorder) ListTeams(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTeams", reflect.TypeOf((*MockOrganizationRepository)(nil).ListTeams), arg0, arg1)
+}
+
+// ListUserOrganizations mocks base method.
+func (m *MockOrganizationRepository) ListUserOrganizations(arg0 context.Context, arg1, arg2 string) ([]models.Organization, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUserOrganizations", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]models.Organization)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUserOrganizations indicates an expected call of ListUserOrganizations.
+func (mr *MockOrganizationRepositoryMockRecorder) ListUserOrganizations(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUserOrganizations", reflect.TypeOf((*MockOrganizationRepository)(nil).ListUserOrganizations), arg0, arg1, arg2)
+}
+
+// RemoveOrganizationMember mocks base method.
+func (m *MockOrganizationRepository) RemoveOrganizationMember(arg0 context.Context, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveOrganizationMember", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveOrganizationMember indicates an expected call of RemoveOrganizationMember.
+func (mr *MockOrganizationRepositoryMockRecorder) RemoveOrganizationMember(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveOrganizationMember", reflect.TypeOf((*MockOrganizationRepository)(nil).RemoveOrganizationMember), arg0, arg1, arg2)
+}
+
+// RemoveTeamMember mocks base method.
+func (m *MockOrganizationRepository) RemoveTeamMember(arg0 context.Context, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveTeamMember", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveTeamMember indicates an expected call of RemoveTeamMember.
+func (mr *MockOrganizationRepositoryMockRecorder) RemoveTeamMember(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveTeamMember", reflect.TypeOf((*MockOrganizationRepository)(nil).RemoveTeamMember), arg0, arg1, arg2)
+}
+
+// SaveOrganizationInvitation mocks base method.
+func (m *MockOrganizationRepository) SaveOrganizationInvitation(arg0 context.Context, arg1 *models.OrganizationInvitation) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveOrganizationInvitation", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveOrganizationInvitation indicates an expected call of SaveOrganizationInvitation.
+func (mr *MockOrganizationRepositoryMockRecorder) SaveOrganizationInvitation(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveOrganizationInvitation", reflect.TypeOf((*MockOrganizationRepository)(nil).SaveOrganizationInvitation), arg0, arg1)
+}
+
+// UpdateOrganizationMemberRole mocks base method.
+func (m *MockOrganizationRepository) UpdateOrganizationMemberRole(arg0 context.Context, arg1, arg2, arg3 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateOrganizationMemberRole", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateOrganizationMemberRole indicates an expected call of UpdateOrganizationMemberRole.
+func (mr *MockOrganizationRepositoryMockRecorder) UpdateOrganizationMemberRole(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateOrganizationMemberRole", reflect.TypeOf((*MockOrganizationRepository)(nil).UpdateOrganizationMemberRole), arg0, arg1, arg2, arg3)
+}
+
+// MockEmailChangeRepository is a mock of EmailChangeRepository interface.
+type MockEmailChangeRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockEmailChangeRepositoryMockRecorder
+}
+
+// MockEmailChangeRepositoryMockRecorder is the mock recorder for MockEmailChangeRepository.
+type MockEmailChangeRepositoryMockRecorder struct {
+	mock *MockEmailChangeRepository
+}
+
+// NewMockEmailChangeRepository creates a new mock instance.
+func NewMockEmailChangeRepository(ctrl *gomock.Controller) *MockEmailChangeRepository {
+	mock := &MockEmailChangeRepository{ctrl: ctrl}
+	mock.recorder = &MockEmailChangeRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockEmailChangeRepository) EXPECT() *MockEmailChangeRepositoryMockRecorder {
+	return m.recorder
+}
+
+// ConfirmEmailChange mocks base method.
+func (m *MockEmailChangeRepository) ConfirmEmailChange(arg0 context.Context, arg1 string, arg2 bool) (*models.EmailChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConfirmEmailChange", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.EmailChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConfirmEmailChange indicates an expected call of ConfirmEmailChange.
+func (mr *MockEmailChangeRepositoryMockRecorder) ConfirmEmailChange(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfirmEmailChange", reflect.TypeOf((*MockEmailChangeRepository)(nil).ConfirmEmailChange), arg0, arg1, arg2)
+}
+
+// DeleteEmailChange mocks base method.
+func (m *MockEmailChangeRepository) DeleteEmailChange(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteEmailChange", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteEmailChange indicates an expected call of DeleteEmailChange.
+func (mr *MockEmailChangeRepositoryMockRecorder) DeleteEmailChange(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteEmailChange", reflect.TypeOf((*MockEmailChangeRepository)(nil).DeleteEmailChange), arg0, arg1)
+}
+
+// GetEmailChangeByToken mocks base method.
+func (m *MockEmailChangeRepository) GetEmailChangeByToken(arg0 context.Context, arg1 string) (*models.EmailChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEmailChangeByToken", arg0, arg1)
+	ret0, _ := ret[0].(*models.EmailChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEmailChangeByToken indicates an expected call of GetEmailChangeByToken.
+func (mr *MockEmailChangeRepositoryMockRecorder) GetEmailChangeByToken(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEmailChangeByToken", reflect.TypeOf((*MockEmailChangeRepository)(nil).GetEmailChangeByToken), arg0, arg1)
+}
+
+// SaveEmailChange mocks base method.
+func (m *MockEmailChangeRepository) SaveEmailChange(arg0 context.Context, arg1 *models.EmailChange) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveEmailChange", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveEmailChange indicates an expected call of SaveEmailChange.
+func (mr *MockEmailChangeRepositoryMockRecorder) SaveEmailChange(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveEmailChange", reflect.TypeOf((*MockEmailChangeRepository)(nil).SaveEmailChange), arg0, arg1)
+}
+
+// MockSecurityAlertRepository is a mock of SecurityAlertRepository interface.
+type MockSecurityAlertRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockSecurityAlertRepositoryMockRecorder
+}
+
+// MockSecurityAlertRepositoryMockRecorder is the mock recorder for MockSecurityAlertRepository.
+type MockSecurityAlertRepositoryMockRecorder struct {
+	mock *MockSecurityAlertRepository
+}
+
+// NewMockSecurityAlertRepository creates a new mock instance.
+func NewMockSecurityAlertRepository(ctrl *gomock.Controller) *MockSecurityAlertRepository {
+	mock := &MockSecurityAlertRepository{ctrl: ctrl}
+	mock.recorder = &MockSecurityAlertRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSecurityAlertRepository) EXPECT() *MockSecurityAlertRepositoryMockRecorder {
+	return m.recorder
+}
+
+// ListSecurityAlerts mocks base method.
+func (m *MockSecurityAlertRepository) ListSecurityAlerts(arg0 context.Context, arg1 string) ([]models.SecurityAlert, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSecurityAlerts", arg0, arg1)
+	ret0, _ := ret[0].([]models.SecurityAlert)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSecurityAlerts indicates an expected call of ListSecurityAlerts.
+func (mr *MockSecurityAlertRepositoryMockRecorder) ListSecurityAlerts(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSecurityAlerts", reflect.TypeOf((*MockSecurityAlertRepository)(nil).ListSecurityAlerts), arg0, arg1)
+}
+
+// SaveSecurityAlert mocks base method.
+func (m *MockSecurityAlertRepository) SaveSecurityAlert(arg0 context.Context, arg1 *models.SecurityAlert) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveSecurityAlert", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveSecurityAlert indicates an expected call of SaveSecurityAlert.
+func (mr *MockSecurityAlertRepositoryMockRecorder) SaveSecurityAlert(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveSecurityAlert", reflect.TypeOf((*MockSecurityAlertRepository)(nil).SaveSecurityAlert), arg0, arg1)
+}
+
+// MockAccountRepository is a mock of AccountRepository interface.
+type MockAccountRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockAccountRepositoryMockRecorder
+}
+
+// MockAccountRepositoryMockRecorder is the mock recorder for MockAccountRepository.
+type MockAccountRepositoryMockRecorder struct {
+	mock *MockAccountRepository
+}
+
+// NewMockAccountRepository creates a new mock instance.
+func NewMockAccountRepository(ctrl *gomock.Controller) *MockAccountRepository {
+	mock := &MockAccountRepository{ctrl: ctrl}
+	mock.recorder = &MockAccountRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAccountRepository) EXPECT() *MockAccountRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CancelUserDeletion mocks base method.
+func (m *MockAccountRepository) CancelUserDeletion(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelUserDeletion", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CancelUserDeletion indicates an expected call of CancelUserDeletion.
+func (mr *MockAccountRepositoryMockRecorder) CancelUserDeletion(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelUserDeletion", reflect.TypeOf((*MockAccountRepository)(nil).CancelUserDeletion), arg0, arg1)
+}
+
+// ListUsersDueForDeletion mocks base method.
+func (m *MockAccountRepository) ListUsersDueForDeletion(arg0 context.Context, arg1 time.Time, arg2 int) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUsersDueForDeletion", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUsersDueForDeletion indicates an expected call of ListUsersDueForDeletion.
+func (mr *MockAccountRepositoryMockRecorder) ListUsersDueForDeletion(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUsersDueForDeletion", reflect.TypeOf((*MockAccountRepository)(nil).ListUsersDueForDeletion), arg0, arg1, arg2)
+}
+
+// PurgeUser mocks base method.
+func (m *MockAccountRepository) PurgeUser(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeUser", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PurgeUser indicates an expected call of PurgeUser.
+func (mr *MockAccountRepositoryMockRecorder) PurgeUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeUser", reflect.TypeOf((*MockAccountRepository)(nil).PurgeUser), arg0, arg1)
+}
+
+// ScheduleUserDeletion mocks base method.
+func (m *MockAccountRepository) ScheduleUserDeletion(arg0 context.Context, arg1 string, arg2 time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ScheduleUserDeletion", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ScheduleUserDeletion indicates an expected call of ScheduleUserDeletion.
+func (mr *MockAccountRepositoryMockRecorder) ScheduleUserDeletion(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScheduleUserDeletion", reflect.TypeOf((*MockAccountRepository)(nil).ScheduleUserDeletion), arg0, arg1, arg2)
+}
+
+// MockCleanupRepository is a mock of CleanupRepository interface.
+type MockCleanupRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockCleanupRepositoryMockRecorder
+}
+
+// MockCleanupRepositoryMockRecorder is the mock recorder for MockCleanupRepository.
+type MockCleanupRepositoryMockRecorder struct {
+	mock *MockCleanupRepository
+}
+
+// NewMockCleanupRepository creates a new mock instance.
+func NewMockCleanupRepository(ctrl *gomock.Controller) *MockCleanupRepository {
+	mock := &MockCleanupRepository{ctrl: ctrl}
+	mock.recorder = &MockCleanupRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCleanupRepository) EXPECT() *MockCleanupRepositoryMockRecorder {
+	return m.recorder
+}
+
+// DeleteExpiredAuthorizationCodes mocks base method.
+func (m *MockCleanupRepository) DeleteExpiredAuthorizationCodes(arg0 context.Context, arg1 time.Time, arg2 int) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpiredAuthorizationCodes", arg0, arg1, arg2)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteExpiredAuthorizationCodes indicates an expected call of DeleteExpiredAuthorizationCodes.
+func (mr *MockCleanupRepositoryMockRecorder) DeleteExpiredAuthorizationCodes(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpiredAuthorizationCodes", reflect.TypeOf((*MockCleanupRepository)(nil).DeleteExpiredAuthorizationCodes), arg0, arg1, arg2)
+}
+
+// DeleteExpiredDeviceCodes mocks base method.
+func (m *MockCleanupRepository) DeleteExpiredDeviceCodes(arg0 context.Context, arg1 time.Time, arg2 int) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpiredDeviceCodes", arg0, arg1, arg2)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteExpiredDeviceCodes indicates an expected call of DeleteExpiredDeviceCodes.
+func (mr *MockCleanupRepositoryMockRecorder) DeleteExpiredDeviceCodes(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpiredDeviceCodes", reflect.TypeOf((*MockCleanupRepository)(nil).DeleteExpiredDeviceCodes), arg0, arg1, arg2)
+}
+
+// DeleteExpiredEmailChanges mocks base method.
+func (m *MockCleanupRepository) DeleteExpiredEmailChanges(arg0 context.Context, arg1 time.Time, arg2 int) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpiredEmailChanges", arg0, arg1, arg2)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteExpiredEmailChanges indicates an expected call of DeleteExpiredEmailChanges.
+func (mr *MockCleanupRepositoryMockRecorder) DeleteExpiredEmailChanges(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpiredEmailChanges", reflect.TypeOf((*MockCleanupRepository)(nil).DeleteExpiredEmailChanges), arg0, arg1, arg2)
+}
+
+// DeleteExpiredRefreshTokens mocks base method.
+func (m *MockCleanupRepository) DeleteExpiredRefreshTokens(arg0 context.Context, arg1 time.Time, arg2 int) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpiredRefreshTokens", arg0, arg1, arg2)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteExpiredRefreshTokens indicates an expected call of DeleteExpiredRefreshTokens.
+func (mr *MockCleanupRepositoryMockRecorder) DeleteExpiredRefreshTokens(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpiredRefreshTokens", reflect.TypeOf((*MockCleanupRepository)(nil).DeleteExpiredRefreshTokens), arg0, arg1, arg2)
+}
+
+// DeleteExpiredRevokedTokens mocks base method.
+func (m *MockCleanupRepository) DeleteExpiredRevokedTokens(arg0 context.Context, arg1 time.Time, arg2 int) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpiredRevokedTokens", arg0, arg1, arg2)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteExpiredRevokedTokens indicates an expected call of DeleteExpiredRevokedTokens.
+func (mr *MockCleanupRepositoryMockRecorder) DeleteExpiredRevokedTokens(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpiredRevokedTokens", reflect.TypeOf((*MockCleanupRepository)(nil).DeleteExpiredRevokedTokens), arg0, arg1, arg2)
+}
+
+// DeleteExpiredWebAuthnSessions mocks base method.
+func (m *MockCleanupRepository) DeleteExpiredWebAuthnSessions(arg0 context.Context, arg1 time.Time, arg2 int) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpiredWebAuthnSessions", arg0, arg1, arg2)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteExpiredWebAuthnSessions indicates an expected call of DeleteExpiredWebAuthnSessions.
+func (mr *MockCleanupRepositoryMockRecorder) DeleteExpiredWebAuthnSessions(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpiredWebAuthnSessions", reflect.TypeOf((*MockCleanupRepository)(nil).DeleteExpiredWebAuthnSessions), arg0, arg1, arg2)
+}
+
+// DeleteStaleLoginCodes mocks base method.
+func (m *MockCleanupRepository) DeleteStaleLoginCodes(arg0 context.Context, arg1 time.Time, arg2 int) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteStaleLoginCodes", arg0, arg1, arg2)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteStaleLoginCodes indicates an expected call of DeleteStaleLoginCodes.
+func (mr *MockCleanupRepositoryMockRecorder) DeleteStaleLoginCodes(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveRefreshToken", reflect.TypeOf((*MockRepository)(nil).SaveRefreshToken), arg0, arg1, arg2, arg3)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteStaleLoginCodes", reflect.TypeOf((*MockCleanupRepository)(nil).DeleteStaleLoginCodes), arg0, arg1, arg2)
 }
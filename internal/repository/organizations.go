@@ -0,0 +1,354 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/auth-service/internal/models"
+	"github.com/lib/pq"
+)
+
+// memberColumns selects a member with the teams of the organization they
+// are in, the query has to join users as u and group by m.org_id, m.user_id
+// and u.email.
+const memberColumns = `m.org_id, m.user_id, COALESCE(u.email, ''), m.role,
+	COALESCE(array_agg(tm.team_id::text ORDER BY tm.team_id) FILTER (WHERE tm.team_id IS NOT NULL), '{}'),
+	m.created_at`
+
+const memberJoins = `FROM organization_members m
+	LEFT JOIN users u ON u.id = m.user_id
+	LEFT JOIN team_members tm ON tm.user_id = m.user_id
+		AND tm.team_id IN (SELECT id FROM teams WHERE org_id = m.org_id)`
+
+// CreateOrganization saves an organization together with its first owner.
+func (p *Postgres) CreateOrganization(ctx context.Context, org *models.Organization, owner *models.OrganizationMember) error {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRowContext(ctx,
+		`INSERT INTO organizations (tenant_id, name)
+		VALUES ($1, $2)
+		RETURNING id, created_at`,
+		org.TenantID, org.Name,
+	).Scan(&org.ID, &org.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create organization: %w", err)
+	}
+
+	owner.OrgID = org.ID
+	if err := addOrganizationMember(ctx, tx, owner); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit organization: %w", err)
+	}
+	return nil
+}
+
+func (p *Postgres) GetOrganization(ctx context.Context, tenantID, orgID string) (*models.Organization, error) {
+	var org models.Organization
+	err := p.db.QueryRowContext(ctx,
+		`SELECT id, tenant_id, name, created_at
+		FROM organizations
+		WHERE tenant_id = $1 AND id::text = $2`,
+		tenantID, orgID).Scan(&org.ID, &org.TenantID, &org.Name, &org.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get organization: %w", err)
+	}
+	return &org, nil
+}
+
+// ListUserOrganizations returns the organizations of the user in the tenant
+// with the role of the user in each.
+func (p *Postgres) ListUserOrganizations(ctx context.Context, tenantID, userID string) ([]models.Organization, error) {
+	rows, err := p.db.QueryContext(ctx,
+		`SELECT o.id, o.tenant_id, o.name, m.role, o.created_at
+		FROM organizations o
+		JOIN organization_members m ON m.org_id = o.id
+		WHERE o.tenant_id = $1 AND m.user_id = $2
+		ORDER BY o.name`,
+		tenantID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list organizations: %w", err)
+	}
+	defer rows.Close()
+
+	var orgs []models.Organization
+	for rows.Next() {
+		var org models.Organization
+		if err := rows.Scan(&org.ID, &org.TenantID, &org.Name, &org.Role, &org.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan organization: %w", err)
+		}
+		orgs = append(orgs, org)
+	}
+	return orgs, rows.Err()
+}
+
+func (p *Postgres) GetOrganizationMember(ctx context.Context, orgID, userID string) (*models.OrganizationMember, error) {
+	member, err := scanOrganizationMember(p.db.QueryRowContext(ctx,
+		`SELECT `+memberColumns+` `+memberJoins+`
+		WHERE m.org_id::text = $1 AND m.user_id = $2
+		GROUP BY m.org_id, m.user_id, u.email`,
+		orgID, userID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get organization member: %w", err)
+	}
+	return member, nil
+}
+
+func (p *Postgres) ListOrganizationMembers(ctx context.Context, orgID string) ([]models.OrganizationMember, error) {
+	rows, err := p.db.QueryContext(ctx,
+		`SELECT `+memberColumns+` `+memberJoins+`
+		WHERE m.org_id::text = $1
+		GROUP BY m.org_id, m.user_id, u.email
+		ORDER BY m.created_at`,
+		orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list organization members: %w", err)
+	}
+	defer rows.Close()
+
+	var members []models.OrganizationMember
+	for rows.Next() {
+		member, err := scanOrganizationMember(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan organization member: %w", err)
+		}
+		members = append(members, *member)
+	}
+	return members, rows.Err()
+}
+
+// AddOrganizationMember returns ErrDuplicate when the user is a member
+// already.
+func (p *Postgres) AddOrganizationMember(ctx context.Context, member *models.OrganizationMember) error {
+	return addOrganizationMember(ctx, p.db, member)
+}
+
+func (p *Postgres) UpdateOrganizationMemberRole(ctx context.Context, orgID, userID, role string) error {
+	result, err := p.db.ExecContext(ctx,
+		`UPDATE organization_members SET role = $3 WHERE org_id::text = $1 AND user_id = $2`,
+		orgID, userID, role)
+	if err != nil {
+		return fmt.Errorf("failed to update organization member: %w", err)
+	}
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// RemoveOrganizationMember removes the user from the organization and from
+// its teams.
+func (p *Postgres) RemoveOrganizationMember(ctx context.Context, orgID, userID string) error {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx,
+		`DELETE FROM organization_members WHERE org_id::text = $1 AND user_id = $2`,
+		orgID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to remove organization member: %w", err)
+	}
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return ErrNotFound
+	}
+
+	_, err = tx.ExecContext(ctx,
+		`DELETE FROM team_members
+		WHERE user_id = $2 AND team_id IN (SELECT id FROM teams WHERE org_id::text = $1)`,
+		orgID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to remove team memberships: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit member removal: %w", err)
+	}
+	return nil
+}
+
+func (p *Postgres) CountOrganizationOwners(ctx context.Context, orgID string) (int, error) {
+	var count int
+	err := p.db.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM organization_members WHERE org_id::text = $1 AND role = 'owner'`,
+		orgID).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count organization owners: %w", err)
+	}
+	return count, nil
+}
+
+func (p *Postgres) SaveOrganizationInvitation(ctx context.Context, invitation *models.OrganizationInvitation) error {
+	err := p.db.QueryRowContext(ctx,
+		`INSERT INTO organization_invitations (org_id, email, role, token_hash, invited_by, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at`,
+		invitation.OrgID,
+		strings.ToLower(invitation.Email),
+		invitation.Role,
+		invitation.TokenHash,
+		invitation.InvitedBy,
+		invitation.ExpiresAt,
+	).Scan(&invitation.ID, &invitation.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save organization invitation: %w", err)
+	}
+	return nil
+}
+
+func (p *Postgres) GetOrganizationInvitation(ctx context.Context, tokenHash string) (*models.OrganizationInvitation, error) {
+	var invitation models.OrganizationInvitation
+	err := p.db.QueryRowContext(ctx,
+		`SELECT id, org_id, email, role, token_hash, invited_by, expires_at, created_at
+		FROM organization_invitations
+		WHERE token_hash = $1`,
+		tokenHash).Scan(
+		&invitation.ID,
+		&invitation.OrgID,
+		&invitation.Email,
+		&invitation.Role,
+		&invitation.TokenHash,
+		&invitation.InvitedBy,
+		&invitation.ExpiresAt,
+		&invitation.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get organization invitation: %w", err)
+	}
+	return &invitation, nil
+}
+
+func (p *Postgres) DeleteOrganizationInvitation(ctx context.Context, id string) error {
+	_, err := p.db.ExecContext(ctx, `DELETE FROM organization_invitations WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete organization invitation: %w", err)
+	}
+	return nil
+}
+
+// CreateTeam returns ErrDuplicate when the organization has a team with the
+// same name.
+func (p *Postgres) CreateTeam(ctx context.Context, team *models.Team) error {
+	err := p.db.QueryRowContext(ctx,
+		`INSERT INTO teams (org_id, name)
+		VALUES ($1, $2)
+		RETURNING id, created_at`,
+		team.OrgID, team.Name,
+	).Scan(&team.ID, &team.CreatedAt)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrDuplicate
+		}
+		return fmt.Errorf("failed to create team: %w", err)
+	}
+	return nil
+}
+
+func (p *Postgres) GetTeam(ctx context.Context, orgID, teamID string) (*models.Team, error) {
+	var team models.Team
+	err := p.db.QueryRowContext(ctx,
+		`SELECT id, org_id, name, created_at
+		FROM teams
+		WHERE org_id::text = $1 AND id::text = $2`,
+		orgID, teamID).Scan(&team.ID, &team.OrgID, &team.Name, &team.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get team: %w", err)
+	}
+	return &team, nil
+}
+
+func (p *Postgres) ListTeams(ctx context.Context, orgID string) ([]models.Team, error) {
+	rows, err := p.db.QueryContext(ctx,
+		`SELECT id, org_id, name, created_at FROM teams WHERE org_id::text = $1 ORDER BY name`,
+		orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list teams: %w", err)
+	}
+	defer rows.Close()
+
+	var teams []models.Team
+	for rows.Next() {
+		var team models.Team
+		if err := rows.Scan(&team.ID, &team.OrgID, &team.Name, &team.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan team: %w", err)
+		}
+		teams = append(teams, team)
+	}
+	return teams, rows.Err()
+}
+
+func (p *Postgres) AddTeamMember(ctx context.Context, teamID, userID string) error {
+	_, err := p.db.ExecContext(ctx,
+		`INSERT INTO team_members (team_id, user_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`,
+		teamID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to add team member: %w", err)
+	}
+	return nil
+}
+
+func (p *Postgres) RemoveTeamMember(ctx context.Context, teamID, userID string) error {
+	result, err := p.db.ExecContext(ctx,
+		`DELETE FROM team_members WHERE team_id = $1 AND user_id = $2`,
+		teamID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to remove team member: %w", err)
+	}
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func addOrganizationMember(ctx context.Context, db queryRower, member *models.OrganizationMember) error {
+	err := db.QueryRowContext(ctx,
+		`INSERT INTO organization_members (org_id, user_id, role)
+		VALUES ($1, $2, $3)
+		RETURNING created_at`,
+		member.OrgID, member.UserID, member.Role,
+	).Scan(&member.CreatedAt)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrDuplicate
+		}
+		return fmt.Errorf("failed to add organization member: %w", err)
+	}
+	return nil
+}
+
+func scanOrganizationMember(row rowScanner) (*models.OrganizationMember, error) {
+	var member models.OrganizationMember
+	err := row.Scan(
+		&member.OrgID,
+		&member.UserID,
+		&member.Email,
+		&member.Role,
+		pq.Array(&member.Teams),
+		&member.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
@@ -3,10 +3,13 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"log"
+	"time"
 
 	"github.com/auth-service/internal/models"
+	"github.com/lib/pq"
 
 	"github.com/auth-service/config"
 )
@@ -48,54 +51,132 @@ func NewPostgres(cfg *config.Config) (*Postgres, error) {
 	return &Postgres{db: db}, nil
 }
 
-func (p *Postgres) SaveRefreshToken(ctx context.Context, userID, tokenHash, ip string) error {
+const refreshTokenColumns = `id, tenant_id, user_id, token_hash, ip, last_ip,
+	country, city, asn, as_org, last_country, last_city, last_asn, last_as_org, user_agent, device_name, device, os, browser,
+	COALESCE(client_id, ''), scope, roles, COALESCE(org_id, ''), idle_timeout, ip_change_policy,
+	access_token_ttl, refresh_token_ttl, expires_at, created_at, last_used_at`
+
+// SaveRefreshToken stores a refresh token. A zero ExpiresAt falls back to
+// the default lifetime of 7 days.
+func (p *Postgres) SaveRefreshToken(ctx context.Context, token *models.RefreshToken) error {
 	persistCtx := context.WithoutCancel(ctx)
 
-	_, err := p.db.ExecContext(
+	var expiresAt sql.NullTime
+	if !token.ExpiresAt.IsZero() {
+		expiresAt = sql.NullTime{Time: token.ExpiresAt, Valid: true}
+	}
+
+	err := p.db.QueryRowContext(
 		persistCtx,
-		`INSERT INTO refresh_tokens (user_id, token_hash, ip, expires_at) 
-         VALUES ($1, $2, $3, NOW() + INTERVAL '7 days')`,
-		userID,
-		tokenHash,
-		ip,
-	)
+		`INSERT INTO refresh_tokens (user_id, token_hash, ip, last_ip, user_agent, device_name, device, os, browser,
+			client_id, scope, roles, expires_at, tenant_id, org_id, idle_timeout, ip_change_policy,
+			country, city, asn, as_org, last_country, last_city, last_asn, last_as_org, access_token_ttl, refresh_token_ttl)
+         VALUES ($1, $2, $3, $3, $4, $5, $6, $7, $8,
+			NULLIF($9, ''), $10, COALESCE($11::TEXT[], '{}'), COALESCE($12, NOW() + INTERVAL '7 days'), $13, NULLIF($14, ''), $15, $16,
+			$17, $18, $19, $20, $17, $18, $19, $20, $21, $22)
+         RETURNING id, created_at, expires_at`,
+		token.UserID,
+		token.TokenHash,
+		token.IP,
+		token.UserAgent,
+		token.DeviceName,
+		token.Device,
+		token.OS,
+		token.Browser,
+		token.ClientID,
+		token.Scope,
+		pq.Array(token.Roles),
+		expiresAt,
+		token.TenantID,
+		token.OrgID,
+		int(token.IdleTimeout.Seconds()),
+		token.IPChangePolicy,
+		token.Location.Country,
+		token.Location.City,
+		int64(token.Location.ASN),
+		token.Location.ASOrg,
+		int(token.AccessTokenTTL.Seconds()),
+		int(token.RefreshTokenTTL.Seconds()),
+	).Scan(&token.ID, &token.CreatedAt, &token.ExpiresAt)
+	token.LastIP = token.IP
+	token.LastLocation = token.Location
 
 	if err != nil {
-		return fmt.Errorf("failed to save refresh token for user %s: %w", userID, err)
+		return fmt.Errorf("failed to save refresh token for user %s: %w", token.UserID, err)
 	}
 
-	log.Printf("Successfully saved refresh token for user %s from IP %s", userID, ip)
+	log.Printf("Successfully saved refresh token for user %s from IP %s", token.UserID, token.IP)
 	return nil
 }
 
-func (p *Postgres) GetRefreshToken(ctx context.Context, tokenHash string) (*models.RefreshToken, error) {
-	var token models.RefreshToken
-	err := p.db.QueryRowContext(ctx,
-		`SELECT id, user_id, token_hash, ip, expires_at, created_at 
-		FROM refresh_tokens 
-		WHERE token_hash = $1`,
-		tokenHash).Scan(
-		&token.ID,
-		&token.UserID,
-		&token.TokenHash,
-		&token.IP,
-		&token.ExpiresAt,
-		&token.CreatedAt)
+// RotateRefreshToken replaces the token of the session token.ID and records
+// token.LastIP and token.LastLocation, as long as the session still has the token oldHash. It
+// returns ErrNotFound when the session is gone or was rotated concurrently.
+// Like in SaveRefreshToken, a zero ExpiresAt falls back to 7 days.
+func (p *Postgres) RotateRefreshToken(ctx context.Context, oldHash string, token *models.RefreshToken) error {
+	var expiresAt sql.NullTime
+	if !token.ExpiresAt.IsZero() {
+		expiresAt = sql.NullTime{Time: token.ExpiresAt, Valid: true}
+	}
 
+	err := p.db.QueryRowContext(context.WithoutCancel(ctx),
+		`UPDATE refresh_tokens
+		SET token_hash = $3, last_ip = $4, roles = COALESCE($5::TEXT[], '{}'), org_id = NULLIF($6, ''),
+			expires_at = COALESCE($7, NOW() + INTERVAL '7 days'), last_used_at = NOW(),
+			last_country = $8, last_city = $9, last_asn = $10, last_as_org = $11
+		WHERE id = $1 AND token_hash = $2
+		RETURNING created_at, last_used_at, expires_at`,
+		token.ID,
+		oldHash,
+		token.TokenHash,
+		token.LastIP,
+		pq.Array(token.Roles),
+		token.OrgID,
+		expiresAt,
+		token.LastLocation.Country,
+		token.LastLocation.City,
+		int64(token.LastLocation.ASN),
+		token.LastLocation.ASOrg,
+	).Scan(&token.CreatedAt, &token.LastUsedAt, &token.ExpiresAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("failed to rotate refresh token: %w", err)
+	}
+	return nil
+}
+
+func (p *Postgres) GetRefreshToken(ctx context.Context, tokenHash string) (*models.RefreshToken, error) {
+	token, err := scanRefreshToken(p.db.QueryRowContext(ctx,
+		`SELECT `+refreshTokenColumns+` FROM refresh_tokens WHERE token_hash = $1`, tokenHash))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, fmt.Errorf("token not found")
 		}
 		return nil, fmt.Errorf("failed to get token: %w", err)
 	}
-	return &token, nil
+	return token, nil
+}
+
+// GetRefreshTokenByID returns a session of the tenant.
+func (p *Postgres) GetRefreshTokenByID(ctx context.Context, tenantID, id string) (*models.RefreshToken, error) {
+	token, err := scanRefreshToken(p.db.QueryRowContext(ctx,
+		`SELECT `+refreshTokenColumns+` FROM refresh_tokens WHERE tenant_id = $1 AND id::text = $2`, tenantID, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get token: %w", err)
+	}
+	return token, nil
 }
 
-func (p *Postgres) GetRefreshTokensByUser(ctx context.Context, userID string) ([]models.RefreshToken, error) {
+func (p *Postgres) GetRefreshTokensByUser(ctx context.Context, tenantID, userID string) ([]models.RefreshToken, error) {
 	rows, err := p.db.QueryContext(ctx,
-		`SELECT id, user_id, token_hash, ip, expires_at, created_at 
-		FROM refresh_tokens 
-		WHERE user_id = $1`, userID)
+		`SELECT `+refreshTokenColumns+`
+		FROM refresh_tokens
+		WHERE tenant_id = $1 AND user_id = $2`, tenantID, userID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get refresh tokens: %w", err)
 	}
@@ -103,17 +184,11 @@ func (p *Postgres) GetRefreshTokensByUser(ctx context.Context, userID string) ([
 
 	var tokens []models.RefreshToken
 	for rows.Next() {
-		var token models.RefreshToken
-		if err := rows.Scan(
-			&token.ID,
-			&token.UserID,
-			&token.TokenHash,
-			&token.IP,
-			&token.ExpiresAt,
-			&token.CreatedAt); err != nil {
+		token, err := scanRefreshToken(rows)
+		if err != nil {
 			return nil, fmt.Errorf("failed to scan token: %w", err)
 		}
-		tokens = append(tokens, token)
+		tokens = append(tokens, *token)
 	}
 
 	if err = rows.Err(); err != nil {
@@ -123,6 +198,55 @@ func (p *Postgres) GetRefreshTokensByUser(ctx context.Context, userID string) ([
 	return tokens, nil
 }
 
+func scanRefreshToken(row rowScanner) (*models.RefreshToken, error) {
+	var (
+		token                 models.RefreshToken
+		idleTimeout           int
+		accessTTL, refreshTTL int
+		asn, lastASN          int64
+	)
+	err := row.Scan(
+		&token.ID,
+		&token.TenantID,
+		&token.UserID,
+		&token.TokenHash,
+		&token.IP,
+		&token.LastIP,
+		&token.Location.Country,
+		&token.Location.City,
+		&asn,
+		&token.Location.ASOrg,
+		&token.LastLocation.Country,
+		&token.LastLocation.City,
+		&lastASN,
+		&token.LastLocation.ASOrg,
+		&token.UserAgent,
+		&token.DeviceName,
+		&token.Device,
+		&token.OS,
+		&token.Browser,
+		&token.ClientID,
+		&token.Scope,
+		pq.Array(&token.Roles),
+		&token.OrgID,
+		&idleTimeout,
+		&token.IPChangePolicy,
+		&accessTTL,
+		&refreshTTL,
+		&token.ExpiresAt,
+		&token.CreatedAt,
+		&token.LastUsedAt)
+	if err != nil {
+		return nil, err
+	}
+	token.IdleTimeout = time.Duration(idleTimeout) * time.Second
+	token.AccessTokenTTL = time.Duration(accessTTL) * time.Second
+	token.RefreshTokenTTL = time.Duration(refreshTTL) * time.Second
+	token.Location.ASN = uint32(asn)
+	token.LastLocation.ASN = uint32(lastASN)
+	return &token, nil
+}
+
 func (p *Postgres) DeleteRefreshToken(ctx context.Context, id string) error {
 	_, err := p.db.ExecContext(ctx,
 		`DELETE FROM refresh_tokens WHERE id = $1`, id)
@@ -132,10 +256,33 @@ func (p *Postgres) DeleteRefreshToken(ctx context.Context, id string) error {
 	return nil
 }
 
-func (p *Postgres) RevokeAllTokens(ctx context.Context, userID string) error {
+// DeleteIdleRefreshTokens deletes up to limit sessions that were not used for
+// their idle timeout, or for defaultTimeout when they have none, and returns
+// how many were deleted. A zero defaultTimeout only sweeps sessions with
+// their own timeout.
+func (p *Postgres) DeleteIdleRefreshTokens(ctx context.Context, defaultTimeout time.Duration, now time.Time, limit int) (int, error) {
+	result, err := p.db.ExecContext(ctx,
+		`DELETE FROM refresh_tokens WHERE id IN (
+			SELECT id FROM refresh_tokens
+			WHERE (idle_timeout > 0 OR $2 > 0)
+				AND COALESCE(last_used_at, created_at) <
+					$1 - make_interval(secs => CASE WHEN idle_timeout > 0 THEN idle_timeout ELSE $2 END)
+			LIMIT $3)`,
+		now, int(defaultTimeout.Seconds()), limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete idle tokens: %w", err)
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to count deleted idle tokens: %w", err)
+	}
+	return int(deleted), nil
+}
+
+func (p *Postgres) RevokeAllTokens(ctx context.Context, tenantID, userID string) error {
 	_, err := p.db.ExecContext(ctx,
-		`DELETE FROM refresh_tokens WHERE user_id = $1`,
-		userID)
+		`DELETE FROM refresh_tokens WHERE tenant_id = $1 AND user_id = $2`,
+		tenantID, userID)
 	if err != nil {
 		return fmt.Errorf("failed to revoke tokens: %w", err)
 	}
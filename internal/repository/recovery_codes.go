@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/auth-service/internal/models"
+)
+
+func (p *Postgres) ReplaceRecoveryCodes(ctx context.Context, tenantID, userID string, codeHashes []string) error {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx,
+		`DELETE FROM mfa_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("failed to delete old recovery codes: %w", err)
+	}
+
+	for _, hash := range codeHashes {
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO mfa_recovery_codes (tenant_id, user_id, code_hash) VALUES ($1, $2, $3)`,
+			tenantID, userID, hash); err != nil {
+			return fmt.Errorf("failed to save recovery code: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit recovery codes: %w", err)
+	}
+	return nil
+}
+
+func (p *Postgres) GetUnusedRecoveryCodes(ctx context.Context, tenantID, userID string) ([]models.RecoveryCode, error) {
+	rows, err := p.db.QueryContext(ctx,
+		`SELECT id, tenant_id, user_id, code_hash, created_at
+		FROM mfa_recovery_codes
+		WHERE tenant_id = $1 AND user_id = $2 AND used_at IS NULL`, tenantID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recovery codes: %w", err)
+	}
+	defer rows.Close()
+
+	var codes []models.RecoveryCode
+	for rows.Next() {
+		var code models.RecoveryCode
+		if err := rows.Scan(
+			&code.ID,
+			&code.TenantID,
+			&code.UserID,
+			&code.CodeHash,
+			&code.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan recovery code: %w", err)
+		}
+		codes = append(codes, code)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating recovery codes: %w", err)
+	}
+
+	return codes, nil
+}
+
+// MarkRecoveryCodeUsed consumes the code. It returns ErrNotFound when the code
+// does not exist or was already used, so two concurrent logins cannot both
+// succeed with the same code.
+func (p *Postgres) MarkRecoveryCodeUsed(ctx context.Context, id string) error {
+	res, err := p.db.ExecContext(ctx,
+		`UPDATE mfa_recovery_codes SET used_at = NOW() WHERE id = $1 AND used_at IS NULL`, id)
+	if err != nil {
+		return fmt.Errorf("failed to mark recovery code used: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to mark recovery code used: %w", err)
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
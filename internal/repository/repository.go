@@ -3,20 +3,198 @@ package repository
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/auth-service/internal/models"
 )
 
 var (
 	ErrDatabase = errors.New("database error")
+	ErrNotFound = errors.New("record not found")
+	// ErrDuplicate is returned when a record with the same unique key exists.
+	ErrDuplicate = errors.New("record already exists")
 )
 
 type Repository interface {
-	SaveRefreshToken(ctx context.Context, userID, tokenHash, ip string) error
-	GetRefreshTokensByUser(ctx context.Context, userID string) ([]models.RefreshToken, error)
+	SaveRefreshToken(ctx context.Context, token *models.RefreshToken) error
+	RotateRefreshToken(ctx context.Context, oldHash string, token *models.RefreshToken) error
+	GetRefreshTokenByID(ctx context.Context, tenantID, id string) (*models.RefreshToken, error)
+	GetRefreshTokensByUser(ctx context.Context, tenantID, userID string) ([]models.RefreshToken, error)
 	DeleteRefreshToken(ctx context.Context, id string) error
-	RevokeAllTokens(ctx context.Context, userID string) error
+	RevokeAllTokens(ctx context.Context, tenantID, userID string) error
+	DeleteIdleRefreshTokens(ctx context.Context, defaultTimeout time.Duration, now time.Time, limit int) (int, error)
+	RecoveryCodeRepository
+	AuditRepository
+	WebAuthnRepository
+	LockoutRepository
+	UserRepository
+	LoginCodeRepository
+	ClientRepository
+	AuthorizationCodeRepository
+	DeviceCodeRepository
+	FederationRepository
+	APIKeyRepository
+	RoleRepository
+	TokenCutoffRepository
+	RevokedTokenRepository
+	OrganizationRepository
+	EmailChangeRepository
+	SecurityAlertRepository
+	AccountRepository
+	CleanupRepository
 	Close() error
 }
 
-//go:generate mockgen -destination=repository_mock.go -package=repository github.com/auth-service/internal/repository Repository
\ No newline at end of file
+type RecoveryCodeRepository interface {
+	ReplaceRecoveryCodes(ctx context.Context, tenantID, userID string, codeHashes []string) error
+	GetUnusedRecoveryCodes(ctx context.Context, tenantID, userID string) ([]models.RecoveryCode, error)
+	MarkRecoveryCodeUsed(ctx context.Context, id string) error
+}
+
+type WebAuthnRepository interface {
+	SaveWebAuthnCredential(ctx context.Context, credential *models.WebAuthnCredential) error
+	GetWebAuthnCredentialsByUser(ctx context.Context, tenantID, userID string) ([]models.WebAuthnCredential, error)
+	UpdateWebAuthnCredentialUsage(ctx context.Context, id string, signCount uint32, backupState bool) error
+	SaveWebAuthnSession(ctx context.Context, session *models.WebAuthnSession) error
+	TakeWebAuthnSession(ctx context.Context, id, ceremony string) (*models.WebAuthnSession, error)
+}
+
+type LockoutRepository interface {
+	GetAuthFailure(ctx context.Context, scope, key string) (*models.AuthFailure, error)
+	RecordAuthFailure(ctx context.Context, scope, key string, window time.Duration) (*models.AuthFailure, error)
+	LockAuthFailure(ctx context.Context, scope, key string, until time.Time) error
+	ClearAuthFailures(ctx context.Context, scope, key string) error
+}
+
+type UserRepository interface {
+	GetUserByEmail(ctx context.Context, tenantID, email string) (*models.User, error)
+	GetUserByID(ctx context.Context, id string) (*models.User, error)
+	UpdateUserProfile(ctx context.Context, user *models.User) error
+	UpdateUserEmail(ctx context.Context, userID, email string) error
+}
+
+type EmailChangeRepository interface {
+	SaveEmailChange(ctx context.Context, change *models.EmailChange) error
+	GetEmailChangeByToken(ctx context.Context, tokenHash string) (*models.EmailChange, error)
+	ConfirmEmailChange(ctx context.Context, id string, oldAddress bool) (*models.EmailChange, error)
+	DeleteEmailChange(ctx context.Context, id string) error
+}
+
+type LoginCodeRepository interface {
+	SaveLoginCode(ctx context.Context, code *models.LoginCode) error
+	GetLoginCode(ctx context.Context, id string) (*models.LoginCode, error)
+	GetLoginCodeByNonce(ctx context.Context, nonceHash string) (*models.LoginCode, error)
+	IncrementLoginCodeAttempts(ctx context.Context, id string) error
+	ConsumeLoginCode(ctx context.Context, id string) error
+}
+
+type ClientRepository interface {
+	CreateClient(ctx context.Context, client *models.OAuthClient) error
+	GetClient(ctx context.Context, clientID string) (*models.OAuthClient, error)
+	UpdateClientSecret(ctx context.Context, clientID, secretHash string) error
+	DisableClient(ctx context.Context, clientID string) error
+}
+
+type AuthorizationCodeRepository interface {
+	SaveAuthorizationCode(ctx context.Context, code *models.AuthorizationCode) error
+	TakeAuthorizationCode(ctx context.Context, codeHash string) (*models.AuthorizationCode, error)
+}
+
+type DeviceCodeRepository interface {
+	SaveDeviceCode(ctx context.Context, code *models.DeviceCode) error
+	GetDeviceCodeByUserCode(ctx context.Context, userCode string) (*models.DeviceCode, error)
+	DecideDeviceCode(ctx context.Context, id, userID, status string) error
+	PollDeviceCode(ctx context.Context, deviceCodeHash string, now time.Time) (*models.DeviceCode, error)
+	DeleteDeviceCode(ctx context.Context, id string) error
+}
+
+type FederationRepository interface {
+	SaveFederatedLogin(ctx context.Context, login *models.FederatedLogin) error
+	TakeFederatedLogin(ctx context.Context, stateHash string) (*models.FederatedLogin, error)
+	GetFederatedIdentity(ctx context.Context, tenantID, issuer, subject string) (*models.FederatedIdentity, error)
+	LinkFederatedIdentity(ctx context.Context, identity *models.FederatedIdentity) error
+	CreateFederatedUser(ctx context.Context, user *models.User, identity *models.FederatedIdentity) error
+}
+
+type APIKeyRepository interface {
+	CreateAPIKey(ctx context.Context, key *models.APIKey) error
+	GetAPIKeyByPrefix(ctx context.Context, prefix string) (*models.APIKey, error)
+	ListAPIKeys(ctx context.Context, userID string) ([]models.APIKey, error)
+	DeleteAPIKey(ctx context.Context, userID, id string) error
+	TouchAPIKey(ctx context.Context, id, ip string, usedAt time.Time) error
+}
+
+type RoleRepository interface {
+	CreateRole(ctx context.Context, role *models.Role) error
+	GetRole(ctx context.Context, tenantID, id string) (*models.Role, error)
+	ListRoles(ctx context.Context, tenantID string) ([]models.Role, error)
+	UpdateRole(ctx context.Context, role *models.Role) error
+	DeleteRole(ctx context.Context, tenantID, id string) error
+	ListPermissions(ctx context.Context) ([]models.Permission, error)
+	GetUserRoles(ctx context.Context, userID string) ([]models.Role, error)
+	SetUserRoles(ctx context.Context, tenantID, userID string, roleNames []string) error
+}
+
+type TokenCutoffRepository interface {
+	SetTokenCutoff(ctx context.Context, userID string, notBefore time.Time) error
+	SetRoleTokenCutoff(ctx context.Context, roleID string, notBefore time.Time) error
+	GetTokenCutoff(ctx context.Context, userID string) (time.Time, error)
+}
+
+type OrganizationRepository interface {
+	CreateOrganization(ctx context.Context, org *models.Organization, owner *models.OrganizationMember) error
+	GetOrganization(ctx context.Context, tenantID, orgID string) (*models.Organization, error)
+	ListUserOrganizations(ctx context.Context, tenantID, userID string) ([]models.Organization, error)
+	GetOrganizationMember(ctx context.Context, orgID, userID string) (*models.OrganizationMember, error)
+	ListOrganizationMembers(ctx context.Context, orgID string) ([]models.OrganizationMember, error)
+	AddOrganizationMember(ctx context.Context, member *models.OrganizationMember) error
+	UpdateOrganizationMemberRole(ctx context.Context, orgID, userID, role string) error
+	RemoveOrganizationMember(ctx context.Context, orgID, userID string) error
+	CountOrganizationOwners(ctx context.Context, orgID string) (int, error)
+	SaveOrganizationInvitation(ctx context.Context, invitation *models.OrganizationInvitation) error
+	GetOrganizationInvitation(ctx context.Context, tokenHash string) (*models.OrganizationInvitation, error)
+	DeleteOrganizationInvitation(ctx context.Context, id string) error
+	CreateTeam(ctx context.Context, team *models.Team) error
+	GetTeam(ctx context.Context, orgID, teamID string) (*models.Team, error)
+	ListTeams(ctx context.Context, orgID string) ([]models.Team, error)
+	AddTeamMember(ctx context.Context, teamID, userID string) error
+	RemoveTeamMember(ctx context.Context, teamID, userID string) error
+}
+
+// RevokedTokenRepository is the denylist of access tokens that were revoked
+// before they expired.
+type RevokedTokenRepository interface {
+	RevokeAccessToken(ctx context.Context, jti string, expiresAt time.Time) error
+	IsAccessTokenRevoked(ctx context.Context, jti string) (bool, error)
+}
+
+type AuditRepository interface {
+	SaveAuditEvent(ctx context.Context, event *models.AuditEvent) error
+	ListAuditEvents(ctx context.Context, userID string) ([]models.AuditEvent, error)
+}
+
+type SecurityAlertRepository interface {
+	SaveSecurityAlert(ctx context.Context, alert *models.SecurityAlert) error
+	ListSecurityAlerts(ctx context.Context, userID string) ([]models.SecurityAlert, error)
+}
+
+// CleanupRepository deletes records that are no longer needed, up to limit
+// at a time. Each method returns how many records it deleted.
+type CleanupRepository interface {
+	DeleteExpiredRefreshTokens(ctx context.Context, now time.Time, limit int) (int, error)
+	DeleteExpiredRevokedTokens(ctx context.Context, now time.Time, limit int) (int, error)
+	DeleteStaleLoginCodes(ctx context.Context, now time.Time, limit int) (int, error)
+	DeleteExpiredAuthorizationCodes(ctx context.Context, now time.Time, limit int) (int, error)
+	DeleteExpiredDeviceCodes(ctx context.Context, now time.Time, limit int) (int, error)
+	DeleteExpiredWebAuthnSessions(ctx context.Context, now time.Time, limit int) (int, error)
+	DeleteExpiredEmailChanges(ctx context.Context, now time.Time, limit int) (int, error)
+}
+
+type AccountRepository interface {
+	ScheduleUserDeletion(ctx context.Context, userID string, deleteAfter time.Time) error
+	CancelUserDeletion(ctx context.Context, userID string) error
+	ListUsersDueForDeletion(ctx context.Context, now time.Time, limit int) ([]string, error)
+	PurgeUser(ctx context.Context, userID string) error
+}
+
+//go:generate mockgen -destination=mocks/mock_repository.go -package=mocks github.com/auth-service/internal/repository Repository,RecoveryCodeRepository,AuditRepository,WebAuthnRepository,LockoutRepository,UserRepository,LoginCodeRepository,ClientRepository,AuthorizationCodeRepository,DeviceCodeRepository,FederationRepository,APIKeyRepository,RoleRepository,TokenCutoffRepository,RevokedTokenRepository,OrganizationRepository,EmailChangeRepository,SecurityAlertRepository,AccountRepository,CleanupRepository
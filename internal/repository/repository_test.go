@@ -7,6 +7,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/auth-service/internal/models"
 	_ "github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
 )
@@ -30,37 +31,37 @@ func TestPostgres_RefreshTokenFlow(t *testing.T) {
 	ctx := context.Background()
 
 	t.Run("Save and Get", func(t *testing.T) {
-		err := repo.SaveRefreshToken(ctx, "user1", "hash1", "127.0.0.1")
+		err := repo.SaveRefreshToken(ctx, &models.RefreshToken{UserID: "user1", TokenHash: "hash1", IP: "127.0.0.1"})
 		assert.NoError(t, err)
 
-		tokens, err := repo.GetRefreshTokensByUser(ctx, "user1")
+		tokens, err := repo.GetRefreshTokensByUser(ctx, "default", "user1")
 		assert.NoError(t, err)
 		assert.Len(t, tokens, 1)
 		assert.Equal(t, "user1", tokens[0].UserID)
 	})
 
 	t.Run("Delete", func(t *testing.T) {
-		_ = repo.SaveRefreshToken(ctx, "user2", "hash2", "127.0.0.2")
-		tokens, _ := repo.GetRefreshTokensByUser(ctx, "user2")
+		_ = repo.SaveRefreshToken(ctx, &models.RefreshToken{UserID: "user2", TokenHash: "hash2", IP: "127.0.0.2"})
+		tokens, _ := repo.GetRefreshTokensByUser(ctx, "default", "user2")
 		assert.NotEmpty(t, tokens)
 
 		err := repo.DeleteRefreshToken(ctx, tokens[0].ID)
 		assert.NoError(t, err)
 
-		tokens, _ = repo.GetRefreshTokensByUser(ctx, "user2")
+		tokens, _ = repo.GetRefreshTokensByUser(ctx, "default", "user2")
 		assert.Empty(t, tokens)
 	})
 
 	t.Run("RevokeAllTokens", func(t *testing.T) {
-		_ = repo.SaveRefreshToken(ctx, "user3", "hash3", "127.0.0.3")
-		_ = repo.SaveRefreshToken(ctx, "user3", "hash4", "127.0.0.3")
-		tokens, _ := repo.GetRefreshTokensByUser(ctx, "user3")
+		_ = repo.SaveRefreshToken(ctx, &models.RefreshToken{UserID: "user3", TokenHash: "hash3", IP: "127.0.0.3"})
+		_ = repo.SaveRefreshToken(ctx, &models.RefreshToken{UserID: "user3", TokenHash: "hash4", IP: "127.0.0.3"})
+		tokens, _ := repo.GetRefreshTokensByUser(ctx, "default", "user3")
 		assert.Len(t, tokens, 2)
 
-		err := repo.RevokeAllTokens(ctx, "user3")
+		err := repo.RevokeAllTokens(ctx, "default", "user3")
 		assert.NoError(t, err)
 
-		tokens, _ = repo.GetRefreshTokensByUser(ctx, "user3")
+		tokens, _ = repo.GetRefreshTokensByUser(ctx, "default", "user3")
 		assert.Empty(t, tokens)
 	})
 }
@@ -73,8 +74,8 @@ func TestPostgres_GetRefreshToken(t *testing.T) {
 	defer repo.Close()
 	ctx := context.Background()
 
-	_ = repo.SaveRefreshToken(ctx, "user4", "hash5", "127.0.0.4")
-	tokens, _ := repo.GetRefreshTokensByUser(ctx, "user4")
+	_ = repo.SaveRefreshToken(ctx, &models.RefreshToken{UserID: "user4", TokenHash: "hash5", IP: "127.0.0.4"})
+	tokens, _ := repo.GetRefreshTokensByUser(ctx, "default", "user4")
 	assert.NotEmpty(t, tokens)
 
 	token, err := repo.GetRefreshToken(ctx, "hash5")
@@ -91,10 +92,30 @@ func TestPostgres_SaveRefreshToken_ExpiresAt(t *testing.T) {
 	defer repo.Close()
 	ctx := context.Background()
 
-	err := repo.SaveRefreshToken(ctx, "user5", "hash6", "127.0.0.5")
+	err := repo.SaveRefreshToken(ctx, &models.RefreshToken{UserID: "user5", TokenHash: "hash6", IP: "127.0.0.5"})
 	assert.NoError(t, err)
 
-	tokens, err := repo.GetRefreshTokensByUser(ctx, "user5")
+	tokens, err := repo.GetRefreshTokensByUser(ctx, "default", "user5")
 	assert.NoError(t, err)
 	assert.WithinDuration(t, time.Now().Add(7*24*time.Hour), tokens[0].ExpiresAt, 2*time.Minute)
 }
+
+func TestPostgres_RefreshTokenWithoutRoles(t *testing.T) {
+	if os.Getenv("CI") == "" {
+		t.Skip("Тест требует запущенной тестовой БД (docker-compose up)")
+	}
+	repo := setupTestDB(t)
+	defer repo.Close()
+	ctx := context.Background()
+
+	token := &models.RefreshToken{TenantID: "default", UserID: "user6", TokenHash: "hash7", IP: "127.0.0.6"}
+	assert.NoError(t, repo.SaveRefreshToken(ctx, token))
+
+	rotated := &models.RefreshToken{ID: token.ID, TokenHash: "hash8", LastIP: "127.0.0.7"}
+	assert.NoError(t, repo.RotateRefreshToken(ctx, "hash7", rotated))
+
+	stored, err := repo.GetRefreshToken(ctx, "hash8")
+	assert.NoError(t, err)
+	assert.Empty(t, stored.Roles)
+	assert.Equal(t, "127.0.0.7", stored.LastIP)
+}
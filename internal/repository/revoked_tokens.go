@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// RevokeAccessToken denylists the access token with the jti until it
+// expires. Revoking a token twice is not an error.
+func (p *Postgres) RevokeAccessToken(ctx context.Context, jti string, expiresAt time.Time) error {
+	_, err := p.db.ExecContext(ctx,
+		`INSERT INTO revoked_access_tokens (jti, expires_at)
+		VALUES ($1, $2)
+		ON CONFLICT (jti) DO NOTHING`,
+		jti, expiresAt)
+	if err != nil {
+		return fmt.Errorf("failed to revoke access token: %w", err)
+	}
+	return nil
+}
+
+func (p *Postgres) IsAccessTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	var revoked bool
+	err := p.db.QueryRowContext(ctx,
+		`SELECT EXISTS (SELECT 1 FROM revoked_access_tokens WHERE jti = $1)`,
+		jti).Scan(&revoked)
+	if err != nil {
+		return false, fmt.Errorf("failed to check access token: %w", err)
+	}
+	return revoked, nil
+}
@@ -0,0 +1,287 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/auth-service/internal/models"
+	"github.com/lib/pq"
+)
+
+// roleColumns selects a role with its permissions, the query has to join
+// role_permissions as rp and group by r.id.
+const roleColumns = `r.id, r.tenant_id, r.name, r.description,
+	COALESCE(array_agg(rp.permission ORDER BY rp.permission) FILTER (WHERE rp.permission IS NOT NULL), '{}'),
+	r.created_at, r.updated_at`
+
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// CreateRole saves a role with its permissions. Permissions missing from the
+// catalog are added to it.
+func (p *Postgres) CreateRole(ctx context.Context, role *models.Role) error {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRowContext(ctx,
+		`INSERT INTO roles (tenant_id, name, description)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at, updated_at`,
+		role.TenantID, role.Name, role.Description,
+	).Scan(&role.ID, &role.CreatedAt, &role.UpdatedAt)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrDuplicate
+		}
+		return fmt.Errorf("failed to create role: %w", err)
+	}
+
+	if err := setRolePermissions(ctx, tx, role.ID, role.Permissions); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit role: %w", err)
+	}
+	return nil
+}
+
+func (p *Postgres) GetRole(ctx context.Context, tenantID, id string) (*models.Role, error) {
+	role, err := scanRole(p.db.QueryRowContext(ctx,
+		`SELECT `+roleColumns+`
+		FROM roles r
+		LEFT JOIN role_permissions rp ON rp.role_id = r.id
+		WHERE r.tenant_id = $1 AND r.id::text = $2
+		GROUP BY r.id`,
+		tenantID, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get role: %w", err)
+	}
+	return role, nil
+}
+
+func (p *Postgres) ListRoles(ctx context.Context, tenantID string) ([]models.Role, error) {
+	return p.queryRoles(ctx,
+		`SELECT `+roleColumns+`
+		FROM roles r
+		LEFT JOIN role_permissions rp ON rp.role_id = r.id
+		WHERE r.tenant_id = $1
+		GROUP BY r.id
+		ORDER BY r.name`,
+		tenantID)
+}
+
+// UpdateRole replaces the description and the permissions of a role.
+func (p *Postgres) UpdateRole(ctx context.Context, role *models.Role) error {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRowContext(ctx,
+		`UPDATE roles SET description = $3, updated_at = NOW()
+		WHERE tenant_id = $1 AND id::text = $2
+		RETURNING name, created_at, updated_at`,
+		role.TenantID, role.ID, role.Description,
+	).Scan(&role.Name, &role.CreatedAt, &role.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("failed to update role: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM role_permissions WHERE role_id = $1`, role.ID); err != nil {
+		return fmt.Errorf("failed to clear role permissions: %w", err)
+	}
+	if err := setRolePermissions(ctx, tx, role.ID, role.Permissions); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit role: %w", err)
+	}
+	return nil
+}
+
+func (p *Postgres) DeleteRole(ctx context.Context, tenantID, id string) error {
+	result, err := p.db.ExecContext(ctx, `DELETE FROM roles WHERE tenant_id = $1 AND id::text = $2`, tenantID, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete role: %w", err)
+	}
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (p *Postgres) ListPermissions(ctx context.Context) ([]models.Permission, error) {
+	rows, err := p.db.QueryContext(ctx, `SELECT name, description FROM permissions ORDER BY name`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list permissions: %w", err)
+	}
+	defer rows.Close()
+
+	var permissions []models.Permission
+	for rows.Next() {
+		var permission models.Permission
+		if err := rows.Scan(&permission.Name, &permission.Description); err != nil {
+			return nil, fmt.Errorf("failed to scan permission: %w", err)
+		}
+		permissions = append(permissions, permission)
+	}
+	return permissions, rows.Err()
+}
+
+func (p *Postgres) GetUserRoles(ctx context.Context, userID string) ([]models.Role, error) {
+	return p.queryRoles(ctx,
+		`SELECT `+roleColumns+`
+		FROM user_roles ur
+		JOIN roles r ON r.id = ur.role_id
+		LEFT JOIN role_permissions rp ON rp.role_id = r.id
+		WHERE ur.user_id = $1
+		GROUP BY r.id
+		ORDER BY r.name`,
+		userID)
+}
+
+// SetUserRoles replaces the roles of a user with the tenant's roles of the
+// given names. It returns ErrNotFound when one of the roles does not exist.
+func (p *Postgres) SetUserRoles(ctx context.Context, tenantID, userID string, roleNames []string) error {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM user_roles WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("failed to clear user roles: %w", err)
+	}
+
+	result, err := tx.ExecContext(ctx,
+		`INSERT INTO user_roles (user_id, role_id)
+		SELECT $2, id FROM roles WHERE tenant_id = $1 AND name = ANY($3)`,
+		tenantID, userID, pq.Array(roleNames))
+	if err != nil {
+		return fmt.Errorf("failed to assign user roles: %w", err)
+	}
+	if rows, err := result.RowsAffected(); err != nil {
+		return fmt.Errorf("failed to assign user roles: %w", err)
+	} else if int(rows) != len(roleNames) {
+		return ErrNotFound
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit user roles: %w", err)
+	}
+	return nil
+}
+
+func (p *Postgres) SetTokenCutoff(ctx context.Context, userID string, notBefore time.Time) error {
+	_, err := p.db.ExecContext(ctx,
+		`INSERT INTO token_cutoffs (user_id, not_before)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE SET not_before = EXCLUDED.not_before`,
+		userID, notBefore)
+	if err != nil {
+		return fmt.Errorf("failed to set token cutoff: %w", err)
+	}
+	return nil
+}
+
+// SetRoleTokenCutoff sets the token cutoff of every user with the role.
+func (p *Postgres) SetRoleTokenCutoff(ctx context.Context, roleID string, notBefore time.Time) error {
+	_, err := p.db.ExecContext(ctx,
+		`INSERT INTO token_cutoffs (user_id, not_before)
+		SELECT user_id, $2 FROM user_roles WHERE role_id::text = $1
+		ON CONFLICT (user_id) DO UPDATE SET not_before = EXCLUDED.not_before`,
+		roleID, notBefore)
+	if err != nil {
+		return fmt.Errorf("failed to set token cutoff of role: %w", err)
+	}
+	return nil
+}
+
+// GetTokenCutoff returns the zero time when the tokens of the user have no
+// cutoff.
+func (p *Postgres) GetTokenCutoff(ctx context.Context, userID string) (time.Time, error) {
+	var notBefore time.Time
+	err := p.db.QueryRowContext(ctx,
+		`SELECT not_before FROM token_cutoffs WHERE user_id = $1`,
+		userID).Scan(&notBefore)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return time.Time{}, fmt.Errorf("failed to get token cutoff: %w", err)
+	}
+	return notBefore, nil
+}
+
+func (p *Postgres) queryRoles(ctx context.Context, query string, args ...interface{}) ([]models.Role, error) {
+	rows, err := p.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list roles: %w", err)
+	}
+	defer rows.Close()
+
+	var roles []models.Role
+	for rows.Next() {
+		role, err := scanRole(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan role: %w", err)
+		}
+		roles = append(roles, *role)
+	}
+	return roles, rows.Err()
+}
+
+func scanRole(row rowScanner) (*models.Role, error) {
+	var role models.Role
+	err := row.Scan(
+		&role.ID,
+		&role.TenantID,
+		&role.Name,
+		&role.Description,
+		pq.Array(&role.Permissions),
+		&role.CreatedAt,
+		&role.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &role, nil
+}
+
+func setRolePermissions(ctx context.Context, db execer, roleID string, permissions []string) error {
+	if len(permissions) == 0 {
+		return nil
+	}
+
+	_, err := db.ExecContext(ctx,
+		`INSERT INTO permissions (name) SELECT unnest($1::text[]) ON CONFLICT (name) DO NOTHING`,
+		pq.Array(permissions))
+	if err != nil {
+		return fmt.Errorf("failed to save permissions: %w", err)
+	}
+	_, err = db.ExecContext(ctx,
+		`INSERT INTO role_permissions (role_id, permission)
+		SELECT $1, unnest($2::text[])`,
+		roleID, pq.Array(permissions))
+	if err != nil {
+		return fmt.Errorf("failed to save role permissions: %w", err)
+	}
+	return nil
+}
+
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
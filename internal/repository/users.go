@@ -0,0 +1,181 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/auth-service/internal/models"
+)
+
+const userColumns = `id, tenant_id, email, COALESCE(password_hash, ''), name, email_verified,
+	locale, timezone, avatar_url, delete_after, created_at, updated_at`
+
+func (p *Postgres) GetUserByEmail(ctx context.Context, tenantID, email string) (*models.User, error) {
+	return p.getUser(ctx, `SELECT `+userColumns+` FROM users WHERE tenant_id = $1 AND email = $2`,
+		tenantID, strings.ToLower(email))
+}
+
+func (p *Postgres) GetUserByID(ctx context.Context, id string) (*models.User, error) {
+	return p.getUser(ctx, `SELECT `+userColumns+` FROM users WHERE id = $1`, id)
+}
+
+func (p *Postgres) getUser(ctx context.Context, query string, args ...interface{}) (*models.User, error) {
+	var user models.User
+	err := p.db.QueryRowContext(ctx, query, args...).Scan(
+		&user.ID,
+		&user.TenantID,
+		&user.Email,
+		&user.PasswordHash,
+		&user.Name,
+		&user.EmailVerified,
+		&user.Locale,
+		&user.Timezone,
+		&user.AvatarURL,
+		&user.DeleteAfter,
+		&user.CreatedAt,
+		&user.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	return &user, nil
+}
+
+// UpdateUserProfile saves the name, locale, timezone and avatar of a user.
+func (p *Postgres) UpdateUserProfile(ctx context.Context, user *models.User) error {
+	err := p.db.QueryRowContext(ctx,
+		`UPDATE users SET name = $2, locale = $3, timezone = $4, avatar_url = $5, updated_at = NOW()
+		WHERE id = $1
+		RETURNING updated_at`,
+		user.ID, user.Name, user.Locale, user.Timezone, user.AvatarURL,
+	).Scan(&user.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("failed to update user profile: %w", err)
+	}
+	return nil
+}
+
+// UpdateUserEmail changes the email of a user and marks it verified. It
+// returns ErrDuplicate when another user of the tenant has the email.
+func (p *Postgres) UpdateUserEmail(ctx context.Context, userID, email string) error {
+	result, err := p.db.ExecContext(ctx,
+		`UPDATE users SET email = $2, email_verified = TRUE, updated_at = NOW() WHERE id = $1`,
+		userID, strings.ToLower(email))
+	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrDuplicate
+		}
+		return fmt.Errorf("failed to update user email: %w", err)
+	}
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// SaveEmailChange replaces the pending email change of the user.
+func (p *Postgres) SaveEmailChange(ctx context.Context, change *models.EmailChange) error {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM email_changes WHERE user_id = $1`, change.UserID); err != nil {
+		return fmt.Errorf("failed to delete email changes: %w", err)
+	}
+	err = tx.QueryRowContext(ctx,
+		`INSERT INTO email_changes (user_id, new_email, old_token_hash, new_token_hash, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at`,
+		change.UserID,
+		strings.ToLower(change.NewEmail),
+		change.OldTokenHash,
+		change.NewTokenHash,
+		change.ExpiresAt,
+	).Scan(&change.ID, &change.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save email change: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit email change: %w", err)
+	}
+	return nil
+}
+
+// GetEmailChangeByToken finds the change that either of its tokens belongs
+// to.
+func (p *Postgres) GetEmailChangeByToken(ctx context.Context, tokenHash string) (*models.EmailChange, error) {
+	change, err := scanEmailChange(p.db.QueryRowContext(ctx,
+		`SELECT `+emailChangeColumns+`
+		FROM email_changes
+		WHERE old_token_hash = $1 OR new_token_hash = $1`,
+		tokenHash))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get email change: %w", err)
+	}
+	return change, nil
+}
+
+// ConfirmEmailChange records the confirmation of the old or the new address
+// and returns the change as stored afterwards.
+func (p *Postgres) ConfirmEmailChange(ctx context.Context, id string, oldAddress bool) (*models.EmailChange, error) {
+	column := "new_confirmed_at"
+	if oldAddress {
+		column = "old_confirmed_at"
+	}
+
+	change, err := scanEmailChange(p.db.QueryRowContext(ctx,
+		`UPDATE email_changes SET `+column+` = COALESCE(`+column+`, NOW())
+		WHERE id = $1
+		RETURNING `+emailChangeColumns,
+		id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to confirm email change: %w", err)
+	}
+	return change, nil
+}
+
+func (p *Postgres) DeleteEmailChange(ctx context.Context, id string) error {
+	_, err := p.db.ExecContext(ctx, `DELETE FROM email_changes WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete email change: %w", err)
+	}
+	return nil
+}
+
+const emailChangeColumns = `id, user_id, new_email, old_token_hash, new_token_hash,
+	old_confirmed_at, new_confirmed_at, expires_at, created_at`
+
+func scanEmailChange(row rowScanner) (*models.EmailChange, error) {
+	var change models.EmailChange
+	err := row.Scan(
+		&change.ID,
+		&change.UserID,
+		&change.NewEmail,
+		&change.OldTokenHash,
+		&change.NewTokenHash,
+		&change.OldConfirmedAt,
+		&change.NewConfirmedAt,
+		&change.ExpiresAt,
+		&change.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &change, nil
+}
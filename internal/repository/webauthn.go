@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/auth-service/internal/models"
+	"github.com/lib/pq"
+)
+
+func (p *Postgres) SaveWebAuthnCredential(ctx context.Context, credential *models.WebAuthnCredential) error {
+	err := p.db.QueryRowContext(ctx,
+		`INSERT INTO webauthn_credentials
+			(tenant_id, user_id, credential_id, public_key, attestation_type, aaguid, sign_count,
+			 transports, backup_eligible, backup_state)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id, created_at`,
+		credential.TenantID,
+		credential.UserID,
+		credential.CredentialID,
+		credential.PublicKey,
+		credential.AttestationType,
+		credential.AAGUID,
+		int64(credential.SignCount),
+		pq.Array(credential.Transports),
+		credential.BackupEligible,
+		credential.BackupState,
+	).Scan(&credential.ID, &credential.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save webauthn credential for user %s: %w", credential.UserID, err)
+	}
+	return nil
+}
+
+func (p *Postgres) GetWebAuthnCredentialsByUser(ctx context.Context, tenantID, userID string) ([]models.WebAuthnCredential, error) {
+	rows, err := p.db.QueryContext(ctx,
+		`SELECT id, tenant_id, user_id, credential_id, public_key, attestation_type, aaguid, sign_count,
+			transports, backup_eligible, backup_state, created_at, last_used_at
+		FROM webauthn_credentials
+		WHERE tenant_id = $1 AND user_id = $2`, tenantID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webauthn credentials: %w", err)
+	}
+	defer rows.Close()
+
+	var credentials []models.WebAuthnCredential
+	for rows.Next() {
+		var (
+			credential models.WebAuthnCredential
+			signCount  int64
+			lastUsedAt sql.NullTime
+		)
+		if err := rows.Scan(
+			&credential.ID,
+			&credential.TenantID,
+			&credential.UserID,
+			&credential.CredentialID,
+			&credential.PublicKey,
+			&credential.AttestationType,
+			&credential.AAGUID,
+			&signCount,
+			pq.Array(&credential.Transports),
+			&credential.BackupEligible,
+			&credential.BackupState,
+			&credential.CreatedAt,
+			&lastUsedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan webauthn credential: %w", err)
+		}
+		credential.SignCount = uint32(signCount)
+		if lastUsedAt.Valid {
+			credential.LastUsedAt = &lastUsedAt.Time
+		}
+		credentials = append(credentials, credential)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating webauthn credentials: %w", err)
+	}
+
+	return credentials, nil
+}
+
+func (p *Postgres) UpdateWebAuthnCredentialUsage(ctx context.Context, id string, signCount uint32, backupState bool) error {
+	_, err := p.db.ExecContext(ctx,
+		`UPDATE webauthn_credentials
+		SET sign_count = $2, backup_state = $3, last_used_at = NOW()
+		WHERE id = $1`,
+		id, int64(signCount), backupState)
+	if err != nil {
+		return fmt.Errorf("failed to update webauthn credential: %w", err)
+	}
+	return nil
+}
+
+func (p *Postgres) SaveWebAuthnSession(ctx context.Context, session *models.WebAuthnSession) error {
+	_, err := p.db.ExecContext(ctx,
+		`INSERT INTO webauthn_sessions (id, user_id, ceremony, data, expires_at)
+		VALUES ($1, $2, $3, $4, $5)`,
+		session.ID,
+		session.UserID,
+		session.Ceremony,
+		session.Data,
+		session.ExpiresAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save webauthn session: %w", err)
+	}
+	return nil
+}
+
+// TakeWebAuthnSession deletes and returns an unexpired ceremony session, so
+// every challenge can be answered only once.
+func (p *Postgres) TakeWebAuthnSession(ctx context.Context, id, ceremony string) (*models.WebAuthnSession, error) {
+	var session models.WebAuthnSession
+	err := p.db.QueryRowContext(ctx,
+		`DELETE FROM webauthn_sessions
+		WHERE id = $1 AND ceremony = $2 AND expires_at > NOW()
+		RETURNING id, user_id, ceremony, data, expires_at`,
+		id, ceremony).Scan(
+		&session.ID,
+		&session.UserID,
+		&session.Ceremony,
+		&session.Data,
+		&session.ExpiresAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get webauthn session: %w", err)
+	}
+	return &session, nil
+}
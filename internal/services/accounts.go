@@ -0,0 +1,169 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/auth-service/internal/models"
+	"github.com/auth-service/internal/repository"
+)
+
+const (
+	AuditDataExported      = "user.data_exported"
+	AuditDeletionScheduled = "user.deletion_scheduled"
+	AuditDeletionCancelled = "user.deletion_cancelled"
+	AuditUserPurged        = "user.purged"
+
+	DefaultAccountDeletionGrace = 30 * 24 * time.Hour
+	purgeBatchSize              = 100
+)
+
+var ErrNoDeletionScheduled = errors.New("account deletion is not scheduled")
+
+// AccountExport is the personal data the service holds about a user.
+type AccountExport struct {
+	ExportedAt     time.Time              `json:"exported_at"`
+	Profile        *models.User           `json:"profile"`
+	Sessions       []ExportedSession      `json:"sessions"`
+	SecurityAlerts []models.SecurityAlert `json:"security_alerts"`
+	AuditEvents    []models.AuditEvent    `json:"audit_events"`
+}
+
+// ExportedSession is a refresh token without its hash.
+type ExportedSession struct {
+	ID           string              `json:"id"`
+	IP           string              `json:"ip"`
+	LastIP       string              `json:"last_ip,omitempty"`
+	Location     *models.GeoLocation `json:"location,omitempty"`
+	LastLocation *models.GeoLocation `json:"last_location,omitempty"`
+	UserAgent    string              `json:"user_agent,omitempty"`
+	DeviceName   string              `json:"device_name,omitempty"`
+	ClientID     string              `json:"client_id,omitempty"`
+	CreatedAt    time.Time           `json:"created_at"`
+	ExpiresAt    time.Time           `json:"expires_at"`
+}
+
+// AccountService exports the data of an account and deletes accounts once
+// the grace period after the request has passed. Until then the user can log
+// in again and cancel the deletion.
+type AccountService struct {
+	repo  repository.Repository
+	audit *AuditLogger
+	grace time.Duration
+}
+
+func NewAccountService(repo repository.Repository, audit *AuditLogger, grace time.Duration) *AccountService {
+	if grace <= 0 {
+		grace = DefaultAccountDeletionGrace
+	}
+	return &AccountService{repo: repo, audit: audit, grace: grace}
+}
+
+func (s *AccountService) Export(ctx context.Context, userID string, ip net.IP) (*AccountExport, error) {
+	user, err := s.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	tokens, err := s.repo.GetRefreshTokensByUser(ctx, user.TenantID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sessions: %w", err)
+	}
+	alerts, err := s.repo.ListSecurityAlerts(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get security alerts: %w", err)
+	}
+	events, err := s.repo.ListAuditEvents(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get audit events: %w", err)
+	}
+
+	export := &AccountExport{
+		ExportedAt:     time.Now().UTC(),
+		Profile:        user,
+		Sessions:       make([]ExportedSession, 0, len(tokens)),
+		SecurityAlerts: alerts,
+		AuditEvents:    events,
+	}
+	for _, token := range tokens {
+		export.Sessions = append(export.Sessions, ExportedSession{
+			ID:           token.ID,
+			IP:           token.IP,
+			LastIP:       token.LastIP,
+			Location:     optionalLocation(token.Location),
+			LastLocation: optionalLocation(token.LastLocation),
+			UserAgent:    token.UserAgent,
+			DeviceName:   token.DeviceName,
+			ClientID:     token.ClientID,
+			CreatedAt:    token.CreatedAt,
+			ExpiresAt:    token.ExpiresAt,
+		})
+	}
+	if export.SecurityAlerts == nil {
+		export.SecurityAlerts = []models.SecurityAlert{}
+	}
+	if export.AuditEvents == nil {
+		export.AuditEvents = []models.AuditEvent{}
+	}
+
+	s.audit.Record(ctx, userID, AuditDataExported, ip, nil)
+	return export, nil
+}
+
+// ScheduleDeletion revokes every token of the user and schedules the
+// account to be purged after the grace period.
+func (s *AccountService) ScheduleDeletion(ctx context.Context, userID string, ip net.IP) (time.Time, error) {
+	deleteAfter := time.Now().Add(s.grace).UTC()
+	if err := s.repo.ScheduleUserDeletion(ctx, userID, deleteAfter); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return time.Time{}, ErrUserNotFound
+		}
+		return time.Time{}, fmt.Errorf("failed to schedule deletion: %w", err)
+	}
+	if err := s.repo.SetTokenCutoff(ctx, userID, tokenCutoff()); err != nil {
+		return time.Time{}, fmt.Errorf("failed to revoke access tokens: %w", err)
+	}
+
+	s.audit.Record(ctx, userID, AuditDeletionScheduled, ip, map[string]string{"delete_after": deleteAfter.Format(time.RFC3339)})
+	return deleteAfter, nil
+}
+
+func (s *AccountService) CancelDeletion(ctx context.Context, userID string, ip net.IP) error {
+	if err := s.repo.CancelUserDeletion(ctx, userID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrNoDeletionScheduled
+		}
+		return fmt.Errorf("failed to cancel deletion: %w", err)
+	}
+
+	s.audit.Record(ctx, userID, AuditDeletionCancelled, ip, nil)
+	return nil
+}
+
+// PurgeDue deletes the accounts whose grace period has ended and returns how
+// many were deleted.
+func (s *AccountService) PurgeDue(ctx context.Context) (int, error) {
+	purged := 0
+	for {
+		ids, err := s.repo.ListUsersDueForDeletion(ctx, time.Now(), purgeBatchSize)
+		if err != nil {
+			return purged, fmt.Errorf("failed to list users due for deletion: %w", err)
+		}
+		for _, id := range ids {
+			if err := s.repo.PurgeUser(ctx, id); err != nil {
+				return purged, fmt.Errorf("failed to purge user %s: %w", id, err)
+			}
+			purged++
+			s.audit.Record(ctx, repository.DeletedUserID, AuditUserPurged, nil, nil)
+		}
+		if len(ids) < purgeBatchSize {
+			return purged, nil
+		}
+	}
+}
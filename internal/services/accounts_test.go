@@ -0,0 +1,70 @@
+package services
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/auth-service/internal/models"
+	"github.com/auth-service/internal/repository"
+	"github.com/auth-service/internal/repository/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccountService(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	accountSvc := NewAccountService(mockRepo, NewAuditLogger(mockRepo), 24*time.Hour)
+	ctx := context.Background()
+	ip := net.ParseIP("192.168.1.1")
+
+	mockRepo.EXPECT().SaveAuditEvent(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	t.Run("Export leaves out token hashes", func(t *testing.T) {
+		mockRepo.EXPECT().GetUserByID(ctx, "user1").Return(&models.User{ID: "user1", TenantID: DefaultTenant}, nil)
+		mockRepo.EXPECT().GetRefreshTokensByUser(ctx, DefaultTenant, "user1").
+			Return([]models.RefreshToken{{ID: "token1", TokenHash: "hash", IP: "10.0.0.1"}}, nil)
+		mockRepo.EXPECT().ListSecurityAlerts(ctx, "user1").Return([]models.SecurityAlert{{Message: "IP changed"}}, nil)
+		mockRepo.EXPECT().ListAuditEvents(ctx, "user1").Return(nil, nil)
+
+		export, err := accountSvc.Export(ctx, "user1", ip)
+		require.NoError(t, err)
+		assert.Equal(t, []ExportedSession{{ID: "token1", IP: "10.0.0.1"}}, export.Sessions)
+		assert.Len(t, export.SecurityAlerts, 1)
+		assert.NotNil(t, export.AuditEvents)
+	})
+
+	t.Run("ScheduleDeletion revokes access tokens", func(t *testing.T) {
+		mockRepo.EXPECT().ScheduleUserDeletion(ctx, "user1", gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ string, deleteAfter time.Time) error {
+				assert.WithinDuration(t, time.Now().Add(24*time.Hour), deleteAfter, time.Minute)
+				return nil
+			})
+		mockRepo.EXPECT().SetTokenCutoff(ctx, "user1", gomock.Any()).Return(nil)
+
+		_, err := accountSvc.ScheduleDeletion(ctx, "user1", ip)
+		require.NoError(t, err)
+	})
+
+	t.Run("CancelDeletion without a scheduled deletion", func(t *testing.T) {
+		mockRepo.EXPECT().CancelUserDeletion(ctx, "user1").Return(repository.ErrNotFound)
+
+		err := accountSvc.CancelDeletion(ctx, "user1", ip)
+		assert.ErrorIs(t, err, ErrNoDeletionScheduled)
+	})
+
+	t.Run("PurgeDue purges every due account", func(t *testing.T) {
+		mockRepo.EXPECT().ListUsersDueForDeletion(ctx, gomock.Any(), purgeBatchSize).Return([]string{"user1", "user2"}, nil)
+		mockRepo.EXPECT().PurgeUser(ctx, "user1").Return(nil)
+		mockRepo.EXPECT().PurgeUser(ctx, "user2").Return(nil)
+
+		purged, err := accountSvc.PurgeDue(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 2, purged)
+	})
+}
@@ -0,0 +1,119 @@
+package services
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/auth-service/internal/models"
+	"github.com/auth-service/internal/repository"
+	"github.com/auth-service/internal/repository/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIKeyService(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	apiKeySvc := NewAPIKeyService(mockRepo, NewAuditLogger(mockRepo))
+	ctx := context.Background()
+	userIP := net.ParseIP("192.168.1.1")
+
+	mockRepo.EXPECT().SaveAuditEvent(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	var stored *models.APIKey
+	var rawKey string
+
+	t.Run("Create", func(t *testing.T) {
+		mockRepo.EXPECT().CreateAPIKey(ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, key *models.APIKey) error {
+				key.ID = "key1"
+				stored = key
+				return nil
+			})
+
+		key, raw, err := apiKeySvc.Create(ctx, "user1", " CI ", []string{"profile:read"}, nil, userIP)
+		require.NoError(t, err)
+		rawKey = raw
+
+		prefix, secret, ok := strings.Cut(raw, ".")
+		require.True(t, ok)
+		assert.Equal(t, key.Prefix, prefix)
+		assert.Equal(t, "CI", key.Name)
+		assert.Equal(t, hashAuthorizationCode(secret), key.SecretHash)
+		assert.NotContains(t, key.SecretHash, secret)
+	})
+
+	t.Run("Create rejects invalid requests", func(t *testing.T) {
+		past := time.Now().Add(-time.Hour)
+
+		_, _, err := apiKeySvc.Create(ctx, "user1", "", nil, nil, userIP)
+		assert.ErrorIs(t, err, ErrInvalidAPIKeyRequest)
+		_, _, err = apiKeySvc.Create(ctx, "user1", "CI", []string{"a b"}, nil, userIP)
+		assert.ErrorIs(t, err, ErrInvalidAPIKeyRequest)
+		_, _, err = apiKeySvc.Create(ctx, "user1", "CI", []string{"profile:write"}, nil, userIP)
+		assert.ErrorIs(t, err, ErrInvalidAPIKeyRequest)
+		_, _, err = apiKeySvc.Create(ctx, "user1", "CI", nil, &past, userIP)
+		assert.ErrorIs(t, err, ErrInvalidAPIKeyRequest)
+	})
+
+	t.Run("Authenticate records use", func(t *testing.T) {
+		mockRepo.EXPECT().GetAPIKeyByPrefix(ctx, stored.Prefix).Return(stored, nil)
+		mockRepo.EXPECT().TouchAPIKey(ctx, "key1", userIP.String(), gomock.Any()).Return(nil)
+
+		key, err := apiKeySvc.Authenticate(ctx, rawKey, userIP)
+		require.NoError(t, err)
+		assert.Equal(t, "user1", key.UserID)
+		assert.Equal(t, []string{"profile:read"}, key.Scopes)
+	})
+
+	t.Run("Recent use is not written again", func(t *testing.T) {
+		usedAt := time.Now().Add(-time.Second)
+		key := *stored
+		key.LastUsedAt = &usedAt
+		key.LastUsedIP = userIP.String()
+		mockRepo.EXPECT().GetAPIKeyByPrefix(ctx, stored.Prefix).Return(&key, nil)
+
+		_, err := apiKeySvc.Authenticate(ctx, rawKey, userIP)
+		require.NoError(t, err)
+	})
+
+	t.Run("Wrong secret", func(t *testing.T) {
+		mockRepo.EXPECT().GetAPIKeyByPrefix(ctx, stored.Prefix).Return(stored, nil)
+
+		_, err := apiKeySvc.Authenticate(ctx, stored.Prefix+".wrong", userIP)
+		assert.ErrorIs(t, err, ErrInvalidAPIKey)
+	})
+
+	t.Run("Expired key", func(t *testing.T) {
+		expiredAt := time.Now().Add(-time.Minute)
+		key := *stored
+		key.ExpiresAt = &expiredAt
+		mockRepo.EXPECT().GetAPIKeyByPrefix(ctx, stored.Prefix).Return(&key, nil)
+
+		_, err := apiKeySvc.Authenticate(ctx, rawKey, userIP)
+		assert.ErrorIs(t, err, ErrInvalidAPIKey)
+	})
+
+	t.Run("Unknown or malformed key", func(t *testing.T) {
+		mockRepo.EXPECT().GetAPIKeyByPrefix(ctx, "missing").Return(nil, repository.ErrNotFound)
+
+		_, err := apiKeySvc.Authenticate(ctx, "missing.secret", userIP)
+		assert.ErrorIs(t, err, ErrInvalidAPIKey)
+		_, err = apiKeySvc.Authenticate(ctx, "no-separator", userIP)
+		assert.ErrorIs(t, err, ErrInvalidAPIKey)
+	})
+
+	t.Run("Revoke", func(t *testing.T) {
+		mockRepo.EXPECT().DeleteAPIKey(ctx, "user1", "key1").Return(nil)
+		mockRepo.EXPECT().DeleteAPIKey(ctx, "user2", "key1").Return(repository.ErrNotFound)
+
+		assert.NoError(t, apiKeySvc.Revoke(ctx, "user1", "key1", userIP))
+		assert.ErrorIs(t, apiKeySvc.Revoke(ctx, "user2", "key1", userIP), ErrAPIKeyNotFound)
+	})
+}
@@ -0,0 +1,40 @@
+package services
+
+import (
+	"context"
+	"log"
+	"net"
+
+	"github.com/auth-service/internal/models"
+	"github.com/auth-service/internal/repository"
+)
+
+const (
+	AuditRecoveryCodesGenerated = "mfa.recovery_codes_generated"
+	AuditRecoveryCodeUsed       = "mfa.recovery_code_used"
+)
+
+// AuditLogger writes security-relevant events to the audit trail. A failed
+// write is logged but never fails the operation being audited.
+type AuditLogger struct {
+	repo repository.AuditRepository
+}
+
+func NewAuditLogger(repo repository.AuditRepository) *AuditLogger {
+	return &AuditLogger{repo: repo}
+}
+
+func (a *AuditLogger) Record(ctx context.Context, userID, eventType string, ip net.IP, details map[string]string) {
+	event := &models.AuditEvent{
+		UserID:  userID,
+		Type:    eventType,
+		Details: details,
+	}
+	if ip != nil {
+		event.IP = ip.String()
+	}
+
+	if err := a.repo.SaveAuditEvent(ctx, event); err != nil {
+		log.Printf("Failed to record audit event %s for user %s: %v", eventType, userID, err)
+	}
+}
@@ -17,24 +17,206 @@ type AuthService struct {
 	repo         repository.Repository
 	tokenService *TokenService
 	notifier     Notifier
+	lockout      *LockoutService
+	access       *RBACService
+	tenants      *Tenants
+	orgs         *OrganizationService
+	limits       SessionLimits
+	audit        *AuditLogger
+	idleTimeout  time.Duration
+	geo          *GeoIP
+	ipPolicy     IPChangePolicy
 }
 
-func NewAuthService(repo repository.Repository, tokenService *TokenService, notifier Notifier) *AuthService {
-	return &AuthService{
+type AuthOption func(*AuthService)
+
+// WithLockout makes RefreshTokens count failed attempts and refuse requests
+// from locked accounts and IPs.
+func WithLockout(lockout *LockoutService) AuthOption {
+	return func(s *AuthService) {
+		s.lockout = lockout
+	}
+}
+
+// WithAccess adds the roles and permissions assigned to the user in the
+// service to every access token, including refreshed ones.
+func WithAccess(access *RBACService) AuthOption {
+	return func(s *AuthService) {
+		s.access = access
+	}
+}
+
+// WithTenants makes token lifetimes fall back to the lifetimes configured for
+// the tenant of the request.
+func WithTenants(tenants *Tenants) AuthOption {
+	return func(s *AuthService) {
+		s.tenants = tenants
+	}
+}
+
+// WithOrganizations lets token pairs be issued for an organization of the
+// user. The organization is dropped once the user is no longer a member.
+func WithOrganizations(orgs *OrganizationService) AuthOption {
+	return func(s *AuthService) {
+		s.orgs = orgs
+	}
+}
+
+func NewAuthService(repo repository.Repository, tokenService *TokenService, notifier Notifier, opts ...AuthOption) *AuthService {
+	s := &AuthService{
 		repo:         repo,
 		tokenService: tokenService,
 		notifier:     notifier,
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
+// RevokeAllTokens revokes the refresh tokens the user has in the tenant of
+// the request.
 func (s *AuthService) RevokeAllTokens(ctx context.Context, userID string) error {
-	return s.repo.RevokeAllTokens(ctx, userID)
+	return s.repo.RevokeAllTokens(ctx, TenantFromContext(ctx), userID)
+}
+
+// AccessTokenRef is the access token a request was made with.
+type AccessTokenRef struct {
+	ID        string
+	SessionID string
+	ExpiresAt time.Time
+}
+
+// Logout ends the session of the access token: its refresh token is revoked
+// and the access token is denylisted until it expires. A session that is
+// already gone is not an error.
+func (s *AuthService) Logout(ctx context.Context, userID string, token AccessTokenRef) error {
+	if token.SessionID != "" {
+		session, err := s.repo.GetRefreshTokenByID(ctx, TenantFromContext(ctx), token.SessionID)
+		switch {
+		case err == nil && session.UserID == userID:
+			if err := s.repo.DeleteRefreshToken(ctx, session.ID); err != nil && !errors.Is(err, repository.ErrNotFound) {
+				return fmt.Errorf("failed to revoke session: %w", err)
+			}
+		case err != nil && !errors.Is(err, repository.ErrNotFound):
+			return fmt.Errorf("failed to get session: %w", err)
+		}
+	}
+	return s.revokeAccessToken(ctx, token)
+}
+
+// LogoutAll revokes every refresh token of the user and the access token of
+// the request. Other access tokens stay valid until they expire.
+func (s *AuthService) LogoutAll(ctx context.Context, userID string, token AccessTokenRef) error {
+	if err := s.RevokeAllTokens(ctx, userID); err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+	return s.revokeAccessToken(ctx, token)
+}
+
+func (s *AuthService) revokeAccessToken(ctx context.Context, token AccessTokenRef) error {
+	if token.ID == "" {
+		return nil
+	}
+	if err := s.repo.RevokeAccessToken(ctx, token.ID, token.ExpiresAt); err != nil {
+		return fmt.Errorf("failed to revoke access token: %w", err)
+	}
+	return nil
+}
+
+// TokenGrant describes a token pair to issue. ClientID and Scope are set when
+// the pair is issued to an OAuth client on behalf of the user. Roles are
+// copied into the access token and kept across refreshes.
+type TokenGrant struct {
+	UserID   string
+	ClientID string
+	Scope    string
+	Roles    []string
+	// OrgID is the organization to issue the pair for.
+	OrgID string
+	// ActorID is the admin acting as the user. Such pairs get no refresh
+	// token.
+	ActorID string
+	IP      net.IP
+	// Zero lifetimes fall back to the defaults.
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+	// MaxSessions caps the sessions the user can have with the client,
+	// zero means no cap.
+	MaxSessions int
+	// IdleTimeout overrides the idle timeout of the session.
+	IdleTimeout time.Duration
+	// IPChangePolicy overrides the IP change mode of the session.
+	IPChangePolicy IPChangeMode
 }
 
 func (s *AuthService) GenerateTokens(ctx context.Context, userID string, ip net.IP) (*models.TokenPair, error) {
-	accessToken, err := s.tokenService.GenerateAccessToken(userID, ip)
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate access token: %w", err)
+	return s.IssueTokens(ctx, TokenGrant{UserID: userID, IP: ip})
+}
+
+// IssueTokens issues a token pair in the tenant of the request.
+func (s *AuthService) IssueTokens(ctx context.Context, grant TokenGrant) (*models.TokenPair, error) {
+	return s.issue(ctx, grant, nil)
+}
+
+// issue issues a token pair for a new session, or for the session rotated
+// when it is set. The access token carries the ID of the session.
+func (s *AuthService) issue(ctx context.Context, grant TokenGrant, rotated *models.RefreshToken) (*models.TokenPair, error) {
+	tenantID := TenantFromContext(ctx)
+	accessTTL, refreshTTL := grant.AccessTokenTTL, grant.RefreshTokenTTL
+	if s.tenants != nil {
+		if tenant, ok := s.tenants.Get(tenantID); ok {
+			if accessTTL == 0 {
+				accessTTL = tenant.AccessTokenTTL
+			}
+			if refreshTTL == 0 {
+				refreshTTL = tenant.RefreshTokenTTL
+			}
+		}
+	}
+	if accessTTL == 0 {
+		accessTTL = DefaultAccessTokenTTL
+	}
+
+	claims := TokenClaims{
+		TenantID: tenantID,
+		UserID:   grant.UserID,
+		IP:       grant.IP.String(),
+		ClientID: grant.ClientID,
+		Scope:    grant.Scope,
+		Roles:    grant.Roles,
+	}
+	if grant.ActorID != "" {
+		claims.Actor = &ActorClaim{Subject: grant.ActorID}
+	}
+	// Assigned roles are looked up on every issue, only the roles of the
+	// authenticator are kept with the refresh token.
+	if s.access != nil && grant.UserID != "" {
+		access, err := s.access.Access(ctx, grant.UserID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get user access: %w", err)
+		}
+		claims.Roles = uniqueSorted(append(append([]string{}, grant.Roles...), access.Roles...))
+		claims.Permissions = access.Permissions
+	}
+	if s.orgs != nil && grant.OrgID != "" {
+		member, err := s.orgs.Membership(ctx, grant.OrgID, grant.UserID)
+		switch {
+		case err == nil:
+			claims.OrgID = member.OrgID
+			claims.OrgRole = member.Role
+			claims.Teams = member.Teams
+		case !errors.Is(err, ErrNotOrgMember):
+			return nil, fmt.Errorf("failed to get organization membership: %w", err)
+		}
+	}
+
+	if grant.ActorID != "" {
+		accessToken, err := s.tokenService.SignAccessToken(claims, accessTTL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate access token: %w", err)
+		}
+		return &models.TokenPair{AccessToken: accessToken, ExpiresIn: int(accessTTL.Seconds())}, nil
 	}
 
 	refreshToken, err := s.tokenService.GenerateRefreshToken()
@@ -47,9 +229,66 @@ func (s *AuthService) GenerateTokens(ctx context.Context, userID string, ip net.
 		return nil, fmt.Errorf("failed to hash refresh token: %w", err)
 	}
 
-	err = s.repo.SaveRefreshToken(ctx, userID, string(hashedToken), ip.String())
+	userAgent := UserAgentFromContext(ctx)
+	device := ParseUserAgent(userAgent)
+	location := s.locate(grant.IP)
+	stored := &models.RefreshToken{
+		TenantID:        tenantID,
+		UserID:          grant.UserID,
+		TokenHash:       string(hashedToken),
+		IP:              grant.IP.String(),
+		LastIP:          grant.IP.String(),
+		Location:        location,
+		LastLocation:    location,
+		UserAgent:       userAgent,
+		DeviceName:      DeviceNameFromContext(ctx),
+		Device:          device.Device,
+		OS:              device.OS,
+		Browser:         device.Browser,
+		ClientID:        grant.ClientID,
+		Scope:           grant.Scope,
+		Roles:           grant.Roles,
+		OrgID:           claims.OrgID,
+		IdleTimeout:     grant.IdleTimeout,
+		IPChangePolicy:  string(grant.IPChangePolicy),
+		AccessTokenTTL:  grant.AccessTokenTTL,
+		RefreshTokenTTL: grant.RefreshTokenTTL,
+	}
+	if refreshTTL > 0 {
+		stored.ExpiresAt = time.Now().Add(refreshTTL)
+	}
+	if rotated != nil {
+		// The session keeps the device it was opened on, only the last
+		// IP and its location follow the refreshes.
+		stored.ID = rotated.ID
+		stored.IP = rotated.IP
+		stored.Location = rotated.Location
+		stored.UserAgent = rotated.UserAgent
+		stored.DeviceName = rotated.DeviceName
+		stored.Device = rotated.Device
+		stored.OS = rotated.OS
+		stored.Browser = rotated.Browser
+		stored.IdleTimeout = rotated.IdleTimeout
+		stored.IPChangePolicy = rotated.IPChangePolicy
+		if err := s.repo.RotateRefreshToken(ctx, rotated.TokenHash, stored); err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return nil, errors.New("refresh token not found in DB")
+			}
+			return nil, fmt.Errorf("failed to rotate refresh token: %w", err)
+		}
+	} else {
+		if err := s.enforceSessionLimits(ctx, grant); err != nil {
+			return nil, err
+		}
+		if err := s.repo.SaveRefreshToken(ctx, stored); err != nil {
+			return nil, fmt.Errorf("failed to save refresh token: %w", err)
+		}
+	}
+
+	claims.SessionID = stored.ID
+	accessToken, err := s.tokenService.SignAccessToken(claims, accessTTL)
 	if err != nil {
-		return nil, fmt.Errorf("failed to save refresh token: %w", err)
+		return nil, fmt.Errorf("failed to generate access token: %w", err)
 	}
 
 	return &models.TokenPair{
@@ -63,7 +302,13 @@ func (s *AuthService) RefreshTokens(ctx context.Context, userID, refreshToken st
 		return nil, errors.New("empty refresh token")
 	}
 
-	tokens, err := s.repo.GetRefreshTokensByUser(ctx, userID)
+	if s.lockout != nil {
+		if err := s.lockout.Check(ctx, userID, clientIP); err != nil {
+			return nil, err
+		}
+	}
+
+	tokens, err := s.repo.GetRefreshTokensByUser(ctx, TenantFromContext(ctx), userID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user tokens: %w", err)
 	}
@@ -77,17 +322,12 @@ func (s *AuthService) RefreshTokens(ctx context.Context, userID, refreshToken st
 	}
 
 	if storedToken == nil {
+		if s.lockout != nil {
+			s.lockout.RegisterFailure(ctx, AttemptRefresh, userID, clientIP)
+		}
 		return nil, errors.New("refresh token not found in DB")
 	}
 
-	if storedToken.IP != clientIP.String() {
-		msg := fmt.Sprintf("Обнаружена смена IP адреса для пользователя %s. Старый IP: %s, Новый IP: %s",
-			userID, storedToken.IP, clientIP.String())
-		s.notifier.SendSecurityAlert(userID, msg)
-
-		log.Printf("SECURITY WARNING: %s", msg)
-	}
-
 	if time.Now().After(storedToken.ExpiresAt) {
 		if err := s.repo.DeleteRefreshToken(ctx, storedToken.ID); err != nil {
 			log.Printf(
@@ -100,13 +340,114 @@ func (s *AuthService) RefreshTokens(ctx context.Context, userID, refreshToken st
 		return nil, errors.New("refresh token expired")
 	}
 
-	if storedToken.IP != clientIP.String() {
-		log.Printf("SECURITY WARNING: IP changed from %s to %s", storedToken.IP, clientIP.String())
+	if s.isIdle(storedToken, time.Now()) {
+		if err := s.repo.DeleteRefreshToken(ctx, storedToken.ID); err != nil {
+			log.Printf("Failed to delete idle session %s of user %s: %v", storedToken.ID, userID, err)
+		}
+		return nil, ErrSessionIdleTimeout
+	}
+
+	mode := s.checkIPChange(ctx, userID, storedToken, clientIP)
+	if mode == IPChangeStepUp || mode == IPChangeDeny {
+		return nil, s.refuseIPChange(ctx, userID, storedToken, clientIP, mode)
+	}
+	s.alertSessionChange(ctx, userID, storedToken, clientIP, mode == IPChangeNotify)
+
+	if err := s.checkSessionClient(ctx, storedToken); err != nil {
+		return nil, err
+	}
+
+	return s.issue(ctx, TokenGrant{
+		UserID:          userID,
+		ClientID:        storedToken.ClientID,
+		Scope:           storedToken.Scope,
+		Roles:           storedToken.Roles,
+		OrgID:           storedToken.OrgID,
+		IP:              clientIP,
+		AccessTokenTTL:  storedToken.AccessTokenTTL,
+		RefreshTokenTTL: storedToken.RefreshTokenTTL,
+	}, storedToken)
+}
+
+// checkSessionClient refuses sessions of OAuth clients that were disabled or
+// deleted since the session was opened.
+func (s *AuthService) checkSessionClient(ctx context.Context, session *models.RefreshToken) error {
+	if session.ClientID == "" {
+		return nil
+	}
+	client, err := s.repo.GetClient(ctx, session.ClientID)
+	if errors.Is(err, repository.ErrNotFound) || (err == nil && client.DisabledAt != nil) {
+		return ErrSessionClientDisabled
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get client: %w", err)
 	}
+	return nil
+}
 
-	if err := s.repo.DeleteRefreshToken(ctx, storedToken.ID); err != nil {
-		return nil, fmt.Errorf("failed to delete old token: %w", err)
+// userSession returns the unexpired session of the user with the ID, or
+// ErrSessionNotFound.
+func (s *AuthService) userSession(ctx context.Context, userID, sessionID string) (*models.RefreshToken, error) {
+	if sessionID == "" {
+		return nil, ErrSessionNotFound
+	}
+	session, err := s.repo.GetRefreshTokenByID(ctx, TenantFromContext(ctx), sessionID)
+	if errors.Is(err, repository.ErrNotFound) || (err == nil && session.UserID != userID) {
+		return nil, ErrSessionNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+	if time.Now().After(session.ExpiresAt) {
+		return nil, ErrSessionNotFound
 	}
+	return session, nil
+}
 
-	return s.GenerateTokens(ctx, userID, clientIP)
+// SwitchOrganization rotates the session of the request into a pair for the
+// organization. The session keeps its client, scope, roles and lifetimes.
+func (s *AuthService) SwitchOrganization(ctx context.Context, userID, sessionID, orgID string, clientIP net.IP) (*models.TokenPair, error) {
+	session, err := s.userSession(ctx, userID, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkSessionClient(ctx, session); err != nil {
+		return nil, err
+	}
+
+	pair, err := s.issue(ctx, TokenGrant{
+		UserID:          userID,
+		ClientID:        session.ClientID,
+		Scope:           session.Scope,
+		Roles:           session.Roles,
+		OrgID:           orgID,
+		IP:              clientIP,
+		AccessTokenTTL:  session.AccessTokenTTL,
+		RefreshTokenTTL: session.RefreshTokenTTL,
+	}, session)
+	if err != nil && err.Error() == "refresh token not found in DB" {
+		return nil, ErrSessionNotFound
+	}
+	return pair, err
+}
+
+// alertSessionChange tells the user when a session is refreshed from
+// another browser than it was last used from, or from another IP when
+// ipChanged is set by the IP change policy.
+func (s *AuthService) alertSessionChange(ctx context.Context, userID string, token *models.RefreshToken, clientIP net.IP, ipChanged bool) {
+	device := ParseUserAgent(UserAgentFromContext(ctx))
+	deviceChanged := device.Browser != "" && token.Browser != "" &&
+		(device.Browser != token.Browser || device.OS != token.OS)
+	if !ipChanged && !deviceChanged {
+		return
+	}
+
+	msg := fmt.Sprintf("Новый вход в аккаунт: %s, IP %s", device, formatIP(clientIP.String(), s.locate(clientIP)))
+	if ipChanged {
+		msg += fmt.Sprintf(", предыдущий IP %s", formatIP(lastIPOf(*token), lastLocationOf(*token)))
+	}
+	if token.DeviceName != "" {
+		msg += fmt.Sprintf(". Сессия устройства «%s»", token.DeviceName)
+	}
+	s.notifier.SendSecurityAlert(userID, msg)
 }
@@ -2,6 +2,7 @@ package services
 
 import (
 	"context"
+	"fmt"
 	"net"
 	"testing"
 	"time"
@@ -15,6 +16,25 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
+// refreshTokenFor matches a refresh token saved for the given user and IP.
+type refreshTokenMatcher struct {
+	userID string
+	ip     string
+}
+
+func refreshTokenFor(userID, ip string) gomock.Matcher {
+	return refreshTokenMatcher{userID: userID, ip: ip}
+}
+
+func (m refreshTokenMatcher) Matches(x interface{}) bool {
+	token, ok := x.(*models.RefreshToken)
+	return ok && token.UserID == m.userID && token.IP == m.ip && token.TokenHash != ""
+}
+
+func (m refreshTokenMatcher) String() string {
+	return fmt.Sprintf("refresh token for user %s from %s", m.userID, m.ip)
+}
+
 func TestAuthService(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -29,7 +49,7 @@ func TestAuthService(t *testing.T) {
 	t.Run("GenerateTokens", func(t *testing.T) {
 		t.Run("Success", func(t *testing.T) {
 			mockRepo.EXPECT().
-				SaveRefreshToken(gomock.Any(), "user1", gomock.Any(), userIP.String()).
+				SaveRefreshToken(gomock.Any(), refreshTokenFor("user1", userIP.String())).
 				Return(nil)
 
 			pair, err := authSvc.GenerateTokens(ctx, "user1", userIP)
@@ -38,14 +58,119 @@ func TestAuthService(t *testing.T) {
 			assert.NotEmpty(t, pair.RefreshToken)
 		})
 
+		t.Run("Stores the user agent and session", func(t *testing.T) {
+			mockRepo.EXPECT().
+				SaveRefreshToken(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, token *models.RefreshToken) error {
+					assert.Equal(t, "curl/8.0", token.UserAgent)
+					assert.Equal(t, "curl", token.Browser)
+					assert.Equal(t, "build server", token.DeviceName)
+					token.ID = "session1"
+					return nil
+				})
+
+			pair, err := authSvc.GenerateTokens(WithDeviceName(WithUserAgent(ctx, "curl/8.0"), " build server "), "user1", userIP)
+			require.NoError(t, err)
+
+			claims, err := tokenSvc.ParseAccessToken(pair.AccessToken)
+			require.NoError(t, err)
+			assert.Equal(t, "session1", claims.SessionID)
+		})
+
 		t.Run("Database error", func(t *testing.T) {
 			mockRepo.EXPECT().
-				SaveRefreshToken(gomock.Any(), "user1", gomock.Any(), userIP.String()).
+				SaveRefreshToken(gomock.Any(), refreshTokenFor("user1", userIP.String())).
 				Return(repository.ErrDatabase)
 
 			_, err := authSvc.GenerateTokens(ctx, "user1", userIP)
 			assert.ErrorIs(t, err, repository.ErrDatabase)
 		})
+
+		t.Run("Tenant of the request", func(t *testing.T) {
+			tenants := NewTenants([]Tenant{{ID: "acme", AccessTokenTTL: 5 * time.Minute, RefreshTokenTTL: time.Hour}}, mockRepo)
+			withTenants := NewAuthService(mockRepo, tokenSvc, mockNotifier, WithTenants(tenants))
+			acme := WithTenant(ctx, "acme")
+
+			mockRepo.EXPECT().
+				SaveRefreshToken(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, token *models.RefreshToken) error {
+					assert.Equal(t, "acme", token.TenantID)
+					assert.WithinDuration(t, time.Now().Add(time.Hour), token.ExpiresAt, time.Minute)
+					return nil
+				})
+
+			pair, err := withTenants.GenerateTokens(acme, "user1", userIP)
+			require.NoError(t, err)
+
+			claims, err := tokenSvc.ParseAccessToken(pair.AccessToken)
+			require.NoError(t, err)
+			assert.Equal(t, "acme", claims.TenantID)
+			assert.WithinDuration(t, time.Now().Add(5*time.Minute), claims.ExpiresAt.Time, time.Minute)
+		})
+
+		t.Run("Embeds assigned roles", func(t *testing.T) {
+			rbacSvc := NewRBACService(mockRepo, NewAuditLogger(mockRepo), nil)
+			withAccess := NewAuthService(mockRepo, tokenSvc, mockNotifier, WithAccess(rbacSvc))
+
+			mockRepo.EXPECT().GetUserRoles(ctx, "user1").
+				Return([]models.Role{{Name: "support", Permissions: []string{"users:read"}}}, nil)
+			mockRepo.EXPECT().GetTokenCutoff(ctx, "user1").Return(time.Time{}, nil)
+			mockRepo.EXPECT().
+				SaveRefreshToken(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, token *models.RefreshToken) error {
+					assert.Equal(t, []string{"developer"}, token.Roles)
+					return nil
+				})
+
+			pair, err := withAccess.IssueTokens(ctx, TokenGrant{UserID: "user1", Roles: []string{"developer"}, IP: userIP})
+			require.NoError(t, err)
+
+			claims, err := tokenSvc.ParseAccessToken(pair.AccessToken)
+			require.NoError(t, err)
+			assert.Equal(t, []string{"developer", "support"}, claims.Roles)
+			assert.Equal(t, []string{"users:read"}, claims.Permissions)
+			assert.NotNil(t, claims.IssuedAt)
+		})
+
+		t.Run("Organization membership", func(t *testing.T) {
+			orgSvc := NewOrganizationService(mockRepo, mockNotifier, NewAuditLogger(mockRepo))
+			withOrgs := NewAuthService(mockRepo, tokenSvc, mockNotifier, WithOrganizations(orgSvc))
+
+			mockRepo.EXPECT().GetOrganization(ctx, DefaultTenant, "org1").Return(&models.Organization{ID: "org1"}, nil)
+			mockRepo.EXPECT().GetOrganizationMember(ctx, "org1", "user1").
+				Return(&models.OrganizationMember{OrgID: "org1", UserID: "user1", Role: OrgRoleAdmin, Teams: []string{"team1"}}, nil)
+			mockRepo.EXPECT().
+				SaveRefreshToken(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, token *models.RefreshToken) error {
+					assert.Equal(t, "org1", token.OrgID)
+					return nil
+				})
+
+			pair, err := withOrgs.IssueTokens(ctx, TokenGrant{UserID: "user1", OrgID: "org1", IP: userIP})
+			require.NoError(t, err)
+
+			claims, err := tokenSvc.ParseAccessToken(pair.AccessToken)
+			require.NoError(t, err)
+			assert.Equal(t, "org1", claims.OrgID)
+			assert.Equal(t, OrgRoleAdmin, claims.OrgRole)
+			assert.Equal(t, []string{"team1"}, claims.Teams)
+
+			// A user removed from the organization gets a pair without it.
+			mockRepo.EXPECT().GetOrganization(ctx, DefaultTenant, "org1").Return(&models.Organization{ID: "org1"}, nil)
+			mockRepo.EXPECT().GetOrganizationMember(ctx, "org1", "user1").Return(nil, repository.ErrNotFound)
+			mockRepo.EXPECT().
+				SaveRefreshToken(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, token *models.RefreshToken) error {
+					assert.Empty(t, token.OrgID)
+					return nil
+				})
+
+			pair, err = withOrgs.IssueTokens(ctx, TokenGrant{UserID: "user1", OrgID: "org1", IP: userIP})
+			require.NoError(t, err)
+			claims, err = tokenSvc.ParseAccessToken(pair.AccessToken)
+			require.NoError(t, err)
+			assert.Empty(t, claims.OrgID)
+		})
 	})
 
 	t.Run("RefreshTokens", func(t *testing.T) {
@@ -61,20 +186,135 @@ func TestAuthService(t *testing.T) {
 
 		t.Run("Valid refresh", func(t *testing.T) {
 			mockRepo.EXPECT().
-				GetRefreshTokensByUser(ctx, "user1").
+				GetRefreshTokensByUser(ctx, DefaultTenant, "user1").
 				Return([]models.RefreshToken{storedToken}, nil)
 
 			mockRepo.EXPECT().
-				DeleteRefreshToken(ctx, "token-id").
-				Return(nil)
+				RotateRefreshToken(gomock.Any(), string(hashedToken), refreshTokenFor("user1", userIP.String())).
+				DoAndReturn(func(_ context.Context, _ string, token *models.RefreshToken) error {
+					assert.Equal(t, "token-id", token.ID)
+					return nil
+				})
+
+			pair, err := authSvc.RefreshTokens(ctx, "user1", refreshToken, userIP)
+			require.NoError(t, err)
+			assert.NotEmpty(t, pair.AccessToken)
+
+			claims, err := tokenSvc.ParseAccessToken(pair.AccessToken)
+			require.NoError(t, err)
+			assert.Equal(t, "token-id", claims.SessionID)
+		})
+
+		t.Run("Token rotated concurrently", func(t *testing.T) {
+			mockRepo.EXPECT().
+				GetRefreshTokensByUser(ctx, DefaultTenant, "user1").
+				Return([]models.RefreshToken{storedToken}, nil)
+			mockRepo.EXPECT().
+				RotateRefreshToken(gomock.Any(), string(hashedToken), gomock.Any()).
+				Return(repository.ErrNotFound)
+
+			_, err := authSvc.RefreshTokens(ctx, "user1", refreshToken, userIP)
+			assert.Error(t, err)
+		})
+
+		t.Run("Refresh from a new IP alerts with the device", func(t *testing.T) {
+			laptopToken := storedToken
+			laptopToken.LastIP = "10.0.0.5"
+			laptopToken.DeviceName = "Work laptop"
+			laptopToken.Browser = "Chrome"
+			laptopToken.OS = "Linux"
+			uaCtx := WithUserAgent(ctx, "Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36")
 
 			mockRepo.EXPECT().
-				SaveRefreshToken(gomock.Any(), "user1", gomock.Any(), userIP.String()).
+				GetRefreshTokensByUser(uaCtx, DefaultTenant, "user1").
+				Return([]models.RefreshToken{laptopToken}, nil)
+			mockNotifier.EXPECT().
+				SendSecurityAlert("user1", gomock.Any()).
+				Do(func(_ string, msg string) {
+					assert.Contains(t, msg, "Chrome на Linux")
+					assert.Contains(t, msg, "предыдущий IP 10.0.0.5")
+				})
+			mockRepo.EXPECT().
+				RotateRefreshToken(gomock.Any(), string(hashedToken), gomock.Any()).
+				DoAndReturn(func(_ context.Context, _ string, token *models.RefreshToken) error {
+					assert.Equal(t, userIP.String(), token.IP)
+					assert.Equal(t, userIP.String(), token.LastIP)
+					assert.Equal(t, "Work laptop", token.DeviceName)
+					return nil
+				})
+
+			_, err := authSvc.RefreshTokens(uaCtx, "user1", refreshToken, userIP)
+			require.NoError(t, err)
+		})
+
+		t.Run("Refresh from another browser alerts", func(t *testing.T) {
+			firefoxToken := storedToken
+			firefoxToken.Browser = "Firefox"
+			firefoxToken.OS = "Windows"
+			uaCtx := WithUserAgent(ctx, "Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36")
+
+			mockRepo.EXPECT().
+				GetRefreshTokensByUser(uaCtx, DefaultTenant, "user1").
+				Return([]models.RefreshToken{firefoxToken}, nil)
+			mockNotifier.EXPECT().
+				SendSecurityAlert("user1", gomock.Any()).
+				Do(func(_ string, msg string) {
+					assert.Contains(t, msg, "Chrome на Linux")
+					assert.NotContains(t, msg, "предыдущий IP")
+				})
+			mockRepo.EXPECT().
+				RotateRefreshToken(gomock.Any(), string(hashedToken), gomock.Any()).
 				Return(nil)
 
+			_, err := authSvc.RefreshTokens(uaCtx, "user1", refreshToken, userIP)
+			require.NoError(t, err)
+		})
+
+		t.Run("Refresh keeps client binding", func(t *testing.T) {
+			clientToken := storedToken
+			clientToken.ClientID = "spa"
+			clientToken.Scope = "openid profile"
+			clientToken.Roles = []string{"admin"}
+			clientToken.AccessTokenTTL = 5 * time.Minute
+			clientToken.RefreshTokenTTL = 24 * time.Hour
+
+			mockRepo.EXPECT().
+				GetRefreshTokensByUser(ctx, DefaultTenant, "user1").
+				Return([]models.RefreshToken{clientToken}, nil)
+			mockRepo.EXPECT().GetClient(ctx, "spa").Return(&models.OAuthClient{ID: "spa"}, nil)
+			mockRepo.EXPECT().
+				RotateRefreshToken(gomock.Any(), string(hashedToken), refreshTokenFor("user1", userIP.String())).
+				DoAndReturn(func(_ context.Context, _ string, token *models.RefreshToken) error {
+					assert.WithinDuration(t, time.Now().Add(24*time.Hour), token.ExpiresAt, time.Minute)
+					assert.Equal(t, "spa", token.ClientID)
+					assert.Equal(t, "openid profile", token.Scope)
+					assert.Equal(t, []string{"admin"}, token.Roles)
+					return nil
+				})
+
 			pair, err := authSvc.RefreshTokens(ctx, "user1", refreshToken, userIP)
 			require.NoError(t, err)
-			assert.NotEmpty(t, pair.AccessToken)
+
+			claims, err := tokenSvc.ParseAccessToken(pair.AccessToken)
+			require.NoError(t, err)
+			assert.Equal(t, "spa", claims.ClientID)
+			assert.Equal(t, "openid profile", claims.Scope)
+			assert.Equal(t, []string{"admin"}, claims.Roles)
+			assert.WithinDuration(t, time.Now().Add(5*time.Minute), claims.ExpiresAt.Time, time.Minute)
+		})
+
+		t.Run("Session of a disabled client", func(t *testing.T) {
+			clientToken := storedToken
+			clientToken.ClientID = "spa"
+			disabledAt := time.Now()
+
+			mockRepo.EXPECT().
+				GetRefreshTokensByUser(ctx, DefaultTenant, "user1").
+				Return([]models.RefreshToken{clientToken}, nil)
+			mockRepo.EXPECT().GetClient(ctx, "spa").Return(&models.OAuthClient{ID: "spa", DisabledAt: &disabledAt}, nil)
+
+			_, err := authSvc.RefreshTokens(ctx, "user1", refreshToken, userIP)
+			assert.ErrorIs(t, err, ErrSessionClientDisabled)
 		})
 
 		t.Run("Expired token", func(t *testing.T) {
@@ -82,7 +322,7 @@ func TestAuthService(t *testing.T) {
 			expiredToken.ExpiresAt = time.Now().Add(-1 * time.Hour)
 
 			mockRepo.EXPECT().
-				GetRefreshTokensByUser(ctx, "user1").
+				GetRefreshTokensByUser(ctx, DefaultTenant, "user1").
 				Return([]models.RefreshToken{expiredToken}, nil)
 
 			mockRepo.EXPECT().
@@ -92,5 +332,114 @@ func TestAuthService(t *testing.T) {
 			_, err := authSvc.RefreshTokens(ctx, "user1", refreshToken, userIP)
 			assert.ErrorContains(t, err, "expired")
 		})
+
+		t.Run("Unknown token counts as failed attempt", func(t *testing.T) {
+			lockout := NewLockoutService(mockRepo, LockoutPolicy{LockThreshold: 10, Window: time.Hour}, NewAuditLogger(mockRepo), mockNotifier)
+			guardedSvc := NewAuthService(mockRepo, tokenSvc, mockNotifier, WithLockout(lockout))
+
+			mockRepo.EXPECT().
+				GetAuthFailure(ctx, gomock.Any(), gomock.Any()).
+				Return(&models.AuthFailure{}, nil).
+				Times(2)
+			mockRepo.EXPECT().
+				GetRefreshTokensByUser(ctx, DefaultTenant, "user1").
+				Return([]models.RefreshToken{storedToken}, nil)
+			mockRepo.EXPECT().
+				RecordAuthFailure(ctx, gomock.Any(), gomock.Any(), time.Hour).
+				Return(&models.AuthFailure{Failures: 1}, nil).
+				Times(2)
+
+			_, err := guardedSvc.RefreshTokens(ctx, "user1", "guessed-token", userIP)
+			assert.ErrorContains(t, err, "not found")
+		})
+	})
+	t.Run("SwitchOrganization", func(t *testing.T) {
+		orgSvc := NewOrganizationService(mockRepo, mockNotifier, NewAuditLogger(mockRepo))
+		withOrgs := NewAuthService(mockRepo, tokenSvc, mockNotifier, WithOrganizations(orgSvc))
+		session := &models.RefreshToken{
+			ID:              "session1",
+			UserID:          "user1",
+			TokenHash:       "old-hash",
+			ClientID:        "spa",
+			Scope:           "openid profile",
+			AccessTokenTTL:  5 * time.Minute,
+			RefreshTokenTTL: 24 * time.Hour,
+			ExpiresAt:       time.Now().Add(time.Hour),
+		}
+
+		t.Run("Rotates the session of the request", func(t *testing.T) {
+			mockRepo.EXPECT().GetRefreshTokenByID(ctx, DefaultTenant, "session1").Return(session, nil)
+			mockRepo.EXPECT().GetClient(ctx, "spa").Return(&models.OAuthClient{ID: "spa"}, nil)
+			mockRepo.EXPECT().GetOrganization(ctx, DefaultTenant, "org1").Return(&models.Organization{ID: "org1"}, nil)
+			mockRepo.EXPECT().GetOrganizationMember(ctx, "org1", "user1").
+				Return(&models.OrganizationMember{OrgID: "org1", UserID: "user1", Role: OrgRoleMember}, nil)
+			mockRepo.EXPECT().
+				RotateRefreshToken(gomock.Any(), "old-hash", gomock.Any()).
+				DoAndReturn(func(_ context.Context, _ string, token *models.RefreshToken) error {
+					assert.Equal(t, "session1", token.ID)
+					assert.Equal(t, "spa", token.ClientID)
+					assert.Equal(t, "org1", token.OrgID)
+					assert.WithinDuration(t, time.Now().Add(24*time.Hour), token.ExpiresAt, time.Minute)
+					return nil
+				})
+
+			pair, err := withOrgs.SwitchOrganization(ctx, "user1", "session1", "org1", userIP)
+			require.NoError(t, err)
+
+			claims, err := tokenSvc.ParseAccessToken(pair.AccessToken)
+			require.NoError(t, err)
+			assert.Equal(t, "session1", claims.SessionID)
+			assert.Equal(t, "org1", claims.OrgID)
+			assert.Equal(t, "spa", claims.ClientID)
+			assert.Equal(t, "openid profile", claims.Scope)
+			assert.WithinDuration(t, time.Now().Add(5*time.Minute), claims.ExpiresAt.Time, time.Minute)
+		})
+
+		t.Run("Session of another user", func(t *testing.T) {
+			mockRepo.EXPECT().GetRefreshTokenByID(ctx, DefaultTenant, "session1").Return(session, nil)
+
+			_, err := withOrgs.SwitchOrganization(ctx, "user2", "session1", "org1", userIP)
+			assert.ErrorIs(t, err, ErrSessionNotFound)
+		})
+	})
+
+	t.Run("Logout", func(t *testing.T) {
+		expiresAt := time.Now().Add(time.Minute)
+		token := AccessTokenRef{ID: "jti1", SessionID: "session1", ExpiresAt: expiresAt}
+
+		t.Run("Revokes the session and the access token", func(t *testing.T) {
+			mockRepo.EXPECT().
+				GetRefreshTokenByID(ctx, DefaultTenant, "session1").
+				Return(&models.RefreshToken{ID: "session1", UserID: "user1"}, nil)
+			mockRepo.EXPECT().DeleteRefreshToken(ctx, "session1").Return(nil)
+			mockRepo.EXPECT().RevokeAccessToken(ctx, "jti1", expiresAt).Return(nil)
+
+			require.NoError(t, authSvc.Logout(ctx, "user1", token))
+		})
+
+		t.Run("Session already gone", func(t *testing.T) {
+			mockRepo.EXPECT().
+				GetRefreshTokenByID(ctx, DefaultTenant, "session1").
+				Return(nil, repository.ErrNotFound)
+			mockRepo.EXPECT().RevokeAccessToken(ctx, "jti1", expiresAt).Return(nil)
+
+			require.NoError(t, authSvc.Logout(ctx, "user1", token))
+		})
+
+		t.Run("Session of another user is kept", func(t *testing.T) {
+			mockRepo.EXPECT().
+				GetRefreshTokenByID(ctx, DefaultTenant, "session1").
+				Return(&models.RefreshToken{ID: "session1", UserID: "user2"}, nil)
+			mockRepo.EXPECT().RevokeAccessToken(ctx, "jti1", expiresAt).Return(nil)
+
+			require.NoError(t, authSvc.Logout(ctx, "user1", token))
+		})
+
+		t.Run("Everywhere", func(t *testing.T) {
+			mockRepo.EXPECT().RevokeAllTokens(ctx, DefaultTenant, "user1").Return(nil)
+			mockRepo.EXPECT().RevokeAccessToken(ctx, "jti1", expiresAt).Return(nil)
+
+			require.NoError(t, authSvc.LogoutAll(ctx, "user1", token))
+		})
 	})
 }
@@ -0,0 +1,82 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/auth-service/internal/repository"
+)
+
+const DefaultCleanupBatchSize = 500
+
+var errJobPanicked = errors.New("job panicked")
+
+// CleanupService deletes expired tokens and stale one-time codes, batchSize
+// rows per query so large backlogs do not hold long locks.
+type CleanupService struct {
+	repo      repository.CleanupRepository
+	batchSize int
+}
+
+func NewCleanupService(repo repository.CleanupRepository, batchSize int) *CleanupService {
+	if batchSize <= 0 {
+		batchSize = DefaultCleanupBatchSize
+	}
+	return &CleanupService{repo: repo, batchSize: batchSize}
+}
+
+// PurgeExpiredTokens deletes expired refresh tokens and the denylist entries
+// of expired access tokens.
+func (s *CleanupService) PurgeExpiredTokens(ctx context.Context) (int, error) {
+	return s.purgeAll(ctx,
+		s.repo.DeleteExpiredRefreshTokens,
+		s.repo.DeleteExpiredRevokedTokens,
+	)
+}
+
+// PurgeStaleCodes deletes one-time codes and ceremonies that expired or were
+// used: magic link codes, authorization and device codes, WebAuthn sessions
+// and email change confirmations.
+func (s *CleanupService) PurgeStaleCodes(ctx context.Context) (int, error) {
+	return s.purgeAll(ctx,
+		s.repo.DeleteStaleLoginCodes,
+		s.repo.DeleteExpiredAuthorizationCodes,
+		s.repo.DeleteExpiredDeviceCodes,
+		s.repo.DeleteExpiredWebAuthnSessions,
+		s.repo.DeleteExpiredEmailChanges,
+	)
+}
+
+type deleteBatchFunc func(ctx context.Context, now time.Time, limit int) (int, error)
+
+// purgeAll runs every purge even when one of them fails.
+func (s *CleanupService) purgeAll(ctx context.Context, purges ...deleteBatchFunc) (int, error) {
+	total := 0
+	var errs []error
+	for _, deleteBatch := range purges {
+		deleted, err := s.purge(ctx, deleteBatch)
+		total += deleted
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return total, errors.Join(errs...)
+}
+
+// purge deletes batches until one comes back short. It stops early when the
+// context is cancelled, the rest is left for the next run.
+func (s *CleanupService) purge(ctx context.Context, deleteBatch deleteBatchFunc) (int, error) {
+	total := 0
+	for ctx.Err() == nil {
+		deleted, err := deleteBatch(ctx, time.Now(), s.batchSize)
+		total += deleted
+		if err != nil {
+			return total, err
+		}
+		if deleted < s.batchSize {
+			break
+		}
+	}
+	return total, nil
+}
@@ -0,0 +1,56 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/auth-service/internal/repository/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCleanupService(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	cleanup := NewCleanupService(mockRepo, 100)
+	ctx := context.Background()
+
+	t.Run("Deletes in batches until a short batch", func(t *testing.T) {
+		gomock.InOrder(
+			mockRepo.EXPECT().DeleteExpiredRefreshTokens(ctx, gomock.Any(), 100).Return(100, nil),
+			mockRepo.EXPECT().DeleteExpiredRefreshTokens(ctx, gomock.Any(), 100).Return(100, nil),
+			mockRepo.EXPECT().DeleteExpiredRefreshTokens(ctx, gomock.Any(), 100).Return(7, nil),
+		)
+		mockRepo.EXPECT().DeleteExpiredRevokedTokens(ctx, gomock.Any(), 100).Return(3, nil)
+
+		deleted, err := cleanup.PurgeExpiredTokens(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 210, deleted)
+	})
+
+	t.Run("One failing table does not stop the others", func(t *testing.T) {
+		dbErr := errors.New("db error")
+		mockRepo.EXPECT().DeleteStaleLoginCodes(ctx, gomock.Any(), 100).Return(0, dbErr)
+		mockRepo.EXPECT().DeleteExpiredAuthorizationCodes(ctx, gomock.Any(), 100).Return(2, nil)
+		mockRepo.EXPECT().DeleteExpiredDeviceCodes(ctx, gomock.Any(), 100).Return(1, nil)
+		mockRepo.EXPECT().DeleteExpiredWebAuthnSessions(ctx, gomock.Any(), 100).Return(0, nil)
+		mockRepo.EXPECT().DeleteExpiredEmailChanges(ctx, gomock.Any(), 100).Return(4, nil)
+
+		deleted, err := cleanup.PurgeStaleCodes(ctx)
+		assert.ErrorIs(t, err, dbErr)
+		assert.Equal(t, 7, deleted)
+	})
+
+	t.Run("Cancelled context stops the purge", func(t *testing.T) {
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+
+		deleted, err := cleanup.PurgeExpiredTokens(cancelled)
+		require.NoError(t, err)
+		assert.Zero(t, deleted)
+	})
+}
@@ -0,0 +1,236 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/auth-service/internal/models"
+	"github.com/auth-service/internal/repository"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	AuditClientCreated       = "client.created"
+	AuditClientSecretRotated = "client.secret_rotated"
+	AuditClientDisabled      = "client.disabled"
+
+	GrantClientCredentials = "client_credentials"
+	GrantAuthorizationCode = "authorization_code"
+	GrantRefreshToken      = "refresh_token"
+	GrantDeviceCode        = "urn:ietf:params:oauth:grant-type:device_code"
+)
+
+var (
+	ErrClientNotFound      = errors.New("client not found")
+	ErrInvalidClient       = errors.New("invalid client credentials")
+	ErrInvalidRegistration = errors.New("invalid client registration")
+	// ErrSessionClientDisabled is returned by RefreshTokens for sessions
+	// of clients that are disabled or gone.
+	ErrSessionClientDisabled = errors.New("client_disabled")
+
+	// supportedGrantTypes are the grants of the token endpoint. Sessions of
+	// clients are refreshed through /auth/refresh, so refresh_token is not one.
+	supportedGrantTypes = map[string]bool{
+		GrantClientCredentials: true,
+		GrantAuthorizationCode: true,
+		GrantDeviceCode:        true,
+	}
+)
+
+type ClientRegistration struct {
+	Name            string
+	Public          bool
+	GrantTypes      []string
+	RedirectURIs    []string
+	Scopes          []string
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+	MaxSessions     int
+	IdleTimeout     time.Duration
+	// IPChangePolicy is empty or an IPChangeMode.
+	IPChangePolicy string
+}
+
+type ClientService struct {
+	repo  repository.ClientRepository
+	audit *AuditLogger
+}
+
+func NewClientService(repo repository.ClientRepository, audit *AuditLogger) *ClientService {
+	return &ClientService{
+		repo:  repo,
+		audit: audit,
+	}
+}
+
+// CreateClient registers a client application. The generated secret is
+// returned once; only its hash is stored. Public clients get no secret.
+func (s *ClientService) CreateClient(ctx context.Context, reg ClientRegistration, adminID string, ip net.IP) (*models.OAuthClient, string, error) {
+	if reg.Name == "" || len(reg.GrantTypes) == 0 {
+		return nil, "", fmt.Errorf("%w: name and grant_types are required", ErrInvalidRegistration)
+	}
+	for _, grantType := range reg.GrantTypes {
+		if !supportedGrantTypes[grantType] {
+			return nil, "", fmt.Errorf("%w: unsupported grant type %q", ErrInvalidRegistration, grantType)
+		}
+	}
+	if reg.Public && hasString(reg.GrantTypes, GrantClientCredentials) {
+		return nil, "", fmt.Errorf("%w: public clients cannot use client_credentials", ErrInvalidRegistration)
+	}
+	if hasString(reg.GrantTypes, GrantAuthorizationCode) && len(reg.RedirectURIs) == 0 {
+		return nil, "", fmt.Errorf("%w: redirect_uris are required for authorization_code", ErrInvalidRegistration)
+	}
+	if reg.AccessTokenTTL < 0 || reg.RefreshTokenTTL < 0 {
+		return nil, "", fmt.Errorf("%w: token lifetimes must not be negative", ErrInvalidRegistration)
+	}
+	if reg.MaxSessions < 0 || reg.IdleTimeout < 0 {
+		return nil, "", fmt.Errorf("%w: max_sessions and idle_timeout must not be negative", ErrInvalidRegistration)
+	}
+	if reg.IPChangePolicy != "" {
+		if _, err := ParseIPChangeMode(reg.IPChangePolicy); err != nil {
+			return nil, "", fmt.Errorf("%w: %v", ErrInvalidRegistration, err)
+		}
+	}
+
+	clientID, err := generateSecureToken(16)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to generate client id: %w", err)
+	}
+	var secret, secretHash string
+	if !reg.Public {
+		secret, secretHash, err = generateClientSecret()
+		if err != nil {
+			return nil, "", err
+		}
+	}
+
+	if reg.RedirectURIs == nil {
+		reg.RedirectURIs = []string{}
+	}
+	if reg.Scopes == nil {
+		reg.Scopes = []string{}
+	}
+	client := &models.OAuthClient{
+		ClientID:        strings.TrimRight(clientID, "="),
+		Name:            reg.Name,
+		SecretHash:      secretHash,
+		Public:          reg.Public,
+		GrantTypes:      reg.GrantTypes,
+		RedirectURIs:    reg.RedirectURIs,
+		Scopes:          reg.Scopes,
+		AccessTokenTTL:  reg.AccessTokenTTL,
+		RefreshTokenTTL: reg.RefreshTokenTTL,
+		MaxSessions:     reg.MaxSessions,
+		IdleTimeout:     reg.IdleTimeout,
+		IPChangePolicy:  reg.IPChangePolicy,
+	}
+	if err := s.repo.CreateClient(ctx, client); err != nil {
+		return nil, "", fmt.Errorf("failed to create client: %w", err)
+	}
+
+	s.audit.Record(ctx, adminID, AuditClientCreated, ip, map[string]string{"client_id": client.ClientID})
+	return client, secret, nil
+}
+
+func (s *ClientService) RotateSecret(ctx context.Context, clientID, adminID string, ip net.IP) (string, error) {
+	secret, secretHash, err := generateClientSecret()
+	if err != nil {
+		return "", err
+	}
+
+	if err := s.repo.UpdateClientSecret(ctx, clientID, secretHash); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return "", ErrClientNotFound
+		}
+		return "", fmt.Errorf("failed to rotate client secret: %w", err)
+	}
+
+	s.audit.Record(ctx, adminID, AuditClientSecretRotated, ip, map[string]string{"client_id": clientID})
+	return secret, nil
+}
+
+func (s *ClientService) DisableClient(ctx context.Context, clientID, adminID string, ip net.IP) error {
+	if err := s.repo.DisableClient(ctx, clientID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrClientNotFound
+		}
+		return fmt.Errorf("failed to disable client: %w", err)
+	}
+
+	s.audit.Record(ctx, adminID, AuditClientDisabled, ip, map[string]string{"client_id": clientID})
+	return nil
+}
+
+// GetClient returns an enabled client.
+func (s *ClientService) GetClient(ctx context.Context, clientID string) (*models.OAuthClient, error) {
+	client, err := s.repo.GetClient(ctx, clientID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrClientNotFound
+		}
+		return nil, fmt.Errorf("failed to get client: %w", err)
+	}
+	if client.DisabledAt != nil {
+		return nil, ErrClientNotFound
+	}
+	return client, nil
+}
+
+// Authenticate checks the client credentials presented at the token
+// endpoint. Public clients are identified by client_id alone and must not
+// present a secret.
+func (s *ClientService) Authenticate(ctx context.Context, clientID, secret string) (*models.OAuthClient, error) {
+	if clientID == "" {
+		return nil, ErrInvalidClient
+	}
+
+	client, err := s.GetClient(ctx, clientID)
+	if err != nil {
+		if errors.Is(err, ErrClientNotFound) {
+			return nil, ErrInvalidClient
+		}
+		return nil, err
+	}
+
+	if client.Public {
+		if secret != "" {
+			return nil, ErrInvalidClient
+		}
+		return client, nil
+	}
+
+	if secret == "" {
+		return nil, ErrInvalidClient
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(client.SecretHash), []byte(secret)); err != nil {
+		return nil, ErrInvalidClient
+	}
+	return client, nil
+}
+
+func generateClientSecret() (string, string, error) {
+	secret, err := generateSecureToken(32)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to generate client secret: %w", err)
+	}
+	secret = strings.TrimRight(secret, "=")
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(secret), bcrypt.DefaultCost)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to hash client secret: %w", err)
+	}
+	return secret, string(hash), nil
+}
+
+func hasString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,266 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/auth-service/internal/models"
+	"github.com/auth-service/internal/repository"
+)
+
+const (
+	AuditDeviceAuthorized = "oauth.device_authorized"
+	AuditDeviceDenied     = "oauth.device_denied"
+
+	deviceCodeTTL      = 10 * time.Minute
+	deviceCodeInterval = 5 * time.Second
+	deviceCodeSize     = 32
+	// User codes use consonants only, so they are easy to type and never
+	// spell words, RFC 8628 section 6.1.
+	userCodeAlphabet = "BCDFGHJKLMNPQRSTVWXZ"
+	userCodeLength   = 8
+	userCodeAttempts = 3
+)
+
+var ErrInvalidUserCode = errors.New("user code is invalid or expired")
+
+// DeviceAuthorization is the response of the device authorization
+// endpoint, RFC 8628 section 3.2.
+type DeviceAuthorization struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete"`
+	ExpiresIn               int    `json:"expires_in"`
+	Interval                int    `json:"interval"`
+}
+
+// DeviceVerification is a pending device authorization shown to the user
+// who entered its user code.
+type DeviceVerification struct {
+	UserCode string
+	Client   *models.OAuthClient
+	Scope    string
+}
+
+// DeviceAuthorization starts the device flow for a client. The device shows
+// the user code and polls the token endpoint with the device code while the
+// user approves the code at the verification URI.
+func (s *OAuthService) DeviceAuthorization(ctx context.Context, clientID, clientSecret, scope string) (*DeviceAuthorization, error) {
+	client, err := s.authenticateClient(ctx, clientID, clientSecret)
+	if err != nil {
+		return nil, err
+	}
+	if !hasString(client.GrantTypes, GrantDeviceCode) {
+		return nil, oauthError(OAuthUnauthorizedClient, "grant type is not allowed for this client")
+	}
+	scope, err = resolveScope(client, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	deviceCode, err := generateSecureToken(deviceCodeSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate device code: %w", err)
+	}
+	deviceCode = strings.TrimRight(deviceCode, "=")
+
+	code := &models.DeviceCode{
+		TenantID:       TenantFromContext(ctx),
+		DeviceCodeHash: hashAuthorizationCode(deviceCode),
+		ClientID:       client.ClientID,
+		Scope:          scope,
+		ExpiresAt:      time.Now().Add(deviceCodeTTL),
+	}
+	for attempt := 1; ; attempt++ {
+		code.UserCode, err = generateUserCode()
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate user code: %w", err)
+		}
+		err = s.codes.SaveDeviceCode(ctx, code)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, repository.ErrDuplicate) || attempt == userCodeAttempts {
+			return nil, fmt.Errorf("failed to save device code: %w", err)
+		}
+	}
+
+	userCode := formatUserCode(code.UserCode)
+	verificationURI := TenantURL(ctx, s.publicURL) + "/oauth/device"
+	return &DeviceAuthorization{
+		DeviceCode:              deviceCode,
+		UserCode:                userCode,
+		VerificationURI:         verificationURI,
+		VerificationURIComplete: verificationURI + "?user_code=" + url.QueryEscape(userCode),
+		ExpiresIn:               int(deviceCodeTTL.Seconds()),
+		Interval:                int(deviceCodeInterval.Seconds()),
+	}, nil
+}
+
+// LookupDeviceCode returns the pending authorization with the user code.
+// The code is matched regardless of case, spaces and dashes.
+func (s *OAuthService) LookupDeviceCode(ctx context.Context, userCode string) (*DeviceVerification, error) {
+	code, err := s.pendingDeviceCode(ctx, userCode)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := s.clients.GetClient(ctx, code.ClientID)
+	if err != nil {
+		if errors.Is(err, ErrClientNotFound) {
+			return nil, ErrInvalidUserCode
+		}
+		return nil, err
+	}
+	return &DeviceVerification{UserCode: formatUserCode(code.UserCode), Client: client, Scope: code.Scope}, nil
+}
+
+// DecideDevice approves or denies the authorization with the user code on
+// behalf of the user.
+func (s *OAuthService) DecideDevice(ctx context.Context, userCode, userID string, allow bool, ip net.IP) error {
+	code, err := s.pendingDeviceCode(ctx, userCode)
+	if err != nil {
+		return err
+	}
+
+	status, event := models.DeviceCodeDenied, AuditDeviceDenied
+	if allow {
+		status, event = models.DeviceCodeApproved, AuditDeviceAuthorized
+	}
+	if err := s.codes.DecideDeviceCode(ctx, code.ID, userID, status); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrInvalidUserCode
+		}
+		return fmt.Errorf("failed to save device decision: %w", err)
+	}
+
+	if userID != "" {
+		s.audit.Record(ctx, userID, event, ip, map[string]string{
+			"client_id": code.ClientID,
+			"scope":     code.Scope,
+		})
+	}
+	return nil
+}
+
+func (s *OAuthService) pendingDeviceCode(ctx context.Context, userCode string) (*models.DeviceCode, error) {
+	userCode = normalizeUserCode(userCode)
+	if len(userCode) != userCodeLength {
+		return nil, ErrInvalidUserCode
+	}
+
+	code, err := s.codes.GetDeviceCodeByUserCode(ctx, userCode)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrInvalidUserCode
+		}
+		return nil, fmt.Errorf("failed to get device code: %w", err)
+	}
+	if code.TenantID != TenantFromContext(ctx) || code.Status != models.DeviceCodePending || time.Now().After(code.ExpiresAt) {
+		return nil, ErrInvalidUserCode
+	}
+	return code, nil
+}
+
+// deviceCode serves a poll of the device. The device gets
+// authorization_pending until the user decides, and slow_down when it polls
+// more often than the interval.
+func (s *OAuthService) deviceCode(ctx context.Context, client *models.OAuthClient, req TokenRequest) (*models.TokenPair, error) {
+	if req.DeviceCode == "" {
+		return nil, oauthError(OAuthInvalidRequest, "device_code is required")
+	}
+
+	now := time.Now()
+	code, err := s.codes.PollDeviceCode(ctx, hashAuthorizationCode(req.DeviceCode), now)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, oauthError(OAuthInvalidGrant, "device code is invalid")
+		}
+		return nil, fmt.Errorf("failed to get device code: %w", err)
+	}
+
+	if code.TenantID != TenantFromContext(ctx) {
+		return nil, oauthError(OAuthInvalidGrant, "device code is invalid")
+	}
+	if code.ClientID != client.ClientID {
+		return nil, oauthError(OAuthInvalidGrant, "device code was issued to another client")
+	}
+	if now.After(code.ExpiresAt) {
+		return nil, oauthError(OAuthExpiredToken, "device code has expired")
+	}
+
+	switch code.Status {
+	case models.DeviceCodePending:
+		if code.LastPolledAt != nil && now.Sub(*code.LastPolledAt) < deviceCodeInterval {
+			return nil, oauthError(OAuthSlowDown, "")
+		}
+		return nil, oauthError(OAuthAuthorizationPending, "")
+	case models.DeviceCodeDenied:
+		if err := s.codes.DeleteDeviceCode(ctx, code.ID); err != nil && !errors.Is(err, repository.ErrNotFound) {
+			return nil, fmt.Errorf("failed to delete device code: %w", err)
+		}
+		return nil, oauthError(OAuthAccessDenied, "the user denied the request")
+	}
+
+	if err := s.codes.DeleteDeviceCode(ctx, code.ID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, oauthError(OAuthInvalidGrant, "device code has already been used")
+		}
+		return nil, fmt.Errorf("failed to delete device code: %w", err)
+	}
+
+	ttl := accessTokenTTL(client)
+	tokens, err := s.authService.IssueTokens(ctx, TokenGrant{
+		UserID:          code.UserID,
+		ClientID:        client.ClientID,
+		Scope:           code.Scope,
+		IP:              req.IP,
+		AccessTokenTTL:  ttl,
+		RefreshTokenTTL: client.RefreshTokenTTL,
+		MaxSessions:     client.MaxSessions,
+		IdleTimeout:     client.IdleTimeout,
+		IPChangePolicy:  IPChangeMode(client.IPChangePolicy),
+	})
+	if err != nil {
+		return nil, sessionLimitError(err)
+	}
+	tokens.TokenType = "Bearer"
+	tokens.ExpiresIn = int(ttl.Seconds())
+	tokens.Scope = code.Scope
+	return tokens, nil
+}
+
+func generateUserCode() (string, error) {
+	code := make([]byte, userCodeLength)
+	max := big.NewInt(int64(len(userCodeAlphabet)))
+	for i := range code {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		code[i] = userCodeAlphabet[n.Int64()]
+	}
+	return string(code), nil
+}
+
+func normalizeUserCode(code string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' {
+			return -1
+		}
+		return r
+	}, strings.ToUpper(strings.TrimSpace(code)))
+}
+
+// formatUserCode splits the code in halves, WDJB-MJHT.
+func formatUserCode(code string) string {
+	return code[:userCodeLength/2] + "-" + code[userCodeLength/2:]
+}
@@ -0,0 +1,459 @@
+package services
+
+import (
+	"context"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/auth-service/internal/models"
+	"github.com/auth-service/internal/repository"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const (
+	AuditFederatedLogin  = "federated.login"
+	AuditFederatedLinked = "federated.linked"
+
+	federatedLoginTTL = 10 * time.Minute
+	// jwksRefreshInterval limits how often an unknown kid makes us fetch the
+	// upstream key set again.
+	jwksRefreshInterval = time.Minute
+)
+
+var (
+	ErrUnknownProvider     = errors.New("unknown identity provider")
+	ErrFederatedLogin      = errors.New("federated login failed")
+	ErrFederatedEmailInUse = errors.New("email belongs to another account")
+)
+
+// FederationProvider is an upstream OpenID Connect provider users can sign in
+// with. TrustEmail links the login to an existing account with the same
+// address when the provider reports the email as verified; otherwise such a
+// login is refused.
+type FederationProvider struct {
+	Name         string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+	TrustEmail   bool
+}
+
+type federationRepository interface {
+	repository.UserRepository
+	repository.FederationRepository
+}
+
+type FederationService struct {
+	repo       federationRepository
+	providers  map[string]*upstreamProvider
+	publicURL  string
+	httpClient *http.Client
+	audit      *AuditLogger
+}
+
+func NewFederationService(
+	repo federationRepository,
+	providers []FederationProvider,
+	publicURL string,
+	httpClient *http.Client,
+	audit *AuditLogger,
+) *FederationService {
+	s := &FederationService{
+		repo:       repo,
+		providers:  make(map[string]*upstreamProvider, len(providers)),
+		publicURL:  strings.TrimRight(publicURL, "/"),
+		httpClient: httpClient,
+		audit:      audit,
+	}
+	for _, p := range providers {
+		if len(p.Scopes) == 0 {
+			p.Scopes = []string{ScopeOpenID, ScopeEmail, ScopeProfile}
+		} else if !hasString(p.Scopes, ScopeOpenID) {
+			p.Scopes = append([]string{ScopeOpenID}, p.Scopes...)
+		}
+		s.providers[p.Name] = &upstreamProvider{FederationProvider: p}
+	}
+	return s
+}
+
+// Begin starts a login at the provider and returns the URL to redirect the
+// browser to and the state the browser has to present on the callback.
+func (s *FederationService) Begin(ctx context.Context, providerName string) (string, string, error) {
+	provider, ok := s.providers[providerName]
+	if !ok {
+		return "", "", ErrUnknownProvider
+	}
+
+	metadata, err := provider.discover(ctx, s.httpClient)
+	if err != nil {
+		return "", "", err
+	}
+
+	state, err := generateSecureToken(32)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to generate state: %w", err)
+	}
+	nonce, err := generateSecureToken(32)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	verifier, err := generateSecureToken(32)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to generate code verifier: %w", err)
+	}
+	state = strings.TrimRight(state, "=")
+	verifier = strings.TrimRight(verifier, "=")
+
+	err = s.repo.SaveFederatedLogin(ctx, &models.FederatedLogin{
+		StateHash:    hashAuthorizationCode(state),
+		TenantID:     TenantFromContext(ctx),
+		Provider:     provider.Name,
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		ExpiresAt:    time.Now().Add(federatedLoginTTL),
+	})
+	if err != nil {
+		return "", "", fmt.Errorf("failed to save federated login: %w", err)
+	}
+
+	challenge := sha256.Sum256([]byte(verifier))
+	query := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {provider.ClientID},
+		"redirect_uri":          {s.redirectURI(ctx, provider)},
+		"scope":                 {strings.Join(provider.Scopes, " ")},
+		"state":                 {state},
+		"nonce":                 {nonce},
+		"code_challenge":        {base64.RawURLEncoding.EncodeToString(challenge[:])},
+		"code_challenge_method": {CodeChallengeMethodS256},
+	}
+
+	redirect, err := url.Parse(metadata.AuthorizationEndpoint)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid authorization endpoint of %s: %w", provider.Name, err)
+	}
+	merged := redirect.Query()
+	for key, values := range query {
+		merged[key] = values
+	}
+	redirect.RawQuery = merged.Encode()
+	return redirect.String(), state, nil
+}
+
+// Complete exchanges the code returned by the provider, validates the ID
+// token and returns the local user for the upstream identity, creating or
+// linking one on the first login.
+func (s *FederationService) Complete(ctx context.Context, providerName, state, code string, ip net.IP) (*models.User, error) {
+	provider, ok := s.providers[providerName]
+	if !ok {
+		return nil, ErrUnknownProvider
+	}
+
+	login, err := s.repo.TakeFederatedLogin(ctx, hashAuthorizationCode(state))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, fmt.Errorf("%w: unknown state", ErrFederatedLogin)
+		}
+		return nil, fmt.Errorf("failed to get federated login: %w", err)
+	}
+	if login.Provider != provider.Name || time.Now().After(login.ExpiresAt) {
+		return nil, fmt.Errorf("%w: login expired", ErrFederatedLogin)
+	}
+	if login.TenantID != TenantFromContext(ctx) {
+		return nil, fmt.Errorf("%w: login started in another tenant", ErrFederatedLogin)
+	}
+
+	metadata, err := provider.discover(ctx, s.httpClient)
+	if err != nil {
+		return nil, err
+	}
+
+	rawIDToken, err := s.exchangeCode(ctx, provider, metadata, code, login.CodeVerifier)
+	if err != nil {
+		return nil, err
+	}
+
+	claims, err := provider.verifyIDToken(ctx, s.httpClient, metadata, rawIDToken, login.Nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.resolveUser(ctx, provider, claims, ip)
+	if err != nil {
+		return nil, err
+	}
+
+	s.audit.Record(ctx, user.ID, AuditFederatedLogin, ip, map[string]string{"provider": provider.Name})
+	return user, nil
+}
+
+func (s *FederationService) resolveUser(ctx context.Context, provider *upstreamProvider, claims *upstreamIDTokenClaims, ip net.IP) (*models.User, error) {
+	identity, err := s.repo.GetFederatedIdentity(ctx, TenantFromContext(ctx), claims.Issuer, claims.Subject)
+	if err == nil {
+		user, err := s.repo.GetUserByID(ctx, identity.UserID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get user: %w", err)
+		}
+		return user, nil
+	}
+	if !errors.Is(err, repository.ErrNotFound) {
+		return nil, fmt.Errorf("failed to get federated identity: %w", err)
+	}
+
+	if claims.Email == "" {
+		return nil, fmt.Errorf("%w: provider did not return an email", ErrFederatedLogin)
+	}
+
+	identity = &models.FederatedIdentity{
+		TenantID: TenantFromContext(ctx),
+		Provider: provider.Name,
+		Issuer:   claims.Issuer,
+		Subject:  claims.Subject,
+	}
+
+	user, err := s.repo.GetUserByEmail(ctx, identity.TenantID, claims.Email)
+	switch {
+	case err == nil:
+		if !provider.TrustEmail || !claims.EmailVerified {
+			return nil, ErrFederatedEmailInUse
+		}
+		identity.UserID = user.ID
+		if err := s.repo.LinkFederatedIdentity(ctx, identity); err != nil {
+			return nil, fmt.Errorf("failed to link federated identity: %w", err)
+		}
+		s.audit.Record(ctx, user.ID, AuditFederatedLinked, ip, map[string]string{"provider": provider.Name})
+		return user, nil
+	case errors.Is(err, repository.ErrNotFound):
+		user = &models.User{
+			TenantID:      identity.TenantID,
+			Email:         claims.Email,
+			Name:          claims.Name,
+			EmailVerified: claims.EmailVerified,
+		}
+		if err := s.repo.CreateFederatedUser(ctx, user, identity); err != nil {
+			return nil, fmt.Errorf("failed to create federated user: %w", err)
+		}
+		return user, nil
+	default:
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+}
+
+func (s *FederationService) exchangeCode(ctx context.Context, provider *upstreamProvider, metadata *providerMetadata, code, verifier string) (string, error) {
+	form := url.Values{
+		"grant_type":    {GrantAuthorizationCode},
+		"code":          {code},
+		"redirect_uri":  {s.redirectURI(ctx, provider)},
+		"code_verifier": {verifier},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, metadata.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("failed to build token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(provider.ClientID), url.QueryEscape(provider.ClientSecret))
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to call token endpoint of %s: %w", provider.Name, err)
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("failed to decode token response of %s: %w", provider.Name, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%w: token endpoint returned %d %s %s", ErrFederatedLogin, resp.StatusCode, body.Error, body.ErrorDescription)
+	}
+	if body.IDToken == "" {
+		return "", fmt.Errorf("%w: token response has no id_token", ErrFederatedLogin)
+	}
+	return body.IDToken, nil
+}
+
+// redirectURI is the callback of the provider in the tenant of the request.
+func (s *FederationService) redirectURI(ctx context.Context, provider *upstreamProvider) string {
+	return TenantURL(ctx, s.publicURL) + "/auth/federated/" + url.PathEscape(provider.Name) + "/callback"
+}
+
+type providerMetadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+type upstreamIDTokenClaims struct {
+	Nonce           string `json:"nonce"`
+	AuthorizedParty string `json:"azp"`
+	Email           string `json:"email"`
+	EmailVerified   bool   `json:"email_verified"`
+	Name            string `json:"name"`
+	jwt.RegisteredClaims
+}
+
+// upstreamProvider caches the discovery document and the signing keys of a
+// provider.
+type upstreamProvider struct {
+	FederationProvider
+
+	mu            sync.Mutex
+	metadata      *providerMetadata
+	keys          map[string]*rsa.PublicKey
+	keysFetchedAt time.Time
+}
+
+func (p *upstreamProvider) discover(ctx context.Context, client *http.Client) (*providerMetadata, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.metadata != nil {
+		return p.metadata, nil
+	}
+
+	var metadata providerMetadata
+	discoveryURL := strings.TrimRight(p.Issuer, "/") + "/.well-known/openid-configuration"
+	if err := getJSON(ctx, client, discoveryURL, &metadata); err != nil {
+		return nil, fmt.Errorf("failed to discover %s: %w", p.Name, err)
+	}
+	// OIDC Discovery section 4.3: the issuer must match the one configured.
+	if metadata.Issuer != p.Issuer {
+		return nil, fmt.Errorf("discovery of %s returned issuer %q", p.Name, metadata.Issuer)
+	}
+	if metadata.AuthorizationEndpoint == "" || metadata.TokenEndpoint == "" || metadata.JWKSURI == "" {
+		return nil, fmt.Errorf("discovery document of %s is incomplete", p.Name)
+	}
+
+	p.metadata = &metadata
+	return p.metadata, nil
+}
+
+func (p *upstreamProvider) verifyIDToken(ctx context.Context, client *http.Client, metadata *providerMetadata, raw, nonce string) (*upstreamIDTokenClaims, error) {
+	var claims upstreamIDTokenClaims
+	parser := jwt.NewParser(jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg()}))
+	_, err := parser.ParseWithClaims(raw, &claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return p.signingKey(ctx, client, metadata, kid)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid id_token: %v", ErrFederatedLogin, err)
+	}
+
+	switch {
+	case claims.Issuer != metadata.Issuer:
+		return nil, fmt.Errorf("%w: id_token issuer %q", ErrFederatedLogin, claims.Issuer)
+	case !claims.VerifyAudience(p.ClientID, true):
+		return nil, fmt.Errorf("%w: id_token is not issued to %s", ErrFederatedLogin, p.ClientID)
+	case len(claims.Audience) > 1 && claims.AuthorizedParty != p.ClientID:
+		return nil, fmt.Errorf("%w: id_token azp %q", ErrFederatedLogin, claims.AuthorizedParty)
+	case claims.ExpiresAt == nil:
+		return nil, fmt.Errorf("%w: id_token has no expiry", ErrFederatedLogin)
+	case claims.Subject == "":
+		return nil, fmt.Errorf("%w: id_token has no subject", ErrFederatedLogin)
+	case claims.Nonce != nonce:
+		return nil, fmt.Errorf("%w: id_token nonce mismatch", ErrFederatedLogin)
+	}
+	return &claims, nil
+}
+
+// signingKey returns the key with the given kid, fetching the key set again
+// when the provider has rotated its keys.
+func (p *upstreamProvider) signingKey(ctx context.Context, client *http.Client, metadata *providerMetadata, kid string) (*rsa.PublicKey, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if key, ok := p.lookupKey(kid); ok {
+		return key, nil
+	}
+	if time.Since(p.keysFetchedAt) < jwksRefreshInterval {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+
+	var keySet JSONWebKeySet
+	if err := getJSON(ctx, client, metadata.JWKSURI, &keySet); err != nil {
+		return nil, fmt.Errorf("failed to fetch keys of %s: %w", p.Name, err)
+	}
+	keys := make(map[string]*rsa.PublicKey, len(keySet.Keys))
+	for _, jwk := range keySet.Keys {
+		if jwk.KeyType != "RSA" || (jwk.Use != "" && jwk.Use != "sig") {
+			continue
+		}
+		key, err := jwk.rsaPublicKey()
+		if err != nil {
+			return nil, fmt.Errorf("invalid key %q of %s: %w", jwk.KeyID, p.Name, err)
+		}
+		keys[jwk.KeyID] = key
+	}
+	p.keys = keys
+	p.keysFetchedAt = time.Now()
+
+	if key, ok := p.lookupKey(kid); ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+// lookupKey finds a key by kid. Tokens without a kid are accepted only when
+// the provider publishes a single key.
+func (p *upstreamProvider) lookupKey(kid string) (*rsa.PublicKey, bool) {
+	if kid == "" && len(p.keys) == 1 {
+		for _, key := range p.keys {
+			return key, true
+		}
+	}
+	key, ok := p.keys[kid]
+	return key, ok
+}
+
+func (k JSONWebKey) rsaPublicKey() (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(k.Modulus)
+	if err != nil {
+		return nil, fmt.Errorf("invalid modulus: %w", err)
+	}
+	e, err := base64.RawURLEncoding.DecodeString(k.Exponent)
+	if err != nil {
+		return nil, fmt.Errorf("invalid exponent: %w", err)
+	}
+	exponent := new(big.Int).SetBytes(e)
+	if len(n) == 0 || !exponent.IsInt64() || exponent.Int64() < 3 || exponent.Int64() > 1<<31-1 {
+		return nil, errors.New("invalid RSA key parameters")
+	}
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent.Int64())}, nil
+}
+
+func getJSON(ctx context.Context, client *http.Client, target string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned %d", target, resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
@@ -0,0 +1,263 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/auth-service/internal/models"
+	"github.com/auth-service/internal/repository"
+	"github.com/auth-service/internal/repository/mocks"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockOIDCProvider is a minimal upstream OpenID provider: discovery, JWKS
+// and a token endpoint that answers every code with an ID token.
+type mockOIDCProvider struct {
+	*httptest.Server
+	key        *rsa.PrivateKey
+	challenges map[string]string
+	// claims builds the ID token for the nonce of the login.
+	claims func(nonce string) jwt.MapClaims
+}
+
+func newMockOIDCProvider(t *testing.T) *mockOIDCProvider {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	p := &mockOIDCProvider{key: key, challenges: make(map[string]string)}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 p.URL,
+			"authorization_endpoint": p.URL + "/authorize?prompt=login",
+			"token_endpoint":         p.URL + "/token",
+			"jwks_uri":               p.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		idTokens, err := NewIDTokenService(key, p.URL)
+		require.NoError(t, err)
+		json.NewEncoder(w).Encode(idTokens.KeySet())
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		clientID, secret, _ := r.BasicAuth()
+		if clientID != "auth-service" || secret != "upstream-secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(map[string]string{"error": OAuthInvalidClient})
+			return
+		}
+
+		nonce, ok := p.challenges[r.PostFormValue("code")]
+		sum := sha256.Sum256([]byte(r.PostFormValue("code_verifier")))
+		if !ok || r.PostFormValue("code") != base64.RawURLEncoding.EncodeToString(sum[:]) {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": OAuthInvalidGrant})
+			return
+		}
+
+		idTokens, err := NewIDTokenService(key, p.URL)
+		require.NoError(t, err)
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, p.claims(nonce))
+		token.Header["kid"] = idTokens.KeySet().Keys[0].KeyID
+		signed, err := token.SignedString(p.key)
+		require.NoError(t, err)
+		json.NewEncoder(w).Encode(map[string]string{"access_token": "upstream", "id_token": signed})
+	})
+	p.Server = httptest.NewServer(mux)
+	t.Cleanup(p.Close)
+
+	p.claims = func(nonce string) jwt.MapClaims {
+		return jwt.MapClaims{
+			"iss":            p.URL,
+			"sub":            "upstream-user",
+			"aud":            "auth-service",
+			"exp":            time.Now().Add(time.Minute).Unix(),
+			"iat":            time.Now().Unix(),
+			"nonce":          nonce,
+			"email":          "user@corp.example",
+			"email_verified": true,
+			"name":           "Иван Петров",
+		}
+	}
+	return p
+}
+
+// authorize plays the browser and the upstream login: it follows the
+// redirect and returns the state and the code sent to the callback. The
+// code is the PKCE challenge, so the token endpoint can check the verifier.
+func (p *mockOIDCProvider) authorize(t *testing.T, redirect string) (string, string) {
+	parsed, err := url.Parse(redirect)
+	require.NoError(t, err)
+	query := parsed.Query()
+
+	assert.Equal(t, p.URL+"/authorize", parsed.Scheme+"://"+parsed.Host+parsed.Path)
+	assert.Equal(t, "login", query.Get("prompt"))
+	assert.Equal(t, "auth-service", query.Get("client_id"))
+	assert.Equal(t, "https://auth.example/auth/federated/corp/callback", query.Get("redirect_uri"))
+	assert.Equal(t, "openid email profile", query.Get("scope"))
+	assert.Equal(t, CodeChallengeMethodS256, query.Get("code_challenge_method"))
+
+	code := query.Get("code_challenge")
+	p.challenges[code] = query.Get("nonce")
+	return query.Get("state"), code
+}
+
+func TestFederationService(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	upstream := newMockOIDCProvider(t)
+	mockRepo := mocks.NewMockRepository(ctrl)
+	federationSvc := NewFederationService(mockRepo, []FederationProvider{{
+		Name:         "corp",
+		Issuer:       upstream.URL,
+		ClientID:     "auth-service",
+		ClientSecret: "upstream-secret",
+	}}, "https://auth.example/", upstream.Client(), NewAuditLogger(mockRepo))
+	ctx := context.Background()
+	userIP := net.ParseIP("192.168.1.1")
+
+	logins := make(map[string]*models.FederatedLogin)
+	mockRepo.EXPECT().SaveFederatedLogin(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, login *models.FederatedLogin) error {
+			logins[login.StateHash] = login
+			return nil
+		}).AnyTimes()
+	mockRepo.EXPECT().TakeFederatedLogin(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, stateHash string) (*models.FederatedLogin, error) {
+			login, ok := logins[stateHash]
+			if !ok {
+				return nil, repository.ErrNotFound
+			}
+			delete(logins, stateHash)
+			return login, nil
+		}).AnyTimes()
+	mockRepo.EXPECT().SaveAuditEvent(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	login := func(t *testing.T) (string, string) {
+		redirect, state, err := federationSvc.Begin(ctx, "corp")
+		require.NoError(t, err)
+		returnedState, code := upstream.authorize(t, redirect)
+		assert.Equal(t, state, returnedState)
+		return state, code
+	}
+
+	t.Run("First login creates a user", func(t *testing.T) {
+		state, code := login(t)
+
+		mockRepo.EXPECT().GetFederatedIdentity(ctx, DefaultTenant, upstream.URL, "upstream-user").Return(nil, repository.ErrNotFound)
+		mockRepo.EXPECT().GetUserByEmail(ctx, DefaultTenant, "user@corp.example").Return(nil, repository.ErrNotFound)
+		mockRepo.EXPECT().CreateFederatedUser(ctx, gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, user *models.User, identity *models.FederatedIdentity) error {
+				assert.Equal(t, "user@corp.example", user.Email)
+				assert.Equal(t, "Иван Петров", user.Name)
+				assert.True(t, user.EmailVerified)
+				assert.Equal(t, "corp", identity.Provider)
+				assert.Equal(t, "upstream-user", identity.Subject)
+				user.ID = "user1"
+				return nil
+			})
+
+		user, err := federationSvc.Complete(ctx, "corp", state, code, userIP)
+		require.NoError(t, err)
+		assert.Equal(t, "user1", user.ID)
+	})
+
+	t.Run("Known identity", func(t *testing.T) {
+		state, code := login(t)
+
+		mockRepo.EXPECT().GetFederatedIdentity(ctx, DefaultTenant, upstream.URL, "upstream-user").
+			Return(&models.FederatedIdentity{UserID: "user1"}, nil)
+		mockRepo.EXPECT().GetUserByID(ctx, "user1").Return(&models.User{ID: "user1"}, nil)
+
+		user, err := federationSvc.Complete(ctx, "corp", state, code, userIP)
+		require.NoError(t, err)
+		assert.Equal(t, "user1", user.ID)
+	})
+
+	t.Run("State is single use", func(t *testing.T) {
+		state, code := login(t)
+
+		mockRepo.EXPECT().GetFederatedIdentity(ctx, DefaultTenant, gomock.Any(), gomock.Any()).
+			Return(&models.FederatedIdentity{UserID: "user1"}, nil)
+		mockRepo.EXPECT().GetUserByID(ctx, "user1").Return(&models.User{ID: "user1"}, nil)
+
+		_, err := federationSvc.Complete(ctx, "corp", state, code, userIP)
+		require.NoError(t, err)
+
+		_, err = federationSvc.Complete(ctx, "corp", state, code, userIP)
+		assert.ErrorIs(t, err, ErrFederatedLogin)
+	})
+
+	t.Run("Email of another account", func(t *testing.T) {
+		state, code := login(t)
+
+		mockRepo.EXPECT().GetFederatedIdentity(ctx, DefaultTenant, gomock.Any(), gomock.Any()).Return(nil, repository.ErrNotFound)
+		mockRepo.EXPECT().GetUserByEmail(ctx, DefaultTenant, "user@corp.example").Return(&models.User{ID: "local"}, nil)
+
+		_, err := federationSvc.Complete(ctx, "corp", state, code, userIP)
+		assert.ErrorIs(t, err, ErrFederatedEmailInUse)
+	})
+
+	t.Run("Invalid ID tokens", func(t *testing.T) {
+		defaultClaims := upstream.claims
+		defer func() { upstream.claims = defaultClaims }()
+
+		tests := map[string]func(claims jwt.MapClaims){
+			"Wrong nonce":    func(claims jwt.MapClaims) { claims["nonce"] = "replayed" },
+			"Wrong audience": func(claims jwt.MapClaims) { claims["aud"] = "another-client" },
+			"Wrong issuer":   func(claims jwt.MapClaims) { claims["iss"] = "https://evil.example" },
+			"Expired":        func(claims jwt.MapClaims) { claims["exp"] = time.Now().Add(-time.Minute).Unix() },
+		}
+		for name, modify := range tests {
+			t.Run(name, func(t *testing.T) {
+				upstream.claims = func(nonce string) jwt.MapClaims {
+					claims := defaultClaims(nonce)
+					modify(claims)
+					return claims
+				}
+				state, code := login(t)
+
+				_, err := federationSvc.Complete(ctx, "corp", state, code, userIP)
+				assert.ErrorIs(t, err, ErrFederatedLogin)
+			})
+		}
+	})
+
+	t.Run("Token signed with another key", func(t *testing.T) {
+		state, code := login(t)
+		upstream.key, _ = rsa.GenerateKey(rand.Reader, 2048)
+
+		_, err := federationSvc.Complete(ctx, "corp", state, code, userIP)
+		assert.ErrorIs(t, err, ErrFederatedLogin)
+	})
+
+	t.Run("Login started in another tenant", func(t *testing.T) {
+		redirect, state, err := federationSvc.Begin(WithTenant(ctx, "acme"), "corp")
+		require.NoError(t, err)
+		parsed, err := url.Parse(redirect)
+		require.NoError(t, err)
+		assert.Equal(t, "https://auth.example/t/acme/auth/federated/corp/callback", parsed.Query().Get("redirect_uri"))
+
+		_, err = federationSvc.Complete(ctx, "corp", state, "code", userIP)
+		assert.ErrorIs(t, err, ErrFederatedLogin)
+	})
+
+	t.Run("Unknown provider", func(t *testing.T) {
+		_, _, err := federationSvc.Begin(ctx, "other")
+		assert.ErrorIs(t, err, ErrUnknownProvider)
+	})
+}
@@ -0,0 +1,175 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/auth-service/internal/models"
+)
+
+// GeoIP looks up where IP addresses are in local MaxMind DB files, usually
+// GeoLite2-City and GeoLite2-ASN. Every file adds the fields it has.
+type GeoIP struct {
+	mu        sync.RWMutex
+	databases []*geoIPDatabase
+}
+
+type geoIPDatabase struct {
+	path    string
+	modTime time.Time
+	size    int64
+	reader  *mmdbReader
+}
+
+// NewGeoIP loads the databases at paths, empty paths are skipped.
+func NewGeoIP(paths ...string) (*GeoIP, error) {
+	g := &GeoIP{}
+	for _, path := range paths {
+		if path == "" {
+			continue
+		}
+		db := &geoIPDatabase{path: path}
+		if err := db.load(); err != nil {
+			return nil, err
+		}
+		g.databases = append(g.databases, db)
+	}
+	return g, nil
+}
+
+// Lookup returns the location of ip, a zero location when the databases do
+// not know it.
+func (g *GeoIP) Lookup(ip net.IP) models.GeoLocation {
+	var location models.GeoLocation
+	if ip == nil {
+		return location
+	}
+
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	for _, db := range g.databases {
+		record, err := db.reader.lookup(ip)
+		if err != nil {
+			log.Printf("GeoIP lookup of %s in %s failed: %v", ip, db.path, err)
+			continue
+		}
+		if record == nil {
+			continue
+		}
+		if country := mmdbString(record, "country", "iso_code"); country != "" {
+			location.Country = country
+		} else if country := mmdbString(record, "registered_country", "iso_code"); country != "" {
+			location.Country = country
+		}
+		if city := mmdbString(record, "city", "names", "en"); city != "" {
+			location.City = city
+		}
+		if asn := mmdbUint(record, "autonomous_system_number"); asn != 0 {
+			location.ASN = uint32(asn)
+			location.ASOrg = mmdbString(record, "autonomous_system_organization")
+		}
+	}
+	return location
+}
+
+// Reload reads the databases that changed on disk since they were loaded and
+// returns how many were reloaded. A file that fails to load, for example
+// because it is still being written, keeps the previous version in use.
+func (g *GeoIP) Reload(ctx context.Context) (int, error) {
+	g.mu.RLock()
+	databases := append([]*geoIPDatabase(nil), g.databases...)
+	g.mu.RUnlock()
+
+	reloaded := 0
+	for i, db := range databases {
+		if ctx.Err() != nil {
+			break
+		}
+		info, err := os.Stat(db.path)
+		if err != nil {
+			return reloaded, fmt.Errorf("failed to stat GeoIP database: %w", err)
+		}
+		if info.ModTime().Equal(db.modTime) && info.Size() == db.size {
+			continue
+		}
+
+		fresh := &geoIPDatabase{path: db.path}
+		if err := fresh.load(); err != nil {
+			return reloaded, err
+		}
+		g.mu.Lock()
+		g.databases[i] = fresh
+		g.mu.Unlock()
+
+		log.Printf("Reloaded GeoIP database %s (%s)", fresh.path, fresh.reader.databaseType)
+		reloaded++
+	}
+	return reloaded, nil
+}
+
+func (db *geoIPDatabase) load() error {
+	info, err := os.Stat(db.path)
+	if err != nil {
+		return fmt.Errorf("failed to stat GeoIP database: %w", err)
+	}
+	buf, err := os.ReadFile(db.path)
+	if err != nil {
+		return fmt.Errorf("failed to read GeoIP database: %w", err)
+	}
+	reader, err := newMMDBReader(buf)
+	if err != nil {
+		return fmt.Errorf("failed to load GeoIP database %s: %w", db.path, err)
+	}
+	db.modTime, db.size, db.reader = info.ModTime(), info.Size(), reader
+	return nil
+}
+
+// WithGeoIP records the location of the IPs of sessions and adds it to the
+// security alerts about them.
+func WithGeoIP(geo *GeoIP) AuthOption {
+	return func(s *AuthService) {
+		s.geo = geo
+	}
+}
+
+func (s *AuthService) locate(ip net.IP) models.GeoLocation {
+	if s.geo == nil {
+		return models.GeoLocation{}
+	}
+	return s.geo.Lookup(ip)
+}
+
+// formatLocation describes a location for security alerts,
+// "Moscow, RU, AS12389 Rostelecom".
+func formatLocation(location models.GeoLocation) string {
+	var parts []string
+	if location.City != "" {
+		parts = append(parts, location.City)
+	}
+	if location.Country != "" {
+		parts = append(parts, location.Country)
+	}
+	if location.ASN != 0 {
+		as := fmt.Sprintf("AS%d", location.ASN)
+		if location.ASOrg != "" {
+			as += " " + location.ASOrg
+		}
+		parts = append(parts, as)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// formatIP is an IP with its location for security alerts.
+func formatIP(ip string, location models.GeoLocation) string {
+	if where := formatLocation(location); where != "" {
+		return fmt.Sprintf("%s (%s)", ip, where)
+	}
+	return ip
+}
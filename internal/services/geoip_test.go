@@ -0,0 +1,316 @@
+package services
+
+import (
+	"context"
+	"encoding/binary"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/auth-service/internal/models"
+	"github.com/auth-service/internal/repository/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// mmdbPointerTo marks a value the test writer stores as a pointer to the data
+// of an earlier network.
+type mmdbPointerTo int
+
+type testNetwork struct {
+	cidr   string
+	record any
+}
+
+// writeTestMMDB builds a MaxMind DB file with the given networks.
+func writeTestMMDB(t *testing.T, path string, ipVersion, recordSize int, networks ...testNetwork) {
+	t.Helper()
+
+	const empty = -1
+	type node struct{ records [2]int }
+	// Records below 0 point to data: -2 is the data of network 0 and so on.
+	nodes := []node{{records: [2]int{empty, empty}}}
+
+	for i, network := range networks {
+		_, ipNet, err := net.ParseCIDR(network.cidr)
+		require.NoError(t, err)
+		ip := ipNet.IP
+		ones, _ := ipNet.Mask.Size()
+		// IPv6 databases keep IPv4 networks under ::/96.
+		if ipVersion == 6 && ip.To4() != nil {
+			ip, ones = append(make(net.IP, 12), ip.To4()...), ones+96
+		}
+
+		current := 0
+		for bit := 0; bit < ones; bit++ {
+			b := int(ip[bit/8]>>(7-bit%8)) & 1
+			if bit == ones-1 {
+				nodes[current].records[b] = -2 - i
+				break
+			}
+			if nodes[current].records[b] == empty {
+				nodes = append(nodes, node{records: [2]int{empty, empty}})
+				nodes[current].records[b] = len(nodes) - 1
+			}
+			current = nodes[current].records[b]
+		}
+	}
+
+	var data []byte
+	offsets := make([]int, len(networks))
+	for i, network := range networks {
+		offsets[i] = len(data)
+		data = append(data, encodeTestMMDB(network.record, offsets)...)
+	}
+
+	nodeCount := len(nodes)
+	value := func(record int) uint32 {
+		switch {
+		case record == empty:
+			return uint32(nodeCount)
+		case record < 0:
+			return uint32(nodeCount + mmdbDataSeparatorSize + offsets[-2-record])
+		default:
+			return uint32(record)
+		}
+	}
+
+	var file []byte
+	for _, n := range nodes {
+		left, right := value(n.records[0]), value(n.records[1])
+		switch recordSize {
+		case 24:
+			file = append(file, byte(left>>16), byte(left>>8), byte(left), byte(right>>16), byte(right>>8), byte(right))
+		case 28:
+			file = append(file, byte(left>>16), byte(left>>8), byte(left), byte(left>>24<<4|right>>24&0x0F),
+				byte(right>>16), byte(right>>8), byte(right))
+		default:
+			file = binary.BigEndian.AppendUint32(file, left)
+			file = binary.BigEndian.AppendUint32(file, right)
+		}
+	}
+	file = append(file, make([]byte, mmdbDataSeparatorSize)...)
+	file = append(file, data...)
+	file = append(file, mmdbMetadataMarker...)
+	file = append(file, encodeTestMMDB(map[string]any{
+		"binary_format_major_version": uint64(2),
+		"database_type":               "Test-DB",
+		"ip_version":                  uint64(ipVersion),
+		"node_count":                  uint64(nodeCount),
+		"record_size":                 uint64(recordSize),
+	}, nil)...)
+
+	require.NoError(t, os.WriteFile(path, file, 0o600))
+}
+
+func encodeTestMMDB(value any, offsets []int) []byte {
+	control := func(kind, size int) []byte {
+		var b []byte
+		switch {
+		case size < 29:
+			b = []byte{byte(size)}
+		case size < 285:
+			b = []byte{29, byte(size - 29)}
+		default:
+			b = []byte{30, byte((size - 285) >> 8), byte(size - 285)}
+		}
+		if kind <= 7 {
+			b[0] |= byte(kind << 5)
+			return b
+		}
+		return append([]byte{b[0], byte(kind - 7)}, b[1:]...)
+	}
+
+	switch v := value.(type) {
+	case string:
+		return append(control(mmdbTypeString, len(v)), v...)
+	case uint64:
+		return binary.BigEndian.AppendUint32(control(mmdbTypeUint32, 4), uint32(v))
+	case mmdbPointerTo:
+		offset := offsets[v]
+		return []byte{byte(mmdbTypePointer<<5 | offset>>8&0x7), byte(offset)}
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		b := control(mmdbTypeMap, len(v))
+		for _, key := range keys {
+			b = append(b, encodeTestMMDB(key, offsets)...)
+			b = append(b, encodeTestMMDB(v[key], offsets)...)
+		}
+		return b
+	}
+	panic("unsupported test value")
+}
+
+func writeTestGeoIP(t *testing.T, dir string) (cityDB, asnDB string) {
+	cityDB, asnDB = filepath.Join(dir, "city.mmdb"), filepath.Join(dir, "asn.mmdb")
+	writeTestMMDB(t, cityDB, 6, 24,
+		testNetwork{"81.2.69.0/24", map[string]any{
+			"city":    map[string]any{"names": map[string]any{"en": "London", "ru": "Лондон"}},
+			"country": map[string]any{"iso_code": "GB"},
+		}},
+		testNetwork{"2001:db8::/32", map[string]any{
+			"registered_country": map[string]any{"iso_code": "DE"},
+		}},
+		testNetwork{"81.2.70.0/24", mmdbPointerTo(0)},
+	)
+	writeTestMMDB(t, asnDB, 4, 28,
+		testNetwork{"81.2.0.0/16", map[string]any{
+			"autonomous_system_number":       uint64(20712),
+			"autonomous_system_organization": "Andrews & Arnold Ltd, a rather long name",
+		}},
+	)
+	return cityDB, asnDB
+}
+
+func TestGeoIP(t *testing.T) {
+	cityDB, asnDB := writeTestGeoIP(t, t.TempDir())
+	geo, err := NewGeoIP(cityDB, asnDB, "")
+	require.NoError(t, err)
+
+	t.Run("Merges city and ASN databases", func(t *testing.T) {
+		assert.Equal(t, models.GeoLocation{
+			Country: "GB",
+			City:    "London",
+			ASN:     20712,
+			ASOrg:   "Andrews & Arnold Ltd, a rather long name",
+		}, geo.Lookup(net.ParseIP("81.2.69.160")))
+	})
+
+	t.Run("Follows pointers", func(t *testing.T) {
+		location := geo.Lookup(net.ParseIP("81.2.70.1"))
+		assert.Equal(t, "London", location.City)
+	})
+
+	t.Run("IPv6 falls back to the registered country", func(t *testing.T) {
+		assert.Equal(t, models.GeoLocation{Country: "DE"}, geo.Lookup(net.ParseIP("2001:db8::1")))
+	})
+
+	t.Run("Unknown IP", func(t *testing.T) {
+		assert.True(t, geo.Lookup(net.ParseIP("10.0.0.1")).IsZero())
+		assert.True(t, geo.Lookup(nil).IsZero())
+	})
+
+	t.Run("Record sizes", func(t *testing.T) {
+		for _, recordSize := range []int{24, 28, 32} {
+			path := filepath.Join(t.TempDir(), "db.mmdb")
+			writeTestMMDB(t, path, 6, recordSize,
+				testNetwork{"192.0.2.0/24", map[string]any{"country": map[string]any{"iso_code": "NL"}}})
+			db, err := NewGeoIP(path)
+			require.NoError(t, err)
+			assert.Equal(t, "NL", db.Lookup(net.ParseIP("192.0.2.7")).Country, "record size %d", recordSize)
+		}
+	})
+
+	t.Run("Invalid file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "broken.mmdb")
+		require.NoError(t, os.WriteFile(path, []byte("not a database"), 0o600))
+		_, err := NewGeoIP(path)
+		assert.ErrorIs(t, err, errMMDBInvalid)
+	})
+}
+
+func TestGeoIPReload(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "city.mmdb")
+	writeTestMMDB(t, path, 6, 24,
+		testNetwork{"192.0.2.0/24", map[string]any{"country": map[string]any{"iso_code": "NL"}}})
+	geo, err := NewGeoIP(path)
+	require.NoError(t, err)
+	ip := net.ParseIP("192.0.2.7")
+
+	reloaded, err := geo.Reload(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, reloaded)
+
+	later := time.Now().Add(time.Minute)
+	writeTestMMDB(t, path, 6, 24,
+		testNetwork{"192.0.2.0/24", map[string]any{"country": map[string]any{"iso_code": "BE"}}})
+	require.NoError(t, os.Chtimes(path, later, later))
+
+	reloaded, err = geo.Reload(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, reloaded)
+	assert.Equal(t, "BE", geo.Lookup(ip).Country)
+
+	t.Run("Broken update keeps the loaded database", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(path, []byte("half written"), 0o600))
+		later = later.Add(time.Minute)
+		require.NoError(t, os.Chtimes(path, later, later))
+
+		_, err := geo.Reload(ctx)
+		assert.ErrorIs(t, err, errMMDBInvalid)
+		assert.Equal(t, "BE", geo.Lookup(ip).Country)
+	})
+}
+
+func TestGeoIPSessions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cityDB, asnDB := writeTestGeoIP(t, t.TempDir())
+	geo, err := NewGeoIP(cityDB, asnDB)
+	require.NoError(t, err)
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	mockNotifier := NewMockNotifier(ctrl)
+	authSvc := NewAuthService(mockRepo, NewTokenService("test-secret"), mockNotifier, WithGeoIP(geo))
+	ctx := context.Background()
+	london := models.GeoLocation{Country: "GB", City: "London", ASN: 20712, ASOrg: "Andrews & Arnold Ltd, a rather long name"}
+
+	t.Run("Login records the location", func(t *testing.T) {
+		mockRepo.EXPECT().
+			SaveRefreshToken(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, token *models.RefreshToken) error {
+				assert.Equal(t, london, token.Location)
+				assert.Equal(t, london, token.LastLocation)
+				return nil
+			})
+
+		_, err := authSvc.GenerateTokens(ctx, "user1", net.ParseIP("81.2.69.160"))
+		require.NoError(t, err)
+	})
+
+	t.Run("Refresh from another IP alerts with both locations", func(t *testing.T) {
+		refreshToken := "valid-refresh-token"
+		hashedToken, _ := bcrypt.GenerateFromPassword([]byte(refreshToken), bcrypt.MinCost)
+		stored := models.RefreshToken{
+			ID:           "session1",
+			UserID:       "user1",
+			TokenHash:    string(hashedToken),
+			IP:           "81.2.69.160",
+			LastIP:       "81.2.69.160",
+			Location:     london,
+			LastLocation: london,
+			ExpiresAt:    time.Now().Add(time.Hour),
+		}
+
+		mockRepo.EXPECT().
+			GetRefreshTokensByUser(ctx, DefaultTenant, "user1").
+			Return([]models.RefreshToken{stored}, nil)
+		mockNotifier.EXPECT().
+			SendSecurityAlert("user1", gomock.Any()).
+			Do(func(_ string, msg string) {
+				assert.Contains(t, msg, "IP 2001:db8::1 (DE)")
+				assert.Contains(t, msg, "предыдущий IP 81.2.69.160 (London, GB, AS20712 Andrews & Arnold Ltd, a rather long name)")
+			})
+		mockRepo.EXPECT().
+			RotateRefreshToken(gomock.Any(), string(hashedToken), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ string, token *models.RefreshToken) error {
+				assert.Equal(t, london, token.Location)
+				assert.Equal(t, models.GeoLocation{Country: "DE"}, token.LastLocation)
+				return nil
+			})
+
+		_, err := authSvc.RefreshTokens(ctx, "user1", refreshToken, net.ParseIP("2001:db8::1"))
+		require.NoError(t, err)
+	})
+}
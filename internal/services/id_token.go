@@ -0,0 +1,147 @@
+package services
+
+import (
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const DefaultIDTokenTTL = time.Hour
+
+// Authentication method references, RFC 8176.
+const (
+	AMRPassword    = "pwd"
+	AMRMultiFactor = "mfa"
+)
+
+// Authentication context classes reported in the acr claim.
+const (
+	ACRSingleFactor = "1"
+	ACRMultiFactor  = "2"
+)
+
+type IDTokenClaims struct {
+	Nonce    string   `json:"nonce,omitempty"`
+	AuthTime int64    `json:"auth_time,omitempty"`
+	ACR      string   `json:"acr,omitempty"`
+	AMR      []string `json:"amr,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// JSONWebKey is the public part of a signing key, RFC 7517.
+type JSONWebKey struct {
+	KeyType   string `json:"kty"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid"`
+	Modulus   string `json:"n"`
+	Exponent  string `json:"e"`
+}
+
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+// IDTokenService signs OpenID Connect ID tokens with RS256, so that clients
+// can verify them without sharing a secret with the service.
+type IDTokenService struct {
+	key    *rsa.PrivateKey
+	keyID  string
+	issuer string
+}
+
+func NewIDTokenService(key *rsa.PrivateKey, issuer string) (*IDTokenService, error) {
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode signing key: %w", err)
+	}
+	sum := sha256.Sum256(der)
+
+	return &IDTokenService{
+		key:    key,
+		keyID:  base64.RawURLEncoding.EncodeToString(sum[:8]),
+		issuer: issuer,
+	}, nil
+}
+
+func (s *IDTokenService) Issuer() string {
+	return s.issuer
+}
+
+// KeySet returns the public keys that verify ID tokens.
+func (s *IDTokenService) KeySet() JSONWebKeySet {
+	return JSONWebKeySet{Keys: []JSONWebKey{{
+		KeyType:   "RSA",
+		Use:       "sig",
+		Algorithm: jwt.SigningMethodRS256.Alg(),
+		KeyID:     s.keyID,
+		Modulus:   base64.RawURLEncoding.EncodeToString(s.key.PublicKey.N.Bytes()),
+		Exponent:  base64.RawURLEncoding.EncodeToString(big.NewInt(int64(s.key.PublicKey.E)).Bytes()),
+	}}}
+}
+
+// Sign issues an ID token to the client for the user authenticated with the
+// given methods.
+func (s *IDTokenService) Sign(userID, clientID, nonce string, authTime time.Time, amr []string) (string, error) {
+	now := time.Now()
+	claims := IDTokenClaims{
+		Nonce:    nonce,
+		AuthTime: authTime.Unix(),
+		ACR:      authenticationClass(amr),
+		AMR:      amr,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    s.issuer,
+			Subject:   userID,
+			Audience:  jwt.ClaimStrings{clientID},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(DefaultIDTokenTTL)),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = s.keyID
+	return token.SignedString(s.key)
+}
+
+func authenticationClass(amr []string) string {
+	if len(amr) > 1 || hasString(amr, AMRMultiFactor) {
+		return ACRMultiFactor
+	}
+	return ACRSingleFactor
+}
+
+// LoadSigningKey reads an RSA private key from a PEM file in PKCS #1 or
+// PKCS #8 form.
+func LoadSigningKey(path string) (*rsa.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signing key: %w", err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("signing key is not PEM encoded")
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse signing key: %w", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("signing key is not an RSA key")
+	}
+	return key, nil
+}
@@ -0,0 +1,51 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/auth-service/internal/models"
+)
+
+const idleSweepBatchSize = 500
+
+// ErrSessionIdleTimeout is returned by RefreshTokens for sessions that were
+// not refreshed for their idle timeout. The session is ended.
+var ErrSessionIdleTimeout = errors.New("session_idle_timeout")
+
+// WithIdleTimeout ends sessions that were not refreshed for timeout. Clients
+// can set their own timeout with OAuthClient.IdleTimeout. Zero disables the
+// global timeout.
+func WithIdleTimeout(timeout time.Duration) AuthOption {
+	return func(s *AuthService) {
+		s.idleTimeout = timeout
+	}
+}
+
+// isIdle tells whether the session was last used longer ago than its idle
+// timeout.
+func (s *AuthService) isIdle(token *models.RefreshToken, now time.Time) bool {
+	timeout := s.idleTimeout
+	if token.IdleTimeout > 0 {
+		timeout = token.IdleTimeout
+	}
+	return timeout > 0 && now.Sub(lastUsed(*token)) > timeout
+}
+
+// SweepIdleSessions deletes the sessions that passed their idle timeout and
+// returns how many were deleted.
+func (s *AuthService) SweepIdleSessions(ctx context.Context) (int, error) {
+	swept := 0
+	for {
+		deleted, err := s.repo.DeleteIdleRefreshTokens(ctx, s.idleTimeout, time.Now(), idleSweepBatchSize)
+		swept += deleted
+		if err != nil {
+			return swept, fmt.Errorf("failed to sweep idle sessions: %w", err)
+		}
+		if deleted < idleSweepBatchSize {
+			return swept, nil
+		}
+	}
+}
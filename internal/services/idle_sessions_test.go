@@ -0,0 +1,99 @@
+package services
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/auth-service/internal/models"
+	"github.com/auth-service/internal/repository/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestIdleSessions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	mockNotifier := NewMockNotifier(ctrl)
+	authSvc := NewAuthService(mockRepo, NewTokenService("test-secret"), mockNotifier, WithIdleTimeout(24*time.Hour))
+	ctx := context.Background()
+	ip := net.ParseIP("192.168.1.1")
+
+	refreshToken := "valid-refresh-token"
+	hashedToken, _ := bcrypt.GenerateFromPassword([]byte(refreshToken), bcrypt.MinCost)
+	now := time.Now()
+	session := func(lastUsedAt time.Time, idleTimeout time.Duration) models.RefreshToken {
+		return models.RefreshToken{
+			ID:          "session1",
+			UserID:      "user1",
+			TokenHash:   string(hashedToken),
+			IP:          ip.String(),
+			IdleTimeout: idleTimeout,
+			CreatedAt:   now.Add(-72 * time.Hour),
+			LastUsedAt:  &lastUsedAt,
+			ExpiresAt:   now.Add(time.Hour),
+		}
+	}
+
+	t.Run("Idle session is rejected and ended", func(t *testing.T) {
+		mockRepo.EXPECT().
+			GetRefreshTokensByUser(ctx, DefaultTenant, "user1").
+			Return([]models.RefreshToken{session(now.Add(-25*time.Hour), 0)}, nil)
+		mockRepo.EXPECT().DeleteRefreshToken(ctx, "session1").Return(nil)
+
+		_, err := authSvc.RefreshTokens(ctx, "user1", refreshToken, ip)
+		assert.ErrorIs(t, err, ErrSessionIdleTimeout)
+	})
+
+	t.Run("Active session is refreshed", func(t *testing.T) {
+		mockRepo.EXPECT().
+			GetRefreshTokensByUser(ctx, DefaultTenant, "user1").
+			Return([]models.RefreshToken{session(now.Add(-time.Hour), 0)}, nil)
+		mockRepo.EXPECT().RotateRefreshToken(gomock.Any(), string(hashedToken), gomock.Any()).Return(nil)
+
+		_, err := authSvc.RefreshTokens(ctx, "user1", refreshToken, ip)
+		require.NoError(t, err)
+	})
+
+	t.Run("Client timeout overrides the global one", func(t *testing.T) {
+		mockRepo.EXPECT().
+			GetRefreshTokensByUser(ctx, DefaultTenant, "user1").
+			Return([]models.RefreshToken{session(now.Add(-time.Hour), 15*time.Minute)}, nil)
+		mockRepo.EXPECT().DeleteRefreshToken(ctx, "session1").Return(nil)
+
+		_, err := authSvc.RefreshTokens(ctx, "user1", refreshToken, ip)
+		assert.ErrorIs(t, err, ErrSessionIdleTimeout)
+	})
+
+	t.Run("Client timeout is stored with the session", func(t *testing.T) {
+		mockRepo.EXPECT().
+			SaveRefreshToken(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, token *models.RefreshToken) error {
+				assert.Equal(t, 15*time.Minute, token.IdleTimeout)
+				return nil
+			})
+
+		_, err := authSvc.IssueTokens(ctx, TokenGrant{UserID: "user1", ClientID: "console", IP: ip, IdleTimeout: 15 * time.Minute})
+		require.NoError(t, err)
+	})
+
+	t.Run("Sweep deletes in batches", func(t *testing.T) {
+		gomock.InOrder(
+			mockRepo.EXPECT().
+				DeleteIdleRefreshTokens(ctx, 24*time.Hour, gomock.Any(), idleSweepBatchSize).
+				Return(idleSweepBatchSize, nil),
+			mockRepo.EXPECT().
+				DeleteIdleRefreshTokens(ctx, 24*time.Hour, gomock.Any(), idleSweepBatchSize).
+				Return(3, nil),
+		)
+
+		swept, err := authSvc.SweepIdleSessions(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, idleSweepBatchSize+3, swept)
+	})
+}
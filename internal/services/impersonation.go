@@ -0,0 +1,89 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/auth-service/internal/models"
+	"github.com/auth-service/internal/repository"
+)
+
+const (
+	// PermissionImpersonate lets an admin act as another user.
+	PermissionImpersonate = "users:impersonate"
+
+	AuditUserImpersonated = "user.impersonated"
+
+	ImpersonationTokenTTL  = 10 * time.Minute
+	maxImpersonationReason = 500
+)
+
+var (
+	ErrImpersonationReason    = errors.New("impersonation reason is required")
+	ErrImpersonationForbidden = errors.New("user cannot be impersonated")
+)
+
+// ImpersonationService issues access tokens an admin uses to act as a user
+// for support. The tokens carry the admin in the act claim, cannot be
+// refreshed, and every one of them is audited and reported to the user.
+type ImpersonationService struct {
+	repo     repository.Repository
+	auth     *AuthService
+	access   AccessProvider
+	notifier Notifier
+	audit    *AuditLogger
+}
+
+func NewImpersonationService(repo repository.Repository, auth *AuthService, access AccessProvider, notifier Notifier, audit *AuditLogger) *ImpersonationService {
+	return &ImpersonationService{repo: repo, auth: auth, access: access, notifier: notifier, audit: audit}
+}
+
+// Impersonate issues an access token for userID on behalf of adminID. Users
+// who may use the admin API or impersonate others themselves cannot be
+// impersonated, that would hand their permissions to the admin.
+func (s *ImpersonationService) Impersonate(ctx context.Context, adminID, userID, reason string, ip net.IP) (*models.TokenPair, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" || utf8.RuneCountInString(reason) > maxImpersonationReason {
+		return nil, ErrImpersonationReason
+	}
+	if adminID == userID {
+		return nil, fmt.Errorf("%w: admins cannot impersonate themselves", ErrImpersonationForbidden)
+	}
+
+	if _, err := s.repo.GetUserByID(ctx, userID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	access, err := s.access.Access(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user access: %w", err)
+	}
+	if access.Can(PermissionAdmin) || access.Can(PermissionImpersonate) {
+		return nil, fmt.Errorf("%w: user is privileged", ErrImpersonationForbidden)
+	}
+
+	pair, err := s.auth.IssueTokens(ctx, TokenGrant{
+		UserID:         userID,
+		ActorID:        adminID,
+		IP:             ip,
+		AccessTokenTTL: ImpersonationTokenTTL,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.audit.Record(ctx, userID, AuditUserImpersonated, ip, map[string]string{"actor_id": adminID, "reason": reason})
+	msg := fmt.Sprintf("Администратор %s вошёл в ваш аккаунт (IP: %s). Причина: %s", adminID, ip.String(), reason)
+	if err := s.notifier.SendSecurityAlert(userID, msg); err != nil {
+		log.Printf("Failed to send impersonation alert to user %s: %v", userID, err)
+	}
+	return pair, nil
+}
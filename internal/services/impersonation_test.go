@@ -0,0 +1,75 @@
+package services
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/auth-service/internal/models"
+	"github.com/auth-service/internal/repository/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImpersonationService(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	mockNotifier := NewMockNotifier(ctrl)
+	tokenSvc := NewTokenService("test-secret")
+	auditLogger := NewAuditLogger(mockRepo)
+	rbacSvc := NewRBACService(mockRepo, auditLogger, []string{"admin1"})
+	impersonationSvc := NewImpersonationService(mockRepo, NewAuthService(mockRepo, tokenSvc, mockNotifier), rbacSvc, mockNotifier, auditLogger)
+	ctx := context.Background()
+	ip := net.ParseIP("192.168.1.1")
+
+	t.Run("Issues a non-refreshable token with the actor", func(t *testing.T) {
+		mockRepo.EXPECT().GetUserByID(ctx, "user1").Return(&models.User{ID: "user1"}, nil)
+		mockRepo.EXPECT().GetUserRoles(ctx, "user1").Return(nil, nil)
+		mockRepo.EXPECT().GetTokenCutoff(ctx, "user1").Return(time.Time{}, nil)
+		mockRepo.EXPECT().SaveAuditEvent(ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, event *models.AuditEvent) error {
+				assert.Equal(t, AuditUserImpersonated, event.Type)
+				assert.Equal(t, "user1", event.UserID)
+				return nil
+			})
+		mockNotifier.EXPECT().SendSecurityAlert("user1", gomock.Any()).
+			DoAndReturn(func(_, message string) error {
+				assert.Contains(t, message, "ticket 42")
+				return nil
+			})
+
+		pair, err := impersonationSvc.Impersonate(ctx, "admin1", "user1", " ticket 42 ", ip)
+		require.NoError(t, err)
+		assert.Empty(t, pair.RefreshToken)
+
+		claims, err := tokenSvc.ParseAccessToken(pair.AccessToken)
+		require.NoError(t, err)
+		assert.Equal(t, "user1", claims.UserID)
+		require.NotNil(t, claims.Actor)
+		assert.Equal(t, "admin1", claims.Actor.Subject)
+		assert.WithinDuration(t, time.Now().Add(ImpersonationTokenTTL), claims.ExpiresAt.Time, time.Minute)
+	})
+
+	t.Run("Reason is required", func(t *testing.T) {
+		_, err := impersonationSvc.Impersonate(ctx, "admin1", "user1", "  ", ip)
+		assert.ErrorIs(t, err, ErrImpersonationReason)
+
+		_, err = impersonationSvc.Impersonate(ctx, "admin1", "user1", strings.Repeat("a", 501), ip)
+		assert.ErrorIs(t, err, ErrImpersonationReason)
+	})
+
+	t.Run("Admins cannot be impersonated", func(t *testing.T) {
+		mockRepo.EXPECT().GetUserByID(ctx, "admin2").Return(&models.User{ID: "admin2"}, nil)
+		mockRepo.EXPECT().GetUserRoles(ctx, "admin2").
+			Return([]models.Role{{Name: "support", Permissions: []string{PermissionImpersonate}}}, nil)
+		mockRepo.EXPECT().GetTokenCutoff(ctx, "admin2").Return(time.Time{}, nil)
+
+		_, err := impersonationSvc.Impersonate(ctx, "admin1", "admin2", "ticket 42", ip)
+		assert.ErrorIs(t, err, ErrImpersonationForbidden)
+	})
+}
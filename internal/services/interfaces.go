@@ -3,19 +3,169 @@ package services
 import (
 	"context"
 	"net"
+	"time"
 
 	"github.com/auth-service/internal/models"
+	"github.com/go-webauthn/webauthn/protocol"
 )
 
 type AuthServiceInterface interface {
 	GenerateTokens(ctx context.Context, userID string, ip net.IP) (*models.TokenPair, error)
+	IssueTokens(ctx context.Context, grant TokenGrant) (*models.TokenPair, error)
 	RefreshTokens(ctx context.Context, userID, refreshToken string, ip net.IP) (*models.TokenPair, error)
+	SwitchOrganization(ctx context.Context, userID, sessionID, orgID string, ip net.IP) (*models.TokenPair, error)
+	CompleteStepUp(ctx context.Context, userID, sessionID string, ip net.IP) (*models.TokenPair, error)
 	RevokeAllTokens(ctx context.Context, userID string) error
+	Logout(ctx context.Context, userID string, token AccessTokenRef) error
+	LogoutAll(ctx context.Context, userID string, token AccessTokenRef) error
+}
+
+type MFAServiceInterface interface {
+	GenerateRecoveryCodes(ctx context.Context, userID string, ip net.IP) ([]string, error)
+	Challenge(ctx context.Context, userID string, roles []string) (string, error)
+	VerifyRecoveryCode(ctx context.Context, challenge, code string, ip net.IP) (*MFALogin, error)
+}
+
+type WebAuthnServiceInterface interface {
+	BeginRegistration(ctx context.Context, userID string) (*protocol.CredentialCreation, string, error)
+	FinishRegistration(ctx context.Context, userID, sessionID string, response []byte, ip net.IP) (*models.WebAuthnCredential, error)
+	BeginLogin(ctx context.Context, userID string) (*protocol.CredentialAssertion, string, error)
+	FinishLogin(ctx context.Context, sessionID string, response []byte, ip net.IP) (string, error)
+}
+
+type LockoutServiceInterface interface {
+	Unlock(ctx context.Context, userID, adminID string, ip net.IP) error
+}
+
+type MagicLinkServiceInterface interface {
+	Request(ctx context.Context, email string, ip net.IP) (string, error)
+	VerifyLink(ctx context.Context, token, nonce string, ip net.IP, device string) (string, error)
+	VerifyCode(ctx context.Context, code, nonce string, ip net.IP, device string) (string, error)
+}
+
+type ClientServiceInterface interface {
+	CreateClient(ctx context.Context, reg ClientRegistration, adminID string, ip net.IP) (*models.OAuthClient, string, error)
+	RotateSecret(ctx context.Context, clientID, adminID string, ip net.IP) (string, error)
+	DisableClient(ctx context.Context, clientID, adminID string, ip net.IP) error
+}
+
+type OAuthServiceInterface interface {
+	ValidateAuthorization(ctx context.Context, req AuthorizeRequest) (*Authorization, error)
+	IssueCode(ctx context.Context, auth *Authorization, authn Authentication, ip net.IP) (string, error)
+	Token(ctx context.Context, req TokenRequest) (*models.TokenPair, error)
+	DeviceAuthorization(ctx context.Context, clientID, clientSecret, scope string) (*DeviceAuthorization, error)
+	LookupDeviceCode(ctx context.Context, userCode string) (*DeviceVerification, error)
+	DecideDevice(ctx context.Context, userCode, userID string, allow bool, ip net.IP) error
+}
+
+type UserInfoServiceInterface interface {
+	UserInfo(ctx context.Context, userID, scope string) (map[string]interface{}, error)
+}
+
+type FederationServiceInterface interface {
+	Begin(ctx context.Context, provider string) (string, string, error)
+	Complete(ctx context.Context, provider, state, code string, ip net.IP) (*models.User, error)
+}
+
+type APIKeyServiceInterface interface {
+	Create(ctx context.Context, userID, name string, scopes []string, expiresAt *time.Time, ip net.IP) (*models.APIKey, string, error)
+	List(ctx context.Context, userID string) ([]models.APIKey, error)
+	Revoke(ctx context.Context, userID, id string, ip net.IP) error
+	Authenticate(ctx context.Context, rawKey string, ip net.IP) (*models.APIKey, error)
+}
+
+type RBACServiceInterface interface {
+	CreateRole(ctx context.Context, name, description string, permissions []string, adminID string, ip net.IP) (*models.Role, error)
+	ListRoles(ctx context.Context) ([]models.Role, error)
+	ListPermissions(ctx context.Context) ([]models.Permission, error)
+	UpdateRole(ctx context.Context, id, description string, permissions []string, forceRefresh bool, adminID string, ip net.IP) (*models.Role, error)
+	DeleteRole(ctx context.Context, id string, forceRefresh bool, adminID string, ip net.IP) error
+	GetUserRoles(ctx context.Context, userID string) ([]models.Role, error)
+	SetUserRoles(ctx context.Context, userID string, roleNames []string, forceRefresh bool, adminID string, ip net.IP) ([]models.Role, error)
+}
+
+type OrganizationServiceInterface interface {
+	Create(ctx context.Context, userID, name string, ip net.IP) (*models.Organization, error)
+	ListForUser(ctx context.Context, userID string) ([]models.Organization, error)
+	Membership(ctx context.Context, orgID, userID string) (*models.OrganizationMember, error)
+	Members(ctx context.Context, orgID, userID string) ([]models.OrganizationMember, error)
+	Invite(ctx context.Context, orgID, userID, email, role string, ip net.IP) (*models.OrganizationInvitation, error)
+	Accept(ctx context.Context, userID, token string, ip net.IP) (*models.OrganizationMember, error)
+	ChangeRole(ctx context.Context, orgID, userID, memberID, role string, ip net.IP) error
+	RemoveMember(ctx context.Context, orgID, userID, memberID string, ip net.IP) error
+	CreateTeam(ctx context.Context, orgID, userID, name string, ip net.IP) (*models.Team, error)
+	ListTeams(ctx context.Context, orgID, userID string) ([]models.Team, error)
+	AddTeamMember(ctx context.Context, orgID, teamID, userID, memberID string, ip net.IP) error
+	RemoveTeamMember(ctx context.Context, orgID, teamID, userID, memberID string, ip net.IP) error
+}
+
+type ProfileServiceInterface interface {
+	Get(ctx context.Context, userID string) (*models.User, error)
+	Update(ctx context.Context, userID string, update ProfileUpdate, ip net.IP) (*models.User, *models.EmailChange, error)
+	ConfirmEmailChange(ctx context.Context, token string, ip net.IP) (*models.EmailChange, bool, error)
+}
+
+type AccountServiceInterface interface {
+	Export(ctx context.Context, userID string, ip net.IP) (*AccountExport, error)
+	ScheduleDeletion(ctx context.Context, userID string, ip net.IP) (time.Time, error)
+	CancelDeletion(ctx context.Context, userID string, ip net.IP) error
+}
+
+type SessionServiceInterface interface {
+	List(ctx context.Context, userID, currentID string, page, perPage int) (*SessionPage, error)
+	Revoke(ctx context.Context, actorID, sessionID string, ip net.IP) error
+}
+
+type ImpersonationServiceInterface interface {
+	Impersonate(ctx context.Context, adminID, userID, reason string, ip net.IP) (*models.TokenPair, error)
+}
+
+// AccessProvider returns the roles and permissions assigned to a user.
+type AccessProvider interface {
+	Access(ctx context.Context, userID string) (*Access, error)
+}
+
+// JobStatsProvider reports the metrics of the background jobs.
+type JobStatsProvider interface {
+	Stats() []JobStats
+}
+
+// TenantMembership tells whether a user belongs to the tenant of the request.
+type TenantMembership interface {
+	OwnsUser(ctx context.Context, userID string) (bool, error)
+}
+
+// Authenticator verifies a user's primary credentials.
+type Authenticator interface {
+	Authenticate(ctx context.Context, email, password string, ip net.IP) (*models.User, error)
+}
+
+// RealmAuthenticator verifies a password with the backend of a realm.
+type RealmAuthenticator interface {
+	AuthenticateRealm(ctx context.Context, realm, login, password string, ip net.IP) (*models.User, error)
 }
 
 type Notifier interface {
 	SendSecurityAlert(userID, message string) error
+	SendEmail(to, subject, body string) error
 }
 
 //go:generate mockgen -destination=mock_auth_service.go -package=services . AuthServiceInterface
+//go:generate mockgen -destination=mock_mfa_service.go -package=services . MFAServiceInterface
+//go:generate mockgen -destination=mock_webauthn_service.go -package=services . WebAuthnServiceInterface
+//go:generate mockgen -destination=mock_lockout_service.go -package=services . LockoutServiceInterface
+//go:generate mockgen -destination=mock_magic_link_service.go -package=services . MagicLinkServiceInterface
+//go:generate mockgen -destination=mock_client_service.go -package=services . ClientServiceInterface
+//go:generate mockgen -destination=mock_oauth_service.go -package=services . OAuthServiceInterface
+//go:generate mockgen -destination=mock_userinfo_service.go -package=services . UserInfoServiceInterface
+//go:generate mockgen -destination=mock_federation_service.go -package=services . FederationServiceInterface
+//go:generate mockgen -destination=mock_api_key_service.go -package=services . APIKeyServiceInterface
+//go:generate mockgen -destination=mock_rbac_service.go -package=services . RBACServiceInterface
+//go:generate mockgen -destination=mock_organization_service.go -package=services . OrganizationServiceInterface
+//go:generate mockgen -destination=mock_profile_service.go -package=services . ProfileServiceInterface
+//go:generate mockgen -destination=mock_account_service.go -package=services . AccountServiceInterface
+//go:generate mockgen -destination=mock_session_service.go -package=services . SessionServiceInterface
+//go:generate mockgen -destination=mock_impersonation_service.go -package=services . ImpersonationServiceInterface
+//go:generate mockgen -destination=mock_authenticator.go -package=services . Authenticator
+//go:generate mockgen -destination=mock_realm_authenticator.go -package=services . RealmAuthenticator
 //go:generate mockgen -destination=mock_notifier.go -package=services . Notifier
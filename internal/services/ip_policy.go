@@ -0,0 +1,248 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/auth-service/internal/models"
+)
+
+// IPChangeMode is what happens when a session is refreshed from another IP
+// than it was last used from.
+type IPChangeMode string
+
+const (
+	// IPChangeAllow lets the refresh through silently.
+	IPChangeAllow IPChangeMode = "allow"
+	// IPChangeNotify lets the refresh through and alerts the user.
+	IPChangeNotify IPChangeMode = "notify"
+	// IPChangeStepUp refuses the refresh with a StepUpError. Once the user
+	// enters a second factor from the new IP, the session continues from
+	// there. It also stays usable from its last IP.
+	IPChangeStepUp IPChangeMode = "step_up"
+	// IPChangeDeny refuses the refresh and ends the session.
+	IPChangeDeny IPChangeMode = "deny"
+
+	AuditSessionIPChange = "session.ip_change"
+)
+
+var (
+	ErrStepUpRequired = errors.New("step_up_required")
+	ErrIPChangeDenied = errors.New("ip_change_denied")
+)
+
+// StepUpError is returned when a refresh from a new IP needs a second
+// factor. Challenge is passed with a recovery code to complete the step-up.
+type StepUpError struct {
+	Challenge string
+}
+
+func (e *StepUpError) Error() string {
+	return ErrStepUpRequired.Error()
+}
+
+func (e *StepUpError) Unwrap() error {
+	return ErrStepUpRequired
+}
+
+// Reasons of IP change decisions, as recorded in the audit log.
+const (
+	ipChangeReasonAllowedNetwork = "allowed_network"
+	ipChangeReasonSameSubnet     = "same_subnet"
+	ipChangeReasonSameASN        = "same_asn"
+	ipChangeReasonSameCountry    = "same_country"
+	ipChangeReasonChanged        = "ip_changed"
+	ipChangeReasonStepUp         = "step_up"
+)
+
+// IPChangePolicy decides about refreshes from a new IP. Changes the
+// tolerance rules match are allowed whatever the mode: moves within the same
+// /24 (IPv4) or /64 (IPv6) network, the same autonomous system or the same
+// country, and moves to one of AllowedNetworks. ASN and country need GeoIP.
+// Clients can set their own mode with OAuthClient.IPChangePolicy.
+type IPChangePolicy struct {
+	Mode            IPChangeMode
+	SameSubnet      bool
+	SameASN         bool
+	SameCountry     bool
+	AllowedNetworks []*net.IPNet
+}
+
+// ParseIPChangeMode returns the mode with the name, notify when the name is
+// empty.
+func ParseIPChangeMode(name string) (IPChangeMode, error) {
+	switch mode := IPChangeMode(name); mode {
+	case "":
+		return IPChangeNotify, nil
+	case IPChangeAllow, IPChangeNotify, IPChangeStepUp, IPChangeDeny:
+		return mode, nil
+	}
+	return "", fmt.Errorf("unknown IP change policy %q", name)
+}
+
+// ParseNetworks parses CIDRs like 10.0.0.0/8 or 2001:db8::/32.
+func ParseNetworks(cidrs []string) ([]*net.IPNet, error) {
+	networks := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(strings.TrimSpace(cidr))
+		if err != nil {
+			return nil, fmt.Errorf("invalid network %q: %w", cidr, err)
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
+
+// WithIPChangePolicy sets what happens on refreshes from a new IP. Every
+// decision is recorded in the audit log. Without it the user is notified.
+func WithIPChangePolicy(policy IPChangePolicy, audit *AuditLogger) AuthOption {
+	return func(s *AuthService) {
+		s.ipPolicy = policy
+		s.audit = audit
+	}
+}
+
+// evaluate returns the mode for a move from previous to current and why.
+func (p IPChangePolicy) evaluate(mode IPChangeMode, previous, current net.IP, previousLocation, currentLocation models.GeoLocation) (IPChangeMode, string) {
+	for _, network := range p.AllowedNetworks {
+		if network.Contains(current) {
+			return IPChangeAllow, ipChangeReasonAllowedNetwork
+		}
+	}
+	if p.SameSubnet && sameSubnet(previous, current) {
+		return IPChangeAllow, ipChangeReasonSameSubnet
+	}
+	if p.SameASN && currentLocation.ASN != 0 && currentLocation.ASN == previousLocation.ASN {
+		return IPChangeAllow, ipChangeReasonSameASN
+	}
+	if p.SameCountry && currentLocation.Country != "" && currentLocation.Country == previousLocation.Country {
+		return IPChangeAllow, ipChangeReasonSameCountry
+	}
+	return mode, ipChangeReasonChanged
+}
+
+// sameSubnet tells whether both IPs are in the same /24, or /64 for IPv6.
+func sameSubnet(a, b net.IP) bool {
+	if a4, b4 := a.To4(), b.To4(); a4 != nil || b4 != nil {
+		mask := net.CIDRMask(24, 32)
+		return a4 != nil && b4 != nil && a4.Mask(mask).Equal(b4.Mask(mask))
+	}
+	mask := net.CIDRMask(64, 128)
+	return a != nil && b != nil && a.Mask(mask).Equal(b.Mask(mask))
+}
+
+// checkIPChange applies the IP change policy to a refresh of token from
+// clientIP and returns the mode to apply. Refreshes from the last IP of the
+// session are allowed without a record.
+func (s *AuthService) checkIPChange(ctx context.Context, userID string, token *models.RefreshToken, clientIP net.IP) IPChangeMode {
+	lastIP := lastIPOf(*token)
+	if lastIP == clientIP.String() {
+		return IPChangeAllow
+	}
+
+	mode := s.ipPolicy.Mode
+	if token.IPChangePolicy != "" {
+		mode = IPChangeMode(token.IPChangePolicy)
+	}
+	if mode == "" {
+		mode = IPChangeNotify
+	}
+
+	previousLocation, currentLocation := lastLocationOf(*token), s.locate(clientIP)
+	mode, reason := s.ipPolicy.evaluate(mode, net.ParseIP(lastIP), clientIP, previousLocation, currentLocation)
+
+	if s.audit != nil {
+		details := map[string]string{
+			"session_id":  token.ID,
+			"previous_ip": lastIP,
+			"decision":    string(mode),
+			"reason":      reason,
+		}
+		if token.ClientID != "" {
+			details["client_id"] = token.ClientID
+		}
+		if where := formatLocation(currentLocation); where != "" {
+			details["location"] = where
+		}
+		if where := formatLocation(previousLocation); where != "" {
+			details["previous_location"] = where
+		}
+		s.audit.Record(ctx, userID, AuditSessionIPChange, clientIP, details)
+	}
+	return mode
+}
+
+// refuseIPChange ends or holds a session refreshed from a new IP and tells
+// the user about it.
+func (s *AuthService) refuseIPChange(ctx context.Context, userID string, token *models.RefreshToken, clientIP net.IP, mode IPChangeMode) error {
+	current := formatIP(clientIP.String(), s.locate(clientIP))
+	previous := formatIP(lastIPOf(*token), lastLocationOf(*token))
+
+	if mode == IPChangeDeny {
+		if err := s.repo.DeleteRefreshToken(ctx, token.ID); err != nil {
+			log.Printf("Failed to revoke session %s of user %s after an IP change: %v", token.ID, userID, err)
+		}
+		s.notifier.SendSecurityAlert(userID, fmt.Sprintf(
+			"Сессия завершена: попытка продолжить её с нового IP %s, предыдущий IP %s. Войдите в аккаунт заново",
+			current, previous))
+		return ErrIPChangeDenied
+	}
+
+	challenge, err := s.tokenService.SignStepUpChallenge(TenantFromContext(ctx), userID, token.ID, clientIP)
+	if err != nil {
+		return fmt.Errorf("failed to sign step-up challenge: %w", err)
+	}
+	s.notifier.SendSecurityAlert(userID, fmt.Sprintf(
+		"Попытка продолжить сессию с нового IP %s (предыдущий IP %s) приостановлена: для продолжения нужно ввести код восстановления",
+		current, previous))
+	return &StepUpError{Challenge: challenge}
+}
+
+// CompleteStepUp continues a session held by IPChangeStepUp from the IP the
+// second factor was entered from. The session is rotated and its last IP
+// becomes clientIP, so later refreshes from there pass.
+func (s *AuthService) CompleteStepUp(ctx context.Context, userID, sessionID string, clientIP net.IP) (*models.TokenPair, error) {
+	session, err := s.userSession(ctx, userID, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if s.isIdle(session, time.Now()) {
+		return nil, ErrSessionIdleTimeout
+	}
+	if err := s.checkSessionClient(ctx, session); err != nil {
+		return nil, err
+	}
+
+	if s.audit != nil {
+		details := map[string]string{
+			"session_id":  session.ID,
+			"previous_ip": lastIPOf(*session),
+			"decision":    string(IPChangeAllow),
+			"reason":      ipChangeReasonStepUp,
+		}
+		if session.ClientID != "" {
+			details["client_id"] = session.ClientID
+		}
+		s.audit.Record(ctx, userID, AuditSessionIPChange, clientIP, details)
+	}
+
+	pair, err := s.issue(ctx, TokenGrant{
+		UserID:          userID,
+		ClientID:        session.ClientID,
+		Scope:           session.Scope,
+		Roles:           session.Roles,
+		OrgID:           session.OrgID,
+		IP:              clientIP,
+		AccessTokenTTL:  session.AccessTokenTTL,
+		RefreshTokenTTL: session.RefreshTokenTTL,
+	}, session)
+	if err != nil && err.Error() == "refresh token not found in DB" {
+		return nil, ErrSessionNotFound
+	}
+	return pair, err
+}
@@ -0,0 +1,193 @@
+package services
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/auth-service/internal/models"
+	"github.com/auth-service/internal/repository/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestIPChangePolicyEvaluate(t *testing.T) {
+	corporate, err := ParseNetworks([]string{"203.0.113.0/24", " 2001:db8:1::/48"})
+	require.NoError(t, err)
+	moscow := models.GeoLocation{Country: "RU", ASN: 12389}
+	policy := IPChangePolicy{Mode: IPChangeDeny, SameSubnet: true, SameASN: true, SameCountry: true, AllowedNetworks: corporate}
+
+	tests := []struct {
+		name                string
+		policy              IPChangePolicy
+		previous, current   string
+		previousLoc, curLoc models.GeoLocation
+		mode                IPChangeMode
+		reason              string
+	}{
+		{"Corporate network", policy, "198.51.100.1", "203.0.113.9", models.GeoLocation{}, models.GeoLocation{}, IPChangeAllow, ipChangeReasonAllowedNetwork},
+		{"Corporate IPv6 network", policy, "198.51.100.1", "2001:db8:1:2::1", models.GeoLocation{}, models.GeoLocation{}, IPChangeAllow, ipChangeReasonAllowedNetwork},
+		{"Same /24", policy, "198.51.100.1", "198.51.100.200", models.GeoLocation{}, models.GeoLocation{}, IPChangeAllow, ipChangeReasonSameSubnet},
+		{"Same /64", policy, "2001:db8:2:3::1", "2001:db8:2:3::ffff", models.GeoLocation{}, models.GeoLocation{}, IPChangeAllow, ipChangeReasonSameSubnet},
+		{"IPv4 and IPv6", policy, "198.51.100.1", "2001:db8:2:3::1", models.GeoLocation{}, models.GeoLocation{}, IPChangeDeny, ipChangeReasonChanged},
+		{"Same ASN", policy, "198.51.100.1", "192.0.2.1", moscow, moscow, IPChangeAllow, ipChangeReasonSameASN},
+		{"Same country", policy, "198.51.100.1", "192.0.2.1", moscow, models.GeoLocation{Country: "RU", ASN: 8359}, IPChangeAllow, ipChangeReasonSameCountry},
+		{"Unknown locations do not match", policy, "198.51.100.1", "192.0.2.1", models.GeoLocation{}, models.GeoLocation{}, IPChangeDeny, ipChangeReasonChanged},
+		{"Rules are opt-in", IPChangePolicy{Mode: IPChangeStepUp}, "198.51.100.1", "198.51.100.2", moscow, moscow, IPChangeStepUp, ipChangeReasonChanged},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mode, reason := tt.policy.evaluate(tt.policy.Mode, net.ParseIP(tt.previous), net.ParseIP(tt.current), tt.previousLoc, tt.curLoc)
+			assert.Equal(t, tt.mode, mode)
+			assert.Equal(t, tt.reason, reason)
+		})
+	}
+
+	_, err = ParseNetworks([]string{"10.0.0.0/33"})
+	assert.Error(t, err)
+	_, err = ParseIPChangeMode("block")
+	assert.Error(t, err)
+}
+
+func TestIPChangePolicy(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	mockNotifier := NewMockNotifier(ctrl)
+	ctx := context.Background()
+	newIP := net.ParseIP("192.0.2.1")
+
+	refreshToken := "valid-refresh-token"
+	hashedToken, _ := bcrypt.GenerateFromPassword([]byte(refreshToken), bcrypt.MinCost)
+	session := models.RefreshToken{
+		ID:        "session1",
+		UserID:    "user1",
+		TokenHash: string(hashedToken),
+		IP:        "198.51.100.1",
+		LastIP:    "198.51.100.7",
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+	tokens := NewTokenService("test-secret")
+	newService := func(policy IPChangePolicy) *AuthService {
+		return NewAuthService(mockRepo, tokens, mockNotifier,
+			WithIPChangePolicy(policy, NewAuditLogger(mockRepo)))
+	}
+	expectDecision := func(ip, decision, reason string) {
+		mockRepo.EXPECT().
+			SaveAuditEvent(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, event *models.AuditEvent) error {
+				assert.Equal(t, AuditSessionIPChange, event.Type)
+				assert.Equal(t, ip, event.IP)
+				assert.Equal(t, "session1", event.Details["session_id"])
+				assert.Equal(t, "198.51.100.7", event.Details["previous_ip"])
+				assert.Equal(t, decision, event.Details["decision"])
+				assert.Equal(t, reason, event.Details["reason"])
+				return nil
+			})
+	}
+
+	t.Run("Deny ends the session", func(t *testing.T) {
+		authSvc := newService(IPChangePolicy{Mode: IPChangeDeny})
+		mockRepo.EXPECT().GetRefreshTokensByUser(ctx, DefaultTenant, "user1").Return([]models.RefreshToken{session}, nil)
+		expectDecision("192.0.2.1", "deny", "ip_changed")
+		mockRepo.EXPECT().DeleteRefreshToken(ctx, "session1").Return(nil)
+		mockNotifier.EXPECT().SendSecurityAlert("user1", gomock.Any()).
+			Do(func(_ string, msg string) {
+				assert.Contains(t, msg, "Сессия завершена")
+				assert.Contains(t, msg, "192.0.2.1")
+			})
+
+		_, err := authSvc.RefreshTokens(ctx, "user1", refreshToken, newIP)
+		assert.ErrorIs(t, err, ErrIPChangeDenied)
+	})
+
+	t.Run("Step-up keeps the session", func(t *testing.T) {
+		authSvc := newService(IPChangePolicy{Mode: IPChangeStepUp})
+		mockRepo.EXPECT().GetRefreshTokensByUser(ctx, DefaultTenant, "user1").Return([]models.RefreshToken{session}, nil)
+		expectDecision("192.0.2.1", "step_up", "ip_changed")
+		mockNotifier.EXPECT().SendSecurityAlert("user1", gomock.Any()).
+			Do(func(_ string, msg string) {
+				assert.Contains(t, msg, "код восстановления")
+			})
+
+		_, err := authSvc.RefreshTokens(ctx, "user1", refreshToken, newIP)
+		assert.ErrorIs(t, err, ErrStepUpRequired)
+
+		var stepUp *StepUpError
+		require.ErrorAs(t, err, &stepUp)
+		claims, err := tokens.ParseMFAChallenge(stepUp.Challenge)
+		require.NoError(t, err)
+		assert.Equal(t, "user1", claims.UserID)
+		assert.Equal(t, "session1", claims.SessionID)
+		assert.Equal(t, "192.0.2.1", claims.IP)
+	})
+
+	t.Run("Completed step-up continues the session from the new IP", func(t *testing.T) {
+		authSvc := newService(IPChangePolicy{Mode: IPChangeStepUp})
+		mockRepo.EXPECT().GetRefreshTokenByID(ctx, DefaultTenant, "session1").Return(&session, nil)
+		expectDecision("192.0.2.1", "allow", "step_up")
+		mockRepo.EXPECT().
+			RotateRefreshToken(gomock.Any(), string(hashedToken), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ string, token *models.RefreshToken) error {
+				assert.Equal(t, "session1", token.ID)
+				assert.Equal(t, "192.0.2.1", token.LastIP)
+				assert.Equal(t, "198.51.100.1", token.IP)
+				return nil
+			})
+
+		pair, err := authSvc.CompleteStepUp(ctx, "user1", "session1", newIP)
+		require.NoError(t, err)
+
+		claims, err := tokens.ParseAccessToken(pair.AccessToken)
+		require.NoError(t, err)
+		assert.Equal(t, "session1", claims.SessionID)
+	})
+
+	t.Run("Step-up of another user's session", func(t *testing.T) {
+		authSvc := newService(IPChangePolicy{Mode: IPChangeStepUp})
+		mockRepo.EXPECT().GetRefreshTokenByID(ctx, DefaultTenant, "session1").Return(&session, nil)
+
+		_, err := authSvc.CompleteStepUp(ctx, "user2", "session1", newIP)
+		assert.ErrorIs(t, err, ErrSessionNotFound)
+	})
+
+	t.Run("Client policy overrides the global one", func(t *testing.T) {
+		authSvc := newService(IPChangePolicy{Mode: IPChangeDeny})
+		clientSession := session
+		clientSession.IPChangePolicy = string(IPChangeAllow)
+		mockRepo.EXPECT().GetRefreshTokensByUser(ctx, DefaultTenant, "user1").Return([]models.RefreshToken{clientSession}, nil)
+		expectDecision("192.0.2.1", "allow", "ip_changed")
+		mockRepo.EXPECT().
+			RotateRefreshToken(gomock.Any(), string(hashedToken), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ string, token *models.RefreshToken) error {
+				assert.Equal(t, "allow", token.IPChangePolicy)
+				return nil
+			})
+
+		_, err := authSvc.RefreshTokens(ctx, "user1", refreshToken, newIP)
+		require.NoError(t, err)
+	})
+
+	t.Run("Tolerated change is not alerted", func(t *testing.T) {
+		authSvc := newService(IPChangePolicy{Mode: IPChangeDeny, SameSubnet: true})
+		mockRepo.EXPECT().GetRefreshTokensByUser(ctx, DefaultTenant, "user1").Return([]models.RefreshToken{session}, nil)
+		expectDecision("198.51.100.99", "allow", "same_subnet")
+		mockRepo.EXPECT().RotateRefreshToken(gomock.Any(), string(hashedToken), gomock.Any()).Return(nil)
+
+		_, err := authSvc.RefreshTokens(ctx, "user1", refreshToken, net.ParseIP("198.51.100.99"))
+		require.NoError(t, err)
+	})
+
+	t.Run("Same IP is not recorded", func(t *testing.T) {
+		authSvc := newService(IPChangePolicy{Mode: IPChangeDeny})
+		mockRepo.EXPECT().GetRefreshTokensByUser(ctx, DefaultTenant, "user1").Return([]models.RefreshToken{session}, nil)
+		mockRepo.EXPECT().RotateRefreshToken(gomock.Any(), string(hashedToken), gomock.Any()).Return(nil)
+
+		_, err := authSvc.RefreshTokens(ctx, "user1", refreshToken, net.ParseIP("198.51.100.7"))
+		require.NoError(t, err)
+	})
+}
@@ -0,0 +1,159 @@
+package services
+
+import (
+	"context"
+	"log"
+	"runtime/debug"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Job is a task the JobManager runs every Interval. Run does one pass and
+// returns how many records it processed.
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context) (int, error)
+}
+
+// JobStats are the metrics of a job since the start of the service.
+type JobStats struct {
+	Name         string        `json:"name"`
+	Interval     time.Duration `json:"interval"`
+	Runs         int64         `json:"runs"`
+	Failures     int64         `json:"failures"`
+	Processed    int64         `json:"processed"`
+	Running      bool          `json:"running"`
+	LastRunAt    *time.Time    `json:"last_run_at,omitempty"`
+	LastDuration time.Duration `json:"last_duration"`
+	LastError    string        `json:"last_error,omitempty"`
+}
+
+// JobManager runs background jobs next to the HTTP server. Every job runs
+// on start and then every interval, never concurrently with itself.
+type JobManager struct {
+	jobs   []Job
+	mu     sync.Mutex
+	stats  map[string]*JobStats
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewJobManager() *JobManager {
+	return &JobManager{stats: make(map[string]*JobStats)}
+}
+
+// Add registers a job. Jobs have to be added before Start, jobs with a zero
+// interval are skipped.
+func (m *JobManager) Add(job Job) {
+	if job.Interval <= 0 {
+		log.Printf("Background job %s is disabled", job.Name)
+		return
+	}
+	m.jobs = append(m.jobs, job)
+	m.stats[job.Name] = &JobStats{Name: job.Name, Interval: job.Interval}
+}
+
+// Start runs the jobs until Stop is called or ctx is cancelled.
+func (m *JobManager) Start(ctx context.Context) {
+	ctx, m.cancel = context.WithCancel(ctx)
+	for _, job := range m.jobs {
+		m.wg.Add(1)
+		go m.loop(ctx, job)
+	}
+}
+
+// Stop cancels the jobs and waits for the running passes to finish, or for
+// ctx to be done. Passes see the cancellation through their context.
+func (m *JobManager) Stop(ctx context.Context) error {
+	if m.cancel != nil {
+		m.cancel()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		m.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Stats returns the metrics of the jobs ordered by name.
+func (m *JobManager) Stats() []JobStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats := make([]JobStats, 0, len(m.stats))
+	for _, s := range m.stats {
+		stats = append(stats, *s)
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Name < stats[j].Name })
+	return stats
+}
+
+func (m *JobManager) loop(ctx context.Context, job Job) {
+	defer m.wg.Done()
+
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+
+	for {
+		m.runOnce(ctx, job)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (m *JobManager) runOnce(ctx context.Context, job Job) {
+	started := time.Now()
+	m.update(job.Name, func(s *JobStats) { s.Running = true })
+
+	processed, err := m.run(ctx, job)
+
+	m.update(job.Name, func(s *JobStats) {
+		s.Running = false
+		s.Runs++
+		s.Processed += int64(processed)
+		s.LastRunAt = &started
+		s.LastDuration = time.Since(started)
+		s.LastError = ""
+		if err != nil {
+			s.Failures++
+			s.LastError = err.Error()
+		}
+	})
+
+	switch {
+	case err != nil:
+		log.Printf("Background job %s failed: %v", job.Name, err)
+	case processed > 0:
+		log.Printf("Background job %s processed %d records", job.Name, processed)
+	}
+}
+
+// run keeps a panicking job from taking the service down.
+func (m *JobManager) run(ctx context.Context, job Job) (processed int, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("Background job %s panicked: %v\n%s", job.Name, r, string(debug.Stack()))
+			err = errJobPanicked
+		}
+	}()
+	return job.Run(ctx)
+}
+
+func (m *JobManager) update(name string, apply func(*JobStats)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	apply(m.stats[name])
+}
@@ -0,0 +1,99 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJobManager(t *testing.T) {
+	t.Run("Runs jobs on start and records stats", func(t *testing.T) {
+		manager := NewJobManager()
+		ran := make(chan struct{}, 1)
+		manager.Add(Job{Name: "purge", Interval: time.Hour, Run: func(ctx context.Context) (int, error) {
+			ran <- struct{}{}
+			return 5, nil
+		}})
+		manager.Add(Job{Name: "disabled", Run: func(ctx context.Context) (int, error) {
+			t.Error("disabled job must not run")
+			return 0, nil
+		}})
+
+		manager.Start(context.Background())
+		<-ran
+		require.NoError(t, manager.Stop(context.Background()))
+
+		stats := manager.Stats()
+		require.Len(t, stats, 1)
+		assert.Equal(t, "purge", stats[0].Name)
+		assert.Equal(t, int64(1), stats[0].Runs)
+		assert.Equal(t, int64(5), stats[0].Processed)
+		assert.NotNil(t, stats[0].LastRunAt)
+		assert.False(t, stats[0].Running)
+	})
+
+	t.Run("Failures and panics are recorded", func(t *testing.T) {
+		manager := NewJobManager()
+		done := make(chan struct{}, 2)
+		manager.Add(Job{Name: "failing", Interval: time.Hour, Run: func(ctx context.Context) (int, error) {
+			defer func() { done <- struct{}{} }()
+			return 0, errors.New("db error")
+		}})
+		manager.Add(Job{Name: "panicking", Interval: time.Hour, Run: func(ctx context.Context) (int, error) {
+			defer func() { done <- struct{}{} }()
+			panic("boom")
+		}})
+
+		manager.Start(context.Background())
+		<-done
+		<-done
+		require.NoError(t, manager.Stop(context.Background()))
+
+		stats := manager.Stats()
+		require.Len(t, stats, 2)
+		assert.Equal(t, int64(1), stats[0].Failures)
+		assert.Equal(t, "db error", stats[0].LastError)
+		assert.Equal(t, int64(1), stats[1].Failures)
+		assert.Equal(t, errJobPanicked.Error(), stats[1].LastError)
+	})
+
+	t.Run("Stop waits for the running pass", func(t *testing.T) {
+		manager := NewJobManager()
+		started := make(chan struct{})
+		finished := false
+		manager.Add(Job{Name: "slow", Interval: time.Hour, Run: func(ctx context.Context) (int, error) {
+			close(started)
+			<-ctx.Done()
+			time.Sleep(10 * time.Millisecond)
+			finished = true
+			return 0, nil
+		}})
+
+		manager.Start(context.Background())
+		<-started
+		require.NoError(t, manager.Stop(context.Background()))
+		assert.True(t, finished)
+	})
+
+	t.Run("Stop gives up after the deadline", func(t *testing.T) {
+		manager := NewJobManager()
+		started := make(chan struct{})
+		release := make(chan struct{})
+		defer close(release)
+		manager.Add(Job{Name: "stuck", Interval: time.Hour, Run: func(ctx context.Context) (int, error) {
+			close(started)
+			<-release
+			return 0, nil
+		}})
+
+		manager.Start(context.Background())
+		<-started
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		assert.ErrorIs(t, manager.Stop(ctx), context.DeadlineExceeded)
+	})
+}
@@ -0,0 +1,300 @@
+package services
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/auth-service/internal/models"
+	"github.com/auth-service/internal/repository"
+	"github.com/go-ldap/ldap/v3"
+)
+
+const (
+	AuditLDAPLogin = "login.ldap"
+
+	ldapProvider = "ldap"
+)
+
+// LDAPConfig describes the directory an LDAPAuthenticator binds against.
+// %s in UserFilter is replaced with the escaped login, in GroupFilter with the
+// escaped DN of the user. GroupRoles maps a group DN or cn to a role.
+type LDAPConfig struct {
+	URL            string
+	StartTLS       bool
+	BindDN         string
+	BindPassword   string
+	UserBaseDN     string
+	UserFilter     string
+	GroupBaseDN    string
+	GroupFilter    string
+	IDAttribute    string
+	EmailAttribute string
+	NameAttribute  string
+	GroupRoles     map[string]string
+	Timeout        time.Duration
+}
+
+// LDAPAuthenticator verifies passwords with a search and bind against an
+// LDAP directory or Active Directory. Directory users get a local account
+// linked to their entry on the first login.
+type LDAPAuthenticator struct {
+	cfg     LDAPConfig
+	repo    federationRepository
+	lockout *LockoutService
+	audit   *AuditLogger
+}
+
+func NewLDAPAuthenticator(cfg LDAPConfig, repo federationRepository, lockout *LockoutService, audit *AuditLogger) *LDAPAuthenticator {
+	if cfg.EmailAttribute == "" {
+		cfg.EmailAttribute = "mail"
+	}
+	if cfg.NameAttribute == "" {
+		cfg.NameAttribute = "cn"
+	}
+	roles := make(map[string]string, len(cfg.GroupRoles))
+	for group, role := range cfg.GroupRoles {
+		roles[strings.ToLower(group)] = role
+	}
+	cfg.GroupRoles = roles
+
+	return &LDAPAuthenticator{
+		cfg:     cfg,
+		repo:    repo,
+		lockout: lockout,
+		audit:   audit,
+	}
+}
+
+func (a *LDAPAuthenticator) Authenticate(ctx context.Context, login, password string, ip net.IP) (*models.User, error) {
+	conn, err := a.connect()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if err := a.bindService(conn); err != nil {
+		return nil, err
+	}
+
+	entry, err := a.findUser(conn, strings.TrimSpace(login))
+	if err != nil {
+		return nil, err
+	}
+
+	var userID string
+	if entry != nil {
+		if identity, err := a.repo.GetFederatedIdentity(ctx, TenantFromContext(ctx), a.cfg.URL, a.subject(entry)); err == nil {
+			userID = identity.UserID
+		} else if !errors.Is(err, repository.ErrNotFound) {
+			return nil, fmt.Errorf("failed to get directory identity: %w", err)
+		}
+	}
+	if err := a.lockout.Check(ctx, userID, ip); err != nil {
+		return nil, err
+	}
+
+	// An empty password would be an unauthenticated bind, which most
+	// directories accept, RFC 4513 section 5.1.2.
+	if entry == nil || password == "" {
+		a.lockout.RegisterFailure(ctx, AttemptLogin, userID, ip)
+		return nil, ErrInvalidCredentials
+	}
+
+	if err := conn.Bind(entry.DN, password); err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) {
+			a.lockout.RegisterFailure(ctx, AttemptLogin, userID, ip)
+			return nil, ErrInvalidCredentials
+		}
+		return nil, fmt.Errorf("failed to bind as %s: %w", entry.DN, err)
+	}
+
+	// Group entries are often readable by the service account only.
+	if err := a.bindService(conn); err != nil {
+		return nil, err
+	}
+	roles, err := a.roles(conn, entry)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := a.localUser(ctx, entry, userID)
+	if err != nil {
+		return nil, err
+	}
+	user.Roles = roles
+
+	a.lockout.RegisterSuccess(ctx, user.ID)
+	a.audit.Record(ctx, user.ID, AuditLDAPLogin, ip, nil)
+	return user, nil
+}
+
+func (a *LDAPAuthenticator) connect() (*ldap.Conn, error) {
+	conn, err := ldap.DialURL(a.cfg.URL, ldap.DialWithDialer(&net.Dialer{Timeout: a.cfg.Timeout}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to LDAP: %w", err)
+	}
+	if a.cfg.Timeout > 0 {
+		conn.SetTimeout(a.cfg.Timeout)
+	}
+
+	if a.cfg.StartTLS {
+		parsed, err := url.Parse(a.cfg.URL)
+		if err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("invalid LDAP URL: %w", err)
+		}
+		if err := conn.StartTLS(&tls.Config{ServerName: parsed.Hostname()}); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("failed to start TLS with LDAP: %w", err)
+		}
+	}
+	return conn, nil
+}
+
+func (a *LDAPAuthenticator) bindService(conn *ldap.Conn) error {
+	var err error
+	if a.cfg.BindDN == "" {
+		err = conn.UnauthenticatedBind("")
+	} else {
+		err = conn.Bind(a.cfg.BindDN, a.cfg.BindPassword)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to bind LDAP service account: %w", err)
+	}
+	return nil
+}
+
+// findUser returns the entry of the login, or nil when the login does not
+// match exactly one entry.
+func (a *LDAPAuthenticator) findUser(conn *ldap.Conn, login string) (*ldap.Entry, error) {
+	if login == "" {
+		return nil, nil
+	}
+
+	result, err := conn.Search(ldap.NewSearchRequest(
+		a.cfg.UserBaseDN,
+		ldap.ScopeWholeSubtree,
+		ldap.NeverDerefAliases,
+		2, 0, false,
+		strings.ReplaceAll(a.cfg.UserFilter, "%s", ldap.EscapeFilter(login)),
+		a.attributes(),
+		nil,
+	))
+	if err != nil && !ldap.IsErrorWithCode(err, ldap.LDAPResultSizeLimitExceeded) {
+		return nil, fmt.Errorf("failed to search LDAP user: %w", err)
+	}
+	if result == nil || len(result.Entries) != 1 {
+		return nil, nil
+	}
+	return result.Entries[0], nil
+}
+
+func (a *LDAPAuthenticator) attributes() []string {
+	attributes := []string{a.cfg.EmailAttribute, a.cfg.NameAttribute, "memberOf"}
+	if a.cfg.IDAttribute != "" {
+		attributes = append(attributes, a.cfg.IDAttribute)
+	}
+	return attributes
+}
+
+// subject identifies the entry across renames when the directory has a
+// stable ID attribute, and falls back to the DN.
+func (a *LDAPAuthenticator) subject(entry *ldap.Entry) string {
+	if a.cfg.IDAttribute != "" {
+		if id := entry.GetAttributeValue(a.cfg.IDAttribute); id != "" {
+			return id
+		}
+	}
+	return entry.DN
+}
+
+// roles maps the groups of the user to roles: groups listed in memberOf and
+// groups found with the group filter.
+func (a *LDAPAuthenticator) roles(conn *ldap.Conn, entry *ldap.Entry) ([]string, error) {
+	groups := entry.GetAttributeValues("memberOf")
+
+	if a.cfg.GroupFilter != "" {
+		result, err := conn.Search(ldap.NewSearchRequest(
+			a.cfg.GroupBaseDN,
+			ldap.ScopeWholeSubtree,
+			ldap.NeverDerefAliases,
+			0, 0, false,
+			strings.ReplaceAll(a.cfg.GroupFilter, "%s", ldap.EscapeFilter(entry.DN)),
+			[]string{"cn"},
+			nil,
+		))
+		if err != nil {
+			return nil, fmt.Errorf("failed to search LDAP groups: %w", err)
+		}
+		for _, group := range result.Entries {
+			groups = append(groups, group.DN)
+			groups = append(groups, group.GetAttributeValues("cn")...)
+		}
+	}
+
+	seen := make(map[string]bool)
+	var roles []string
+	for _, group := range groups {
+		role, ok := a.cfg.GroupRoles[strings.ToLower(group)]
+		if ok && !seen[role] {
+			seen[role] = true
+			roles = append(roles, role)
+		}
+	}
+	sort.Strings(roles)
+	return roles, nil
+}
+
+// localUser returns the account linked to the entry. On the first login the
+// entry is linked to the account with the same email, or a new account is
+// created; the directory is trusted with the addresses it holds.
+func (a *LDAPAuthenticator) localUser(ctx context.Context, entry *ldap.Entry, userID string) (*models.User, error) {
+	if userID != "" {
+		user, err := a.repo.GetUserByID(ctx, userID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get user: %w", err)
+		}
+		return user, nil
+	}
+
+	email := entry.GetAttributeValue(a.cfg.EmailAttribute)
+	if email == "" {
+		return nil, fmt.Errorf("LDAP entry %s has no %s attribute", entry.DN, a.cfg.EmailAttribute)
+	}
+	identity := &models.FederatedIdentity{
+		TenantID: TenantFromContext(ctx),
+		Provider: ldapProvider,
+		Issuer:   a.cfg.URL,
+		Subject:  a.subject(entry),
+	}
+
+	user, err := a.repo.GetUserByEmail(ctx, identity.TenantID, email)
+	switch {
+	case err == nil:
+		identity.UserID = user.ID
+		if err := a.repo.LinkFederatedIdentity(ctx, identity); err != nil {
+			return nil, fmt.Errorf("failed to link directory identity: %w", err)
+		}
+		return user, nil
+	case errors.Is(err, repository.ErrNotFound):
+		user = &models.User{
+			TenantID:      identity.TenantID,
+			Email:         email,
+			Name:          entry.GetAttributeValue(a.cfg.NameAttribute),
+			EmailVerified: true,
+		}
+		if err := a.repo.CreateFederatedUser(ctx, user, identity); err != nil {
+			return nil, fmt.Errorf("failed to create directory user: %w", err)
+		}
+		return user, nil
+	default:
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+}
@@ -0,0 +1,262 @@
+package services
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/auth-service/internal/models"
+	"github.com/auth-service/internal/repository"
+	"github.com/auth-service/internal/repository/mocks"
+	ber "github.com/go-asn1-ber/asn1-ber"
+	"github.com/go-ldap/ldap/v3"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type ldapTestEntry struct {
+	password   string
+	attributes map[string][]string
+}
+
+// ldapStandIn is an in-process LDAP server that understands simple binds
+// and searches. Searches are answered by their filter, not evaluated.
+type ldapStandIn struct {
+	listener net.Listener
+	entries  map[string]ldapTestEntry
+	searches map[string][]string
+}
+
+func newLDAPStandIn(t *testing.T) *ldapStandIn {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	s := &ldapStandIn{
+		listener: listener,
+		entries:  make(map[string]ldapTestEntry),
+		searches: make(map[string][]string),
+	}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	t.Cleanup(func() { listener.Close() })
+	return s
+}
+
+func (s *ldapStandIn) URL() string {
+	return "ldap://" + s.listener.Addr().String()
+}
+
+func (s *ldapStandIn) serve(conn net.Conn) {
+	defer conn.Close()
+	for {
+		packet, err := ber.ReadPacket(conn)
+		if err != nil || len(packet.Children) < 2 {
+			return
+		}
+		id, _ := packet.Children[0].Value.(int64)
+		op := packet.Children[1]
+
+		switch op.Tag {
+		case ldap.ApplicationBindRequest:
+			dn := op.Children[1].Data.String()
+			password := op.Children[2].Data.String()
+			code := uint16(ldap.LDAPResultSuccess)
+			if entry, ok := s.entries[dn]; dn != "" && (!ok || entry.password != password) {
+				code = ldap.LDAPResultInvalidCredentials
+			}
+			s.reply(conn, id, ldapResult(ldap.ApplicationBindResponse, code))
+		case ldap.ApplicationSearchRequest:
+			filter, err := ldap.DecompileFilter(op.Children[6])
+			if err != nil {
+				s.reply(conn, id, ldapResult(ldap.ApplicationSearchResultDone, ldap.LDAPResultProtocolError))
+				continue
+			}
+			for _, dn := range s.searches[filter] {
+				s.reply(conn, id, s.searchEntry(dn))
+			}
+			s.reply(conn, id, ldapResult(ldap.ApplicationSearchResultDone, ldap.LDAPResultSuccess))
+		case ldap.ApplicationUnbindRequest:
+			return
+		}
+	}
+}
+
+func (s *ldapStandIn) reply(conn net.Conn, id int64, op *ber.Packet) {
+	packet := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "LDAP Response")
+	packet.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, id, "Message ID"))
+	packet.AppendChild(op)
+	conn.Write(packet.Bytes())
+}
+
+func (s *ldapStandIn) searchEntry(dn string) *ber.Packet {
+	op := ber.Encode(ber.ClassApplication, ber.TypeConstructed, ldap.ApplicationSearchResultEntry, nil, "Search Result Entry")
+	op.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, dn, "DN"))
+	attributes := ber.NewSequence("Attributes")
+	for name, values := range s.entries[dn].attributes {
+		attribute := ber.NewSequence("Attribute")
+		attribute.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, name, "Type"))
+		set := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSet, nil, "Values")
+		for _, value := range values {
+			set.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, value, "Value"))
+		}
+		attribute.AppendChild(set)
+		attributes.AppendChild(attribute)
+	}
+	op.AppendChild(attributes)
+	return op
+}
+
+func ldapResult(tag ber.Tag, code uint16) *ber.Packet {
+	op := ber.Encode(ber.ClassApplication, ber.TypeConstructed, tag, nil, "Result")
+	op.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, uint64(code), "Result Code"))
+	op.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "Matched DN"))
+	op.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "Diagnostic Message"))
+	return op
+}
+
+func TestLDAPAuthenticator(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	const (
+		serviceDN = "cn=auth-service,ou=services,dc=corp,dc=example"
+		userDN    = "uid=ivan,ou=people,dc=corp,dc=example"
+		adminsDN  = "cn=admins,ou=groups,dc=corp,dc=example"
+	)
+
+	directory := newLDAPStandIn(t)
+	directory.entries[serviceDN] = ldapTestEntry{password: "service-secret"}
+	directory.entries[userDN] = ldapTestEntry{
+		password: "correct horse",
+		attributes: map[string][]string{
+			"mail":      {"ivan@corp.example"},
+			"cn":        {"Иван Петров"},
+			"entryUUID": {"0b4b9a5e-5c1a-4b7e-9d0c-6f1e2a3b4c5d"},
+			"memberOf":  {"cn=developers,ou=groups,dc=corp,dc=example"},
+		},
+	}
+	directory.entries[adminsDN] = ldapTestEntry{attributes: map[string][]string{"cn": {"admins"}}}
+	directory.searches["(&(objectClass=person)(mail=ivan@corp.example))"] = []string{userDN}
+	directory.searches["(&(objectClass=groupOfNames)(member="+userDN+"))"] = []string{adminsDN}
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	mockNotifier := NewMockNotifier(ctrl)
+	audit := NewAuditLogger(mockRepo)
+	lockout := NewLockoutService(mockRepo, LockoutPolicy{LockThreshold: 10, Window: time.Hour}, audit, mockNotifier)
+	authenticator := NewLDAPAuthenticator(LDAPConfig{
+		URL:          directory.URL(),
+		BindDN:       serviceDN,
+		BindPassword: "service-secret",
+		UserBaseDN:   "ou=people,dc=corp,dc=example",
+		UserFilter:   "(&(objectClass=person)(mail=%s))",
+		GroupBaseDN:  "ou=groups,dc=corp,dc=example",
+		GroupFilter:  "(&(objectClass=groupOfNames)(member=%s))",
+		IDAttribute:  "entryUUID",
+		GroupRoles: map[string]string{
+			"CN=Developers,OU=Groups,DC=corp,DC=example": "developer",
+			"admins": "admin",
+		},
+		Timeout: time.Second,
+	}, mockRepo, lockout, audit)
+	ctx := context.Background()
+	userIP := net.ParseIP("192.168.1.1")
+	subject := "0b4b9a5e-5c1a-4b7e-9d0c-6f1e2a3b4c5d"
+
+	mockRepo.EXPECT().SaveAuditEvent(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	mockRepo.EXPECT().GetAuthFailure(ctx, gomock.Any(), gomock.Any()).Return(&models.AuthFailure{}, nil).AnyTimes()
+
+	t.Run("First login creates a user", func(t *testing.T) {
+		mockRepo.EXPECT().GetFederatedIdentity(ctx, DefaultTenant, directory.URL(), subject).Return(nil, repository.ErrNotFound)
+		mockRepo.EXPECT().GetUserByEmail(ctx, DefaultTenant, "ivan@corp.example").Return(nil, repository.ErrNotFound)
+		mockRepo.EXPECT().CreateFederatedUser(ctx, gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, user *models.User, identity *models.FederatedIdentity) error {
+				assert.Equal(t, "Иван Петров", user.Name)
+				assert.Equal(t, "ldap", identity.Provider)
+				user.ID = "user1"
+				return nil
+			})
+		mockRepo.EXPECT().ClearAuthFailures(ctx, lockoutScopeAccount, "user1").Return(nil)
+
+		user, err := authenticator.Authenticate(ctx, "ivan@corp.example", "correct horse", userIP)
+		require.NoError(t, err)
+		assert.Equal(t, "user1", user.ID)
+		assert.Equal(t, []string{"admin", "developer"}, user.Roles)
+	})
+
+	t.Run("Known user", func(t *testing.T) {
+		mockRepo.EXPECT().GetFederatedIdentity(ctx, DefaultTenant, directory.URL(), subject).
+			Return(&models.FederatedIdentity{UserID: "user1"}, nil)
+		mockRepo.EXPECT().GetUserByID(ctx, "user1").Return(&models.User{ID: "user1"}, nil)
+		mockRepo.EXPECT().ClearAuthFailures(ctx, lockoutScopeAccount, "user1").Return(nil)
+
+		user, err := authenticator.Authenticate(ctx, "ivan@corp.example", "correct horse", userIP)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"admin", "developer"}, user.Roles)
+	})
+
+	t.Run("Wrong password", func(t *testing.T) {
+		mockRepo.EXPECT().GetFederatedIdentity(ctx, DefaultTenant, directory.URL(), subject).
+			Return(&models.FederatedIdentity{UserID: "user1"}, nil)
+		mockRepo.EXPECT().
+			RecordAuthFailure(ctx, gomock.Any(), gomock.Any(), time.Hour).
+			Return(&models.AuthFailure{Failures: 1}, nil).
+			Times(2)
+
+		_, err := authenticator.Authenticate(ctx, "ivan@corp.example", "wrong", userIP)
+		assert.ErrorIs(t, err, ErrInvalidCredentials)
+	})
+
+	t.Run("Empty password", func(t *testing.T) {
+		mockRepo.EXPECT().GetFederatedIdentity(ctx, DefaultTenant, directory.URL(), subject).
+			Return(&models.FederatedIdentity{UserID: "user1"}, nil)
+		mockRepo.EXPECT().
+			RecordAuthFailure(ctx, gomock.Any(), gomock.Any(), time.Hour).
+			Return(&models.AuthFailure{Failures: 1}, nil).
+			Times(2)
+
+		_, err := authenticator.Authenticate(ctx, "ivan@corp.example", "", userIP)
+		assert.ErrorIs(t, err, ErrInvalidCredentials)
+	})
+
+	t.Run("Unknown user", func(t *testing.T) {
+		mockRepo.EXPECT().
+			RecordAuthFailure(ctx, lockoutScopeIP, userIP.String(), time.Hour).
+			Return(&models.AuthFailure{Failures: 1}, nil)
+
+		_, err := authenticator.Authenticate(ctx, "nobody@corp.example", "whatever", userIP)
+		assert.ErrorIs(t, err, ErrInvalidCredentials)
+	})
+
+	t.Run("Filter injection", func(t *testing.T) {
+		mockRepo.EXPECT().
+			RecordAuthFailure(ctx, lockoutScopeIP, userIP.String(), time.Hour).
+			Return(&models.AuthFailure{Failures: 1}, nil)
+
+		_, err := authenticator.Authenticate(ctx, "*)(mail=ivan@corp.example", "correct horse", userIP)
+		assert.ErrorIs(t, err, ErrInvalidCredentials)
+	})
+
+	t.Run("Service account rejected", func(t *testing.T) {
+		broken := NewLDAPAuthenticator(LDAPConfig{
+			URL:          directory.URL(),
+			BindDN:       serviceDN,
+			BindPassword: "wrong",
+			Timeout:      time.Second,
+		}, mockRepo, lockout, audit)
+
+		_, err := broken.Authenticate(ctx, "ivan@corp.example", "correct horse", userIP)
+		require.Error(t, err)
+		assert.NotErrorIs(t, err, ErrInvalidCredentials)
+		assert.True(t, strings.Contains(err.Error(), "service account"))
+	})
+}
@@ -0,0 +1,241 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/auth-service/internal/models"
+	"github.com/auth-service/internal/repository"
+)
+
+const (
+	AuditAccountLocked   = "account.locked"
+	AuditAccountUnlocked = "account.unlocked"
+
+	AttemptLogin   = "login"
+	AttemptMFA     = "mfa"
+	AttemptRefresh = "refresh"
+
+	lockoutScopeAccount = "account"
+	lockoutScopeIP      = "ip"
+	// lockoutScopeMFA counts second factor failures of an account apart
+	// from the account counter, so a correct password does not reset them.
+	lockoutScopeMFA = "mfa"
+)
+
+var ErrTooManyAttempts = errors.New("too many failed attempts")
+
+// LockedError is returned while an account or IP is in back-off or locked.
+// RetryAfter tells the client when the next attempt will be considered.
+type LockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LockedError) Error() string {
+	return fmt.Sprintf("%s, retry after %s", ErrTooManyAttempts, e.RetryAfter.Round(time.Second))
+}
+
+func (e *LockedError) Unwrap() error {
+	return ErrTooManyAttempts
+}
+
+type LockoutPolicy struct {
+	// DelayThreshold is the number of failures after which every further
+	// attempt has to wait BaseDelay, doubled for each extra failure.
+	DelayThreshold int
+	BaseDelay      time.Duration
+	MaxDelay       time.Duration
+	// LockThreshold is the number of failures that locks the account for
+	// LockDuration.
+	LockThreshold   int
+	LockDuration    time.Duration
+	IPLockThreshold int
+	// Window is how long a failure is remembered when no new ones arrive.
+	Window time.Duration
+}
+
+type LockoutService struct {
+	repo     repository.LockoutRepository
+	policy   LockoutPolicy
+	audit    *AuditLogger
+	notifier Notifier
+}
+
+func NewLockoutService(repo repository.LockoutRepository, policy LockoutPolicy, audit *AuditLogger, notifier Notifier) *LockoutService {
+	return &LockoutService{
+		repo:     repo,
+		policy:   policy,
+		audit:    audit,
+		notifier: notifier,
+	}
+}
+
+// Check returns a *LockedError if either the account or the IP has to wait
+// before the next credential attempt.
+func (s *LockoutService) Check(ctx context.Context, userID string, ip net.IP) error {
+	return s.check(ctx, s.keys(userID, ip))
+}
+
+// CheckSecondFactor is Check for the second step of a login, which also
+// waits for the second factor failures of the account.
+func (s *LockoutService) CheckSecondFactor(ctx context.Context, userID string, ip net.IP) error {
+	keys := s.keys(userID, ip)
+	if userID != "" {
+		keys = append(keys, lockoutKey{scope: lockoutScopeMFA, value: userID})
+	}
+	return s.check(ctx, keys)
+}
+
+func (s *LockoutService) check(ctx context.Context, keys []lockoutKey) error {
+	var retryAfter time.Duration
+
+	for _, key := range keys {
+		failure, err := s.repo.GetAuthFailure(ctx, key.scope, key.value)
+		if err != nil {
+			return fmt.Errorf("failed to check lockout: %w", err)
+		}
+		if wait := s.waitFor(failure, time.Now()); wait > retryAfter {
+			retryAfter = wait
+		}
+	}
+
+	if retryAfter > 0 {
+		return &LockedError{RetryAfter: retryAfter}
+	}
+	return nil
+}
+
+// RegisterFailure counts a failed attempt of the given kind and locks the
+// account or IP once its threshold is reached. AttemptMFA failures of the
+// account are counted apart, see CheckSecondFactor.
+func (s *LockoutService) RegisterFailure(ctx context.Context, kind, userID string, ip net.IP) {
+	keys := s.keys(userID, ip)
+	if kind == AttemptMFA && userID != "" {
+		keys[0].scope = lockoutScopeMFA
+	}
+	for _, key := range keys {
+		failure, err := s.repo.RecordAuthFailure(ctx, key.scope, key.value, s.policy.Window)
+		if err != nil {
+			log.Printf("Failed to record %s failure for %s %s: %v", kind, key.scope, key.value, err)
+			continue
+		}
+
+		threshold := s.policy.LockThreshold
+		if key.scope == lockoutScopeIP {
+			threshold = s.policy.IPLockThreshold
+		}
+		if threshold <= 0 || failure.Failures < threshold {
+			continue
+		}
+		if failure.LockedUntil != nil && failure.LockedUntil.After(time.Now()) {
+			continue
+		}
+
+		until := time.Now().Add(s.policy.LockDuration)
+		if err := s.repo.LockAuthFailure(ctx, key.scope, key.value, until); err != nil {
+			log.Printf("Failed to lock %s %s: %v", key.scope, key.value, err)
+			continue
+		}
+
+		if key.scope == lockoutScopeIP {
+			log.Printf("SECURITY WARNING: IP %s locked after %d failed attempts", key.value, failure.Failures)
+			continue
+		}
+
+		s.audit.Record(ctx, userID, AuditAccountLocked, ip, map[string]string{
+			"kind":     kind,
+			"failures": strconv.Itoa(failure.Failures),
+			"until":    until.UTC().Format(time.RFC3339),
+		})
+		msg := fmt.Sprintf("Аккаунт временно заблокирован после %d неудачных попыток входа. Последняя попытка с IP %s. Блокировка будет снята через %s",
+			failure.Failures, ip.String(), s.policy.LockDuration)
+		if err := s.notifier.SendSecurityAlert(userID, msg); err != nil {
+			log.Printf("Failed to send lockout alert to user %s: %v", userID, err)
+		}
+	}
+}
+
+// RegisterSuccess resets the account counter after a successful primary
+// authentication. IP counters are left to expire on their own.
+func (s *LockoutService) RegisterSuccess(ctx context.Context, userID string) {
+	if userID == "" {
+		return
+	}
+	if err := s.repo.ClearAuthFailures(ctx, lockoutScopeAccount, userID); err != nil {
+		log.Printf("Failed to reset auth failures for user %s: %v", userID, err)
+	}
+}
+
+// RegisterSecondFactorSuccess resets the counters of the account once a
+// login is completed with the second factor.
+func (s *LockoutService) RegisterSecondFactorSuccess(ctx context.Context, userID string) {
+	if err := s.repo.ClearAuthFailures(ctx, lockoutScopeMFA, userID); err != nil {
+		log.Printf("Failed to reset second factor failures for user %s: %v", userID, err)
+	}
+	s.RegisterSuccess(ctx, userID)
+}
+
+// Unlock lifts a lock placed on an account by an administrator.
+func (s *LockoutService) Unlock(ctx context.Context, userID, adminID string, ip net.IP) error {
+	for _, scope := range []string{lockoutScopeAccount, lockoutScopeMFA} {
+		if err := s.repo.ClearAuthFailures(ctx, scope, userID); err != nil {
+			return fmt.Errorf("failed to unlock account: %w", err)
+		}
+	}
+
+	s.audit.Record(ctx, userID, AuditAccountUnlocked, ip, map[string]string{
+		"admin_id": adminID,
+	})
+	return nil
+}
+
+func (s *LockoutService) waitFor(failure *models.AuthFailure, now time.Time) time.Duration {
+	if failure.LockedUntil != nil && failure.LockedUntil.After(now) {
+		return failure.LockedUntil.Sub(now)
+	}
+	if s.policy.Window > 0 && now.Sub(failure.LastFailedAt) > s.policy.Window {
+		return 0
+	}
+
+	delay := s.backoff(failure.Failures)
+	if next := failure.LastFailedAt.Add(delay); next.After(now) {
+		return next.Sub(now)
+	}
+	return 0
+}
+
+func (s *LockoutService) backoff(failures int) time.Duration {
+	if s.policy.DelayThreshold <= 0 || failures < s.policy.DelayThreshold {
+		return 0
+	}
+
+	delay := s.policy.BaseDelay
+	for i := s.policy.DelayThreshold; i < failures; i++ {
+		delay *= 2
+		if s.policy.MaxDelay > 0 && delay >= s.policy.MaxDelay {
+			return s.policy.MaxDelay
+		}
+	}
+	return delay
+}
+
+type lockoutKey struct {
+	scope string
+	value string
+}
+
+func (s *LockoutService) keys(userID string, ip net.IP) []lockoutKey {
+	var keys []lockoutKey
+	if userID != "" {
+		keys = append(keys, lockoutKey{scope: lockoutScopeAccount, value: userID})
+	}
+	if ip != nil {
+		keys = append(keys, lockoutKey{scope: lockoutScopeIP, value: ip.String()})
+	}
+	return keys
+}
@@ -0,0 +1,158 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/auth-service/internal/models"
+	"github.com/auth-service/internal/repository/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLockoutService(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	mockNotifier := NewMockNotifier(ctrl)
+	policy := LockoutPolicy{
+		DelayThreshold:  3,
+		BaseDelay:       time.Second,
+		MaxDelay:        time.Minute,
+		LockThreshold:   10,
+		LockDuration:    15 * time.Minute,
+		IPLockThreshold: 50,
+		Window:          time.Hour,
+	}
+	svc := NewLockoutService(mockRepo, policy, NewAuditLogger(mockRepo), mockNotifier)
+	ctx := context.Background()
+	userIP := net.ParseIP("192.168.1.1")
+
+	t.Run("Backoff", func(t *testing.T) {
+		assert.Equal(t, time.Duration(0), svc.backoff(2))
+		assert.Equal(t, time.Second, svc.backoff(3))
+		assert.Equal(t, 4*time.Second, svc.backoff(5))
+		assert.Equal(t, time.Minute, svc.backoff(20))
+	})
+
+	t.Run("Check", func(t *testing.T) {
+		t.Run("No failures", func(t *testing.T) {
+			mockRepo.EXPECT().GetAuthFailure(ctx, "account", "user1").Return(&models.AuthFailure{}, nil)
+			mockRepo.EXPECT().GetAuthFailure(ctx, "ip", userIP.String()).Return(&models.AuthFailure{}, nil)
+
+			assert.NoError(t, svc.Check(ctx, "user1", userIP))
+		})
+
+		t.Run("Progressive delay", func(t *testing.T) {
+			mockRepo.EXPECT().
+				GetAuthFailure(ctx, "account", "user1").
+				Return(&models.AuthFailure{Failures: 5, LastFailedAt: time.Now()}, nil)
+			mockRepo.EXPECT().GetAuthFailure(ctx, "ip", userIP.String()).Return(&models.AuthFailure{}, nil)
+
+			err := svc.Check(ctx, "user1", userIP)
+			var locked *LockedError
+			require.True(t, errors.As(err, &locked))
+			assert.InDelta(t, 4*time.Second, locked.RetryAfter, float64(time.Second))
+		})
+
+		t.Run("Delay elapsed", func(t *testing.T) {
+			mockRepo.EXPECT().
+				GetAuthFailure(ctx, "account", "user1").
+				Return(&models.AuthFailure{Failures: 3, LastFailedAt: time.Now().Add(-2 * time.Second)}, nil)
+			mockRepo.EXPECT().GetAuthFailure(ctx, "ip", userIP.String()).Return(&models.AuthFailure{}, nil)
+
+			assert.NoError(t, svc.Check(ctx, "user1", userIP))
+		})
+
+		t.Run("IP locked", func(t *testing.T) {
+			lockedUntil := time.Now().Add(10 * time.Minute)
+			mockRepo.EXPECT().GetAuthFailure(ctx, "account", "user1").Return(&models.AuthFailure{}, nil)
+			mockRepo.EXPECT().
+				GetAuthFailure(ctx, "ip", userIP.String()).
+				Return(&models.AuthFailure{Failures: 50, LastFailedAt: time.Now(), LockedUntil: &lockedUntil}, nil)
+
+			err := svc.Check(ctx, "user1", userIP)
+			assert.ErrorIs(t, err, ErrTooManyAttempts)
+		})
+	})
+
+	t.Run("RegisterFailure", func(t *testing.T) {
+		t.Run("Below threshold", func(t *testing.T) {
+			mockRepo.EXPECT().
+				RecordAuthFailure(ctx, "account", "user1", time.Hour).
+				Return(&models.AuthFailure{Failures: 4}, nil)
+			mockRepo.EXPECT().
+				RecordAuthFailure(ctx, "ip", userIP.String(), time.Hour).
+				Return(&models.AuthFailure{Failures: 4}, nil)
+
+			svc.RegisterFailure(ctx, AttemptLogin, "user1", userIP)
+		})
+
+		t.Run("Locks account and alerts user", func(t *testing.T) {
+			mockRepo.EXPECT().
+				RecordAuthFailure(ctx, "account", "user1", time.Hour).
+				Return(&models.AuthFailure{Failures: 10}, nil)
+			mockRepo.EXPECT().
+				RecordAuthFailure(ctx, "ip", userIP.String(), time.Hour).
+				Return(&models.AuthFailure{Failures: 10}, nil)
+			mockRepo.EXPECT().
+				LockAuthFailure(ctx, "account", "user1", gomock.Any()).
+				DoAndReturn(func(_ context.Context, _, _ string, until time.Time) error {
+					assert.WithinDuration(t, time.Now().Add(15*time.Minute), until, time.Minute)
+					return nil
+				})
+			mockRepo.EXPECT().
+				SaveAuditEvent(ctx, gomock.Any()).
+				DoAndReturn(func(_ context.Context, event *models.AuditEvent) error {
+					assert.Equal(t, AuditAccountLocked, event.Type)
+					assert.Equal(t, AttemptLogin, event.Details["kind"])
+					return nil
+				})
+			mockNotifier.EXPECT().SendSecurityAlert("user1", gomock.Any()).Return(nil)
+
+			svc.RegisterFailure(ctx, AttemptLogin, "user1", userIP)
+		})
+
+		t.Run("Already locked", func(t *testing.T) {
+			lockedUntil := time.Now().Add(time.Minute)
+			mockRepo.EXPECT().
+				RecordAuthFailure(ctx, "account", "user1", time.Hour).
+				Return(&models.AuthFailure{Failures: 11, LockedUntil: &lockedUntil}, nil)
+			mockRepo.EXPECT().
+				RecordAuthFailure(ctx, "ip", userIP.String(), time.Hour).
+				Return(&models.AuthFailure{Failures: 11}, nil)
+
+			svc.RegisterFailure(ctx, AttemptRefresh, "user1", userIP)
+		})
+
+		t.Run("Second factor failures are counted apart", func(t *testing.T) {
+			mockRepo.EXPECT().
+				RecordAuthFailure(ctx, "mfa", "user1", time.Hour).
+				Return(&models.AuthFailure{Failures: 1}, nil)
+			mockRepo.EXPECT().
+				RecordAuthFailure(ctx, "ip", userIP.String(), time.Hour).
+				Return(&models.AuthFailure{Failures: 1}, nil)
+
+			svc.RegisterFailure(ctx, AttemptMFA, "user1", userIP)
+		})
+	})
+
+	t.Run("Unlock", func(t *testing.T) {
+		mockRepo.EXPECT().ClearAuthFailures(ctx, "account", "user1").Return(nil)
+		mockRepo.EXPECT().ClearAuthFailures(ctx, "mfa", "user1").Return(nil)
+		mockRepo.EXPECT().
+			SaveAuditEvent(ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, event *models.AuditEvent) error {
+				assert.Equal(t, AuditAccountUnlocked, event.Type)
+				assert.Equal(t, "admin1", event.Details["admin_id"])
+				return nil
+			})
+
+		assert.NoError(t, svc.Unlock(ctx, "user1", "admin1", userIP))
+	})
+}
@@ -0,0 +1,234 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/auth-service/internal/models"
+	"github.com/auth-service/internal/repository"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	AuditMagicLinkRequested = "magic_link.requested"
+	AuditMagicLinkLogin     = "magic_link.login"
+
+	maxLoginCodeAttempts = 5
+)
+
+var ErrInvalidLoginCode = errors.New("invalid or expired login code")
+
+type magicLinkRepository interface {
+	repository.UserRepository
+	repository.LoginCodeRepository
+}
+
+type MagicLinkService struct {
+	repo      magicLinkRepository
+	secret    []byte
+	publicURL string
+	ttl       time.Duration
+	notifier  Notifier
+	audit     *AuditLogger
+	lockout   *LockoutService
+}
+
+func NewMagicLinkService(
+	repo magicLinkRepository,
+	secret, publicURL string,
+	ttl time.Duration,
+	notifier Notifier,
+	audit *AuditLogger,
+	lockout *LockoutService,
+) *MagicLinkService {
+	return &MagicLinkService{
+		repo:      repo,
+		secret:    []byte(secret),
+		publicURL: strings.TrimRight(publicURL, "/"),
+		ttl:       ttl,
+		notifier:  notifier,
+		audit:     audit,
+		lockout:   lockout,
+	}
+}
+
+// Request emails a magic link and a 6-digit code to the user and returns the
+// nonce that has to be stored in the requesting browser. Unknown addresses
+// get a nonce as well, so the response does not reveal who has an account.
+func (s *MagicLinkService) Request(ctx context.Context, email string, ip net.IP) (string, error) {
+	nonce, err := generateSecureToken(32)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	user, err := s.repo.GetUserByEmail(ctx, TenantFromContext(ctx), strings.TrimSpace(email))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nonce, nil
+		}
+		return "", fmt.Errorf("failed to get user: %w", err)
+	}
+
+	code, err := generateNumericCode(6)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate login code: %w", err)
+	}
+	codeHash, err := bcrypt.GenerateFromPassword([]byte(code), bcrypt.DefaultCost)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash login code: %w", err)
+	}
+
+	loginCode := &models.LoginCode{
+		TenantID:  TenantFromContext(ctx),
+		UserID:    user.ID,
+		CodeHash:  string(codeHash),
+		NonceHash: hashNonce(nonce),
+		ExpiresAt: time.Now().Add(s.ttl),
+	}
+	if err := s.repo.SaveLoginCode(ctx, loginCode); err != nil {
+		return "", fmt.Errorf("failed to save login code: %w", err)
+	}
+
+	link := TenantURL(ctx, s.publicURL) + "/auth/magic-link/callback?token=" + url.QueryEscape(s.signLink(loginCode))
+	body := fmt.Sprintf("Для входа перейдите по ссылке в том же браузере:\n%s\n\nИли введите код: %s\n\nСсылка и код действуют %s.",
+		link, code, s.ttl)
+	if err := s.notifier.SendEmail(user.Email, "Вход в аккаунт", body); err != nil {
+		return "", fmt.Errorf("failed to send login link: %w", err)
+	}
+
+	s.audit.Record(ctx, user.ID, AuditMagicLinkRequested, ip, nil)
+	return nonce, nil
+}
+
+// VerifyLink checks a magic link token against the nonce of the browser that
+// follows it and returns the user to log in.
+func (s *MagicLinkService) VerifyLink(ctx context.Context, token, nonce string, ip net.IP, device string) (string, error) {
+	id, ok := s.verifyLinkSignature(token)
+	if !ok {
+		return "", ErrInvalidLoginCode
+	}
+
+	loginCode, err := s.repo.GetLoginCode(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return "", ErrInvalidLoginCode
+		}
+		return "", fmt.Errorf("failed to get login code: %w", err)
+	}
+
+	if nonce == "" || !hmac.Equal([]byte(loginCode.NonceHash), []byte(hashNonce(nonce))) {
+		return "", ErrInvalidLoginCode
+	}
+
+	return s.consume(ctx, loginCode, "link", ip, device)
+}
+
+// VerifyCode checks a 6-digit code entered in the browser that requested it.
+func (s *MagicLinkService) VerifyCode(ctx context.Context, code, nonce string, ip net.IP, device string) (string, error) {
+	if nonce == "" {
+		return "", ErrInvalidLoginCode
+	}
+
+	loginCode, err := s.repo.GetLoginCodeByNonce(ctx, hashNonce(nonce))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return "", ErrInvalidLoginCode
+		}
+		return "", fmt.Errorf("failed to get login code: %w", err)
+	}
+
+	if err := s.lockout.Check(ctx, loginCode.UserID, ip); err != nil {
+		return "", err
+	}
+
+	if loginCode.Attempts >= maxLoginCodeAttempts {
+		return "", ErrInvalidLoginCode
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(loginCode.CodeHash), []byte(strings.TrimSpace(code))); err != nil {
+		if err := s.repo.IncrementLoginCodeAttempts(ctx, loginCode.ID); err != nil {
+			return "", fmt.Errorf("failed to update login code: %w", err)
+		}
+		s.lockout.RegisterFailure(ctx, AttemptLogin, loginCode.UserID, ip)
+		return "", ErrInvalidLoginCode
+	}
+
+	return s.consume(ctx, loginCode, "code", ip, device)
+}
+
+func (s *MagicLinkService) consume(ctx context.Context, loginCode *models.LoginCode, method string, ip net.IP, device string) (string, error) {
+	if loginCode.UsedAt != nil || time.Now().After(loginCode.ExpiresAt) || loginCode.TenantID != TenantFromContext(ctx) {
+		return "", ErrInvalidLoginCode
+	}
+
+	if err := s.repo.ConsumeLoginCode(ctx, loginCode.ID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return "", ErrInvalidLoginCode
+		}
+		return "", fmt.Errorf("failed to consume login code: %w", err)
+	}
+
+	s.lockout.RegisterSuccess(ctx, loginCode.UserID)
+	s.audit.Record(ctx, loginCode.UserID, AuditMagicLinkLogin, ip, map[string]string{
+		"method": method,
+		"device": device,
+	})
+	return loginCode.UserID, nil
+}
+
+// signLink produces "<id>.<expires>.<signature>" so that links can be
+// rejected before touching the database.
+func (s *MagicLinkService) signLink(loginCode *models.LoginCode) string {
+	payload := loginCode.ID + "." + strconv.FormatInt(loginCode.ExpiresAt.Unix(), 10)
+	return payload + "." + s.sign(payload)
+}
+
+func (s *MagicLinkService) verifyLinkSignature(token string) (string, bool) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return "", false
+	}
+
+	payload := parts[0] + "." + parts[1]
+	if !hmac.Equal([]byte(parts[2]), []byte(s.sign(payload))) {
+		return "", false
+	}
+
+	expires, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || time.Now().Unix() > expires {
+		return "", false
+	}
+	return parts[0], true
+}
+
+func (s *MagicLinkService) sign(payload string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte("magic-link:" + payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func hashNonce(nonce string) string {
+	sum := sha256.Sum256([]byte(nonce))
+	return hex.EncodeToString(sum[:])
+}
+
+func generateNumericCode(digits int) (string, error) {
+	limit := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(digits)), nil)
+	n, err := rand.Int(rand.Reader, limit)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%0*d", digits, n), nil
+}
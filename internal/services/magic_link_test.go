@@ -0,0 +1,194 @@
+package services
+
+import (
+	"context"
+	"net"
+	"net/url"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/auth-service/internal/models"
+	"github.com/auth-service/internal/repository"
+	"github.com/auth-service/internal/repository/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMagicLinkService(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	mockNotifier := NewMockNotifier(ctrl)
+	audit := NewAuditLogger(mockRepo)
+	lockout := NewLockoutService(mockRepo, LockoutPolicy{LockThreshold: 10, Window: time.Hour}, audit, mockNotifier)
+	svc := NewMagicLinkService(mockRepo, "test-secret", "http://localhost:8081/", 15*time.Minute, mockNotifier, audit, lockout)
+	ctx := context.Background()
+	userIP := net.ParseIP("192.168.1.1")
+
+	mockRepo.EXPECT().SaveAuditEvent(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	var (
+		saved *models.LoginCode
+		link  string
+		code  string
+		nonce string
+	)
+
+	t.Run("Request", func(t *testing.T) {
+		t.Run("Known user", func(t *testing.T) {
+			mockRepo.EXPECT().
+				GetUserByEmail(ctx, DefaultTenant, "user@example.com").
+				Return(&models.User{ID: "user1", Email: "user@example.com"}, nil)
+			mockRepo.EXPECT().
+				SaveLoginCode(ctx, gomock.Any()).
+				DoAndReturn(func(_ context.Context, loginCode *models.LoginCode) error {
+					loginCode.ID = "code-id"
+					saved = loginCode
+					return nil
+				})
+			mockNotifier.EXPECT().
+				SendEmail("user@example.com", gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_, _, body string) error {
+					link = regexp.MustCompile(`http://\S+`).FindString(body)
+					code = regexp.MustCompile(`\b\d{6}\b`).FindString(body)
+					return nil
+				})
+
+			var err error
+			nonce, err = svc.Request(ctx, " user@example.com ", userIP)
+			require.NoError(t, err)
+			assert.NotEmpty(t, nonce)
+			assert.Equal(t, hashNonce(nonce), saved.NonceHash)
+			assert.Equal(t, DefaultTenant, saved.TenantID)
+			assert.NotContains(t, saved.CodeHash, code)
+			assert.Contains(t, link, "http://localhost:8081/auth/magic-link/callback?token=code-id.")
+			assert.Len(t, code, 6)
+		})
+
+		t.Run("Link of another tenant", func(t *testing.T) {
+			tenantCtx := WithTenant(ctx, "acme")
+			mockRepo.EXPECT().
+				GetUserByEmail(tenantCtx, "acme", "user@acme.example").
+				Return(&models.User{ID: "user2", TenantID: "acme", Email: "user@acme.example"}, nil)
+			mockRepo.EXPECT().
+				SaveLoginCode(tenantCtx, gomock.Any()).
+				DoAndReturn(func(_ context.Context, loginCode *models.LoginCode) error {
+					assert.Equal(t, "acme", loginCode.TenantID)
+					loginCode.ID = "acme-code-id"
+					return nil
+				})
+			mockNotifier.EXPECT().
+				SendEmail("user@acme.example", gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_, _, body string) error {
+					assert.Contains(t, body, "http://localhost:8081/t/acme/auth/magic-link/callback?token=acme-code-id.")
+					return nil
+				})
+
+			_, err := svc.Request(tenantCtx, "user@acme.example", userIP)
+			require.NoError(t, err)
+		})
+
+		t.Run("Unknown user", func(t *testing.T) {
+			mockRepo.EXPECT().
+				GetUserByEmail(ctx, DefaultTenant, "nobody@example.com").
+				Return(nil, repository.ErrNotFound)
+
+			otherNonce, err := svc.Request(ctx, "nobody@example.com", userIP)
+			require.NoError(t, err)
+			assert.NotEmpty(t, otherNonce)
+		})
+	})
+
+	linkToken := func(t *testing.T) string {
+		parsed, err := url.Parse(link)
+		require.NoError(t, err)
+		return parsed.Query().Get("token")
+	}
+
+	t.Run("VerifyLink", func(t *testing.T) {
+		t.Run("Different browser", func(t *testing.T) {
+			mockRepo.EXPECT().GetLoginCode(ctx, "code-id").Return(saved, nil)
+
+			_, err := svc.VerifyLink(ctx, linkToken(t), "other-nonce", userIP, "Firefox")
+			assert.ErrorIs(t, err, ErrInvalidLoginCode)
+		})
+
+		t.Run("Tampered token", func(t *testing.T) {
+			_, err := svc.VerifyLink(ctx, "other-id"+linkToken(t)[len("code-id"):], nonce, userIP, "Firefox")
+			assert.ErrorIs(t, err, ErrInvalidLoginCode)
+		})
+
+		t.Run("Another tenant", func(t *testing.T) {
+			tenantCtx := WithTenant(ctx, "acme")
+			mockRepo.EXPECT().GetLoginCode(tenantCtx, "code-id").Return(saved, nil)
+
+			_, err := svc.VerifyLink(tenantCtx, linkToken(t), nonce, userIP, "Firefox")
+			assert.ErrorIs(t, err, ErrInvalidLoginCode)
+		})
+
+		t.Run("Success", func(t *testing.T) {
+			mockRepo.EXPECT().GetLoginCode(ctx, "code-id").Return(saved, nil)
+			mockRepo.EXPECT().ConsumeLoginCode(ctx, "code-id").Return(nil)
+			mockRepo.EXPECT().ClearAuthFailures(ctx, "account", "user1").Return(nil)
+
+			userID, err := svc.VerifyLink(ctx, linkToken(t), nonce, userIP, "Firefox")
+			require.NoError(t, err)
+			assert.Equal(t, "user1", userID)
+		})
+
+		t.Run("Already used", func(t *testing.T) {
+			mockRepo.EXPECT().GetLoginCode(ctx, "code-id").Return(saved, nil)
+			mockRepo.EXPECT().ConsumeLoginCode(ctx, "code-id").Return(repository.ErrNotFound)
+
+			_, err := svc.VerifyLink(ctx, linkToken(t), nonce, userIP, "Firefox")
+			assert.ErrorIs(t, err, ErrInvalidLoginCode)
+		})
+	})
+
+	t.Run("VerifyCode", func(t *testing.T) {
+		expectNotLocked := func() {
+			mockRepo.EXPECT().GetAuthFailure(ctx, gomock.Any(), gomock.Any()).Return(&models.AuthFailure{}, nil).Times(2)
+		}
+
+		t.Run("Wrong code", func(t *testing.T) {
+			mockRepo.EXPECT().GetLoginCodeByNonce(ctx, hashNonce(nonce)).Return(saved, nil)
+			expectNotLocked()
+			mockRepo.EXPECT().IncrementLoginCodeAttempts(ctx, "code-id").Return(nil)
+			mockRepo.EXPECT().
+				RecordAuthFailure(ctx, gomock.Any(), gomock.Any(), time.Hour).
+				Return(&models.AuthFailure{Failures: 1}, nil).Times(2)
+
+			_, err := svc.VerifyCode(ctx, "not-a-code", nonce, userIP, "Firefox")
+			assert.ErrorIs(t, err, ErrInvalidLoginCode)
+		})
+
+		t.Run("Too many attempts", func(t *testing.T) {
+			exhausted := *saved
+			exhausted.Attempts = maxLoginCodeAttempts
+			mockRepo.EXPECT().GetLoginCodeByNonce(ctx, hashNonce(nonce)).Return(&exhausted, nil)
+			expectNotLocked()
+
+			_, err := svc.VerifyCode(ctx, code, nonce, userIP, "Firefox")
+			assert.ErrorIs(t, err, ErrInvalidLoginCode)
+		})
+
+		t.Run("Success", func(t *testing.T) {
+			mockRepo.EXPECT().GetLoginCodeByNonce(ctx, hashNonce(nonce)).Return(saved, nil)
+			expectNotLocked()
+			mockRepo.EXPECT().ConsumeLoginCode(ctx, "code-id").Return(nil)
+			mockRepo.EXPECT().ClearAuthFailures(ctx, "account", "user1").Return(nil)
+
+			userID, err := svc.VerifyCode(ctx, code, nonce, userIP, "Firefox")
+			require.NoError(t, err)
+			assert.Equal(t, "user1", userID)
+		})
+
+		t.Run("Missing nonce", func(t *testing.T) {
+			_, err := svc.VerifyCode(ctx, code, "", userIP, "Firefox")
+			assert.ErrorIs(t, err, ErrInvalidLoginCode)
+		})
+	})
+}
@@ -0,0 +1,190 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/auth-service/internal/repository"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	recoveryCodeCount    = 10
+	recoveryCodeLength   = 10
+	recoveryCodeAlphabet = "abcdefghjkmnpqrstuvwxyz023456789"
+)
+
+var (
+	ErrInvalidRecoveryCode = errors.New("invalid recovery code")
+	ErrInvalidMFAChallenge = errors.New("invalid or expired mfa challenge")
+)
+
+type mfaRepository interface {
+	repository.RecoveryCodeRepository
+	repository.WebAuthnRepository
+}
+
+// MFALogin is a login, or a step-up of a session, completed with the
+// second factor.
+type MFALogin struct {
+	UserID string
+	// Roles are the roles reported by the backend of the first factor.
+	Roles []string
+	// SessionID is set for a step-up: the session to keep from the new IP.
+	SessionID string
+}
+
+type MFAService struct {
+	repo     mfaRepository
+	tokens   *TokenService
+	audit    *AuditLogger
+	notifier Notifier
+	lockout  *LockoutService
+}
+
+func NewMFAService(repo mfaRepository, tokens *TokenService, audit *AuditLogger, notifier Notifier, lockout *LockoutService) *MFAService {
+	return &MFAService{
+		repo:     repo,
+		tokens:   tokens,
+		audit:    audit,
+		notifier: notifier,
+		lockout:  lockout,
+	}
+}
+
+// Challenge is called once the password of the user is verified. Users
+// with a passkey need a second factor to finish the login: they get a
+// challenge to present with a recovery code, or sign in with the passkey
+// instead. Other users get an empty challenge and are logged in.
+func (s *MFAService) Challenge(ctx context.Context, userID string, roles []string) (string, error) {
+	tenantID := TenantFromContext(ctx)
+	credentials, err := s.repo.GetWebAuthnCredentialsByUser(ctx, tenantID, userID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get passkeys: %w", err)
+	}
+	if len(credentials) == 0 {
+		return "", nil
+	}
+
+	challenge, err := s.tokens.SignMFAChallenge(tenantID, userID, roles)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign mfa challenge: %w", err)
+	}
+	return challenge, nil
+}
+
+// GenerateRecoveryCodes replaces any previous codes of the user with a fresh
+// set. The plain codes are returned once and only their hashes are stored.
+func (s *MFAService) GenerateRecoveryCodes(ctx context.Context, userID string, ip net.IP) ([]string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+
+	for i := 0; i < recoveryCodeCount; i++ {
+		code, err := generateRecoveryCode()
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate recovery code: %w", err)
+		}
+		hash, err := bcrypt.GenerateFromPassword([]byte(normalizeRecoveryCode(code)), bcrypt.DefaultCost)
+		if err != nil {
+			return nil, fmt.Errorf("failed to hash recovery code: %w", err)
+		}
+		codes = append(codes, code)
+		hashes = append(hashes, string(hash))
+	}
+
+	if err := s.repo.ReplaceRecoveryCodes(ctx, TenantFromContext(ctx), userID, hashes); err != nil {
+		return nil, fmt.Errorf("failed to save recovery codes: %w", err)
+	}
+
+	s.audit.Record(ctx, userID, AuditRecoveryCodesGenerated, ip, map[string]string{
+		"count": strconv.Itoa(len(codes)),
+	})
+	return codes, nil
+}
+
+// VerifyRecoveryCode accepts an unused recovery code as the second factor
+// of the login or step-up the challenge was issued for and consumes it.
+// Only codes generated in the tenant of the request count, and step-ups
+// only from the IP they were asked for.
+func (s *MFAService) VerifyRecoveryCode(ctx context.Context, challenge, code string, ip net.IP) (*MFALogin, error) {
+	claims, err := s.tokens.ParseMFAChallenge(challenge)
+	if err != nil || claims.TenantID != TenantFromContext(ctx) {
+		return nil, ErrInvalidMFAChallenge
+	}
+	if claims.SessionID != "" && claims.IP != ip.String() {
+		return nil, ErrInvalidMFAChallenge
+	}
+	userID := claims.UserID
+
+	if err := s.lockout.CheckSecondFactor(ctx, userID, ip); err != nil {
+		return nil, err
+	}
+
+	normalized := normalizeRecoveryCode(code)
+	if normalized == "" {
+		s.lockout.RegisterFailure(ctx, AttemptMFA, userID, ip)
+		return nil, ErrInvalidRecoveryCode
+	}
+
+	codes, err := s.repo.GetUnusedRecoveryCodes(ctx, TenantFromContext(ctx), userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recovery codes: %w", err)
+	}
+
+	matchedID := ""
+	for _, stored := range codes {
+		if err := bcrypt.CompareHashAndPassword([]byte(stored.CodeHash), []byte(normalized)); err == nil {
+			matchedID = stored.ID
+			break
+		}
+	}
+	if matchedID == "" {
+		s.lockout.RegisterFailure(ctx, AttemptMFA, userID, ip)
+		return nil, ErrInvalidRecoveryCode
+	}
+
+	if err := s.repo.MarkRecoveryCodeUsed(ctx, matchedID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrInvalidRecoveryCode
+		}
+		return nil, fmt.Errorf("failed to consume recovery code: %w", err)
+	}
+
+	s.lockout.RegisterSecondFactorSuccess(ctx, userID)
+
+	remaining := len(codes) - 1
+	s.audit.Record(ctx, userID, AuditRecoveryCodeUsed, ip, map[string]string{
+		"code_id":   matchedID,
+		"remaining": strconv.Itoa(remaining),
+	})
+
+	msg := fmt.Sprintf("Для входа в аккаунт использован резервный код восстановления (IP: %s). Осталось неиспользованных кодов: %d",
+		ip.String(), remaining)
+	if err := s.notifier.SendSecurityAlert(userID, msg); err != nil {
+		log.Printf("Failed to send recovery code alert to user %s: %v", userID, err)
+	}
+	return &MFALogin{UserID: userID, Roles: claims.Roles, SessionID: claims.SessionID}, nil
+}
+
+func generateRecoveryCode() (string, error) {
+	b := make([]byte, recoveryCodeLength)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	for i := range b {
+		b[i] = recoveryCodeAlphabet[int(b[i])%len(recoveryCodeAlphabet)]
+	}
+	half := recoveryCodeLength / 2
+	return string(b[:half]) + "-" + string(b[half:]), nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	return strings.ReplaceAll(code, "-", "")
+}
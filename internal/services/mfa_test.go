@@ -0,0 +1,208 @@
+package services
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/auth-service/internal/models"
+	"github.com/auth-service/internal/repository"
+	"github.com/auth-service/internal/repository/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestMFAService(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	mockNotifier := NewMockNotifier(ctrl)
+	audit := NewAuditLogger(mockRepo)
+	lockout := NewLockoutService(mockRepo, LockoutPolicy{LockThreshold: 10, Window: time.Hour}, audit, mockNotifier)
+	tokens := NewTokenService("test-secret")
+	mfaSvc := NewMFAService(mockRepo, tokens, audit, mockNotifier, lockout)
+	ctx := context.Background()
+	userIP := net.ParseIP("192.168.1.1")
+
+	t.Run("GenerateRecoveryCodes", func(t *testing.T) {
+		var savedHashes []string
+		mockRepo.EXPECT().
+			ReplaceRecoveryCodes(ctx, DefaultTenant, "user1", gomock.Len(recoveryCodeCount)).
+			DoAndReturn(func(_ context.Context, _, _ string, hashes []string) error {
+				savedHashes = hashes
+				return nil
+			})
+		mockRepo.EXPECT().
+			SaveAuditEvent(ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, event *models.AuditEvent) error {
+				assert.Equal(t, AuditRecoveryCodesGenerated, event.Type)
+				return nil
+			})
+
+		codes, err := mfaSvc.GenerateRecoveryCodes(ctx, "user1", userIP)
+		require.NoError(t, err)
+		assert.Len(t, codes, recoveryCodeCount)
+
+		seen := make(map[string]bool)
+		for i, code := range codes {
+			assert.False(t, seen[code], "codes must be unique")
+			seen[code] = true
+			assert.NotContains(t, savedHashes, code, "codes must be stored hashed")
+			assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(savedHashes[i]), []byte(normalizeRecoveryCode(code))))
+		}
+	})
+
+	expectNotLocked := func() {
+		mockRepo.EXPECT().
+			GetAuthFailure(ctx, gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, scope, key string) (*models.AuthFailure, error) {
+				return &models.AuthFailure{Scope: scope, Key: key}, nil
+			}).Times(3)
+	}
+	expectFailureRecorded := func() {
+		mockRepo.EXPECT().
+			RecordAuthFailure(ctx, gomock.Any(), gomock.Any(), time.Hour).
+			Return(&models.AuthFailure{Failures: 1, LastFailedAt: time.Now()}, nil).Times(2)
+	}
+
+	t.Run("Challenge", func(t *testing.T) {
+		t.Run("No passkeys", func(t *testing.T) {
+			mockRepo.EXPECT().GetWebAuthnCredentialsByUser(ctx, DefaultTenant, "user1").Return(nil, nil)
+
+			challenge, err := mfaSvc.Challenge(ctx, "user1", nil)
+			require.NoError(t, err)
+			assert.Empty(t, challenge)
+		})
+
+		t.Run("Passkey registered", func(t *testing.T) {
+			mockRepo.EXPECT().
+				GetWebAuthnCredentialsByUser(ctx, DefaultTenant, "user1").
+				Return([]models.WebAuthnCredential{{ID: "cred-1", UserID: "user1"}}, nil)
+
+			challenge, err := mfaSvc.Challenge(ctx, "user1", []string{"admin"})
+			require.NoError(t, err)
+
+			claims, err := tokens.ParseMFAChallenge(challenge)
+			require.NoError(t, err)
+			assert.Equal(t, "user1", claims.UserID)
+			assert.Equal(t, []string{"admin"}, claims.Roles)
+
+			_, err = tokens.ParseAccessToken(challenge)
+			assert.ErrorIs(t, err, ErrInvalidToken)
+		})
+	})
+
+	t.Run("VerifyRecoveryCode", func(t *testing.T) {
+		challenge, err := tokens.SignMFAChallenge(DefaultTenant, "user1", []string{"admin"})
+		require.NoError(t, err)
+		hash, _ := bcrypt.GenerateFromPassword([]byte("abcde23456"), bcrypt.MinCost)
+		stored := []models.RecoveryCode{
+			{ID: "code-1", UserID: "user1", CodeHash: "$2a$04$invalidinvalidinvalidinvalidinvalidinvalidinvalidinva"},
+			{ID: "code-2", UserID: "user1", CodeHash: string(hash)},
+		}
+
+		t.Run("Valid code", func(t *testing.T) {
+			expectNotLocked()
+			mockRepo.EXPECT().GetUnusedRecoveryCodes(ctx, DefaultTenant, "user1").Return(stored, nil)
+			mockRepo.EXPECT().MarkRecoveryCodeUsed(ctx, "code-2").Return(nil)
+			mockRepo.EXPECT().ClearAuthFailures(ctx, "mfa", "user1").Return(nil)
+			mockRepo.EXPECT().ClearAuthFailures(ctx, "account", "user1").Return(nil)
+			mockRepo.EXPECT().
+				SaveAuditEvent(ctx, gomock.Any()).
+				DoAndReturn(func(_ context.Context, event *models.AuditEvent) error {
+					assert.Equal(t, AuditRecoveryCodeUsed, event.Type)
+					assert.Equal(t, "1", event.Details["remaining"])
+					return nil
+				})
+			mockNotifier.EXPECT().
+				SendSecurityAlert("user1", gomock.Any()).
+				DoAndReturn(func(_, msg string) error {
+					assert.True(t, strings.Contains(msg, userIP.String()))
+					return nil
+				})
+
+			login, err := mfaSvc.VerifyRecoveryCode(ctx, challenge, "ABCDE-23456", userIP)
+			require.NoError(t, err)
+			assert.Equal(t, "user1", login.UserID)
+			assert.Equal(t, []string{"admin"}, login.Roles)
+		})
+
+		t.Run("Unknown code", func(t *testing.T) {
+			expectNotLocked()
+			mockRepo.EXPECT().GetUnusedRecoveryCodes(ctx, DefaultTenant, "user1").Return(stored, nil)
+			expectFailureRecorded()
+
+			_, err := mfaSvc.VerifyRecoveryCode(ctx, challenge, "zzzzz-zzzzz", userIP)
+			assert.ErrorIs(t, err, ErrInvalidRecoveryCode)
+		})
+
+		t.Run("Already used concurrently", func(t *testing.T) {
+			expectNotLocked()
+			mockRepo.EXPECT().GetUnusedRecoveryCodes(ctx, DefaultTenant, "user1").Return(stored, nil)
+			mockRepo.EXPECT().MarkRecoveryCodeUsed(ctx, "code-2").Return(repository.ErrNotFound)
+
+			_, err := mfaSvc.VerifyRecoveryCode(ctx, challenge, "abcde23456", userIP)
+			assert.ErrorIs(t, err, ErrInvalidRecoveryCode)
+		})
+
+		t.Run("Empty code", func(t *testing.T) {
+			expectNotLocked()
+			expectFailureRecorded()
+
+			_, err := mfaSvc.VerifyRecoveryCode(ctx, challenge, " - ", userIP)
+			assert.ErrorIs(t, err, ErrInvalidRecoveryCode)
+		})
+
+		t.Run("Locked out", func(t *testing.T) {
+			lockedUntil := time.Now().Add(10 * time.Minute)
+			mockRepo.EXPECT().
+				GetAuthFailure(ctx, "account", "user1").
+				Return(&models.AuthFailure{Failures: 10, LastFailedAt: time.Now(), LockedUntil: &lockedUntil}, nil)
+			mockRepo.EXPECT().
+				GetAuthFailure(ctx, "ip", userIP.String()).
+				Return(&models.AuthFailure{}, nil)
+			mockRepo.EXPECT().
+				GetAuthFailure(ctx, "mfa", "user1").
+				Return(&models.AuthFailure{}, nil)
+
+			_, err := mfaSvc.VerifyRecoveryCode(ctx, challenge, "abcde23456", userIP)
+			assert.ErrorIs(t, err, ErrTooManyAttempts)
+		})
+
+		t.Run("Step-up", func(t *testing.T) {
+			stepUp, err := tokens.SignStepUpChallenge(DefaultTenant, "user1", "session1", userIP)
+			require.NoError(t, err)
+
+			_, err = mfaSvc.VerifyRecoveryCode(ctx, stepUp, "abcde23456", net.ParseIP("192.0.2.1"))
+			assert.ErrorIs(t, err, ErrInvalidMFAChallenge, "step-ups are bound to the new IP")
+
+			expectNotLocked()
+			mockRepo.EXPECT().GetUnusedRecoveryCodes(ctx, DefaultTenant, "user1").Return(stored, nil)
+			mockRepo.EXPECT().MarkRecoveryCodeUsed(ctx, "code-2").Return(nil)
+			mockRepo.EXPECT().ClearAuthFailures(ctx, gomock.Any(), "user1").Return(nil).Times(2)
+			mockRepo.EXPECT().SaveAuditEvent(ctx, gomock.Any()).Return(nil)
+			mockNotifier.EXPECT().SendSecurityAlert("user1", gomock.Any()).Return(nil)
+
+			login, err := mfaSvc.VerifyRecoveryCode(ctx, stepUp, "abcde23456", userIP)
+			require.NoError(t, err)
+			assert.Equal(t, "session1", login.SessionID)
+		})
+
+		t.Run("Invalid challenge", func(t *testing.T) {
+			access, err := tokens.GenerateAccessToken("user1", nil)
+			require.NoError(t, err)
+			foreign, err := tokens.SignMFAChallenge("acme", "user1", nil)
+			require.NoError(t, err)
+
+			for _, token := range []string{"garbage", access, foreign} {
+				_, err := mfaSvc.VerifyRecoveryCode(ctx, token, "abcde23456", userIP)
+				assert.ErrorIs(t, err, ErrInvalidMFAChallenge)
+			}
+		})
+	})
+}
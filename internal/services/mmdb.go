@@ -0,0 +1,334 @@
+package services
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+)
+
+// mmdbMetadataMarker starts the metadata section at the end of a MaxMind DB
+// file, see https://maxmind.github.io/MaxMind-DB/.
+var mmdbMetadataMarker = []byte("\xAB\xCD\xEFMaxMind.com")
+
+const mmdbDataSeparatorSize = 16
+
+var errMMDBInvalid = errors.New("invalid MaxMind DB file")
+
+// mmdbReader looks up records in a MaxMind DB file (GeoLite2, GeoIP2 and
+// compatible databases) held in memory.
+type mmdbReader struct {
+	buf          []byte
+	dataSection  []byte
+	databaseType string
+	nodeCount    uint
+	recordSize   uint
+	ipVersion    uint
+	ipv4Start    uint
+}
+
+func newMMDBReader(buf []byte) (*mmdbReader, error) {
+	markerAt := bytes.LastIndex(buf, mmdbMetadataMarker)
+	if markerAt < 0 {
+		return nil, fmt.Errorf("%w: metadata not found", errMMDBInvalid)
+	}
+	metadata := buf[markerAt+len(mmdbMetadataMarker):]
+	value, _, err := (&mmdbDecoder{buf: metadata}).decode(0, 0)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errMMDBInvalid, err)
+	}
+	meta, ok := value.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("%w: metadata is not a map", errMMDBInvalid)
+	}
+
+	r := &mmdbReader{
+		buf:          buf,
+		databaseType: mmdbString(meta, "database_type"),
+		nodeCount:    uint(mmdbUint(meta, "node_count")),
+		recordSize:   uint(mmdbUint(meta, "record_size")),
+		ipVersion:    uint(mmdbUint(meta, "ip_version")),
+	}
+	if r.recordSize != 24 && r.recordSize != 28 && r.recordSize != 32 {
+		return nil, fmt.Errorf("%w: unsupported record size %d", errMMDBInvalid, r.recordSize)
+	}
+	if r.ipVersion != 4 && r.ipVersion != 6 {
+		return nil, fmt.Errorf("%w: unsupported IP version %d", errMMDBInvalid, r.ipVersion)
+	}
+
+	treeSize := r.nodeCount * r.recordSize / 4
+	if treeSize+mmdbDataSeparatorSize > uint(markerAt) {
+		return nil, fmt.Errorf("%w: search tree is larger than the file", errMMDBInvalid)
+	}
+	r.dataSection = buf[treeSize+mmdbDataSeparatorSize : markerAt]
+
+	// IPv4 addresses live under ::/96 of IPv6 databases.
+	if r.ipVersion == 6 {
+		node := uint(0)
+		for i := 0; i < 96 && node < r.nodeCount; i++ {
+			node = r.readNode(node, 0)
+		}
+		r.ipv4Start = node
+	}
+	return r, nil
+}
+
+// lookup returns the record of the network of ip, nil when the database has
+// no record for it.
+func (r *mmdbReader) lookup(ip net.IP) (map[string]any, error) {
+	node, bits := uint(0), 128
+	if ip4 := ip.To4(); ip4 != nil {
+		ip, bits = ip4, 32
+		node = r.ipv4Start
+	} else if ip = ip.To16(); ip == nil || r.ipVersion == 4 {
+		return nil, nil
+	}
+
+	for i := 0; i < bits && node < r.nodeCount; i++ {
+		bit := uint(ip[i>>3]>>(7-uint(i&7))) & 1
+		node = r.readNode(node, bit)
+	}
+	if node == r.nodeCount {
+		return nil, nil
+	}
+	if node < r.nodeCount {
+		return nil, fmt.Errorf("%w: search tree is too deep", errMMDBInvalid)
+	}
+
+	offset := node - r.nodeCount - mmdbDataSeparatorSize
+	value, _, err := (&mmdbDecoder{buf: r.dataSection}).decode(offset, 0)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errMMDBInvalid, err)
+	}
+	record, _ := value.(map[string]any)
+	return record, nil
+}
+
+func (r *mmdbReader) readNode(node, bit uint) uint {
+	b := r.buf[node*r.recordSize/4:]
+	switch r.recordSize {
+	case 24:
+		b = b[bit*3:]
+		return uint(b[0])<<16 | uint(b[1])<<8 | uint(b[2])
+	case 28:
+		if bit == 0 {
+			return uint(b[3]&0xF0)<<20 | uint(b[0])<<16 | uint(b[1])<<8 | uint(b[2])
+		}
+		return uint(b[3]&0x0F)<<24 | uint(b[4])<<16 | uint(b[5])<<8 | uint(b[6])
+	default:
+		return uint(binary.BigEndian.Uint32(b[bit*4:]))
+	}
+}
+
+const (
+	mmdbTypePointer   = 1
+	mmdbTypeString    = 2
+	mmdbTypeDouble    = 3
+	mmdbTypeBytes     = 4
+	mmdbTypeUint16    = 5
+	mmdbTypeUint32    = 6
+	mmdbTypeMap       = 7
+	mmdbTypeInt32     = 8
+	mmdbTypeUint64    = 9
+	mmdbTypeUint128   = 10
+	mmdbTypeArray     = 11
+	mmdbTypeContainer = 12
+	mmdbTypeEndMarker = 13
+	mmdbTypeBool      = 14
+	mmdbTypeFloat     = 15
+
+	// mmdbMaxDepth guards against pointer loops in broken files.
+	mmdbMaxDepth = 32
+)
+
+// mmdbDecoder decodes the data section format. Maps become map[string]any,
+// arrays []any, unsigned integers uint64, bytes and uint128 values []byte.
+type mmdbDecoder struct {
+	buf []byte
+}
+
+// decode returns the value at offset and the offset after it.
+func (d *mmdbDecoder) decode(offset uint, depth int) (any, uint, error) {
+	if depth > mmdbMaxDepth {
+		return nil, 0, errors.New("data is nested too deep")
+	}
+	kind, size, offset, err := d.control(offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if kind == mmdbTypePointer {
+		target, next, err := d.pointer(size, offset)
+		if err != nil {
+			return nil, 0, err
+		}
+		value, _, err := d.decode(target, depth+1)
+		return value, next, err
+	}
+
+	switch kind {
+	case mmdbTypeMap:
+		m := make(map[string]any, size)
+		for i := uint(0); i < size; i++ {
+			key, next, err := d.decode(offset, depth+1)
+			if err != nil {
+				return nil, 0, err
+			}
+			k, ok := key.(string)
+			if !ok {
+				return nil, 0, errors.New("map key is not a string")
+			}
+			m[k], offset, err = d.decode(next, depth+1)
+			if err != nil {
+				return nil, 0, err
+			}
+		}
+		return m, offset, nil
+	case mmdbTypeArray:
+		a := make([]any, 0, size)
+		for i := uint(0); i < size; i++ {
+			var value any
+			value, offset, err = d.decode(offset, depth+1)
+			if err != nil {
+				return nil, 0, err
+			}
+			a = append(a, value)
+		}
+		return a, offset, nil
+	case mmdbTypeBool:
+		return size != 0, offset, nil
+	}
+
+	if offset+size > uint(len(d.buf)) {
+		return nil, 0, errors.New("value is out of bounds")
+	}
+	b := d.buf[offset : offset+size]
+	next := offset + size
+	switch kind {
+	case mmdbTypeString:
+		return string(b), next, nil
+	case mmdbTypeBytes, mmdbTypeUint128:
+		return append([]byte(nil), b...), next, nil
+	case mmdbTypeDouble:
+		if size != 8 {
+			return nil, 0, errors.New("invalid double size")
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(b)), next, nil
+	case mmdbTypeFloat:
+		if size != 4 {
+			return nil, 0, errors.New("invalid float size")
+		}
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(b))), next, nil
+	case mmdbTypeUint16, mmdbTypeUint32, mmdbTypeUint64:
+		if size > 8 {
+			return nil, 0, errors.New("invalid integer size")
+		}
+		var n uint64
+		for _, c := range b {
+			n = n<<8 | uint64(c)
+		}
+		return n, next, nil
+	case mmdbTypeInt32:
+		if size > 4 {
+			return nil, 0, errors.New("invalid integer size")
+		}
+		var n uint32
+		for _, c := range b {
+			n = n<<8 | uint32(c)
+		}
+		return int64(int32(n)), next, nil
+	default:
+		return nil, 0, fmt.Errorf("unsupported data type %d", kind)
+	}
+}
+
+// control reads the control byte of a field and returns its type, its size
+// and the offset of its payload.
+func (d *mmdbDecoder) control(offset uint) (kind, size, next uint, err error) {
+	if offset >= uint(len(d.buf)) {
+		return 0, 0, 0, errors.New("field is out of bounds")
+	}
+	ctrl := d.buf[offset]
+	offset++
+	kind = uint(ctrl >> 5)
+	if kind == 0 {
+		if offset >= uint(len(d.buf)) {
+			return 0, 0, 0, errors.New("field is out of bounds")
+		}
+		kind = 7 + uint(d.buf[offset])
+		offset++
+	}
+	if kind == mmdbTypePointer {
+		// Pointers keep their size bits for the pointer itself.
+		return kind, uint(ctrl & 0x1F), offset, nil
+	}
+
+	size = uint(ctrl & 0x1F)
+	if size >= 29 {
+		extra := size - 28
+		if offset+extra > uint(len(d.buf)) {
+			return 0, 0, 0, errors.New("field size is out of bounds")
+		}
+		var n uint
+		for _, c := range d.buf[offset : offset+extra] {
+			n = n<<8 | uint(c)
+		}
+		switch size {
+		case 29:
+			size = 29 + n
+		case 30:
+			size = 285 + n
+		default:
+			size = 65821 + n
+		}
+		offset += extra
+	}
+	return kind, size, offset, nil
+}
+
+// pointer resolves a pointer whose control bits are bits.
+func (d *mmdbDecoder) pointer(bits, offset uint) (target, next uint, err error) {
+	length := bits>>3&0x3 + 1
+	if offset+length > uint(len(d.buf)) {
+		return 0, 0, errors.New("pointer is out of bounds")
+	}
+	var n uint
+	for _, c := range d.buf[offset : offset+length] {
+		n = n<<8 | uint(c)
+	}
+	switch length {
+	case 1:
+		target = (bits&0x7)<<8 | n
+	case 2:
+		target = ((bits&0x7)<<16 | n) + 2048
+	case 3:
+		target = ((bits&0x7)<<24 | n) + 526336
+	default:
+		target = n
+	}
+	return target, offset + length, nil
+}
+
+func mmdbMapValue(m map[string]any, path ...string) any {
+	var value any = m
+	for _, key := range path {
+		node, ok := value.(map[string]any)
+		if !ok {
+			return nil
+		}
+		value = node[key]
+	}
+	return value
+}
+
+func mmdbString(m map[string]any, path ...string) string {
+	s, _ := mmdbMapValue(m, path...).(string)
+	return s
+}
+
+func mmdbUint(m map[string]any, path ...string) uint64 {
+	n, _ := mmdbMapValue(m, path...).(uint64)
+	return n
+}
@@ -0,0 +1,81 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/auth-service/internal/services (interfaces: AccountServiceInterface)
+
+// Package services is a generated GoMock package.
+package services
+
+import (
+	context "context"
+	net "net"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockAccountServiceInterface is a mock of AccountServiceInterface interface.
+type MockAccountServiceInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockAccountServiceInterfaceMockRecorder
+}
+
+// MockAccountServiceInterfaceMockRecorder is the mock recorder for MockAccountServiceInterface.
+type MockAccountServiceInterfaceMockRecorder struct {
+	mock *MockAccountServiceInterface
+}
+
+// NewMockAccountServiceInterface creates a new mock instance.
+func NewMockAccountServiceInterface(ctrl *gomock.Controller) *MockAccountServiceInterface {
+	mock := &MockAccountServiceInterface{ctrl: ctrl}
+	mock.recorder = &MockAccountServiceInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAccountServiceInterface) EXPECT() *MockAccountServiceInterfaceMockRecorder {
+	return m.recorder
+}
+
+// CancelDeletion mocks base method.
+func (m *MockAccountServiceInterface) CancelDeletion(arg0 context.Context, arg1 string, arg2 net.IP) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelDeletion", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CancelDeletion indicates an expected call of CancelDeletion.
+func (mr *MockAccountServiceInterfaceMockRecorder) CancelDeletion(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelDeletion", reflect.TypeOf((*MockAccountServiceInterface)(nil).CancelDeletion), arg0, arg1, arg2)
+}
+
+// Export mocks base method.
+func (m *MockAccountServiceInterface) Export(arg0 context.Context, arg1 string, arg2 net.IP) (*AccountExport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Export", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*AccountExport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Export indicates an expected call of Export.
+func (mr *MockAccountServiceInterfaceMockRecorder) Export(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Export", reflect.TypeOf((*MockAccountServiceInterface)(nil).Export), arg0, arg1, arg2)
+}
+
+// ScheduleDeletion mocks base method.
+func (m *MockAccountServiceInterface) ScheduleDeletion(arg0 context.Context, arg1 string, arg2 net.IP) (time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ScheduleDeletion", arg0, arg1, arg2)
+	ret0, _ := ret[0].(time.Time)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ScheduleDeletion indicates an expected call of ScheduleDeletion.
+func (mr *MockAccountServiceInterfaceMockRecorder) ScheduleDeletion(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScheduleDeletion", reflect.TypeOf((*MockAccountServiceInterface)(nil).ScheduleDeletion), arg0, arg1, arg2)
+}
@@ -0,0 +1,98 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/auth-service/internal/services (interfaces: APIKeyServiceInterface)
+
+// Package services is a generated GoMock package.
+package services
+
+import (
+	context "context"
+	net "net"
+	reflect "reflect"
+	time "time"
+
+	models "github.com/auth-service/internal/models"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockAPIKeyServiceInterface is a mock of APIKeyServiceInterface interface.
+type MockAPIKeyServiceInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockAPIKeyServiceInterfaceMockRecorder
+}
+
+// MockAPIKeyServiceInterfaceMockRecorder is the mock recorder for MockAPIKeyServiceInterface.
+type MockAPIKeyServiceInterfaceMockRecorder struct {
+	mock *MockAPIKeyServiceInterface
+}
+
+// NewMockAPIKeyServiceInterface creates a new mock instance.
+func NewMockAPIKeyServiceInterface(ctrl *gomock.Controller) *MockAPIKeyServiceInterface {
+	mock := &MockAPIKeyServiceInterface{ctrl: ctrl}
+	mock.recorder = &MockAPIKeyServiceInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAPIKeyServiceInterface) EXPECT() *MockAPIKeyServiceInterfaceMockRecorder {
+	return m.recorder
+}
+
+// Authenticate mocks base method.
+func (m *MockAPIKeyServiceInterface) Authenticate(arg0 context.Context, arg1 string, arg2 net.IP) (*models.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Authenticate", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Authenticate indicates an expected call of Authenticate.
+func (mr *MockAPIKeyServiceInterfaceMockRecorder) Authenticate(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authenticate", reflect.TypeOf((*MockAPIKeyServiceInterface)(nil).Authenticate), arg0, arg1, arg2)
+}
+
+// Create mocks base method.
+func (m *MockAPIKeyServiceInterface) Create(arg0 context.Context, arg1, arg2 string, arg3 []string, arg4 *time.Time, arg5 net.IP) (*models.APIKey, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", arg0, arg1, arg2, arg3, arg4, arg5)
+	ret0, _ := ret[0].(*models.APIKey)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockAPIKeyServiceInterfaceMockRecorder) Create(arg0, arg1, arg2, arg3, arg4, arg5 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockAPIKeyServiceInterface)(nil).Create), arg0, arg1, arg2, arg3, arg4, arg5)
+}
+
+// List mocks base method.
+func (m *MockAPIKeyServiceInterface) List(arg0 context.Context, arg1 string) ([]models.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", arg0, arg1)
+	ret0, _ := ret[0].([]models.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockAPIKeyServiceInterfaceMockRecorder) List(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockAPIKeyServiceInterface)(nil).List), arg0, arg1)
+}
+
+// Revoke mocks base method.
+func (m *MockAPIKeyServiceInterface) Revoke(arg0 context.Context, arg1, arg2 string, arg3 net.IP) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Revoke", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Revoke indicates an expected call of Revoke.
+func (mr *MockAPIKeyServiceInterfaceMockRecorder) Revoke(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Revoke", reflect.TypeOf((*MockAPIKeyServiceInterface)(nil).Revoke), arg0, arg1, arg2, arg3)
+}
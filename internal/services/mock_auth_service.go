@@ -36,6 +36,21 @@ func (m *MockAuthServiceInterface) EXPECT() *MockAuthServiceInterfaceMockRecorde
 	return m.recorder
 }
 
+// CompleteStepUp mocks base method.
+func (m *MockAuthServiceInterface) CompleteStepUp(arg0 context.Context, arg1, arg2 string, arg3 net.IP) (*models.TokenPair, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteStepUp", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*models.TokenPair)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CompleteStepUp indicates an expected call of CompleteStepUp.
+func (mr *MockAuthServiceInterfaceMockRecorder) CompleteStepUp(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteStepUp", reflect.TypeOf((*MockAuthServiceInterface)(nil).CompleteStepUp), arg0, arg1, arg2, arg3)
+}
+
 // GenerateTokens mocks base method.
 func (m *MockAuthServiceInterface) GenerateTokens(arg0 context.Context, arg1 string, arg2 net.IP) (*models.TokenPair, error) {
 	m.ctrl.T.Helper()
@@ -51,6 +66,49 @@ func (mr *MockAuthServiceInterfaceMockRecorder) GenerateTokens(arg0, arg1, arg2
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateTokens", reflect.TypeOf((*MockAuthServiceInterface)(nil).GenerateTokens), arg0, arg1, arg2)
 }
 
+// IssueTokens mocks base method.
+func (m *MockAuthServiceInterface) IssueTokens(arg0 context.Context, arg1 TokenGrant) (*models.TokenPair, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IssueTokens", arg0, arg1)
+	ret0, _ := ret[0].(*models.TokenPair)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IssueTokens indicates an expected call of IssueTokens.
+func (mr *MockAuthServiceInterfaceMockRecorder) IssueTokens(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IssueTokens", reflect.TypeOf((*MockAuthServiceInterface)(nil).IssueTokens), arg0, arg1)
+}
+
+// Logout mocks base method.
+func (m *MockAuthServiceInterface) Logout(arg0 context.Context, arg1 string, arg2 AccessTokenRef) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Logout", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Logout indicates an expected call of Logout.
+func (mr *MockAuthServiceInterfaceMockRecorder) Logout(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Logout", reflect.TypeOf((*MockAuthServiceInterface)(nil).Logout), arg0, arg1, arg2)
+}
+
+// LogoutAll mocks base method.
+func (m *MockAuthServiceInterface) LogoutAll(arg0 context.Context, arg1 string, arg2 AccessTokenRef) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LogoutAll", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LogoutAll indicates an expected call of LogoutAll.
+func (mr *MockAuthServiceInterfaceMockRecorder) LogoutAll(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LogoutAll", reflect.TypeOf((*MockAuthServiceInterface)(nil).LogoutAll), arg0, arg1, arg2)
+}
+
 // RefreshTokens mocks base method.
 func (m *MockAuthServiceInterface) RefreshTokens(arg0 context.Context, arg1, arg2 string, arg3 net.IP) (*models.TokenPair, error) {
 	m.ctrl.T.Helper()
@@ -79,3 +137,18 @@ func (mr *MockAuthServiceInterfaceMockRecorder) RevokeAllTokens(arg0, arg1 inter
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAllTokens", reflect.TypeOf((*MockAuthServiceInterface)(nil).RevokeAllTokens), arg0, arg1)
 }
+
+// SwitchOrganization mocks base method.
+func (m *MockAuthServiceInterface) SwitchOrganization(arg0 context.Context, arg1, arg2, arg3 string, arg4 net.IP) (*models.TokenPair, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SwitchOrganization", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(*models.TokenPair)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SwitchOrganization indicates an expected call of SwitchOrganization.
+func (mr *MockAuthServiceInterfaceMockRecorder) SwitchOrganization(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SwitchOrganization", reflect.TypeOf((*MockAuthServiceInterface)(nil).SwitchOrganization), arg0, arg1, arg2, arg3, arg4)
+}
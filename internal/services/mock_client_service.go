@@ -0,0 +1,82 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/auth-service/internal/services (interfaces: ClientServiceInterface)
+
+// Package services is a generated GoMock package.
+package services
+
+import (
+	context "context"
+	net "net"
+	reflect "reflect"
+
+	models "github.com/auth-service/internal/models"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockClientServiceInterface is a mock of ClientServiceInterface interface.
+type MockClientServiceInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockClientServiceInterfaceMockRecorder
+}
+
+// MockClientServiceInterfaceMockRecorder is the mock recorder for MockClientServiceInterface.
+type MockClientServiceInterfaceMockRecorder struct {
+	mock *MockClientServiceInterface
+}
+
+// NewMockClientServiceInterface creates a new mock instance.
+func NewMockClientServiceInterface(ctrl *gomock.Controller) *MockClientServiceInterface {
+	mock := &MockClientServiceInterface{ctrl: ctrl}
+	mock.recorder = &MockClientServiceInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockClientServiceInterface) EXPECT() *MockClientServiceInterfaceMockRecorder {
+	return m.recorder
+}
+
+// CreateClient mocks base method.
+func (m *MockClientServiceInterface) CreateClient(arg0 context.Context, arg1 ClientRegistration, arg2 string, arg3 net.IP) (*models.OAuthClient, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateClient", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*models.OAuthClient)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// CreateClient indicates an expected call of CreateClient.
+func (mr *MockClientServiceInterfaceMockRecorder) CreateClient(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateClient", reflect.TypeOf((*MockClientServiceInterface)(nil).CreateClient), arg0, arg1, arg2, arg3)
+}
+
+// DisableClient mocks base method.
+func (m *MockClientServiceInterface) DisableClient(arg0 context.Context, arg1, arg2 string, arg3 net.IP) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DisableClient", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DisableClient indicates an expected call of DisableClient.
+func (mr *MockClientServiceInterfaceMockRecorder) DisableClient(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisableClient", reflect.TypeOf((*MockClientServiceInterface)(nil).DisableClient), arg0, arg1, arg2, arg3)
+}
+
+// RotateSecret mocks base method.
+func (m *MockClientServiceInterface) RotateSecret(arg0 context.Context, arg1, arg2 string, arg3 net.IP) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RotateSecret", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RotateSecret indicates an expected call of RotateSecret.
+func (mr *MockClientServiceInterfaceMockRecorder) RotateSecret(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RotateSecret", reflect.TypeOf((*MockClientServiceInterface)(nil).RotateSecret), arg0, arg1, arg2, arg3)
+}
@@ -0,0 +1,68 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/auth-service/internal/services (interfaces: FederationServiceInterface)
+
+// Package services is a generated GoMock package.
+package services
+
+import (
+	context "context"
+	net "net"
+	reflect "reflect"
+
+	models "github.com/auth-service/internal/models"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockFederationServiceInterface is a mock of FederationServiceInterface interface.
+type MockFederationServiceInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockFederationServiceInterfaceMockRecorder
+}
+
+// MockFederationServiceInterfaceMockRecorder is the mock recorder for MockFederationServiceInterface.
+type MockFederationServiceInterfaceMockRecorder struct {
+	mock *MockFederationServiceInterface
+}
+
+// NewMockFederationServiceInterface creates a new mock instance.
+func NewMockFederationServiceInterface(ctrl *gomock.Controller) *MockFederationServiceInterface {
+	mock := &MockFederationServiceInterface{ctrl: ctrl}
+	mock.recorder = &MockFederationServiceInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFederationServiceInterface) EXPECT() *MockFederationServiceInterfaceMockRecorder {
+	return m.recorder
+}
+
+// Begin mocks base method.
+func (m *MockFederationServiceInterface) Begin(arg0 context.Context, arg1 string) (string, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Begin", arg0, arg1)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Begin indicates an expected call of Begin.
+func (mr *MockFederationServiceInterfaceMockRecorder) Begin(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Begin", reflect.TypeOf((*MockFederationServiceInterface)(nil).Begin), arg0, arg1)
+}
+
+// Complete mocks base method.
+func (m *MockFederationServiceInterface) Complete(arg0 context.Context, arg1, arg2, arg3 string, arg4 net.IP) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Complete", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Complete indicates an expected call of Complete.
+func (mr *MockFederationServiceInterfaceMockRecorder) Complete(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Complete", reflect.TypeOf((*MockFederationServiceInterface)(nil).Complete), arg0, arg1, arg2, arg3, arg4)
+}
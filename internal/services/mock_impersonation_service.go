@@ -0,0 +1,52 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/auth-service/internal/services (interfaces: ImpersonationServiceInterface)
+
+// Package services is a generated GoMock package.
+package services
+
+import (
+	context "context"
+	net "net"
+	reflect "reflect"
+
+	models "github.com/auth-service/internal/models"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockImpersonationServiceInterface is a mock of ImpersonationServiceInterface interface.
+type MockImpersonationServiceInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockImpersonationServiceInterfaceMockRecorder
+}
+
+// MockImpersonationServiceInterfaceMockRecorder is the mock recorder for MockImpersonationServiceInterface.
+type MockImpersonationServiceInterfaceMockRecorder struct {
+	mock *MockImpersonationServiceInterface
+}
+
+// NewMockImpersonationServiceInterface creates a new mock instance.
+func NewMockImpersonationServiceInterface(ctrl *gomock.Controller) *MockImpersonationServiceInterface {
+	mock := &MockImpersonationServiceInterface{ctrl: ctrl}
+	mock.recorder = &MockImpersonationServiceInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockImpersonationServiceInterface) EXPECT() *MockImpersonationServiceInterfaceMockRecorder {
+	return m.recorder
+}
+
+// Impersonate mocks base method.
+func (m *MockImpersonationServiceInterface) Impersonate(arg0 context.Context, arg1, arg2, arg3 string, arg4 net.IP) (*models.TokenPair, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Impersonate", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(*models.TokenPair)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Impersonate indicates an expected call of Impersonate.
+func (mr *MockImpersonationServiceInterfaceMockRecorder) Impersonate(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Impersonate", reflect.TypeOf((*MockImpersonationServiceInterface)(nil).Impersonate), arg0, arg1, arg2, arg3, arg4)
+}
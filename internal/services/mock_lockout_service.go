@@ -0,0 +1,50 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/auth-service/internal/services (interfaces: LockoutServiceInterface)
+
+// Package services is a generated GoMock package.
+package services
+
+import (
+	context "context"
+	net "net"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockLockoutServiceInterface is a mock of LockoutServiceInterface interface.
+type MockLockoutServiceInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockLockoutServiceInterfaceMockRecorder
+}
+
+// MockLockoutServiceInterfaceMockRecorder is the mock recorder for MockLockoutServiceInterface.
+type MockLockoutServiceInterfaceMockRecorder struct {
+	mock *MockLockoutServiceInterface
+}
+
+// NewMockLockoutServiceInterface creates a new mock instance.
+func NewMockLockoutServiceInterface(ctrl *gomock.Controller) *MockLockoutServiceInterface {
+	mock := &MockLockoutServiceInterface{ctrl: ctrl}
+	mock.recorder = &MockLockoutServiceInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLockoutServiceInterface) EXPECT() *MockLockoutServiceInterfaceMockRecorder {
+	return m.recorder
+}
+
+// Unlock mocks base method.
+func (m *MockLockoutServiceInterface) Unlock(arg0 context.Context, arg1, arg2 string, arg3 net.IP) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Unlock", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Unlock indicates an expected call of Unlock.
+func (mr *MockLockoutServiceInterfaceMockRecorder) Unlock(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unlock", reflect.TypeOf((*MockLockoutServiceInterface)(nil).Unlock), arg0, arg1, arg2, arg3)
+}
@@ -0,0 +1,81 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/auth-service/internal/services (interfaces: MagicLinkServiceInterface)
+
+// Package services is a generated GoMock package.
+package services
+
+import (
+	context "context"
+	net "net"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockMagicLinkServiceInterface is a mock of MagicLinkServiceInterface interface.
+type MockMagicLinkServiceInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockMagicLinkServiceInterfaceMockRecorder
+}
+
+// MockMagicLinkServiceInterfaceMockRecorder is the mock recorder for MockMagicLinkServiceInterface.
+type MockMagicLinkServiceInterfaceMockRecorder struct {
+	mock *MockMagicLinkServiceInterface
+}
+
+// NewMockMagicLinkServiceInterface creates a new mock instance.
+func NewMockMagicLinkServiceInterface(ctrl *gomock.Controller) *MockMagicLinkServiceInterface {
+	mock := &MockMagicLinkServiceInterface{ctrl: ctrl}
+	mock.recorder = &MockMagicLinkServiceInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMagicLinkServiceInterface) EXPECT() *MockMagicLinkServiceInterfaceMockRecorder {
+	return m.recorder
+}
+
+// Request mocks base method.
+func (m *MockMagicLinkServiceInterface) Request(arg0 context.Context, arg1 string, arg2 net.IP) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Request", arg0, arg1, arg2)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Request indicates an expected call of Request.
+func (mr *MockMagicLinkServiceInterfaceMockRecorder) Request(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Request", reflect.TypeOf((*MockMagicLinkServiceInterface)(nil).Request), arg0, arg1, arg2)
+}
+
+// VerifyCode mocks base method.
+func (m *MockMagicLinkServiceInterface) VerifyCode(arg0 context.Context, arg1, arg2 string, arg3 net.IP, arg4 string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyCode", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyCode indicates an expected call of VerifyCode.
+func (mr *MockMagicLinkServiceInterfaceMockRecorder) VerifyCode(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyCode", reflect.TypeOf((*MockMagicLinkServiceInterface)(nil).VerifyCode), arg0, arg1, arg2, arg3, arg4)
+}
+
+// VerifyLink mocks base method.
+func (m *MockMagicLinkServiceInterface) VerifyLink(arg0 context.Context, arg1, arg2 string, arg3 net.IP, arg4 string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyLink", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyLink indicates an expected call of VerifyLink.
+func (mr *MockMagicLinkServiceInterfaceMockRecorder) VerifyLink(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyLink", reflect.TypeOf((*MockMagicLinkServiceInterface)(nil).VerifyLink), arg0, arg1, arg2, arg3, arg4)
+}
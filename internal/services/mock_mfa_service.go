@@ -0,0 +1,81 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/auth-service/internal/services (interfaces: MFAServiceInterface)
+
+// Package services is a generated GoMock package.
+package services
+
+import (
+	context "context"
+	net "net"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockMFAServiceInterface is a mock of MFAServiceInterface interface.
+type MockMFAServiceInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockMFAServiceInterfaceMockRecorder
+}
+
+// MockMFAServiceInterfaceMockRecorder is the mock recorder for MockMFAServiceInterface.
+type MockMFAServiceInterfaceMockRecorder struct {
+	mock *MockMFAServiceInterface
+}
+
+// NewMockMFAServiceInterface creates a new mock instance.
+func NewMockMFAServiceInterface(ctrl *gomock.Controller) *MockMFAServiceInterface {
+	mock := &MockMFAServiceInterface{ctrl: ctrl}
+	mock.recorder = &MockMFAServiceInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMFAServiceInterface) EXPECT() *MockMFAServiceInterfaceMockRecorder {
+	return m.recorder
+}
+
+// Challenge mocks base method.
+func (m *MockMFAServiceInterface) Challenge(arg0 context.Context, arg1 string, arg2 []string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Challenge", arg0, arg1, arg2)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Challenge indicates an expected call of Challenge.
+func (mr *MockMFAServiceInterfaceMockRecorder) Challenge(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Challenge", reflect.TypeOf((*MockMFAServiceInterface)(nil).Challenge), arg0, arg1, arg2)
+}
+
+// GenerateRecoveryCodes mocks base method.
+func (m *MockMFAServiceInterface) GenerateRecoveryCodes(arg0 context.Context, arg1 string, arg2 net.IP) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GenerateRecoveryCodes", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GenerateRecoveryCodes indicates an expected call of GenerateRecoveryCodes.
+func (mr *MockMFAServiceInterfaceMockRecorder) GenerateRecoveryCodes(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateRecoveryCodes", reflect.TypeOf((*MockMFAServiceInterface)(nil).GenerateRecoveryCodes), arg0, arg1, arg2)
+}
+
+// VerifyRecoveryCode mocks base method.
+func (m *MockMFAServiceInterface) VerifyRecoveryCode(arg0 context.Context, arg1, arg2 string, arg3 net.IP) (*MFALogin, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyRecoveryCode", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*MFALogin)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyRecoveryCode indicates an expected call of VerifyRecoveryCode.
+func (mr *MockMFAServiceInterfaceMockRecorder) VerifyRecoveryCode(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyRecoveryCode", reflect.TypeOf((*MockMFAServiceInterface)(nil).VerifyRecoveryCode), arg0, arg1, arg2, arg3)
+}
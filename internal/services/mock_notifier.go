@@ -33,6 +33,20 @@ func (m *MockNotifier) EXPECT() *MockNotifierMockRecorder {
 	return m.recorder
 }
 
+// SendEmail mocks base method.
+func (m *MockNotifier) SendEmail(arg0, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendEmail", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendEmail indicates an expected call of SendEmail.
+func (mr *MockNotifierMockRecorder) SendEmail(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendEmail", reflect.TypeOf((*MockNotifier)(nil).SendEmail), arg0, arg1, arg2)
+}
+
 // SendSecurityAlert mocks base method.
 func (m *MockNotifier) SendSecurityAlert(arg0, arg1 string) error {
 	m.ctrl.T.Helper()
@@ -0,0 +1,126 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/auth-service/internal/services (interfaces: OAuthServiceInterface)
+
+// Package services is a generated GoMock package.
+package services
+
+import (
+	context "context"
+	net "net"
+	reflect "reflect"
+
+	models "github.com/auth-service/internal/models"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockOAuthServiceInterface is a mock of OAuthServiceInterface interface.
+type MockOAuthServiceInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockOAuthServiceInterfaceMockRecorder
+}
+
+// MockOAuthServiceInterfaceMockRecorder is the mock recorder for MockOAuthServiceInterface.
+type MockOAuthServiceInterfaceMockRecorder struct {
+	mock *MockOAuthServiceInterface
+}
+
+// NewMockOAuthServiceInterface creates a new mock instance.
+func NewMockOAuthServiceInterface(ctrl *gomock.Controller) *MockOAuthServiceInterface {
+	mock := &MockOAuthServiceInterface{ctrl: ctrl}
+	mock.recorder = &MockOAuthServiceInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOAuthServiceInterface) EXPECT() *MockOAuthServiceInterfaceMockRecorder {
+	return m.recorder
+}
+
+// DecideDevice mocks base method.
+func (m *MockOAuthServiceInterface) DecideDevice(arg0 context.Context, arg1, arg2 string, arg3 bool, arg4 net.IP) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DecideDevice", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DecideDevice indicates an expected call of DecideDevice.
+func (mr *MockOAuthServiceInterfaceMockRecorder) DecideDevice(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DecideDevice", reflect.TypeOf((*MockOAuthServiceInterface)(nil).DecideDevice), arg0, arg1, arg2, arg3, arg4)
+}
+
+// DeviceAuthorization mocks base method.
+func (m *MockOAuthServiceInterface) DeviceAuthorization(arg0 context.Context, arg1, arg2, arg3 string) (*DeviceAuthorization, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeviceAuthorization", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*DeviceAuthorization)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeviceAuthorization indicates an expected call of DeviceAuthorization.
+func (mr *MockOAuthServiceInterfaceMockRecorder) DeviceAuthorization(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeviceAuthorization", reflect.TypeOf((*MockOAuthServiceInterface)(nil).DeviceAuthorization), arg0, arg1, arg2, arg3)
+}
+
+// IssueCode mocks base method.
+func (m *MockOAuthServiceInterface) IssueCode(arg0 context.Context, arg1 *Authorization, arg2 Authentication, arg3 net.IP) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IssueCode", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IssueCode indicates an expected call of IssueCode.
+func (mr *MockOAuthServiceInterfaceMockRecorder) IssueCode(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IssueCode", reflect.TypeOf((*MockOAuthServiceInterface)(nil).IssueCode), arg0, arg1, arg2, arg3)
+}
+
+// LookupDeviceCode mocks base method.
+func (m *MockOAuthServiceInterface) LookupDeviceCode(arg0 context.Context, arg1 string) (*DeviceVerification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LookupDeviceCode", arg0, arg1)
+	ret0, _ := ret[0].(*DeviceVerification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LookupDeviceCode indicates an expected call of LookupDeviceCode.
+func (mr *MockOAuthServiceInterfaceMockRecorder) LookupDeviceCode(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LookupDeviceCode", reflect.TypeOf((*MockOAuthServiceInterface)(nil).LookupDeviceCode), arg0, arg1)
+}
+
+// Token mocks base method.
+func (m *MockOAuthServiceInterface) Token(arg0 context.Context, arg1 TokenRequest) (*models.TokenPair, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Token", arg0, arg1)
+	ret0, _ := ret[0].(*models.TokenPair)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Token indicates an expected call of Token.
+func (mr *MockOAuthServiceInterfaceMockRecorder) Token(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Token", reflect.TypeOf((*MockOAuthServiceInterface)(nil).Token), arg0, arg1)
+}
+
+// ValidateAuthorization mocks base method.
+func (m *MockOAuthServiceInterface) ValidateAuthorization(arg0 context.Context, arg1 AuthorizeRequest) (*Authorization, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ValidateAuthorization", arg0, arg1)
+	ret0, _ := ret[0].(*Authorization)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ValidateAuthorization indicates an expected call of ValidateAuthorization.
+func (mr *MockOAuthServiceInterfaceMockRecorder) ValidateAuthorization(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ValidateAuthorization", reflect.TypeOf((*MockOAuthServiceInterface)(nil).ValidateAuthorization), arg0, arg1)
+}
@@ -0,0 +1,213 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/auth-service/internal/services (interfaces: OrganizationServiceInterface)
+
+// Package services is a generated GoMock package.
+package services
+
+import (
+	context "context"
+	net "net"
+	reflect "reflect"
+
+	models "github.com/auth-service/internal/models"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockOrganizationServiceInterface is a mock of OrganizationServiceInterface interface.
+type MockOrganizationServiceInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockOrganizationServiceInterfaceMockRecorder
+}
+
+// MockOrganizationServiceInterfaceMockRecorder is the mock recorder for MockOrganizationServiceInterface.
+type MockOrganizationServiceInterfaceMockRecorder struct {
+	mock *MockOrganizationServiceInterface
+}
+
+// NewMockOrganizationServiceInterface creates a new mock instance.
+func NewMockOrganizationServiceInterface(ctrl *gomock.Controller) *MockOrganizationServiceInterface {
+	mock := &MockOrganizationServiceInterface{ctrl: ctrl}
+	mock.recorder = &MockOrganizationServiceInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOrganizationServiceInterface) EXPECT() *MockOrganizationServiceInterfaceMockRecorder {
+	return m.recorder
+}
+
+// Accept mocks base method.
+func (m *MockOrganizationServiceInterface) Accept(arg0 context.Context, arg1, arg2 string, arg3 net.IP) (*models.OrganizationMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Accept", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*models.OrganizationMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Accept indicates an expected call of Accept.
+func (mr *MockOrganizationServiceInterfaceMockRecorder) Accept(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Accept", reflect.TypeOf((*MockOrganizationServiceInterface)(nil).Accept), arg0, arg1, arg2, arg3)
+}
+
+// AddTeamMember mocks base method.
+func (m *MockOrganizationServiceInterface) AddTeamMember(arg0 context.Context, arg1, arg2, arg3, arg4 string, arg5 net.IP) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddTeamMember", arg0, arg1, arg2, arg3, arg4, arg5)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddTeamMember indicates an expected call of AddTeamMember.
+func (mr *MockOrganizationServiceInterfaceMockRecorder) AddTeamMember(arg0, arg1, arg2, arg3, arg4, arg5 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddTeamMember", reflect.TypeOf((*MockOrganizationServiceInterface)(nil).AddTeamMember), arg0, arg1, arg2, arg3, arg4, arg5)
+}
+
+// ChangeRole mocks base method.
+func (m *MockOrganizationServiceInterface) ChangeRole(arg0 context.Context, arg1, arg2, arg3, arg4 string, arg5 net.IP) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangeRole", arg0, arg1, arg2, arg3, arg4, arg5)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ChangeRole indicates an expected call of ChangeRole.
+func (mr *MockOrganizationServiceInterfaceMockRecorder) ChangeRole(arg0, arg1, arg2, arg3, arg4, arg5 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangeRole", reflect.TypeOf((*MockOrganizationServiceInterface)(nil).ChangeRole), arg0, arg1, arg2, arg3, arg4, arg5)
+}
+
+// Create mocks base method.
+func (m *MockOrganizationServiceInterface) Create(arg0 context.Context, arg1, arg2 string, arg3 net.IP) (*models.Organization, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*models.Organization)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockOrganizationServiceInterfaceMockRecorder) Create(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockOrganizationServiceInterface)(nil).Create), arg0, arg1, arg2, arg3)
+}
+
+// CreateTeam mocks base method.
+func (m *MockOrganizationServiceInterface) CreateTeam(arg0 context.Context, arg1, arg2, arg3 string, arg4 net.IP) (*models.Team, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTeam", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(*models.Team)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateTeam indicates an expected call of CreateTeam.
+func (mr *MockOrganizationServiceInterfaceMockRecorder) CreateTeam(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTeam", reflect.TypeOf((*MockOrganizationServiceInterface)(nil).CreateTeam), arg0, arg1, arg2, arg3, arg4)
+}
+
+// Invite mocks base method.
+func (m *MockOrganizationServiceInterface) Invite(arg0 context.Context, arg1, arg2, arg3, arg4 string, arg5 net.IP) (*models.OrganizationInvitation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Invite", arg0, arg1, arg2, arg3, arg4, arg5)
+	ret0, _ := ret[0].(*models.OrganizationInvitation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Invite indicates an expected call of Invite.
+func (mr *MockOrganizationServiceInterfaceMockRecorder) Invite(arg0, arg1, arg2, arg3, arg4, arg5 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Invite", reflect.TypeOf((*MockOrganizationServiceInterface)(nil).Invite), arg0, arg1, arg2, arg3, arg4, arg5)
+}
+
+// ListForUser mocks base method.
+func (m *MockOrganizationServiceInterface) ListForUser(arg0 context.Context, arg1 string) ([]models.Organization, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListForUser", arg0, arg1)
+	ret0, _ := ret[0].([]models.Organization)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListForUser indicates an expected call of ListForUser.
+func (mr *MockOrganizationServiceInterfaceMockRecorder) ListForUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListForUser", reflect.TypeOf((*MockOrganizationServiceInterface)(nil).ListForUser), arg0, arg1)
+}
+
+// ListTeams mocks base method.
+func (m *MockOrganizationServiceInterface) ListTeams(arg0 context.Context, arg1, arg2 string) ([]models.Team, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTeams", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]models.Team)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTeams indicates an expected call of ListTeams.
+func (mr *MockOrganizationServiceInterfaceMockRecorder) ListTeams(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTeams", reflect.TypeOf((*MockOrganizationServiceInterface)(nil).ListTeams), arg0, arg1, arg2)
+}
+
+// Members mocks base method.
+func (m *MockOrganizationServiceInterface) Members(arg0 context.Context, arg1, arg2 string) ([]models.OrganizationMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Members", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]models.OrganizationMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Members indicates an expected call of Members.
+func (mr *MockOrganizationServiceInterfaceMockRecorder) Members(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Members", reflect.TypeOf((*MockOrganizationServiceInterface)(nil).Members), arg0, arg1, arg2)
+}
+
+// Membership mocks base method.
+func (m *MockOrganizationServiceInterface) Membership(arg0 context.Context, arg1, arg2 string) (*models.OrganizationMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Membership", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.OrganizationMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Membership indicates an expected call of Membership.
+func (mr *MockOrganizationServiceInterfaceMockRecorder) Membership(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Membership", reflect.TypeOf((*MockOrganizationServiceInterface)(nil).Membership), arg0, arg1, arg2)
+}
+
+// RemoveMember mocks base method.
+func (m *MockOrganizationServiceInterface) RemoveMember(arg0 context.Context, arg1, arg2, arg3 string, arg4 net.IP) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveMember", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveMember indicates an expected call of RemoveMember.
+func (mr *MockOrganizationServiceInterfaceMockRecorder) RemoveMember(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveMember", reflect.TypeOf((*MockOrganizationServiceInterface)(nil).RemoveMember), arg0, arg1, arg2, arg3, arg4)
+}
+
+// RemoveTeamMember mocks base method.
+func (m *MockOrganizationServiceInterface) RemoveTeamMember(arg0 context.Context, arg1, arg2, arg3, arg4 string, arg5 net.IP) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveTeamMember", arg0, arg1, arg2, arg3, arg4, arg5)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveTeamMember indicates an expected call of RemoveTeamMember.
+func (mr *MockOrganizationServiceInterfaceMockRecorder) RemoveTeamMember(arg0, arg1, arg2, arg3, arg4, arg5 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveTeamMember", reflect.TypeOf((*MockOrganizationServiceInterface)(nil).RemoveTeamMember), arg0, arg1, arg2, arg3, arg4, arg5)
+}
@@ -0,0 +1,84 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/auth-service/internal/services (interfaces: ProfileServiceInterface)
+
+// Package services is a generated GoMock package.
+package services
+
+import (
+	context "context"
+	net "net"
+	reflect "reflect"
+
+	models "github.com/auth-service/internal/models"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockProfileServiceInterface is a mock of ProfileServiceInterface interface.
+type MockProfileServiceInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockProfileServiceInterfaceMockRecorder
+}
+
+// MockProfileServiceInterfaceMockRecorder is the mock recorder for MockProfileServiceInterface.
+type MockProfileServiceInterfaceMockRecorder struct {
+	mock *MockProfileServiceInterface
+}
+
+// NewMockProfileServiceInterface creates a new mock instance.
+func NewMockProfileServiceInterface(ctrl *gomock.Controller) *MockProfileServiceInterface {
+	mock := &MockProfileServiceInterface{ctrl: ctrl}
+	mock.recorder = &MockProfileServiceInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockProfileServiceInterface) EXPECT() *MockProfileServiceInterfaceMockRecorder {
+	return m.recorder
+}
+
+// ConfirmEmailChange mocks base method.
+func (m *MockProfileServiceInterface) ConfirmEmailChange(arg0 context.Context, arg1 string, arg2 net.IP) (*models.EmailChange, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConfirmEmailChange", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.EmailChange)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ConfirmEmailChange indicates an expected call of ConfirmEmailChange.
+func (mr *MockProfileServiceInterfaceMockRecorder) ConfirmEmailChange(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfirmEmailChange", reflect.TypeOf((*MockProfileServiceInterface)(nil).ConfirmEmailChange), arg0, arg1, arg2)
+}
+
+// Get mocks base method.
+func (m *MockProfileServiceInterface) Get(arg0 context.Context, arg1 string) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", arg0, arg1)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockProfileServiceInterfaceMockRecorder) Get(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockProfileServiceInterface)(nil).Get), arg0, arg1)
+}
+
+// Update mocks base method.
+func (m *MockProfileServiceInterface) Update(arg0 context.Context, arg1 string, arg2 ProfileUpdate, arg3 net.IP) (*models.User, *models.EmailChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(*models.EmailChange)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockProfileServiceInterfaceMockRecorder) Update(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockProfileServiceInterface)(nil).Update), arg0, arg1, arg2, arg3)
+}
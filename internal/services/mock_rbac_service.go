@@ -0,0 +1,141 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/auth-service/internal/services (interfaces: RBACServiceInterface)
+
+// Package services is a generated GoMock package.
+package services
+
+import (
+	context "context"
+	net "net"
+	reflect "reflect"
+
+	models "github.com/auth-service/internal/models"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockRBACServiceInterface is a mock of RBACServiceInterface interface.
+type MockRBACServiceInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockRBACServiceInterfaceMockRecorder
+}
+
+// MockRBACServiceInterfaceMockRecorder is the mock recorder for MockRBACServiceInterface.
+type MockRBACServiceInterfaceMockRecorder struct {
+	mock *MockRBACServiceInterface
+}
+
+// NewMockRBACServiceInterface creates a new mock instance.
+func NewMockRBACServiceInterface(ctrl *gomock.Controller) *MockRBACServiceInterface {
+	mock := &MockRBACServiceInterface{ctrl: ctrl}
+	mock.recorder = &MockRBACServiceInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRBACServiceInterface) EXPECT() *MockRBACServiceInterfaceMockRecorder {
+	return m.recorder
+}
+
+// CreateRole mocks base method.
+func (m *MockRBACServiceInterface) CreateRole(arg0 context.Context, arg1, arg2 string, arg3 []string, arg4 string, arg5 net.IP) (*models.Role, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateRole", arg0, arg1, arg2, arg3, arg4, arg5)
+	ret0, _ := ret[0].(*models.Role)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateRole indicates an expected call of CreateRole.
+func (mr *MockRBACServiceInterfaceMockRecorder) CreateRole(arg0, arg1, arg2, arg3, arg4, arg5 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRole", reflect.TypeOf((*MockRBACServiceInterface)(nil).CreateRole), arg0, arg1, arg2, arg3, arg4, arg5)
+}
+
+// DeleteRole mocks base method.
+func (m *MockRBACServiceInterface) DeleteRole(arg0 context.Context, arg1 string, arg2 bool, arg3 string, arg4 net.IP) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteRole", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteRole indicates an expected call of DeleteRole.
+func (mr *MockRBACServiceInterfaceMockRecorder) DeleteRole(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRole", reflect.TypeOf((*MockRBACServiceInterface)(nil).DeleteRole), arg0, arg1, arg2, arg3, arg4)
+}
+
+// GetUserRoles mocks base method.
+func (m *MockRBACServiceInterface) GetUserRoles(arg0 context.Context, arg1 string) ([]models.Role, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserRoles", arg0, arg1)
+	ret0, _ := ret[0].([]models.Role)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserRoles indicates an expected call of GetUserRoles.
+func (mr *MockRBACServiceInterfaceMockRecorder) GetUserRoles(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserRoles", reflect.TypeOf((*MockRBACServiceInterface)(nil).GetUserRoles), arg0, arg1)
+}
+
+// ListPermissions mocks base method.
+func (m *MockRBACServiceInterface) ListPermissions(arg0 context.Context) ([]models.Permission, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPermissions", arg0)
+	ret0, _ := ret[0].([]models.Permission)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPermissions indicates an expected call of ListPermissions.
+func (mr *MockRBACServiceInterfaceMockRecorder) ListPermissions(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPermissions", reflect.TypeOf((*MockRBACServiceInterface)(nil).ListPermissions), arg0)
+}
+
+// ListRoles mocks base method.
+func (m *MockRBACServiceInterface) ListRoles(arg0 context.Context) ([]models.Role, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListRoles", arg0)
+	ret0, _ := ret[0].([]models.Role)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListRoles indicates an expected call of ListRoles.
+func (mr *MockRBACServiceInterfaceMockRecorder) ListRoles(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRoles", reflect.TypeOf((*MockRBACServiceInterface)(nil).ListRoles), arg0)
+}
+
+// SetUserRoles mocks base method.
+func (m *MockRBACServiceInterface) SetUserRoles(arg0 context.Context, arg1 string, arg2 []string, arg3 bool, arg4 string, arg5 net.IP) ([]models.Role, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetUserRoles", arg0, arg1, arg2, arg3, arg4, arg5)
+	ret0, _ := ret[0].([]models.Role)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetUserRoles indicates an expected call of SetUserRoles.
+func (mr *MockRBACServiceInterfaceMockRecorder) SetUserRoles(arg0, arg1, arg2, arg3, arg4, arg5 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetUserRoles", reflect.TypeOf((*MockRBACServiceInterface)(nil).SetUserRoles), arg0, arg1, arg2, arg3, arg4, arg5)
+}
+
+// UpdateRole mocks base method.
+func (m *MockRBACServiceInterface) UpdateRole(arg0 context.Context, arg1, arg2 string, arg3 []string, arg4 bool, arg5 string, arg6 net.IP) (*models.Role, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateRole", arg0, arg1, arg2, arg3, arg4, arg5, arg6)
+	ret0, _ := ret[0].(*models.Role)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateRole indicates an expected call of UpdateRole.
+func (mr *MockRBACServiceInterfaceMockRecorder) UpdateRole(arg0, arg1, arg2, arg3, arg4, arg5, arg6 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRole", reflect.TypeOf((*MockRBACServiceInterface)(nil).UpdateRole), arg0, arg1, arg2, arg3, arg4, arg5, arg6)
+}
@@ -0,0 +1,52 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/auth-service/internal/services (interfaces: RealmAuthenticator)
+
+// Package services is a generated GoMock package.
+package services
+
+import (
+	context "context"
+	net "net"
+	reflect "reflect"
+
+	models "github.com/auth-service/internal/models"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockRealmAuthenticator is a mock of RealmAuthenticator interface.
+type MockRealmAuthenticator struct {
+	ctrl     *gomock.Controller
+	recorder *MockRealmAuthenticatorMockRecorder
+}
+
+// MockRealmAuthenticatorMockRecorder is the mock recorder for MockRealmAuthenticator.
+type MockRealmAuthenticatorMockRecorder struct {
+	mock *MockRealmAuthenticator
+}
+
+// NewMockRealmAuthenticator creates a new mock instance.
+func NewMockRealmAuthenticator(ctrl *gomock.Controller) *MockRealmAuthenticator {
+	mock := &MockRealmAuthenticator{ctrl: ctrl}
+	mock.recorder = &MockRealmAuthenticatorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRealmAuthenticator) EXPECT() *MockRealmAuthenticatorMockRecorder {
+	return m.recorder
+}
+
+// AuthenticateRealm mocks base method.
+func (m *MockRealmAuthenticator) AuthenticateRealm(arg0 context.Context, arg1, arg2, arg3 string, arg4 net.IP) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AuthenticateRealm", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AuthenticateRealm indicates an expected call of AuthenticateRealm.
+func (mr *MockRealmAuthenticatorMockRecorder) AuthenticateRealm(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuthenticateRealm", reflect.TypeOf((*MockRealmAuthenticator)(nil).AuthenticateRealm), arg0, arg1, arg2, arg3, arg4)
+}
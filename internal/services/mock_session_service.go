@@ -0,0 +1,65 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/auth-service/internal/services (interfaces: SessionServiceInterface)
+
+// Package services is a generated GoMock package.
+package services
+
+import (
+	context "context"
+	net "net"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockSessionServiceInterface is a mock of SessionServiceInterface interface.
+type MockSessionServiceInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockSessionServiceInterfaceMockRecorder
+}
+
+// MockSessionServiceInterfaceMockRecorder is the mock recorder for MockSessionServiceInterface.
+type MockSessionServiceInterfaceMockRecorder struct {
+	mock *MockSessionServiceInterface
+}
+
+// NewMockSessionServiceInterface creates a new mock instance.
+func NewMockSessionServiceInterface(ctrl *gomock.Controller) *MockSessionServiceInterface {
+	mock := &MockSessionServiceInterface{ctrl: ctrl}
+	mock.recorder = &MockSessionServiceInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSessionServiceInterface) EXPECT() *MockSessionServiceInterfaceMockRecorder {
+	return m.recorder
+}
+
+// List mocks base method.
+func (m *MockSessionServiceInterface) List(arg0 context.Context, arg1, arg2 string, arg3, arg4 int) (*SessionPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(*SessionPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockSessionServiceInterfaceMockRecorder) List(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockSessionServiceInterface)(nil).List), arg0, arg1, arg2, arg3, arg4)
+}
+
+// Revoke mocks base method.
+func (m *MockSessionServiceInterface) Revoke(arg0 context.Context, arg1, arg2 string, arg3 net.IP) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Revoke", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Revoke indicates an expected call of Revoke.
+func (mr *MockSessionServiceInterfaceMockRecorder) Revoke(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Revoke", reflect.TypeOf((*MockSessionServiceInterface)(nil).Revoke), arg0, arg1, arg2, arg3)
+}
@@ -0,0 +1,50 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/auth-service/internal/services (interfaces: UserInfoServiceInterface)
+
+// Package services is a generated GoMock package.
+package services
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockUserInfoServiceInterface is a mock of UserInfoServiceInterface interface.
+type MockUserInfoServiceInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockUserInfoServiceInterfaceMockRecorder
+}
+
+// MockUserInfoServiceInterfaceMockRecorder is the mock recorder for MockUserInfoServiceInterface.
+type MockUserInfoServiceInterfaceMockRecorder struct {
+	mock *MockUserInfoServiceInterface
+}
+
+// NewMockUserInfoServiceInterface creates a new mock instance.
+func NewMockUserInfoServiceInterface(ctrl *gomock.Controller) *MockUserInfoServiceInterface {
+	mock := &MockUserInfoServiceInterface{ctrl: ctrl}
+	mock.recorder = &MockUserInfoServiceInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockUserInfoServiceInterface) EXPECT() *MockUserInfoServiceInterfaceMockRecorder {
+	return m.recorder
+}
+
+// UserInfo mocks base method.
+func (m *MockUserInfoServiceInterface) UserInfo(arg0 context.Context, arg1, arg2 string) (map[string]interface{}, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UserInfo", arg0, arg1, arg2)
+	ret0, _ := ret[0].(map[string]interface{})
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UserInfo indicates an expected call of UserInfo.
+func (mr *MockUserInfoServiceInterfaceMockRecorder) UserInfo(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserInfo", reflect.TypeOf((*MockUserInfoServiceInterface)(nil).UserInfo), arg0, arg1, arg2)
+}
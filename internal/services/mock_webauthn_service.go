@@ -0,0 +1,100 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/auth-service/internal/services (interfaces: WebAuthnServiceInterface)
+
+// Package services is a generated GoMock package.
+package services
+
+import (
+	context "context"
+	net "net"
+	reflect "reflect"
+
+	models "github.com/auth-service/internal/models"
+	protocol "github.com/go-webauthn/webauthn/protocol"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockWebAuthnServiceInterface is a mock of WebAuthnServiceInterface interface.
+type MockWebAuthnServiceInterface struct {
+	ctrl     *gomock.Controller
+	recorder *MockWebAuthnServiceInterfaceMockRecorder
+}
+
+// MockWebAuthnServiceInterfaceMockRecorder is the mock recorder for MockWebAuthnServiceInterface.
+type MockWebAuthnServiceInterfaceMockRecorder struct {
+	mock *MockWebAuthnServiceInterface
+}
+
+// NewMockWebAuthnServiceInterface creates a new mock instance.
+func NewMockWebAuthnServiceInterface(ctrl *gomock.Controller) *MockWebAuthnServiceInterface {
+	mock := &MockWebAuthnServiceInterface{ctrl: ctrl}
+	mock.recorder = &MockWebAuthnServiceInterfaceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWebAuthnServiceInterface) EXPECT() *MockWebAuthnServiceInterfaceMockRecorder {
+	return m.recorder
+}
+
+// BeginLogin mocks base method.
+func (m *MockWebAuthnServiceInterface) BeginLogin(arg0 context.Context, arg1 string) (*protocol.CredentialAssertion, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BeginLogin", arg0, arg1)
+	ret0, _ := ret[0].(*protocol.CredentialAssertion)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// BeginLogin indicates an expected call of BeginLogin.
+func (mr *MockWebAuthnServiceInterfaceMockRecorder) BeginLogin(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BeginLogin", reflect.TypeOf((*MockWebAuthnServiceInterface)(nil).BeginLogin), arg0, arg1)
+}
+
+// BeginRegistration mocks base method.
+func (m *MockWebAuthnServiceInterface) BeginRegistration(arg0 context.Context, arg1 string) (*protocol.CredentialCreation, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BeginRegistration", arg0, arg1)
+	ret0, _ := ret[0].(*protocol.CredentialCreation)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// BeginRegistration indicates an expected call of BeginRegistration.
+func (mr *MockWebAuthnServiceInterfaceMockRecorder) BeginRegistration(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BeginRegistration", reflect.TypeOf((*MockWebAuthnServiceInterface)(nil).BeginRegistration), arg0, arg1)
+}
+
+// FinishLogin mocks base method.
+func (m *MockWebAuthnServiceInterface) FinishLogin(arg0 context.Context, arg1 string, arg2 []byte, arg3 net.IP) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FinishLogin", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FinishLogin indicates an expected call of FinishLogin.
+func (mr *MockWebAuthnServiceInterfaceMockRecorder) FinishLogin(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FinishLogin", reflect.TypeOf((*MockWebAuthnServiceInterface)(nil).FinishLogin), arg0, arg1, arg2, arg3)
+}
+
+// FinishRegistration mocks base method.
+func (m *MockWebAuthnServiceInterface) FinishRegistration(arg0 context.Context, arg1, arg2 string, arg3 []byte, arg4 net.IP) (*models.WebAuthnCredential, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FinishRegistration", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(*models.WebAuthnCredential)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FinishRegistration indicates an expected call of FinishRegistration.
+func (mr *MockWebAuthnServiceInterfaceMockRecorder) FinishRegistration(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FinishRegistration", reflect.TypeOf((*MockWebAuthnServiceInterface)(nil).FinishRegistration), arg0, arg1, arg2, arg3, arg4)
+}
@@ -1,8 +1,14 @@
 package services
 
 import (
+	"bytes"
+	"log"
+	"os"
 	"testing"
 
+	"github.com/auth-service/internal/models"
+	"github.com/auth-service/internal/repository/mocks"
+	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -11,3 +17,29 @@ func TestEmailNotifier_SendSecurityAlert(t *testing.T) {
 	err := notifier.SendSecurityAlert("user1", "test message")
 	assert.NoError(t, err)
 }
+
+func TestEmailNotifier_SendEmail(t *testing.T) {
+	var logged bytes.Buffer
+	log.SetOutput(&logged)
+	defer log.SetOutput(os.Stderr)
+
+	notifier := NewEmailNotifier()
+	err := notifier.SendEmail("user@example.com", "subject", "code 123456")
+	assert.NoError(t, err)
+	assert.Contains(t, logged.String(), "user@example.com: subject")
+	assert.NotContains(t, logged.String(), "123456")
+}
+
+func TestAlertRecorder_SendSecurityAlert(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	mockNotifier := NewMockNotifier(ctrl)
+	recorder := NewAlertRecorder(mockNotifier, mockRepo)
+
+	mockRepo.EXPECT().SaveSecurityAlert(gomock.Any(), &models.SecurityAlert{UserID: "user1", Message: "test message"}).Return(nil)
+	mockNotifier.EXPECT().SendSecurityAlert("user1", "test message").Return(nil)
+
+	assert.NoError(t, recorder.SendSecurityAlert("user1", "test message"))
+}
@@ -1,6 +1,12 @@
 package services
 
-import "log"
+import (
+	"context"
+	"log"
+
+	"github.com/auth-service/internal/models"
+	"github.com/auth-service/internal/repository"
+)
 
 type EmailNotifier struct{}
 
@@ -12,3 +18,29 @@ func (n *EmailNotifier) SendSecurityAlert(userID, message string) error {
 	log.Printf("Email alert for user %s: %s", userID, message)
 	return nil
 }
+
+// SendEmail logs the recipient and subject only. Bodies carry login links,
+// codes and confirmation tokens, which must not end up in logs.
+func (n *EmailNotifier) SendEmail(to, subject, body string) error {
+	log.Printf("Email to %s: %s", to, subject)
+	return nil
+}
+
+// AlertRecorder keeps a copy of every security alert, so users can export
+// the alerts they were sent.
+type AlertRecorder struct {
+	Notifier
+	repo repository.SecurityAlertRepository
+}
+
+func NewAlertRecorder(notifier Notifier, repo repository.SecurityAlertRepository) *AlertRecorder {
+	return &AlertRecorder{Notifier: notifier, repo: repo}
+}
+
+func (r *AlertRecorder) SendSecurityAlert(userID, message string) error {
+	alert := &models.SecurityAlert{UserID: userID, Message: message}
+	if err := r.repo.SaveSecurityAlert(context.Background(), alert); err != nil {
+		log.Printf("Failed to record security alert for user %s: %v", userID, err)
+	}
+	return r.Notifier.SendSecurityAlert(userID, message)
+}
@@ -0,0 +1,416 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/auth-service/internal/models"
+	"github.com/auth-service/internal/repository"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// Error codes of the authorization and token endpoints, RFC 6749 sections
+// 4.1.2.1 and 5.2.
+const (
+	OAuthInvalidRequest          = "invalid_request"
+	OAuthInvalidClient           = "invalid_client"
+	OAuthInvalidGrant            = "invalid_grant"
+	OAuthUnauthorizedClient      = "unauthorized_client"
+	OAuthUnsupportedGrantType    = "unsupported_grant_type"
+	OAuthUnsupportedResponseType = "unsupported_response_type"
+	OAuthInvalidScope            = "invalid_scope"
+	OAuthAccessDenied            = "access_denied"
+	OAuthServerError             = "server_error"
+
+	// Errors of the device code grant, RFC 8628 section 3.5.
+	OAuthAuthorizationPending = "authorization_pending"
+	OAuthSlowDown             = "slow_down"
+	OAuthExpiredToken         = "expired_token"
+)
+
+const (
+	AuditOAuthAuthorized = "oauth.authorized"
+
+	ScopeOpenID             = "openid"
+	CodeChallengeMethodS256 = "S256"
+
+	authorizationCodeTTL  = 60 * time.Second
+	authorizationCodeSize = 32
+	codeChallengeLength   = 43
+	minCodeVerifierLength = 43
+	maxCodeVerifierLength = 128
+)
+
+// OAuthError is an error that is reported to the client as is.
+type OAuthError struct {
+	Code        string
+	Description string
+}
+
+func (e *OAuthError) Error() string {
+	if e.Description == "" {
+		return e.Code
+	}
+	return e.Code + ": " + e.Description
+}
+
+func oauthError(code, description string) *OAuthError {
+	return &OAuthError{Code: code, Description: description}
+}
+
+type TokenRequest struct {
+	GrantType    string
+	ClientID     string
+	ClientSecret string
+	Scope        string
+	Code         string
+	RedirectURI  string
+	CodeVerifier string
+	DeviceCode   string
+	IP           net.IP
+}
+
+type AuthorizeRequest struct {
+	ResponseType        string
+	ClientID            string
+	RedirectURI         string
+	Scope               string
+	State               string
+	Nonce               string
+	CodeChallenge       string
+	CodeChallengeMethod string
+}
+
+// Authentication records how and when the user logged in on the
+// authorization page.
+type Authentication struct {
+	UserID  string
+	Time    time.Time
+	Methods []string
+}
+
+// Authorization is an authorization request that passed validation and is
+// waiting for the user to log in and consent.
+type Authorization struct {
+	Client        *models.OAuthClient
+	RedirectURI   string
+	Scope         string
+	State         string
+	Nonce         string
+	CodeChallenge string
+}
+
+// RedirectURL returns the redirect URI with params and the state added.
+func (a *Authorization) RedirectURL(params url.Values) string {
+	target, err := url.Parse(a.RedirectURI)
+	if err != nil {
+		return a.RedirectURI
+	}
+
+	query := target.Query()
+	for key, values := range params {
+		query[key] = values
+	}
+	if a.State != "" {
+		query.Set("state", a.State)
+	}
+	target.RawQuery = query.Encode()
+	return target.String()
+}
+
+// ErrorRedirect returns the redirect URI that reports err to the client.
+func (a *Authorization) ErrorRedirect(err *OAuthError) string {
+	params := url.Values{"error": {err.Code}}
+	if err.Description != "" {
+		params.Set("error_description", err.Description)
+	}
+	return a.RedirectURL(params)
+}
+
+type oauthRepository interface {
+	repository.AuthorizationCodeRepository
+	repository.DeviceCodeRepository
+}
+
+type OAuthService struct {
+	clients      *ClientService
+	tokenService *TokenService
+	authService  *AuthService
+	codes        oauthRepository
+	idTokens     *IDTokenService
+	audit        *AuditLogger
+	publicURL    string
+}
+
+func NewOAuthService(
+	clients *ClientService,
+	tokenService *TokenService,
+	authService *AuthService,
+	codes oauthRepository,
+	idTokens *IDTokenService,
+	audit *AuditLogger,
+	publicURL string,
+) *OAuthService {
+	return &OAuthService{
+		clients:      clients,
+		tokenService: tokenService,
+		authService:  authService,
+		codes:        codes,
+		idTokens:     idTokens,
+		audit:        audit,
+		publicURL:    strings.TrimRight(publicURL, "/"),
+	}
+}
+
+// ValidateAuthorization checks an authorization request against the client
+// registry. Errors found before the redirect URI is trusted come with a nil
+// Authorization and have to be shown to the user; later ones come with the
+// Authorization, so that they can be sent to the client via ErrorRedirect.
+func (s *OAuthService) ValidateAuthorization(ctx context.Context, req AuthorizeRequest) (*Authorization, error) {
+	client, err := s.clients.GetClient(ctx, req.ClientID)
+	if err != nil {
+		if errors.Is(err, ErrClientNotFound) {
+			return nil, oauthError(OAuthInvalidRequest, "unknown client")
+		}
+		return nil, err
+	}
+
+	if req.RedirectURI == "" {
+		return nil, oauthError(OAuthInvalidRequest, "redirect_uri is required")
+	}
+	if !hasString(client.RedirectURIs, req.RedirectURI) {
+		return nil, oauthError(OAuthInvalidRequest, "redirect_uri is not registered for this client")
+	}
+
+	auth := &Authorization{
+		Client:      client,
+		RedirectURI: req.RedirectURI,
+		State:       req.State,
+	}
+
+	if req.ResponseType != "code" {
+		return auth, oauthError(OAuthUnsupportedResponseType, "only the code response type is supported")
+	}
+	if !hasString(client.GrantTypes, GrantAuthorizationCode) {
+		return auth, oauthError(OAuthUnauthorizedClient, "grant type is not allowed for this client")
+	}
+	if req.CodeChallengeMethod != CodeChallengeMethodS256 {
+		return auth, oauthError(OAuthInvalidRequest, "code_challenge_method must be S256")
+	}
+	if len(req.CodeChallenge) != codeChallengeLength {
+		return auth, oauthError(OAuthInvalidRequest, "code_challenge is invalid")
+	}
+
+	scope, err := resolveScope(client, req.Scope)
+	if err != nil {
+		return auth, err
+	}
+
+	auth.Scope = scope
+	auth.Nonce = req.Nonce
+	auth.CodeChallenge = req.CodeChallenge
+	return auth, nil
+}
+
+// IssueCode stores a single-use authorization code for the authenticated user
+// and returns the redirect URI that delivers it.
+func (s *OAuthService) IssueCode(ctx context.Context, auth *Authorization, authn Authentication, ip net.IP) (string, error) {
+	code, err := generateSecureToken(authorizationCodeSize)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate authorization code: %w", err)
+	}
+	code = strings.TrimRight(code, "=")
+
+	err = s.codes.SaveAuthorizationCode(ctx, &models.AuthorizationCode{
+		TenantID:      TenantFromContext(ctx),
+		CodeHash:      hashAuthorizationCode(code),
+		ClientID:      auth.Client.ClientID,
+		UserID:        authn.UserID,
+		RedirectURI:   auth.RedirectURI,
+		Scope:         auth.Scope,
+		Nonce:         auth.Nonce,
+		CodeChallenge: auth.CodeChallenge,
+		AMR:           authn.Methods,
+		AuthTime:      authn.Time,
+		ExpiresAt:     time.Now().Add(authorizationCodeTTL),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to save authorization code: %w", err)
+	}
+
+	s.audit.Record(ctx, authn.UserID, AuditOAuthAuthorized, ip, map[string]string{
+		"client_id": auth.Client.ClientID,
+		"scope":     auth.Scope,
+	})
+	return auth.RedirectURL(url.Values{"code": {code}}), nil
+}
+
+// Token serves a token endpoint request. Errors meant for the client are
+// returned as *OAuthError.
+func (s *OAuthService) Token(ctx context.Context, req TokenRequest) (*models.TokenPair, error) {
+	if req.GrantType == "" {
+		return nil, oauthError(OAuthInvalidRequest, "grant_type is required")
+	}
+
+	client, err := s.authenticateClient(ctx, req.ClientID, req.ClientSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	switch req.GrantType {
+	case GrantClientCredentials, GrantAuthorizationCode, GrantDeviceCode:
+	default:
+		return nil, oauthError(OAuthUnsupportedGrantType, "")
+	}
+	if !hasString(client.GrantTypes, req.GrantType) {
+		return nil, oauthError(OAuthUnauthorizedClient, "grant type is not allowed for this client")
+	}
+
+	switch req.GrantType {
+	case GrantAuthorizationCode:
+		return s.authorizationCode(ctx, client, req)
+	case GrantDeviceCode:
+		return s.deviceCode(ctx, client, req)
+	}
+	return s.clientCredentials(client, req)
+}
+
+func (s *OAuthService) authenticateClient(ctx context.Context, clientID, secret string) (*models.OAuthClient, error) {
+	client, err := s.clients.Authenticate(ctx, clientID, secret)
+	if err != nil {
+		if errors.Is(err, ErrInvalidClient) {
+			return nil, oauthError(OAuthInvalidClient, "client authentication failed")
+		}
+		return nil, err
+	}
+	return client, nil
+}
+
+func (s *OAuthService) clientCredentials(client *models.OAuthClient, req TokenRequest) (*models.TokenPair, error) {
+	scope, err := resolveScope(client, req.Scope)
+	if err != nil {
+		return nil, err
+	}
+
+	ttl := accessTokenTTL(client)
+	accessToken, err := s.tokenService.SignAccessToken(TokenClaims{
+		IP:       req.IP.String(),
+		ClientID: client.ClientID,
+		Scope:    scope,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject: client.ClientID,
+		},
+	}, ttl)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.TokenPair{
+		AccessToken: accessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   int(ttl.Seconds()),
+		Scope:       scope,
+	}, nil
+}
+
+func (s *OAuthService) authorizationCode(ctx context.Context, client *models.OAuthClient, req TokenRequest) (*models.TokenPair, error) {
+	if req.Code == "" || req.RedirectURI == "" || req.CodeVerifier == "" {
+		return nil, oauthError(OAuthInvalidRequest, "code, redirect_uri and code_verifier are required")
+	}
+
+	code, err := s.codes.TakeAuthorizationCode(ctx, hashAuthorizationCode(req.Code))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, oauthError(OAuthInvalidGrant, "authorization code is invalid")
+		}
+		return nil, fmt.Errorf("failed to get authorization code: %w", err)
+	}
+
+	if code.TenantID != TenantFromContext(ctx) {
+		return nil, oauthError(OAuthInvalidGrant, "authorization code is invalid")
+	}
+	if time.Now().After(code.ExpiresAt) {
+		return nil, oauthError(OAuthInvalidGrant, "authorization code has expired")
+	}
+	if code.ClientID != client.ClientID || code.RedirectURI != req.RedirectURI {
+		return nil, oauthError(OAuthInvalidGrant, "authorization code was issued to another client or redirect_uri")
+	}
+	if !verifyCodeChallenge(req.CodeVerifier, code.CodeChallenge) {
+		return nil, oauthError(OAuthInvalidGrant, "code_verifier does not match code_challenge")
+	}
+
+	ttl := accessTokenTTL(client)
+	tokens, err := s.authService.IssueTokens(ctx, TokenGrant{
+		UserID:          code.UserID,
+		ClientID:        client.ClientID,
+		Scope:           code.Scope,
+		IP:              req.IP,
+		AccessTokenTTL:  ttl,
+		RefreshTokenTTL: client.RefreshTokenTTL,
+		MaxSessions:     client.MaxSessions,
+		IdleTimeout:     client.IdleTimeout,
+		IPChangePolicy:  IPChangeMode(client.IPChangePolicy),
+	})
+	if err != nil {
+		return nil, sessionLimitError(err)
+	}
+	tokens.TokenType = "Bearer"
+	tokens.ExpiresIn = int(ttl.Seconds())
+	tokens.Scope = code.Scope
+
+	if hasString(strings.Fields(code.Scope), ScopeOpenID) {
+		tokens.IDToken, err = s.idTokens.Sign(code.UserID, client.ClientID, code.Nonce, code.AuthTime, code.AMR)
+		if err != nil {
+			return nil, fmt.Errorf("failed to sign id token: %w", err)
+		}
+	}
+	return tokens, nil
+}
+
+func accessTokenTTL(client *models.OAuthClient) time.Duration {
+	if client.AccessTokenTTL == 0 {
+		return DefaultAccessTokenTTL
+	}
+	return client.AccessTokenTTL
+}
+
+// resolveScope checks the requested scope against the scopes registered for
+// the client. An empty request grants all of them.
+func resolveScope(client *models.OAuthClient, requested string) (string, error) {
+	scopes := strings.Fields(requested)
+	if len(scopes) == 0 {
+		return strings.Join(client.Scopes, " "), nil
+	}
+
+	for _, scope := range scopes {
+		if !hasString(client.Scopes, scope) {
+			return "", oauthError(OAuthInvalidScope, "scope "+scope+" is not allowed for this client")
+		}
+	}
+	return strings.Join(scopes, " "), nil
+}
+
+// verifyCodeChallenge checks a PKCE verifier against an S256 challenge,
+// RFC 7636 section 4.6.
+func verifyCodeChallenge(verifier, challenge string) bool {
+	if len(verifier) < minCodeVerifierLength || len(verifier) > maxCodeVerifierLength {
+		return false
+	}
+	sum := sha256.Sum256([]byte(verifier))
+	expected := base64.RawURLEncoding.EncodeToString(sum[:])
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(challenge)) == 1
+}
+
+func hashAuthorizationCode(code string) string {
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
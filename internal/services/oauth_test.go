@@ -0,0 +1,537 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"net"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/auth-service/internal/models"
+	"github.com/auth-service/internal/repository"
+	"github.com/auth-service/internal/repository/mocks"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestClientService(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	clientSvc := NewClientService(mockRepo, NewAuditLogger(mockRepo))
+	ctx := context.Background()
+	adminIP := net.ParseIP("192.168.1.1")
+
+	mockRepo.EXPECT().SaveAuditEvent(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	t.Run("CreateClient", func(t *testing.T) {
+		var stored *models.OAuthClient
+		mockRepo.EXPECT().
+			CreateClient(ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, client *models.OAuthClient) error {
+				stored = client
+				return nil
+			})
+
+		client, secret, err := clientSvc.CreateClient(ctx, ClientRegistration{
+			Name:           "billing",
+			GrantTypes:     []string{GrantClientCredentials},
+			Scopes:         []string{"invoices:read"},
+			AccessTokenTTL: 5 * time.Minute,
+		}, "admin1", adminIP)
+		require.NoError(t, err)
+
+		assert.NotEmpty(t, client.ClientID)
+		assert.NotContains(t, secret, "=")
+		assert.Equal(t, 5*time.Minute, stored.AccessTokenTTL)
+		assert.NotNil(t, stored.RedirectURIs)
+		assert.NotEqual(t, secret, stored.SecretHash)
+		assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(stored.SecretHash), []byte(secret)))
+	})
+
+	t.Run("CreateClient rejects unknown grant type", func(t *testing.T) {
+		_, _, err := clientSvc.CreateClient(ctx, ClientRegistration{
+			Name:       "legacy",
+			GrantTypes: []string{"password"},
+		}, "admin1", adminIP)
+		assert.ErrorIs(t, err, ErrInvalidRegistration)
+
+		_, _, err = clientSvc.CreateClient(ctx, ClientRegistration{
+			Name:       "legacy",
+			GrantTypes: []string{GrantClientCredentials, GrantRefreshToken},
+		}, "admin1", adminIP)
+		assert.ErrorIs(t, err, ErrInvalidRegistration)
+	})
+
+	t.Run("CreateClient public", func(t *testing.T) {
+		mockRepo.EXPECT().
+			CreateClient(ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, client *models.OAuthClient) error {
+				assert.NotNil(t, client.Scopes)
+				return nil
+			})
+
+		client, secret, err := clientSvc.CreateClient(ctx, ClientRegistration{
+			Name:         "mobile",
+			Public:       true,
+			GrantTypes:   []string{GrantAuthorizationCode},
+			RedirectURIs: []string{"com.example.app:/callback"},
+		}, "admin1", adminIP)
+		require.NoError(t, err)
+		assert.Empty(t, secret)
+		assert.Empty(t, client.SecretHash)
+
+		_, _, err = clientSvc.CreateClient(ctx, ClientRegistration{
+			Name:       "mobile",
+			Public:     true,
+			GrantTypes: []string{GrantClientCredentials},
+		}, "admin1", adminIP)
+		assert.ErrorIs(t, err, ErrInvalidRegistration)
+	})
+
+	t.Run("CreateClient rejects unknown IP change policy", func(t *testing.T) {
+		_, _, err := clientSvc.CreateClient(ctx, ClientRegistration{
+			Name:           "console",
+			GrantTypes:     []string{GrantClientCredentials},
+			IPChangePolicy: "block",
+		}, "admin1", adminIP)
+		assert.ErrorIs(t, err, ErrInvalidRegistration)
+	})
+
+	t.Run("RotateSecret", func(t *testing.T) {
+		var newHash string
+		mockRepo.EXPECT().
+			UpdateClientSecret(ctx, "client1", gomock.Any()).
+			DoAndReturn(func(_ context.Context, _, secretHash string) error {
+				newHash = secretHash
+				return nil
+			})
+
+		secret, err := clientSvc.RotateSecret(ctx, "client1", "admin1", adminIP)
+		require.NoError(t, err)
+		assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(newHash), []byte(secret)))
+	})
+
+	t.Run("RotateSecret unknown client", func(t *testing.T) {
+		mockRepo.EXPECT().UpdateClientSecret(ctx, "missing", gomock.Any()).Return(repository.ErrNotFound)
+
+		_, err := clientSvc.RotateSecret(ctx, "missing", "admin1", adminIP)
+		assert.ErrorIs(t, err, ErrClientNotFound)
+	})
+
+	t.Run("Authenticate disabled client", func(t *testing.T) {
+		hash, _ := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+		disabledAt := time.Now()
+		mockRepo.EXPECT().GetClient(ctx, "client1").Return(&models.OAuthClient{
+			ClientID:   "client1",
+			SecretHash: string(hash),
+			DisabledAt: &disabledAt,
+		}, nil)
+
+		_, err := clientSvc.Authenticate(ctx, "client1", "secret")
+		assert.ErrorIs(t, err, ErrInvalidClient)
+	})
+}
+
+func TestOAuthService(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	mockNotifier := NewMockNotifier(ctrl)
+	tokenSvc := NewTokenService("test_secret")
+	audit := NewAuditLogger(mockRepo)
+	signingKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	idTokenSvc, err := NewIDTokenService(signingKey, "https://auth.example")
+	require.NoError(t, err)
+	oauthSvc := NewOAuthService(
+		NewClientService(mockRepo, audit),
+		tokenSvc,
+		NewAuthService(mockRepo, tokenSvc, mockNotifier),
+		mockRepo,
+		idTokenSvc,
+		audit,
+		"https://auth.example",
+	)
+	ctx := context.Background()
+	userIP := net.ParseIP("10.0.0.1")
+
+	mockRepo.EXPECT().SaveAuditEvent(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	require.NoError(t, err)
+	client := &models.OAuthClient{
+		ClientID:       "client1",
+		SecretHash:     string(hash),
+		GrantTypes:     []string{GrantClientCredentials},
+		Scopes:         []string{"invoices:read", "invoices:write"},
+		AccessTokenTTL: 5 * time.Minute,
+	}
+	mockRepo.EXPECT().GetClient(ctx, "client1").Return(client, nil).AnyTimes()
+
+	request := func(scope string) TokenRequest {
+		return TokenRequest{
+			GrantType:    GrantClientCredentials,
+			ClientID:     "client1",
+			ClientSecret: "secret",
+			Scope:        scope,
+			IP:           userIP,
+		}
+	}
+
+	assertOAuthError := func(t *testing.T, err error, code string) {
+		var oauthErr *OAuthError
+		require.ErrorAs(t, err, &oauthErr)
+		assert.Equal(t, code, oauthErr.Code)
+	}
+
+	t.Run("Client credentials", func(t *testing.T) {
+		tokens, err := oauthSvc.Token(ctx, request("invoices:read"))
+		require.NoError(t, err)
+
+		assert.Equal(t, "Bearer", tokens.TokenType)
+		assert.Equal(t, 300, tokens.ExpiresIn)
+		assert.Equal(t, "invoices:read", tokens.Scope)
+		assert.Empty(t, tokens.RefreshToken)
+
+		claims, err := tokenSvc.ParseAccessToken(tokens.AccessToken)
+		require.NoError(t, err)
+		assert.Equal(t, "client1", claims.ClientID)
+		assert.Equal(t, "client1", claims.Subject)
+		assert.Empty(t, claims.UserID)
+		assert.Equal(t, "invoices:read", claims.Scope)
+	})
+
+	t.Run("Empty scope grants registered scopes", func(t *testing.T) {
+		tokens, err := oauthSvc.Token(ctx, request(""))
+		require.NoError(t, err)
+		assert.Equal(t, "invoices:read invoices:write", tokens.Scope)
+	})
+
+	t.Run("Scope outside registration", func(t *testing.T) {
+		_, err := oauthSvc.Token(ctx, request("invoices:read admin"))
+		assertOAuthError(t, err, OAuthInvalidScope)
+	})
+
+	t.Run("Wrong secret", func(t *testing.T) {
+		req := request("")
+		req.ClientSecret = "wrong"
+		_, err := oauthSvc.Token(ctx, req)
+		assertOAuthError(t, err, OAuthInvalidClient)
+	})
+
+	t.Run("Unsupported grant type", func(t *testing.T) {
+		req := request("")
+		req.GrantType = GrantRefreshToken
+		_, err := oauthSvc.Token(ctx, req)
+		assertOAuthError(t, err, OAuthUnsupportedGrantType)
+	})
+
+	t.Run("Missing grant type", func(t *testing.T) {
+		req := request("")
+		req.GrantType = ""
+		_, err := oauthSvc.Token(ctx, req)
+		assertOAuthError(t, err, OAuthInvalidRequest)
+	})
+
+	t.Run("Authorization code", func(t *testing.T) {
+		spa := &models.OAuthClient{
+			ClientID:       "spa",
+			Name:           "Web app",
+			Public:         true,
+			GrantTypes:     []string{GrantAuthorizationCode},
+			RedirectURIs:   []string{"https://app.example/callback"},
+			Scopes:         []string{ScopeOpenID, "profile"},
+			AccessTokenTTL: 10 * time.Minute,
+		}
+		mockRepo.EXPECT().GetClient(gomock.Any(), "spa").Return(spa, nil).AnyTimes()
+
+		codes := make(map[string]*models.AuthorizationCode)
+		mockRepo.EXPECT().
+			SaveAuthorizationCode(ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, code *models.AuthorizationCode) error {
+				codes[code.CodeHash] = code
+				return nil
+			}).AnyTimes()
+		mockRepo.EXPECT().
+			TakeAuthorizationCode(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, codeHash string) (*models.AuthorizationCode, error) {
+				code, ok := codes[codeHash]
+				if !ok {
+					return nil, repository.ErrNotFound
+				}
+				delete(codes, codeHash)
+				return code, nil
+			}).AnyTimes()
+
+		verifier := "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+		sum := sha256.Sum256([]byte(verifier))
+		challenge := base64.RawURLEncoding.EncodeToString(sum[:])
+
+		authorizeRequest := AuthorizeRequest{
+			ResponseType:        "code",
+			ClientID:            "spa",
+			RedirectURI:         "https://app.example/callback",
+			Scope:               "openid",
+			State:               "xyz",
+			Nonce:               "n-0S6_WzA2Mj",
+			CodeChallenge:       challenge,
+			CodeChallengeMethod: CodeChallengeMethodS256,
+		}
+
+		authorize := func(t *testing.T) string {
+			auth, err := oauthSvc.ValidateAuthorization(ctx, authorizeRequest)
+			require.NoError(t, err)
+
+			redirect, err := oauthSvc.IssueCode(ctx, auth, Authentication{
+				UserID:  "user1",
+				Time:    time.Now(),
+				Methods: []string{AMRPassword},
+			}, userIP)
+			require.NoError(t, err)
+
+			parsed, err := url.Parse(redirect)
+			require.NoError(t, err)
+			assert.Equal(t, "app.example", parsed.Host)
+			assert.Equal(t, "xyz", parsed.Query().Get("state"))
+			return parsed.Query().Get("code")
+		}
+
+		exchange := func(code, verifier string) (*models.TokenPair, error) {
+			return oauthSvc.Token(ctx, TokenRequest{
+				GrantType:    GrantAuthorizationCode,
+				ClientID:     "spa",
+				Code:         code,
+				RedirectURI:  "https://app.example/callback",
+				CodeVerifier: verifier,
+				IP:           userIP,
+			})
+		}
+
+		t.Run("Exchange", func(t *testing.T) {
+			code := authorize(t)
+			for hash := range codes {
+				assert.NotEqual(t, code, hash)
+			}
+
+			mockRepo.EXPECT().SaveRefreshToken(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, token *models.RefreshToken) error {
+					assert.Equal(t, "spa", token.ClientID)
+					assert.Equal(t, "openid", token.Scope)
+					return nil
+				})
+
+			tokens, err := exchange(code, verifier)
+			require.NoError(t, err)
+			assert.NotEmpty(t, tokens.RefreshToken)
+			assert.Equal(t, 600, tokens.ExpiresIn)
+
+			claims, err := tokenSvc.ParseAccessToken(tokens.AccessToken)
+			require.NoError(t, err)
+			assert.Equal(t, "user1", claims.UserID)
+			assert.Equal(t, "spa", claims.ClientID)
+
+			var idClaims IDTokenClaims
+			_, err = jwt.ParseWithClaims(tokens.IDToken, &idClaims, func(token *jwt.Token) (interface{}, error) {
+				assert.Equal(t, "RS256", token.Method.Alg())
+				return &signingKey.PublicKey, nil
+			})
+			require.NoError(t, err)
+			assert.Equal(t, "https://auth.example", idClaims.Issuer)
+			assert.Equal(t, "user1", idClaims.Subject)
+			assert.Equal(t, jwt.ClaimStrings{"spa"}, idClaims.Audience)
+			assert.Equal(t, "n-0S6_WzA2Mj", idClaims.Nonce)
+			assert.Equal(t, ACRSingleFactor, idClaims.ACR)
+			assert.Equal(t, []string{AMRPassword}, idClaims.AMR)
+		})
+
+		t.Run("Code is single use", func(t *testing.T) {
+			code := authorize(t)
+			mockRepo.EXPECT().SaveRefreshToken(gomock.Any(), gomock.Any()).Return(nil)
+
+			_, err := exchange(code, verifier)
+			require.NoError(t, err)
+			_, err = exchange(code, verifier)
+			assertOAuthError(t, err, OAuthInvalidGrant)
+		})
+
+		t.Run("Wrong code verifier", func(t *testing.T) {
+			code := authorize(t)
+
+			_, err := exchange(code, "wrong-verifier-wrong-verifier-wrong-verifier")
+			assertOAuthError(t, err, OAuthInvalidGrant)
+		})
+
+		t.Run("Expired code", func(t *testing.T) {
+			code := authorize(t)
+			codes[hashAuthorizationCode(code)].ExpiresAt = time.Now().Add(-time.Second)
+
+			_, err := exchange(code, verifier)
+			assertOAuthError(t, err, OAuthInvalidGrant)
+		})
+
+		t.Run("Code of another tenant", func(t *testing.T) {
+			code := authorize(t)
+			assert.Equal(t, DefaultTenant, codes[hashAuthorizationCode(code)].TenantID)
+
+			_, err := oauthSvc.Token(WithTenant(ctx, "acme"), TokenRequest{
+				GrantType:    GrantAuthorizationCode,
+				ClientID:     "spa",
+				Code:         code,
+				RedirectURI:  "https://app.example/callback",
+				CodeVerifier: verifier,
+				IP:           userIP,
+			})
+			assertOAuthError(t, err, OAuthInvalidGrant)
+		})
+
+		t.Run("Code of another client", func(t *testing.T) {
+			code := authorize(t)
+
+			_, err := oauthSvc.Token(ctx, TokenRequest{
+				GrantType:    GrantAuthorizationCode,
+				ClientID:     "client1",
+				ClientSecret: "secret",
+				Code:         code,
+				RedirectURI:  "https://app.example/callback",
+				CodeVerifier: verifier,
+				IP:           userIP,
+			})
+			assertOAuthError(t, err, OAuthUnauthorizedClient)
+		})
+
+		t.Run("Public client must not send a secret", func(t *testing.T) {
+			_, err := oauthSvc.Token(ctx, TokenRequest{
+				GrantType:    GrantAuthorizationCode,
+				ClientID:     "spa",
+				ClientSecret: "guess",
+			})
+			assertOAuthError(t, err, OAuthInvalidClient)
+		})
+
+		t.Run("Unregistered redirect URI", func(t *testing.T) {
+			req := authorizeRequest
+			req.RedirectURI = "https://evil.example/callback"
+
+			auth, err := oauthSvc.ValidateAuthorization(ctx, req)
+			assert.Nil(t, auth)
+			assertOAuthError(t, err, OAuthInvalidRequest)
+		})
+
+		t.Run("PKCE is required", func(t *testing.T) {
+			req := authorizeRequest
+			req.CodeChallengeMethod = "plain"
+
+			auth, err := oauthSvc.ValidateAuthorization(ctx, req)
+			require.NotNil(t, auth)
+			assertOAuthError(t, err, OAuthInvalidRequest)
+
+			redirect, err := url.Parse(auth.ErrorRedirect(&OAuthError{Code: OAuthInvalidRequest}))
+			require.NoError(t, err)
+			assert.Equal(t, "invalid_request", redirect.Query().Get("error"))
+			assert.Equal(t, "xyz", redirect.Query().Get("state"))
+		})
+	})
+
+	t.Run("Device code", func(t *testing.T) {
+		cli := &models.OAuthClient{
+			ClientID:   "cli",
+			Name:       "CLI",
+			Public:     true,
+			GrantTypes: []string{GrantDeviceCode},
+			Scopes:     []string{"invoices:read"},
+		}
+		mockRepo.EXPECT().GetClient(gomock.Any(), "cli").Return(cli, nil).AnyTimes()
+
+		var stored *models.DeviceCode
+		mockRepo.EXPECT().SaveDeviceCode(ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, code *models.DeviceCode) error {
+				code.ID = "device1"
+				code.Status = models.DeviceCodePending
+				stored = code
+				return nil
+			})
+		mockRepo.EXPECT().GetDeviceCodeByUserCode(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, userCode string) (*models.DeviceCode, error) {
+				if stored == nil || userCode != stored.UserCode {
+					return nil, repository.ErrNotFound
+				}
+				copied := *stored
+				return &copied, nil
+			}).AnyTimes()
+		mockRepo.EXPECT().PollDeviceCode(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, hash string, now time.Time) (*models.DeviceCode, error) {
+				if stored == nil || hash != stored.DeviceCodeHash {
+					return nil, repository.ErrNotFound
+				}
+				previous := *stored
+				stored.LastPolledAt = &now
+				return &previous, nil
+			}).AnyTimes()
+
+		auth, err := oauthSvc.DeviceAuthorization(ctx, "cli", "", "")
+		require.NoError(t, err)
+		assert.Equal(t, "https://auth.example/oauth/device", auth.VerificationURI)
+		assert.Regexp(t, `^[B-Z]{4}-[B-Z]{4}$`, auth.UserCode)
+		assert.Equal(t, "invoices:read", stored.Scope)
+		assert.Equal(t, DefaultTenant, stored.TenantID)
+
+		poll := func() (*models.TokenPair, error) {
+			return oauthSvc.Token(ctx, TokenRequest{GrantType: GrantDeviceCode, ClientID: "cli", DeviceCode: auth.DeviceCode, IP: userIP})
+		}
+
+		_, err = poll()
+		assertOAuthError(t, err, OAuthAuthorizationPending)
+		_, err = poll()
+		assertOAuthError(t, err, OAuthSlowDown)
+
+		_, err = oauthSvc.LookupDeviceCode(WithTenant(ctx, "acme"), auth.UserCode)
+		assert.ErrorIs(t, err, ErrInvalidUserCode)
+
+		verification, err := oauthSvc.LookupDeviceCode(ctx, strings.ToLower(auth.UserCode))
+		require.NoError(t, err)
+		assert.Equal(t, "CLI", verification.Client.Name)
+
+		mockRepo.EXPECT().DecideDeviceCode(ctx, "device1", "user1", models.DeviceCodeApproved).
+			DoAndReturn(func(_ context.Context, _, userID, status string) error {
+				stored.UserID, stored.Status = userID, status
+				return nil
+			})
+		require.NoError(t, oauthSvc.DecideDevice(ctx, auth.UserCode, "user1", true, userIP))
+
+		_, err = oauthSvc.LookupDeviceCode(ctx, auth.UserCode)
+		assert.ErrorIs(t, err, ErrInvalidUserCode)
+
+		_, err = oauthSvc.Token(WithTenant(ctx, "acme"), TokenRequest{GrantType: GrantDeviceCode, ClientID: "cli", DeviceCode: auth.DeviceCode, IP: userIP})
+		assertOAuthError(t, err, OAuthInvalidGrant)
+
+		past := time.Now().Add(-time.Minute)
+		stored.LastPolledAt = &past
+		mockRepo.EXPECT().DeleteDeviceCode(ctx, "device1").Return(nil)
+		mockRepo.EXPECT().SaveRefreshToken(ctx, gomock.Any()).Return(nil)
+
+		tokens, err := poll()
+		require.NoError(t, err)
+		assert.Equal(t, "Bearer", tokens.TokenType)
+		claims, err := tokenSvc.ParseAccessToken(tokens.AccessToken)
+		require.NoError(t, err)
+		assert.Equal(t, "user1", claims.UserID)
+		assert.Equal(t, "cli", claims.ClientID)
+
+		t.Run("Expired", func(t *testing.T) {
+			stored.ExpiresAt = time.Now().Add(-time.Second)
+			_, err := poll()
+			assertOAuthError(t, err, OAuthExpiredToken)
+		})
+	})
+}
@@ -0,0 +1,418 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/auth-service/internal/models"
+	"github.com/auth-service/internal/repository"
+)
+
+const (
+	OrgRoleOwner  = "owner"
+	OrgRoleAdmin  = "admin"
+	OrgRoleMember = "member"
+
+	AuditOrganizationCreated = "organization.created"
+	AuditOrgMemberInvited    = "organization.member_invited"
+	AuditOrgMemberJoined     = "organization.member_joined"
+	AuditOrgMemberRemoved    = "organization.member_removed"
+	AuditOrgMemberRole       = "organization.member_role_changed"
+	AuditTeamCreated         = "team.created"
+	AuditTeamMemberAdded     = "team.member_added"
+	AuditTeamMemberRemoved   = "team.member_removed"
+
+	invitationTTL = 7 * 24 * time.Hour
+)
+
+var (
+	ErrNotOrgMember        = errors.New("not a member of the organization")
+	ErrOrgForbidden        = errors.New("not allowed in the organization")
+	ErrInvalidOrganization = errors.New("invalid organization request")
+	ErrLastOwner           = errors.New("organization must keep an owner")
+	ErrAlreadyMember       = errors.New("already a member of the organization")
+	ErrInvalidInvitation   = errors.New("invalid or expired invitation")
+	ErrTeamNotFound        = errors.New("team not found")
+	ErrTeamExists          = errors.New("team already exists")
+)
+
+type organizationRepository interface {
+	repository.UserRepository
+	repository.OrganizationRepository
+}
+
+// OrganizationService manages the organizations of the tenant of the
+// request. Owners and admins manage members and teams, only owners grant
+// or take away the owner role.
+type OrganizationService struct {
+	repo     organizationRepository
+	notifier Notifier
+	audit    *AuditLogger
+}
+
+func NewOrganizationService(repo organizationRepository, notifier Notifier, audit *AuditLogger) *OrganizationService {
+	return &OrganizationService{
+		repo:     repo,
+		notifier: notifier,
+		audit:    audit,
+	}
+}
+
+// Create creates an organization owned by the user.
+func (s *OrganizationService) Create(ctx context.Context, userID, name string, ip net.IP) (*models.Organization, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > 255 {
+		return nil, fmt.Errorf("%w: name must be 1 to 255 characters", ErrInvalidOrganization)
+	}
+
+	org := &models.Organization{TenantID: TenantFromContext(ctx), Name: name, Role: OrgRoleOwner}
+	owner := &models.OrganizationMember{UserID: userID, Role: OrgRoleOwner}
+	if err := s.repo.CreateOrganization(ctx, org, owner); err != nil {
+		return nil, fmt.Errorf("failed to create organization: %w", err)
+	}
+
+	s.audit.Record(ctx, userID, AuditOrganizationCreated, ip, map[string]string{"org_id": org.ID})
+	return org, nil
+}
+
+func (s *OrganizationService) ListForUser(ctx context.Context, userID string) ([]models.Organization, error) {
+	orgs, err := s.repo.ListUserOrganizations(ctx, TenantFromContext(ctx), userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list organizations: %w", err)
+	}
+	if orgs == nil {
+		orgs = []models.Organization{}
+	}
+	return orgs, nil
+}
+
+// Membership returns the membership of the user in an organization of the
+// tenant of the request, or ErrNotOrgMember.
+func (s *OrganizationService) Membership(ctx context.Context, orgID, userID string) (*models.OrganizationMember, error) {
+	if _, err := s.repo.GetOrganization(ctx, TenantFromContext(ctx), orgID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrNotOrgMember
+		}
+		return nil, fmt.Errorf("failed to get organization: %w", err)
+	}
+
+	member, err := s.repo.GetOrganizationMember(ctx, orgID, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrNotOrgMember
+		}
+		return nil, fmt.Errorf("failed to get organization member: %w", err)
+	}
+	return member, nil
+}
+
+// Members lists the members of an organization to one of them.
+func (s *OrganizationService) Members(ctx context.Context, orgID, userID string) ([]models.OrganizationMember, error) {
+	if _, err := s.Membership(ctx, orgID, userID); err != nil {
+		return nil, err
+	}
+
+	members, err := s.repo.ListOrganizationMembers(ctx, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list organization members: %w", err)
+	}
+	if members == nil {
+		members = []models.OrganizationMember{}
+	}
+	return members, nil
+}
+
+// Invite emails an invitation to join the organization with the role. The
+// invitation is accepted by the account with the same email.
+func (s *OrganizationService) Invite(ctx context.Context, orgID, userID, email, role string, ip net.IP) (*models.OrganizationInvitation, error) {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if email == "" || !strings.Contains(email, "@") {
+		return nil, fmt.Errorf("%w: invalid email", ErrInvalidOrganization)
+	}
+	if err := validateOrgRole(role); err != nil {
+		return nil, err
+	}
+	if _, err := s.requireManager(ctx, orgID, userID, role); err != nil {
+		return nil, err
+	}
+
+	token, err := generateSecureToken(32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate invitation token: %w", err)
+	}
+	invitation := &models.OrganizationInvitation{
+		OrgID:     orgID,
+		Email:     email,
+		Role:      role,
+		TokenHash: hashInvitationToken(token),
+		InvitedBy: userID,
+		ExpiresAt: time.Now().Add(invitationTTL),
+	}
+	if err := s.repo.SaveOrganizationInvitation(ctx, invitation); err != nil {
+		return nil, fmt.Errorf("failed to save invitation: %w", err)
+	}
+
+	body := fmt.Sprintf("Вас пригласили в организацию. Чтобы принять приглашение, войдите в аккаунт с этим адресом и отправьте код:\n%s\n\nПриглашение действует %s.",
+		token, invitationTTL)
+	if err := s.notifier.SendEmail(email, "Приглашение в организацию", body); err != nil {
+		return nil, fmt.Errorf("failed to send invitation: %w", err)
+	}
+
+	s.audit.Record(ctx, userID, AuditOrgMemberInvited, ip, map[string]string{"org_id": orgID, "email": email, "role": role})
+	return invitation, nil
+}
+
+// Accept adds the user to the organization of an invitation sent to their
+// email.
+func (s *OrganizationService) Accept(ctx context.Context, userID, token string, ip net.IP) (*models.OrganizationMember, error) {
+	invitation, err := s.repo.GetOrganizationInvitation(ctx, hashInvitationToken(token))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrInvalidInvitation
+		}
+		return nil, fmt.Errorf("failed to get invitation: %w", err)
+	}
+	if time.Now().After(invitation.ExpiresAt) {
+		return nil, ErrInvalidInvitation
+	}
+	if _, err := s.repo.GetOrganization(ctx, TenantFromContext(ctx), invitation.OrgID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrInvalidInvitation
+		}
+		return nil, fmt.Errorf("failed to get organization: %w", err)
+	}
+
+	user, err := s.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrInvalidInvitation
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if !strings.EqualFold(user.Email, invitation.Email) {
+		return nil, ErrInvalidInvitation
+	}
+
+	member := &models.OrganizationMember{OrgID: invitation.OrgID, UserID: userID, Email: user.Email, Role: invitation.Role, Teams: []string{}}
+	if err := s.repo.AddOrganizationMember(ctx, member); err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			return nil, ErrAlreadyMember
+		}
+		return nil, fmt.Errorf("failed to add organization member: %w", err)
+	}
+	if err := s.repo.DeleteOrganizationInvitation(ctx, invitation.ID); err != nil {
+		return nil, fmt.Errorf("failed to delete invitation: %w", err)
+	}
+
+	s.audit.Record(ctx, userID, AuditOrgMemberJoined, ip, map[string]string{"org_id": invitation.OrgID, "role": invitation.Role})
+	return member, nil
+}
+
+// ChangeRole changes the role of a member. The last owner keeps the role.
+func (s *OrganizationService) ChangeRole(ctx context.Context, orgID, userID, memberID, role string, ip net.IP) error {
+	if err := validateOrgRole(role); err != nil {
+		return err
+	}
+	if _, err := s.requireManager(ctx, orgID, userID, role); err != nil {
+		return err
+	}
+	member, err := s.member(ctx, orgID, memberID)
+	if err != nil {
+		return err
+	}
+	if member.Role == OrgRoleOwner {
+		if _, err := s.requireManager(ctx, orgID, userID, OrgRoleOwner); err != nil {
+			return err
+		}
+		if role != OrgRoleOwner {
+			if err := s.keepOwner(ctx, orgID); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := s.repo.UpdateOrganizationMemberRole(ctx, orgID, memberID, role); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrNotOrgMember
+		}
+		return fmt.Errorf("failed to update organization member: %w", err)
+	}
+
+	s.audit.Record(ctx, userID, AuditOrgMemberRole, ip, map[string]string{"org_id": orgID, "user_id": memberID, "role": role})
+	return nil
+}
+
+// RemoveMember removes a member from the organization. Members may leave on
+// their own, the last owner may not.
+func (s *OrganizationService) RemoveMember(ctx context.Context, orgID, userID, memberID string, ip net.IP) error {
+	member, err := s.Membership(ctx, orgID, memberID)
+	if err != nil {
+		return err
+	}
+	if userID != memberID {
+		if _, err := s.requireManager(ctx, orgID, userID, member.Role); err != nil {
+			return err
+		}
+	}
+	if member.Role == OrgRoleOwner {
+		if err := s.keepOwner(ctx, orgID); err != nil {
+			return err
+		}
+	}
+
+	if err := s.repo.RemoveOrganizationMember(ctx, orgID, memberID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrNotOrgMember
+		}
+		return fmt.Errorf("failed to remove organization member: %w", err)
+	}
+
+	s.audit.Record(ctx, userID, AuditOrgMemberRemoved, ip, map[string]string{"org_id": orgID, "user_id": memberID})
+	return nil
+}
+
+func (s *OrganizationService) CreateTeam(ctx context.Context, orgID, userID, name string, ip net.IP) (*models.Team, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > 255 {
+		return nil, fmt.Errorf("%w: name must be 1 to 255 characters", ErrInvalidOrganization)
+	}
+	if _, err := s.requireManager(ctx, orgID, userID, OrgRoleMember); err != nil {
+		return nil, err
+	}
+
+	team := &models.Team{OrgID: orgID, Name: name}
+	if err := s.repo.CreateTeam(ctx, team); err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			return nil, ErrTeamExists
+		}
+		return nil, fmt.Errorf("failed to create team: %w", err)
+	}
+
+	s.audit.Record(ctx, userID, AuditTeamCreated, ip, map[string]string{"org_id": orgID, "team_id": team.ID})
+	return team, nil
+}
+
+func (s *OrganizationService) ListTeams(ctx context.Context, orgID, userID string) ([]models.Team, error) {
+	if _, err := s.Membership(ctx, orgID, userID); err != nil {
+		return nil, err
+	}
+
+	teams, err := s.repo.ListTeams(ctx, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list teams: %w", err)
+	}
+	if teams == nil {
+		teams = []models.Team{}
+	}
+	return teams, nil
+}
+
+// AddTeamMember adds a member of the organization to one of its teams.
+func (s *OrganizationService) AddTeamMember(ctx context.Context, orgID, teamID, userID, memberID string, ip net.IP) error {
+	if _, err := s.requireManager(ctx, orgID, userID, OrgRoleMember); err != nil {
+		return err
+	}
+	if _, err := s.member(ctx, orgID, memberID); err != nil {
+		return err
+	}
+	if err := s.team(ctx, orgID, teamID); err != nil {
+		return err
+	}
+
+	if err := s.repo.AddTeamMember(ctx, teamID, memberID); err != nil {
+		return fmt.Errorf("failed to add team member: %w", err)
+	}
+
+	s.audit.Record(ctx, userID, AuditTeamMemberAdded, ip, map[string]string{"org_id": orgID, "team_id": teamID, "user_id": memberID})
+	return nil
+}
+
+func (s *OrganizationService) RemoveTeamMember(ctx context.Context, orgID, teamID, userID, memberID string, ip net.IP) error {
+	if _, err := s.requireManager(ctx, orgID, userID, OrgRoleMember); err != nil {
+		return err
+	}
+	if err := s.team(ctx, orgID, teamID); err != nil {
+		return err
+	}
+
+	if err := s.repo.RemoveTeamMember(ctx, teamID, memberID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrNotOrgMember
+		}
+		return fmt.Errorf("failed to remove team member: %w", err)
+	}
+
+	s.audit.Record(ctx, userID, AuditTeamMemberRemoved, ip, map[string]string{"org_id": orgID, "team_id": teamID, "user_id": memberID})
+	return nil
+}
+
+// requireManager checks that the user may manage members with the role:
+// owners manage everyone, admins everyone but owners.
+func (s *OrganizationService) requireManager(ctx context.Context, orgID, userID, role string) (*models.OrganizationMember, error) {
+	manager, err := s.Membership(ctx, orgID, userID)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case manager.Role == OrgRoleOwner:
+		return manager, nil
+	case manager.Role == OrgRoleAdmin && role != OrgRoleOwner:
+		return manager, nil
+	default:
+		return nil, ErrOrgForbidden
+	}
+}
+
+// member looks up another member once the organization is known to be in
+// the tenant.
+func (s *OrganizationService) member(ctx context.Context, orgID, memberID string) (*models.OrganizationMember, error) {
+	member, err := s.repo.GetOrganizationMember(ctx, orgID, memberID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrNotOrgMember
+		}
+		return nil, fmt.Errorf("failed to get organization member: %w", err)
+	}
+	return member, nil
+}
+
+func (s *OrganizationService) team(ctx context.Context, orgID, teamID string) error {
+	if _, err := s.repo.GetTeam(ctx, orgID, teamID); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrTeamNotFound
+		}
+		return fmt.Errorf("failed to get team: %w", err)
+	}
+	return nil
+}
+
+func (s *OrganizationService) keepOwner(ctx context.Context, orgID string) error {
+	owners, err := s.repo.CountOrganizationOwners(ctx, orgID)
+	if err != nil {
+		return fmt.Errorf("failed to count organization owners: %w", err)
+	}
+	if owners <= 1 {
+		return ErrLastOwner
+	}
+	return nil
+}
+
+func validateOrgRole(role string) error {
+	switch role {
+	case OrgRoleOwner, OrgRoleAdmin, OrgRoleMember:
+		return nil
+	default:
+		return fmt.Errorf("%w: role must be owner, admin or member", ErrInvalidOrganization)
+	}
+}
+
+func hashInvitationToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,138 @@
+package services
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/auth-service/internal/models"
+	"github.com/auth-service/internal/repository"
+	"github.com/auth-service/internal/repository/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrganizationService(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	mockNotifier := NewMockNotifier(ctrl)
+	orgSvc := NewOrganizationService(mockRepo, mockNotifier, NewAuditLogger(mockRepo))
+	ctx := context.Background()
+	ip := net.ParseIP("192.168.1.1")
+
+	mockRepo.EXPECT().SaveAuditEvent(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	org := &models.Organization{ID: "org1", TenantID: DefaultTenant, Name: "Acme"}
+	expectMember := func(userID, role string) {
+		mockRepo.EXPECT().GetOrganization(ctx, DefaultTenant, "org1").Return(org, nil)
+		mockRepo.EXPECT().GetOrganizationMember(ctx, "org1", userID).
+			Return(&models.OrganizationMember{OrgID: "org1", UserID: userID, Role: role}, nil)
+	}
+
+	t.Run("Create makes the user the owner", func(t *testing.T) {
+		mockRepo.EXPECT().CreateOrganization(ctx, gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, o *models.Organization, owner *models.OrganizationMember) error {
+				assert.Equal(t, DefaultTenant, o.TenantID)
+				assert.Equal(t, "user1", owner.UserID)
+				assert.Equal(t, OrgRoleOwner, owner.Role)
+				o.ID = "org1"
+				return nil
+			})
+
+		created, err := orgSvc.Create(ctx, "user1", " Acme ", ip)
+		require.NoError(t, err)
+		assert.Equal(t, "Acme", created.Name)
+	})
+
+	t.Run("Invite emails a token whose hash is stored", func(t *testing.T) {
+		expectMember("user1", OrgRoleAdmin)
+
+		var stored *models.OrganizationInvitation
+		mockRepo.EXPECT().SaveOrganizationInvitation(ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, invitation *models.OrganizationInvitation) error {
+				stored = invitation
+				return nil
+			})
+		mockNotifier.EXPECT().SendEmail("new@example.com", gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_, _, body string) error {
+				lines := strings.Split(body, "\n")
+				assert.Equal(t, stored.TokenHash, hashInvitationToken(lines[1]))
+				return nil
+			})
+
+		_, err := orgSvc.Invite(ctx, "org1", "user1", "New@Example.com", OrgRoleMember, ip)
+		require.NoError(t, err)
+		assert.Equal(t, "new@example.com", stored.Email)
+	})
+
+	t.Run("Admins cannot invite owners", func(t *testing.T) {
+		expectMember("user1", OrgRoleAdmin)
+
+		_, err := orgSvc.Invite(ctx, "org1", "user1", "new@example.com", OrgRoleOwner, ip)
+		assert.ErrorIs(t, err, ErrOrgForbidden)
+	})
+
+	t.Run("Accept requires the invited email", func(t *testing.T) {
+		invitation := &models.OrganizationInvitation{
+			ID: "inv1", OrgID: "org1", Email: "new@example.com", Role: OrgRoleMember, ExpiresAt: time.Now().Add(time.Hour),
+		}
+		mockRepo.EXPECT().GetOrganizationInvitation(ctx, hashInvitationToken("token")).Return(invitation, nil).Times(2)
+		mockRepo.EXPECT().GetOrganization(ctx, DefaultTenant, "org1").Return(org, nil).Times(2)
+
+		mockRepo.EXPECT().GetUserByID(ctx, "other").Return(&models.User{ID: "other", Email: "other@example.com"}, nil)
+		_, err := orgSvc.Accept(ctx, "other", "token", ip)
+		assert.ErrorIs(t, err, ErrInvalidInvitation)
+
+		mockRepo.EXPECT().GetUserByID(ctx, "user2").Return(&models.User{ID: "user2", Email: "New@example.com"}, nil)
+		mockRepo.EXPECT().AddOrganizationMember(ctx, gomock.Any()).Return(nil)
+		mockRepo.EXPECT().DeleteOrganizationInvitation(ctx, "inv1").Return(nil)
+		member, err := orgSvc.Accept(ctx, "user2", "token", ip)
+		require.NoError(t, err)
+		assert.Equal(t, OrgRoleMember, member.Role)
+	})
+
+	t.Run("Accept rejects expired invitations", func(t *testing.T) {
+		mockRepo.EXPECT().GetOrganizationInvitation(ctx, hashInvitationToken("old")).
+			Return(&models.OrganizationInvitation{OrgID: "org1", ExpiresAt: time.Now().Add(-time.Hour)}, nil)
+
+		_, err := orgSvc.Accept(ctx, "user2", "old", ip)
+		assert.ErrorIs(t, err, ErrInvalidInvitation)
+	})
+
+	t.Run("The last owner cannot leave", func(t *testing.T) {
+		expectMember("user1", OrgRoleOwner)
+		mockRepo.EXPECT().CountOrganizationOwners(ctx, "org1").Return(1, nil)
+
+		err := orgSvc.RemoveMember(ctx, "org1", "user1", "user1", ip)
+		assert.ErrorIs(t, err, ErrLastOwner)
+	})
+
+	t.Run("Members cannot remove others", func(t *testing.T) {
+		expectMember("user2", OrgRoleMember)
+		expectMember("user3", OrgRoleMember)
+
+		err := orgSvc.RemoveMember(ctx, "org1", "user3", "user2", ip)
+		assert.ErrorIs(t, err, ErrOrgForbidden)
+	})
+
+	t.Run("Organizations of other tenants are hidden", func(t *testing.T) {
+		tenantCtx := WithTenant(ctx, "acme")
+		mockRepo.EXPECT().GetOrganization(tenantCtx, "acme", "org1").Return(nil, repository.ErrNotFound)
+
+		_, err := orgSvc.Members(tenantCtx, "org1", "user1")
+		assert.ErrorIs(t, err, ErrNotOrgMember)
+	})
+
+	t.Run("AddTeamMember requires a member", func(t *testing.T) {
+		expectMember("user1", OrgRoleOwner)
+		mockRepo.EXPECT().GetOrganizationMember(ctx, "org1", "stranger").Return(nil, repository.ErrNotFound)
+
+		err := orgSvc.AddTeamMember(ctx, "org1", "team1", "user1", "stranger", ip)
+		assert.ErrorIs(t, err, ErrNotOrgMember)
+	})
+}
@@ -0,0 +1,66 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/auth-service/internal/models"
+	"github.com/auth-service/internal/repository"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const AuditPasswordLogin = "login.password"
+
+var ErrInvalidCredentials = errors.New("invalid email or password")
+
+// dummyPasswordHash is compared against when the user does not exist, so
+// that unknown addresses take as long to reject as wrong passwords.
+var dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("dummy-password"), bcrypt.DefaultCost)
+
+// LocalAuthenticator checks email and password against the users table.
+type LocalAuthenticator struct {
+	repo    repository.UserRepository
+	lockout *LockoutService
+	audit   *AuditLogger
+}
+
+func NewLocalAuthenticator(repo repository.UserRepository, lockout *LockoutService, audit *AuditLogger) *LocalAuthenticator {
+	return &LocalAuthenticator{
+		repo:    repo,
+		lockout: lockout,
+		audit:   audit,
+	}
+}
+
+func (a *LocalAuthenticator) Authenticate(ctx context.Context, email, password string, ip net.IP) (*models.User, error) {
+	user, err := a.repo.GetUserByEmail(ctx, TenantFromContext(ctx), strings.TrimSpace(email))
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	var userID string
+	if user != nil {
+		userID = user.ID
+	}
+	if err := a.lockout.Check(ctx, userID, ip); err != nil {
+		return nil, err
+	}
+
+	if user == nil || user.PasswordHash == "" {
+		_ = bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(password))
+		a.lockout.RegisterFailure(ctx, AttemptLogin, userID, ip)
+		return nil, ErrInvalidCredentials
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+		a.lockout.RegisterFailure(ctx, AttemptLogin, userID, ip)
+		return nil, ErrInvalidCredentials
+	}
+
+	a.lockout.RegisterSuccess(ctx, user.ID)
+	a.audit.Record(ctx, user.ID, AuditPasswordLogin, ip, nil)
+	return user, nil
+}
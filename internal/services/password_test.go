@@ -0,0 +1,83 @@
+package services
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/auth-service/internal/models"
+	"github.com/auth-service/internal/repository"
+	"github.com/auth-service/internal/repository/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestLocalAuthenticator(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	mockNotifier := NewMockNotifier(ctrl)
+	audit := NewAuditLogger(mockRepo)
+	lockout := NewLockoutService(mockRepo, LockoutPolicy{LockThreshold: 10, Window: time.Hour}, audit, mockNotifier)
+	authenticator := NewLocalAuthenticator(mockRepo, lockout, audit)
+	ctx := context.Background()
+	userIP := net.ParseIP("192.168.1.1")
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("correct horse"), bcrypt.MinCost)
+	require.NoError(t, err)
+	user := &models.User{ID: "user1", Email: "user@example.com", PasswordHash: string(hash)}
+
+	mockRepo.EXPECT().SaveAuditEvent(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	mockRepo.EXPECT().GetAuthFailure(ctx, gomock.Any(), gomock.Any()).Return(&models.AuthFailure{}, nil).AnyTimes()
+
+	t.Run("Valid password", func(t *testing.T) {
+		mockRepo.EXPECT().GetUserByEmail(ctx, DefaultTenant, "user@example.com").Return(user, nil)
+		mockRepo.EXPECT().ClearAuthFailures(ctx, lockoutScopeAccount, "user1").Return(nil)
+
+		got, err := authenticator.Authenticate(ctx, " user@example.com ", "correct horse", userIP)
+		require.NoError(t, err)
+		assert.Equal(t, "user1", got.ID)
+	})
+
+	t.Run("Wrong password", func(t *testing.T) {
+		mockRepo.EXPECT().GetUserByEmail(ctx, DefaultTenant, "user@example.com").Return(user, nil)
+		mockRepo.EXPECT().
+			RecordAuthFailure(ctx, gomock.Any(), gomock.Any(), time.Hour).
+			Return(&models.AuthFailure{Failures: 1}, nil).
+			Times(2)
+
+		_, err := authenticator.Authenticate(ctx, "user@example.com", "wrong", userIP)
+		assert.ErrorIs(t, err, ErrInvalidCredentials)
+	})
+
+	t.Run("Unknown user", func(t *testing.T) {
+		mockRepo.EXPECT().GetUserByEmail(ctx, DefaultTenant, "nobody@example.com").Return(nil, repository.ErrNotFound)
+		mockRepo.EXPECT().
+			RecordAuthFailure(ctx, lockoutScopeIP, userIP.String(), time.Hour).
+			Return(&models.AuthFailure{Failures: 1}, nil)
+
+		_, err := authenticator.Authenticate(ctx, "nobody@example.com", "whatever", userIP)
+		assert.ErrorIs(t, err, ErrInvalidCredentials)
+	})
+
+	t.Run("Locked account", func(t *testing.T) {
+		lockedUntil := time.Now().Add(time.Minute)
+		lockedRepo := mocks.NewMockRepository(ctrl)
+		lockedAuthenticator := NewLocalAuthenticator(lockedRepo, NewLockoutService(lockedRepo, LockoutPolicy{}, audit, mockNotifier), audit)
+
+		lockedRepo.EXPECT().GetUserByEmail(ctx, DefaultTenant, "user@example.com").Return(user, nil)
+		lockedRepo.EXPECT().
+			GetAuthFailure(ctx, lockoutScopeAccount, "user1").
+			Return(&models.AuthFailure{LockedUntil: &lockedUntil}, nil)
+		lockedRepo.EXPECT().
+			GetAuthFailure(ctx, lockoutScopeIP, userIP.String()).
+			Return(&models.AuthFailure{}, nil)
+
+		_, err := lockedAuthenticator.Authenticate(ctx, "user@example.com", "correct horse", userIP)
+		assert.ErrorIs(t, err, ErrTooManyAttempts)
+	})
+}
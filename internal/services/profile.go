@@ -0,0 +1,266 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	// Timezones are validated against the embedded database, so they do not
+	// depend on the zoneinfo of the host.
+	_ "time/tzdata"
+
+	"github.com/auth-service/internal/models"
+	"github.com/auth-service/internal/repository"
+)
+
+const (
+	AuditProfileUpdated       = "user.profile_updated"
+	AuditEmailChangeRequested = "user.email_change_requested"
+	AuditEmailChanged         = "user.email_changed"
+
+	emailChangeTTL = 24 * time.Hour
+)
+
+var (
+	ErrInvalidEmailChange = errors.New("invalid or expired email confirmation")
+	ErrEmailTaken         = errors.New("email is already in use")
+
+	localePattern = regexp.MustCompile(`^[a-zA-Z]{2,3}(-[a-zA-Z0-9]{2,8})*$`)
+)
+
+// ValidationError lists the invalid fields of a request with the reason
+// for each.
+type ValidationError struct {
+	Fields map[string]string
+}
+
+func (e *ValidationError) Error() string {
+	names := make([]string, 0, len(e.Fields))
+	for name := range e.Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return "invalid fields: " + strings.Join(names, ", ")
+}
+
+// ProfileUpdate changes the fields that are not nil.
+type ProfileUpdate struct {
+	Name      *string
+	Email     *string
+	Locale    *string
+	Timezone  *string
+	AvatarURL *string
+}
+
+type profileRepository interface {
+	repository.UserRepository
+	repository.EmailChangeRepository
+}
+
+// ProfileService lets users manage their own profile. A new email is only
+// applied once it has been confirmed from both the old and the new address.
+type ProfileService struct {
+	repo      profileRepository
+	publicURL string
+	notifier  Notifier
+	audit     *AuditLogger
+}
+
+func NewProfileService(repo profileRepository, publicURL string, notifier Notifier, audit *AuditLogger) *ProfileService {
+	return &ProfileService{
+		repo:      repo,
+		publicURL: strings.TrimRight(publicURL, "/"),
+		notifier:  notifier,
+		audit:     audit,
+	}
+}
+
+func (s *ProfileService) Get(ctx context.Context, userID string) (*models.User, error) {
+	user, err := s.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	return user, nil
+}
+
+// Update validates and saves the changed fields. It returns the profile
+// and, when the email changes, the pending change that has to be confirmed.
+func (s *ProfileService) Update(ctx context.Context, userID string, update ProfileUpdate, ip net.IP) (*models.User, *models.EmailChange, error) {
+	if err := validateProfile(update); err != nil {
+		return nil, nil, err
+	}
+
+	user, err := s.Get(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var changed []string
+	set := func(field string, value *string, target *string) {
+		if value != nil && strings.TrimSpace(*value) != *target {
+			*target = strings.TrimSpace(*value)
+			changed = append(changed, field)
+		}
+	}
+	set("name", update.Name, &user.Name)
+	set("locale", update.Locale, &user.Locale)
+	set("timezone", update.Timezone, &user.Timezone)
+	set("avatar_url", update.AvatarURL, &user.AvatarURL)
+
+	if len(changed) > 0 {
+		if err := s.repo.UpdateUserProfile(ctx, user); err != nil {
+			return nil, nil, fmt.Errorf("failed to update profile: %w", err)
+		}
+		s.audit.Record(ctx, userID, AuditProfileUpdated, ip, map[string]string{"fields": strings.Join(changed, " ")})
+	}
+
+	var change *models.EmailChange
+	if update.Email != nil && !strings.EqualFold(strings.TrimSpace(*update.Email), user.Email) {
+		change, err = s.requestEmailChange(ctx, user, strings.ToLower(strings.TrimSpace(*update.Email)), ip)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	return user, change, nil
+}
+
+// ConfirmEmailChange confirms a pending change with the token sent to one
+// of the addresses. The change is applied after the second confirmation.
+func (s *ProfileService) ConfirmEmailChange(ctx context.Context, token string, ip net.IP) (*models.EmailChange, bool, error) {
+	tokenHash := hashEmailChangeToken(token)
+	change, err := s.repo.GetEmailChangeByToken(ctx, tokenHash)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, false, ErrInvalidEmailChange
+		}
+		return nil, false, fmt.Errorf("failed to get email change: %w", err)
+	}
+	if time.Now().After(change.ExpiresAt) {
+		return nil, false, ErrInvalidEmailChange
+	}
+
+	change, err = s.repo.ConfirmEmailChange(ctx, change.ID, change.OldTokenHash == tokenHash)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, false, ErrInvalidEmailChange
+		}
+		return nil, false, fmt.Errorf("failed to confirm email change: %w", err)
+	}
+	if change.OldConfirmedAt == nil || change.NewConfirmedAt == nil {
+		return change, false, nil
+	}
+
+	if err := s.repo.UpdateUserEmail(ctx, change.UserID, change.NewEmail); err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			return nil, false, ErrEmailTaken
+		}
+		return nil, false, fmt.Errorf("failed to update email: %w", err)
+	}
+	if err := s.repo.DeleteEmailChange(ctx, change.ID); err != nil {
+		return nil, false, fmt.Errorf("failed to delete email change: %w", err)
+	}
+
+	s.audit.Record(ctx, change.UserID, AuditEmailChanged, ip, map[string]string{"email": change.NewEmail})
+	return change, true, nil
+}
+
+func (s *ProfileService) requestEmailChange(ctx context.Context, user *models.User, email string, ip net.IP) (*models.EmailChange, error) {
+	if _, err := s.repo.GetUserByEmail(ctx, TenantFromContext(ctx), email); err == nil {
+		return nil, &ValidationError{Fields: map[string]string{"email": "is already in use"}}
+	} else if !errors.Is(err, repository.ErrNotFound) {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	oldToken, err := generateSecureToken(32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate confirmation token: %w", err)
+	}
+	newToken, err := generateSecureToken(32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate confirmation token: %w", err)
+	}
+
+	change := &models.EmailChange{
+		UserID:       user.ID,
+		NewEmail:     email,
+		OldTokenHash: hashEmailChangeToken(oldToken),
+		NewTokenHash: hashEmailChangeToken(newToken),
+		ExpiresAt:    time.Now().Add(emailChangeTTL),
+	}
+	if err := s.repo.SaveEmailChange(ctx, change); err != nil {
+		return nil, fmt.Errorf("failed to save email change: %w", err)
+	}
+
+	oldBody := fmt.Sprintf("Запрошена смена email аккаунта на %s. Чтобы подтвердить её, перейдите по ссылке:\n%s\n\nЕсли вы не запрашивали смену, смените пароль.",
+		email, s.confirmationLink(oldToken))
+	if err := s.notifier.SendEmail(user.Email, "Смена email", oldBody); err != nil {
+		return nil, fmt.Errorf("failed to send confirmation: %w", err)
+	}
+	newBody := fmt.Sprintf("Чтобы подтвердить этот адрес для аккаунта, перейдите по ссылке:\n%s\n\nСсылка действует %s.",
+		s.confirmationLink(newToken), emailChangeTTL)
+	if err := s.notifier.SendEmail(email, "Подтверждение email", newBody); err != nil {
+		return nil, fmt.Errorf("failed to send confirmation: %w", err)
+	}
+
+	s.audit.Record(ctx, user.ID, AuditEmailChangeRequested, ip, map[string]string{"email": email})
+	return change, nil
+}
+
+func (s *ProfileService) confirmationLink(token string) string {
+	return s.publicURL + "/auth/email/confirm?token=" + url.QueryEscape(token)
+}
+
+func validateProfile(update ProfileUpdate) error {
+	fields := make(map[string]string)
+
+	if update.Name != nil && len(strings.TrimSpace(*update.Name)) > 255 {
+		fields["name"] = "must be at most 255 characters"
+	}
+	if update.Email != nil {
+		email := strings.TrimSpace(*update.Email)
+		if addr, err := mail.ParseAddress(email); err != nil || addr.Address != email || len(email) > 320 {
+			fields["email"] = "must be a valid email address"
+		}
+	}
+	if update.Locale != nil {
+		if locale := strings.TrimSpace(*update.Locale); locale != "" && (len(locale) > 35 || !localePattern.MatchString(locale)) {
+			fields["locale"] = "must be a BCP 47 language tag such as ru-RU"
+		}
+	}
+	if update.Timezone != nil {
+		if tz := strings.TrimSpace(*update.Timezone); tz != "" {
+			if _, err := time.LoadLocation(tz); err != nil || tz == "Local" || len(tz) > 64 {
+				fields["timezone"] = "must be an IANA time zone such as Europe/Moscow"
+			}
+		}
+	}
+	if update.AvatarURL != nil {
+		if avatar := strings.TrimSpace(*update.AvatarURL); avatar != "" {
+			u, err := url.Parse(avatar)
+			if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" || len(avatar) > 2048 {
+				fields["avatar_url"] = "must be an http or https URL"
+			}
+		}
+	}
+
+	if len(fields) > 0 {
+		return &ValidationError{Fields: fields}
+	}
+	return nil
+}
+
+func hashEmailChangeToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
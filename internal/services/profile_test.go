@@ -0,0 +1,131 @@
+package services
+
+import (
+	"context"
+	"net"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/auth-service/internal/models"
+	"github.com/auth-service/internal/repository"
+	"github.com/auth-service/internal/repository/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProfileService(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	mockNotifier := NewMockNotifier(ctrl)
+	profileSvc := NewProfileService(mockRepo, "https://auth.example/", mockNotifier, NewAuditLogger(mockRepo))
+	ctx := context.Background()
+	ip := net.ParseIP("192.168.1.1")
+
+	mockRepo.EXPECT().SaveAuditEvent(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	str := func(s string) *string { return &s }
+
+	t.Run("Update validates every field", func(t *testing.T) {
+		_, _, err := profileSvc.Update(ctx, "user1", ProfileUpdate{
+			Email:     str("not an email"),
+			Locale:    str("ru RU"),
+			Timezone:  str("Mars/Olympus"),
+			AvatarURL: str("javascript:alert(1)"),
+		}, ip)
+
+		var validationErr *ValidationError
+		require.ErrorAs(t, err, &validationErr)
+		assert.Len(t, validationErr.Fields, 4)
+	})
+
+	t.Run("Update saves changed fields", func(t *testing.T) {
+		mockRepo.EXPECT().GetUserByID(ctx, "user1").Return(&models.User{ID: "user1", Email: "old@example.com"}, nil)
+		mockRepo.EXPECT().UpdateUserProfile(ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, user *models.User) error {
+				assert.Equal(t, "Ivan", user.Name)
+				assert.Equal(t, "ru-RU", user.Locale)
+				assert.Equal(t, "Europe/Moscow", user.Timezone)
+				return nil
+			})
+
+		user, change, err := profileSvc.Update(ctx, "user1", ProfileUpdate{
+			Name:     str(" Ivan "),
+			Email:    str("old@example.com"),
+			Locale:   str("ru-RU"),
+			Timezone: str("Europe/Moscow"),
+		}, ip)
+		require.NoError(t, err)
+		assert.Nil(t, change)
+		assert.Equal(t, "Ivan", user.Name)
+	})
+
+	t.Run("Email change is confirmed by both addresses", func(t *testing.T) {
+		mockRepo.EXPECT().GetUserByID(ctx, "user1").Return(&models.User{ID: "user1", Email: "old@example.com"}, nil)
+		mockRepo.EXPECT().GetUserByEmail(ctx, DefaultTenant, "new@example.com").Return(nil, repository.ErrNotFound)
+
+		var stored *models.EmailChange
+		mockRepo.EXPECT().SaveEmailChange(ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, change *models.EmailChange) error {
+				change.ID = "change1"
+				stored = change
+				return nil
+			})
+		tokens := make(map[string]string)
+		mockNotifier.EXPECT().SendEmail(gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(to, _, body string) error {
+				_, link, _ := strings.Cut(body, "https://auth.example/auth/email/confirm?token=")
+				token, err := url.QueryUnescape(strings.Fields(link)[0])
+				tokens[to] = token
+				return err
+			}).Times(2)
+
+		_, change, err := profileSvc.Update(ctx, "user1", ProfileUpdate{Email: str("New@example.com")}, ip)
+		require.NoError(t, err)
+		require.NotNil(t, change)
+		assert.Equal(t, "new@example.com", change.NewEmail)
+		require.Contains(t, tokens, "old@example.com")
+		require.Contains(t, tokens, "new@example.com")
+
+		now := time.Now()
+		mockRepo.EXPECT().GetEmailChangeByToken(ctx, stored.OldTokenHash).Return(stored, nil)
+		mockRepo.EXPECT().ConfirmEmailChange(ctx, "change1", true).
+			Return(&models.EmailChange{ID: "change1", UserID: "user1", NewEmail: "new@example.com", OldConfirmedAt: &now}, nil)
+
+		_, applied, err := profileSvc.ConfirmEmailChange(ctx, tokens["old@example.com"], ip)
+		require.NoError(t, err)
+		assert.False(t, applied)
+
+		mockRepo.EXPECT().GetEmailChangeByToken(ctx, stored.NewTokenHash).Return(stored, nil)
+		mockRepo.EXPECT().ConfirmEmailChange(ctx, "change1", false).
+			Return(&models.EmailChange{ID: "change1", UserID: "user1", NewEmail: "new@example.com", OldConfirmedAt: &now, NewConfirmedAt: &now}, nil)
+		mockRepo.EXPECT().UpdateUserEmail(ctx, "user1", "new@example.com").Return(nil)
+		mockRepo.EXPECT().DeleteEmailChange(ctx, "change1").Return(nil)
+
+		_, applied, err = profileSvc.ConfirmEmailChange(ctx, tokens["new@example.com"], ip)
+		require.NoError(t, err)
+		assert.True(t, applied)
+	})
+
+	t.Run("Email of another user", func(t *testing.T) {
+		mockRepo.EXPECT().GetUserByID(ctx, "user1").Return(&models.User{ID: "user1", Email: "old@example.com"}, nil)
+		mockRepo.EXPECT().GetUserByEmail(ctx, DefaultTenant, "taken@example.com").Return(&models.User{ID: "user2"}, nil)
+
+		_, _, err := profileSvc.Update(ctx, "user1", ProfileUpdate{Email: str("taken@example.com")}, ip)
+		var validationErr *ValidationError
+		require.ErrorAs(t, err, &validationErr)
+		assert.Contains(t, validationErr.Fields, "email")
+	})
+
+	t.Run("Expired confirmation", func(t *testing.T) {
+		mockRepo.EXPECT().GetEmailChangeByToken(ctx, hashEmailChangeToken("old")).
+			Return(&models.EmailChange{ID: "change1", ExpiresAt: time.Now().Add(-time.Minute)}, nil)
+
+		_, _, err := profileSvc.ConfirmEmailChange(ctx, "old", ip)
+		assert.ErrorIs(t, err, ErrInvalidEmailChange)
+	})
+}
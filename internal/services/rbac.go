@@ -0,0 +1,320 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/auth-service/internal/models"
+	"github.com/auth-service/internal/repository"
+)
+
+const (
+	// PermissionAdmin grants access to the admin API.
+	PermissionAdmin = "admin"
+
+	AuditRoleCreated      = "role.created"
+	AuditRoleUpdated      = "role.updated"
+	AuditRoleDeleted      = "role.deleted"
+	AuditUserRolesChanged = "user.roles_changed"
+
+	// accessCacheTTL bounds how long another instance keeps serving
+	// permissions that were changed elsewhere.
+	accessCacheTTL = time.Minute
+)
+
+var (
+	ErrRoleNotFound = errors.New("role not found")
+	ErrRoleExists   = errors.New("role already exists")
+	ErrInvalidRole  = errors.New("invalid role")
+
+	roleNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_.:-]{0,63}$`)
+)
+
+type rbacRepository interface {
+	repository.RoleRepository
+	repository.TokenCutoffRepository
+}
+
+// Access is what a user may do according to the roles assigned to them.
+// Access tokens issued before TokensNotBefore are stale and have to be
+// refreshed.
+type Access struct {
+	Roles           []string
+	Permissions     []string
+	TokensNotBefore time.Time
+}
+
+func (a *Access) Can(permission string) bool {
+	return hasString(a.Permissions, permission)
+}
+
+type cachedAccess struct {
+	access   *Access
+	loadedAt time.Time
+}
+
+// RBACService manages the roles of the tenant of the request and answers
+// permission checks from a short-lived cache. Users listed as administrators
+// in the configuration always have PermissionAdmin, so the first roles can be
+// assigned.
+type RBACService struct {
+	repo   rbacRepository
+	audit  *AuditLogger
+	admins map[string]bool
+
+	mu    sync.Mutex
+	cache map[string]cachedAccess
+	// generation changes on every invalidation, so a load that raced with
+	// a change is not cached.
+	generation uint64
+}
+
+func NewRBACService(repo rbacRepository, audit *AuditLogger, adminUserIDs []string) *RBACService {
+	admins := make(map[string]bool, len(adminUserIDs))
+	for _, id := range adminUserIDs {
+		admins[id] = true
+	}
+
+	return &RBACService{
+		repo:   repo,
+		audit:  audit,
+		admins: admins,
+		cache:  make(map[string]cachedAccess),
+	}
+}
+
+func (s *RBACService) CreateRole(ctx context.Context, name, description string, permissions []string, adminID string, ip net.IP) (*models.Role, error) {
+	permissions, err := normalizePermissions(permissions)
+	if err != nil {
+		return nil, err
+	}
+	if !roleNamePattern.MatchString(name) {
+		return nil, fmt.Errorf("%w: name must be 1 to 64 lowercase letters, digits or _.:-", ErrInvalidRole)
+	}
+
+	role := &models.Role{TenantID: TenantFromContext(ctx), Name: name, Description: description, Permissions: permissions}
+	if err := s.repo.CreateRole(ctx, role); err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			return nil, ErrRoleExists
+		}
+		return nil, fmt.Errorf("failed to create role: %w", err)
+	}
+
+	s.audit.Record(ctx, adminID, AuditRoleCreated, ip, map[string]string{"role": name})
+	return role, nil
+}
+
+func (s *RBACService) ListRoles(ctx context.Context) ([]models.Role, error) {
+	roles, err := s.repo.ListRoles(ctx, TenantFromContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list roles: %w", err)
+	}
+	if roles == nil {
+		roles = []models.Role{}
+	}
+	return roles, nil
+}
+
+func (s *RBACService) ListPermissions(ctx context.Context) ([]models.Permission, error) {
+	permissions, err := s.repo.ListPermissions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list permissions: %w", err)
+	}
+	if permissions == nil {
+		permissions = []models.Permission{}
+	}
+	return permissions, nil
+}
+
+// UpdateRole replaces the description and the permissions of a role. With
+// forceRefresh the access tokens of its users have to be refreshed.
+func (s *RBACService) UpdateRole(ctx context.Context, id, description string, permissions []string, forceRefresh bool, adminID string, ip net.IP) (*models.Role, error) {
+	permissions, err := normalizePermissions(permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	role := &models.Role{ID: id, TenantID: TenantFromContext(ctx), Description: description, Permissions: permissions}
+	if err := s.repo.UpdateRole(ctx, role); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrRoleNotFound
+		}
+		return nil, fmt.Errorf("failed to update role: %w", err)
+	}
+	if forceRefresh {
+		if err := s.repo.SetRoleTokenCutoff(ctx, id, tokenCutoff()); err != nil {
+			return nil, fmt.Errorf("failed to force token refresh: %w", err)
+		}
+	}
+	s.invalidateAll()
+
+	s.audit.Record(ctx, adminID, AuditRoleUpdated, ip, map[string]string{
+		"role":          role.Name,
+		"permissions":   strings.Join(permissions, " "),
+		"force_refresh": fmt.Sprint(forceRefresh),
+	})
+	return role, nil
+}
+
+func (s *RBACService) DeleteRole(ctx context.Context, id string, forceRefresh bool, adminID string, ip net.IP) error {
+	role, err := s.repo.GetRole(ctx, TenantFromContext(ctx), id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrRoleNotFound
+		}
+		return fmt.Errorf("failed to get role: %w", err)
+	}
+
+	// The cutoff has to be set while the role still lists its users.
+	if forceRefresh {
+		if err := s.repo.SetRoleTokenCutoff(ctx, id, tokenCutoff()); err != nil {
+			return fmt.Errorf("failed to force token refresh: %w", err)
+		}
+	}
+	if err := s.repo.DeleteRole(ctx, TenantFromContext(ctx), id); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrRoleNotFound
+		}
+		return fmt.Errorf("failed to delete role: %w", err)
+	}
+	s.invalidateAll()
+
+	s.audit.Record(ctx, adminID, AuditRoleDeleted, ip, map[string]string{"role": role.Name})
+	return nil
+}
+
+func (s *RBACService) GetUserRoles(ctx context.Context, userID string) ([]models.Role, error) {
+	roles, err := s.repo.GetUserRoles(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user roles: %w", err)
+	}
+	if roles == nil {
+		roles = []models.Role{}
+	}
+	return roles, nil
+}
+
+// SetUserRoles replaces the roles of a user. With forceRefresh the access
+// tokens the user already has must be refreshed to be accepted again.
+func (s *RBACService) SetUserRoles(ctx context.Context, userID string, roleNames []string, forceRefresh bool, adminID string, ip net.IP) ([]models.Role, error) {
+	roleNames = uniqueSorted(roleNames)
+
+	if err := s.repo.SetUserRoles(ctx, TenantFromContext(ctx), userID, roleNames); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, fmt.Errorf("%w: unknown role in %s", ErrInvalidRole, strings.Join(roleNames, ", "))
+		}
+		return nil, fmt.Errorf("failed to set user roles: %w", err)
+	}
+	if forceRefresh {
+		if err := s.repo.SetTokenCutoff(ctx, userID, tokenCutoff()); err != nil {
+			return nil, fmt.Errorf("failed to force token refresh: %w", err)
+		}
+	}
+	s.invalidate(userID)
+
+	s.audit.Record(ctx, adminID, AuditUserRolesChanged, ip, map[string]string{
+		"user_id":       userID,
+		"roles":         strings.Join(roleNames, " "),
+		"force_refresh": fmt.Sprint(forceRefresh),
+	})
+	return s.GetUserRoles(ctx, userID)
+}
+
+// Access returns the roles and permissions of a user, cached for up to
+// accessCacheTTL.
+func (s *RBACService) Access(ctx context.Context, userID string) (*Access, error) {
+	s.mu.Lock()
+	cached, ok := s.cache[userID]
+	generation := s.generation
+	s.mu.Unlock()
+	if ok && time.Since(cached.loadedAt) < accessCacheTTL {
+		return cached.access, nil
+	}
+
+	access, err := s.loadAccess(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	if s.generation == generation {
+		s.cache[userID] = cachedAccess{access: access, loadedAt: time.Now()}
+	}
+	s.mu.Unlock()
+	return access, nil
+}
+
+func (s *RBACService) loadAccess(ctx context.Context, userID string) (*Access, error) {
+	roles, err := s.repo.GetUserRoles(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user roles: %w", err)
+	}
+	notBefore, err := s.repo.GetTokenCutoff(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get token cutoff: %w", err)
+	}
+
+	var names, permissions []string
+	for _, role := range roles {
+		names = append(names, role.Name)
+		permissions = append(permissions, role.Permissions...)
+	}
+	if s.admins[userID] {
+		permissions = append(permissions, PermissionAdmin)
+	}
+
+	return &Access{
+		Roles:           uniqueSorted(names),
+		Permissions:     uniqueSorted(permissions),
+		TokensNotBefore: notBefore,
+	}, nil
+}
+
+func (s *RBACService) invalidate(userID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.cache, userID)
+	s.generation++
+}
+
+func (s *RBACService) invalidateAll() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cache = make(map[string]cachedAccess)
+	s.generation++
+}
+
+// tokenCutoff is the current time truncated to the precision of the iat
+// claim, so tokens issued right after the change are not rejected.
+func tokenCutoff() time.Time {
+	return time.Now().Truncate(time.Second)
+}
+
+func normalizePermissions(permissions []string) ([]string, error) {
+	for _, permission := range permissions {
+		if permission == "" || len(permission) > 128 || strings.ContainsAny(permission, " \t\n") {
+			return nil, fmt.Errorf("%w: invalid permission %q", ErrInvalidRole, permission)
+		}
+	}
+	return uniqueSorted(permissions), nil
+}
+
+func uniqueSorted(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			result = append(result, value)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
@@ -0,0 +1,129 @@
+package services
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/auth-service/internal/models"
+	"github.com/auth-service/internal/repository"
+	"github.com/auth-service/internal/repository/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRBACService(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	rbacSvc := NewRBACService(mockRepo, NewAuditLogger(mockRepo), []string{"root"})
+	ctx := context.Background()
+	adminIP := net.ParseIP("192.168.1.1")
+
+	mockRepo.EXPECT().SaveAuditEvent(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	supportRole := models.Role{ID: "role1", Name: "support", Permissions: []string{"users:read"}}
+
+	t.Run("CreateRole", func(t *testing.T) {
+		mockRepo.EXPECT().CreateRole(ctx, gomock.Any()).
+			DoAndReturn(func(_ context.Context, role *models.Role) error {
+				assert.Equal(t, []string{"users:read", "users:unlock"}, role.Permissions)
+				role.ID = "role1"
+				return nil
+			})
+
+		role, err := rbacSvc.CreateRole(ctx, "support", "", []string{"users:unlock", "users:read", "users:read"}, "root", adminIP)
+		require.NoError(t, err)
+		assert.Equal(t, "role1", role.ID)
+	})
+
+	t.Run("CreateRole rejects invalid and duplicate roles", func(t *testing.T) {
+		_, err := rbacSvc.CreateRole(ctx, "Support Team", "", nil, "root", adminIP)
+		assert.ErrorIs(t, err, ErrInvalidRole)
+		_, err = rbacSvc.CreateRole(ctx, "support", "", []string{"users read"}, "root", adminIP)
+		assert.ErrorIs(t, err, ErrInvalidRole)
+
+		mockRepo.EXPECT().CreateRole(ctx, gomock.Any()).Return(repository.ErrDuplicate)
+		_, err = rbacSvc.CreateRole(ctx, "support", "", nil, "root", adminIP)
+		assert.ErrorIs(t, err, ErrRoleExists)
+	})
+
+	t.Run("Access is cached", func(t *testing.T) {
+		mockRepo.EXPECT().GetUserRoles(ctx, "user1").Return([]models.Role{supportRole}, nil)
+		mockRepo.EXPECT().GetTokenCutoff(ctx, "user1").Return(time.Time{}, nil)
+
+		for i := 0; i < 2; i++ {
+			access, err := rbacSvc.Access(ctx, "user1")
+			require.NoError(t, err)
+			assert.Equal(t, []string{"support"}, access.Roles)
+			assert.True(t, access.Can("users:read"))
+			assert.False(t, access.Can(PermissionAdmin))
+		}
+	})
+
+	t.Run("Configured admins", func(t *testing.T) {
+		mockRepo.EXPECT().GetUserRoles(ctx, "root").Return(nil, nil)
+		mockRepo.EXPECT().GetTokenCutoff(ctx, "root").Return(time.Time{}, nil)
+
+		access, err := rbacSvc.Access(ctx, "root")
+		require.NoError(t, err)
+		assert.True(t, access.Can(PermissionAdmin))
+	})
+
+	t.Run("SetUserRoles invalidates the cache", func(t *testing.T) {
+		mockRepo.EXPECT().SetUserRoles(ctx, DefaultTenant, "user1", []string{"admin", "support"}).Return(nil)
+		mockRepo.EXPECT().SetTokenCutoff(ctx, "user1", gomock.Any()).Return(nil)
+		mockRepo.EXPECT().GetUserRoles(ctx, "user1").
+			Return([]models.Role{{Name: "admin", Permissions: []string{PermissionAdmin}}, supportRole}, nil).
+			Times(2)
+		cutoff := time.Now().Truncate(time.Second)
+		mockRepo.EXPECT().GetTokenCutoff(ctx, "user1").Return(cutoff, nil)
+
+		_, err := rbacSvc.SetUserRoles(ctx, "user1", []string{"support", "admin", "support"}, true, "root", adminIP)
+		require.NoError(t, err)
+
+		access, err := rbacSvc.Access(ctx, "user1")
+		require.NoError(t, err)
+		assert.True(t, access.Can(PermissionAdmin))
+		assert.Equal(t, cutoff, access.TokensNotBefore)
+	})
+
+	t.Run("SetUserRoles with unknown role", func(t *testing.T) {
+		mockRepo.EXPECT().SetUserRoles(ctx, DefaultTenant, "user1", []string{"missing"}).Return(repository.ErrNotFound)
+
+		_, err := rbacSvc.SetUserRoles(ctx, "user1", []string{"missing"}, false, "root", adminIP)
+		assert.ErrorIs(t, err, ErrInvalidRole)
+	})
+
+	t.Run("UpdateRole invalidates every user", func(t *testing.T) {
+		mockRepo.EXPECT().UpdateRole(ctx, gomock.Any()).Return(nil)
+		mockRepo.EXPECT().GetUserRoles(ctx, "user1").Return(nil, nil)
+		mockRepo.EXPECT().GetTokenCutoff(ctx, "user1").Return(time.Time{}, nil)
+
+		_, err := rbacSvc.UpdateRole(ctx, "role1", "", nil, false, "root", adminIP)
+		require.NoError(t, err)
+
+		access, err := rbacSvc.Access(ctx, "user1")
+		require.NoError(t, err)
+		assert.Empty(t, access.Permissions)
+	})
+
+	t.Run("DeleteRole forces refresh before deleting", func(t *testing.T) {
+		gomock.InOrder(
+			mockRepo.EXPECT().GetRole(ctx, DefaultTenant, "role1").Return(&supportRole, nil),
+			mockRepo.EXPECT().SetRoleTokenCutoff(ctx, "role1", gomock.Any()).Return(nil),
+			mockRepo.EXPECT().DeleteRole(ctx, DefaultTenant, "role1").Return(nil),
+		)
+
+		assert.NoError(t, rbacSvc.DeleteRole(ctx, "role1", true, "root", adminIP))
+	})
+
+	t.Run("DeleteRole unknown role", func(t *testing.T) {
+		mockRepo.EXPECT().GetRole(ctx, DefaultTenant, "missing").Return(nil, repository.ErrNotFound)
+
+		assert.ErrorIs(t, rbacSvc.DeleteRole(ctx, "missing", false, "root", adminIP), ErrRoleNotFound)
+	})
+}
@@ -0,0 +1,65 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+
+	"github.com/auth-service/internal/models"
+)
+
+const (
+	RealmLocal = "local"
+	RealmLDAP  = "ldap"
+)
+
+var ErrUnknownRealm = errors.New("unknown realm")
+
+// Realms chooses the password backend for a login: the realm named in the
+// request, the realm configured for the domain of the email, or the
+// fallback realm.
+type Realms struct {
+	authenticators map[string]Authenticator
+	domains        map[string]string
+	fallback       string
+}
+
+func NewRealms(fallback string, authenticator Authenticator) *Realms {
+	return &Realms{
+		authenticators: map[string]Authenticator{fallback: authenticator},
+		domains:        make(map[string]string),
+		fallback:       fallback,
+	}
+}
+
+// Add registers a realm and routes logins of the given email domains to it.
+func (r *Realms) Add(name string, authenticator Authenticator, domains []string) {
+	r.authenticators[name] = authenticator
+	for _, domain := range domains {
+		r.domains[strings.ToLower(domain)] = name
+	}
+}
+
+// Authenticate checks the password with the realm chosen by the email
+// domain, so Realms can stand in wherever an Authenticator is expected.
+func (r *Realms) Authenticate(ctx context.Context, login, password string, ip net.IP) (*models.User, error) {
+	return r.AuthenticateRealm(ctx, "", login, password, ip)
+}
+
+func (r *Realms) AuthenticateRealm(ctx context.Context, realm, login, password string, ip net.IP) (*models.User, error) {
+	if realm == "" {
+		realm = r.fallback
+		if at := strings.LastIndex(login, "@"); at >= 0 {
+			if name, ok := r.domains[strings.ToLower(strings.TrimSpace(login[at+1:]))]; ok {
+				realm = name
+			}
+		}
+	}
+
+	authenticator, ok := r.authenticators[realm]
+	if !ok {
+		return nil, ErrUnknownRealm
+	}
+	return authenticator.Authenticate(ctx, login, password, ip)
+}
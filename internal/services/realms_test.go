@@ -0,0 +1,51 @@
+package services
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/auth-service/internal/models"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRealms(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	local := NewMockAuthenticator(ctrl)
+	directory := NewMockAuthenticator(ctrl)
+	realms := NewRealms(RealmLocal, local)
+	realms.Add(RealmLDAP, directory, []string{"Corp.Example"})
+	ctx := context.Background()
+	userIP := net.ParseIP("192.168.1.1")
+
+	t.Run("Domain of the email", func(t *testing.T) {
+		directory.EXPECT().Authenticate(ctx, "ivan@CORP.example", "secret", userIP).Return(&models.User{ID: "user1"}, nil)
+
+		user, err := realms.Authenticate(ctx, "ivan@CORP.example", "secret", userIP)
+		require.NoError(t, err)
+		assert.Equal(t, "user1", user.ID)
+	})
+
+	t.Run("Fallback realm", func(t *testing.T) {
+		local.EXPECT().Authenticate(ctx, "user@example.com", "secret", userIP).Return(&models.User{ID: "user2"}, nil)
+
+		_, err := realms.Authenticate(ctx, "user@example.com", "secret", userIP)
+		require.NoError(t, err)
+	})
+
+	t.Run("Explicit realm", func(t *testing.T) {
+		directory.EXPECT().Authenticate(ctx, "ivan", "secret", userIP).Return(&models.User{ID: "user1"}, nil)
+
+		_, err := realms.AuthenticateRealm(ctx, RealmLDAP, "ivan", "secret", userIP)
+		require.NoError(t, err)
+	})
+
+	t.Run("Unknown realm", func(t *testing.T) {
+		_, err := realms.AuthenticateRealm(ctx, "kerberos", "ivan", "secret", userIP)
+		assert.ErrorIs(t, err, ErrUnknownRealm)
+	})
+}
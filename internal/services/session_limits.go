@@ -0,0 +1,158 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/auth-service/internal/models"
+)
+
+// SessionLimitPolicy is what happens to a login of a user who already has
+// the maximum number of sessions.
+type SessionLimitPolicy string
+
+const (
+	// SessionLimitEvictOldest ends the sessions opened first.
+	SessionLimitEvictOldest SessionLimitPolicy = "evict_oldest"
+	// SessionLimitEvictLRU ends the sessions refreshed least recently.
+	SessionLimitEvictLRU SessionLimitPolicy = "evict_lru"
+	// SessionLimitReject refuses the login.
+	SessionLimitReject SessionLimitPolicy = "reject"
+
+	AuditSessionEvicted = "session.evicted"
+)
+
+var ErrSessionLimit = errors.New("too many active sessions")
+
+// SessionLimits caps the sessions of a user. MaxPerUser counts all sessions
+// of the user, zero means no cap. Clients can cap their own sessions with
+// OAuthClient.MaxSessions.
+type SessionLimits struct {
+	MaxPerUser int
+	Policy     SessionLimitPolicy
+}
+
+// ParseSessionLimitPolicy returns the policy with the name, evict_oldest
+// when the name is empty.
+func ParseSessionLimitPolicy(name string) (SessionLimitPolicy, error) {
+	switch policy := SessionLimitPolicy(name); policy {
+	case "":
+		return SessionLimitEvictOldest, nil
+	case SessionLimitEvictOldest, SessionLimitEvictLRU, SessionLimitReject:
+		return policy, nil
+	}
+	return "", fmt.Errorf("unknown session limit policy %q", name)
+}
+
+// WithSessionLimits caps the number of sessions a user can have. Evictions
+// are recorded in the audit log.
+func WithSessionLimits(limits SessionLimits, audit *AuditLogger) AuthOption {
+	return func(s *AuthService) {
+		s.limits = limits
+		s.audit = audit
+	}
+}
+
+// enforceSessionLimits makes room for a new session of the grant. Sessions
+// over the cap of the user and over the cap of the client are evicted, or
+// ErrSessionLimit is returned when the policy rejects the login.
+func (s *AuthService) enforceSessionLimits(ctx context.Context, grant TokenGrant) error {
+	if s.limits.MaxPerUser <= 0 && grant.MaxSessions <= 0 {
+		return nil
+	}
+
+	tokens, err := s.repo.GetRefreshTokensByUser(ctx, TenantFromContext(ctx), grant.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to get user sessions: %w", err)
+	}
+
+	now := time.Now()
+	var active, client []models.RefreshToken
+	for _, token := range tokens {
+		if now.After(token.ExpiresAt) {
+			continue
+		}
+		active = append(active, token)
+		if token.ClientID == grant.ClientID {
+			client = append(client, token)
+		}
+	}
+
+	evict := map[string]models.RefreshToken{}
+	if err := s.pickEvictions(grant.MaxSessions, client, evict); err != nil {
+		return err
+	}
+	remaining := make([]models.RefreshToken, 0, len(active))
+	for _, token := range active {
+		if _, ok := evict[token.ID]; !ok {
+			remaining = append(remaining, token)
+		}
+	}
+	if err := s.pickEvictions(s.limits.MaxPerUser, remaining, evict); err != nil {
+		return err
+	}
+
+	for _, token := range evict {
+		s.evictSession(ctx, grant, token)
+	}
+	return nil
+}
+
+// sessionLimitError turns a rejected login into an error of the token
+// endpoint.
+func sessionLimitError(err error) error {
+	if errors.Is(err, ErrSessionLimit) {
+		return oauthError(OAuthInvalidGrant, "the user has too many active sessions")
+	}
+	return err
+}
+
+// pickEvictions adds to evict the sessions to end so that one more session
+// fits under max.
+func (s *AuthService) pickEvictions(max int, sessions []models.RefreshToken, evict map[string]models.RefreshToken) error {
+	if max <= 0 || len(sessions) < max {
+		return nil
+	}
+	if s.limits.Policy == SessionLimitReject {
+		return fmt.Errorf("%w: the limit is %d", ErrSessionLimit, max)
+	}
+
+	sorted := append([]models.RefreshToken{}, sessions...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if s.limits.Policy == SessionLimitEvictLRU {
+			return lastUsed(sorted[i]).Before(lastUsed(sorted[j]))
+		}
+		return sorted[i].CreatedAt.Before(sorted[j].CreatedAt)
+	})
+	for _, token := range sorted[:len(sorted)-max+1] {
+		evict[token.ID] = token
+	}
+	return nil
+}
+
+func (s *AuthService) evictSession(ctx context.Context, grant TokenGrant, token models.RefreshToken) {
+	if err := s.repo.DeleteRefreshToken(ctx, token.ID); err != nil {
+		log.Printf("Failed to evict session %s of user %s: %v", token.ID, token.UserID, err)
+		return
+	}
+
+	if s.audit != nil {
+		s.audit.Record(ctx, token.UserID, AuditSessionEvicted, grant.IP, map[string]string{
+			"session_id": token.ID,
+			"client_id":  token.ClientID,
+			"policy":     string(s.limits.Policy),
+		})
+	}
+
+	device := DeviceInfo{Device: token.Device, OS: token.OS, Browser: token.Browser}.String()
+	if token.DeviceName != "" {
+		device = "«" + token.DeviceName + "»"
+	}
+	msg := fmt.Sprintf("Сессия на устройстве %s (IP %s) завершена: достигнут лимит активных сессий",
+		device, formatIP(lastIPOf(token), lastLocationOf(token)))
+	s.notifier.SendSecurityAlert(token.UserID, msg)
+}
@@ -0,0 +1,115 @@
+package services
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/auth-service/internal/models"
+	"github.com/auth-service/internal/repository/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionLimits(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	mockNotifier := NewMockNotifier(ctrl)
+	tokenSvc := NewTokenService("test-secret")
+	auditLogger := NewAuditLogger(mockRepo)
+	ctx := context.Background()
+	ip := net.ParseIP("192.168.1.1")
+
+	mockRepo.EXPECT().SaveAuditEvent(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	now := time.Now()
+	recently := now.Add(-time.Minute)
+	sessions := []models.RefreshToken{
+		{ID: "first", UserID: "user1", CreatedAt: now.Add(-72 * time.Hour), LastUsedAt: &recently, ExpiresAt: now.Add(time.Hour)},
+		{ID: "idle", UserID: "user1", CreatedAt: now.Add(-48 * time.Hour), ExpiresAt: now.Add(time.Hour),
+			IP: "10.0.0.5", Browser: "Firefox", OS: "Windows"},
+		{ID: "spa", UserID: "user1", ClientID: "spa", CreatedAt: now.Add(-24 * time.Hour), ExpiresAt: now.Add(time.Hour)},
+		{ID: "expired", UserID: "user1", CreatedAt: now.Add(-96 * time.Hour), ExpiresAt: now.Add(-time.Hour)},
+	}
+
+	newService := func(policy SessionLimitPolicy, max int) *AuthService {
+		return NewAuthService(mockRepo, tokenSvc, mockNotifier,
+			WithSessionLimits(SessionLimits{MaxPerUser: max, Policy: policy}, auditLogger))
+	}
+
+	t.Run("Under the limit", func(t *testing.T) {
+		mockRepo.EXPECT().GetRefreshTokensByUser(ctx, DefaultTenant, "user1").Return(sessions, nil)
+		mockRepo.EXPECT().SaveRefreshToken(gomock.Any(), gomock.Any()).Return(nil)
+
+		_, err := newService(SessionLimitEvictOldest, 4).GenerateTokens(ctx, "user1", ip)
+		require.NoError(t, err)
+	})
+
+	t.Run("Evicts the oldest session", func(t *testing.T) {
+		mockRepo.EXPECT().GetRefreshTokensByUser(ctx, DefaultTenant, "user1").Return(sessions, nil)
+		mockRepo.EXPECT().DeleteRefreshToken(ctx, "first").Return(nil)
+		mockNotifier.EXPECT().SendSecurityAlert("user1", gomock.Any())
+		mockRepo.EXPECT().SaveRefreshToken(gomock.Any(), gomock.Any()).Return(nil)
+
+		_, err := newService(SessionLimitEvictOldest, 3).GenerateTokens(ctx, "user1", ip)
+		require.NoError(t, err)
+	})
+
+	t.Run("Evicts the least recently used session", func(t *testing.T) {
+		mockRepo.EXPECT().GetRefreshTokensByUser(ctx, DefaultTenant, "user1").Return(sessions, nil)
+		mockRepo.EXPECT().DeleteRefreshToken(ctx, "idle").Return(nil)
+		mockNotifier.EXPECT().
+			SendSecurityAlert("user1", gomock.Any()).
+			Do(func(_ string, msg string) {
+				assert.Contains(t, msg, "Firefox на Windows")
+				assert.Contains(t, msg, "10.0.0.5")
+			})
+		mockRepo.EXPECT().SaveRefreshToken(gomock.Any(), gomock.Any()).Return(nil)
+
+		_, err := newService(SessionLimitEvictLRU, 3).GenerateTokens(ctx, "user1", ip)
+		require.NoError(t, err)
+	})
+
+	t.Run("Rejects the login", func(t *testing.T) {
+		mockRepo.EXPECT().GetRefreshTokensByUser(ctx, DefaultTenant, "user1").Return(sessions, nil)
+
+		_, err := newService(SessionLimitReject, 3).GenerateTokens(ctx, "user1", ip)
+		assert.ErrorIs(t, err, ErrSessionLimit)
+	})
+
+	t.Run("Client limit counts the sessions of the client", func(t *testing.T) {
+		mockRepo.EXPECT().GetRefreshTokensByUser(ctx, DefaultTenant, "user1").Return(sessions, nil)
+		mockRepo.EXPECT().DeleteRefreshToken(ctx, "spa").Return(nil)
+		mockNotifier.EXPECT().SendSecurityAlert("user1", gomock.Any())
+		mockRepo.EXPECT().SaveRefreshToken(gomock.Any(), gomock.Any()).Return(nil)
+
+		_, err := newService(SessionLimitEvictOldest, 0).IssueTokens(ctx, TokenGrant{
+			UserID:      "user1",
+			ClientID:    "spa",
+			IP:          ip,
+			MaxSessions: 1,
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("Refresh is not limited", func(t *testing.T) {
+		mockRepo.EXPECT().RotateRefreshToken(gomock.Any(), "old-hash", gomock.Any()).Return(nil)
+
+		_, err := newService(SessionLimitReject, 1).issue(ctx, TokenGrant{UserID: "user1", IP: ip},
+			&models.RefreshToken{ID: "first", TokenHash: "old-hash"})
+		require.NoError(t, err)
+	})
+
+	t.Run("Unknown policy", func(t *testing.T) {
+		_, err := ParseSessionLimitPolicy("evict_random")
+		assert.Error(t, err)
+
+		policy, err := ParseSessionLimitPolicy("")
+		require.NoError(t, err)
+		assert.Equal(t, SessionLimitEvictOldest, policy)
+	})
+}
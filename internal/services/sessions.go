@@ -0,0 +1,220 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/auth-service/internal/models"
+	"github.com/auth-service/internal/repository"
+)
+
+const (
+	AuditSessionRevoked = "session.revoked"
+
+	DefaultSessionsPerPage = 20
+	MaxSessionsPerPage     = 100
+	maxUserAgentLength     = 512
+	maxDeviceNameLength    = 64
+)
+
+var ErrSessionNotFound = errors.New("session not found")
+
+type userAgentContextKey struct{}
+
+// WithUserAgent returns a context that carries the user agent of the
+// request, which is stored with the sessions issued for it.
+func WithUserAgent(ctx context.Context, userAgent string) context.Context {
+	if len(userAgent) > maxUserAgentLength {
+		userAgent = userAgent[:maxUserAgentLength]
+	}
+	return context.WithValue(ctx, userAgentContextKey{}, userAgent)
+}
+
+func UserAgentFromContext(ctx context.Context) string {
+	userAgent, _ := ctx.Value(userAgentContextKey{}).(string)
+	return userAgent
+}
+
+type deviceNameContextKey struct{}
+
+// WithDeviceName returns a context that carries the name the client gave
+// its device, "Work laptop".
+func WithDeviceName(ctx context.Context, name string) context.Context {
+	name = strings.TrimSpace(name)
+	if len(name) > maxDeviceNameLength {
+		name = strings.ToValidUTF8(name[:maxDeviceNameLength], "")
+	}
+	return context.WithValue(ctx, deviceNameContextKey{}, name)
+}
+
+func DeviceNameFromContext(ctx context.Context) string {
+	name, _ := ctx.Value(deviceNameContextKey{}).(string)
+	return name
+}
+
+// Session is a refresh token as shown to its owner.
+type Session struct {
+	ID     string `json:"id"`
+	IP     string `json:"ip"`
+	LastIP string `json:"last_ip"`
+	// Location and LastLocation are set when GeoIP is configured.
+	Location     *models.GeoLocation `json:"location,omitempty"`
+	LastLocation *models.GeoLocation `json:"last_location,omitempty"`
+	UserAgent    string              `json:"user_agent"`
+	DeviceName   string              `json:"device_name,omitempty"`
+	Device       string              `json:"device,omitempty"`
+	OS           string              `json:"os,omitempty"`
+	Browser      string              `json:"browser,omitempty"`
+	ClientID     string              `json:"client_id,omitempty"`
+	CreatedAt    time.Time           `json:"created_at"`
+	LastUsedAt   time.Time           `json:"last_used_at"`
+	ExpiresAt    time.Time           `json:"expires_at"`
+	// Current marks the session of the access token of the request.
+	Current bool `json:"current"`
+}
+
+type SessionPage struct {
+	Sessions []Session `json:"sessions"`
+	Total    int       `json:"total"`
+	Page     int       `json:"page"`
+	PerPage  int       `json:"per_page"`
+}
+
+// SessionService lists the sessions of a user and revokes single sessions.
+type SessionService struct {
+	repo   repository.Repository
+	access AccessProvider
+	audit  *AuditLogger
+}
+
+func NewSessionService(repo repository.Repository, access AccessProvider, audit *AuditLogger) *SessionService {
+	return &SessionService{repo: repo, access: access, audit: audit}
+}
+
+// List returns the active sessions of the user in the tenant of the request,
+// most recently used first. currentID is the session of the caller.
+func (s *SessionService) List(ctx context.Context, userID, currentID string, page, perPage int) (*SessionPage, error) {
+	if page < 1 {
+		page = 1
+	}
+	if perPage < 1 {
+		perPage = DefaultSessionsPerPage
+	}
+	if perPage > MaxSessionsPerPage {
+		perPage = MaxSessionsPerPage
+	}
+
+	tokens, err := s.repo.GetRefreshTokensByUser(ctx, TenantFromContext(ctx), userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sessions: %w", err)
+	}
+
+	now := time.Now()
+	sessions := make([]Session, 0, len(tokens))
+	for _, token := range tokens {
+		if now.After(token.ExpiresAt) {
+			continue
+		}
+		sessions = append(sessions, Session{
+			ID:           token.ID,
+			IP:           token.IP,
+			LastIP:       lastIPOf(token),
+			Location:     optionalLocation(token.Location),
+			LastLocation: optionalLocation(lastLocationOf(token)),
+			UserAgent:    token.UserAgent,
+			DeviceName:   token.DeviceName,
+			Device:       token.Device,
+			OS:           token.OS,
+			Browser:      token.Browser,
+			ClientID:     token.ClientID,
+			CreatedAt:    token.CreatedAt,
+			LastUsedAt:   lastUsed(token),
+			ExpiresAt:    token.ExpiresAt,
+			Current:      currentID != "" && token.ID == currentID,
+		})
+	}
+	sort.SliceStable(sessions, func(i, j int) bool {
+		return sessions[i].LastUsedAt.After(sessions[j].LastUsedAt)
+	})
+
+	result := &SessionPage{Sessions: []Session{}, Total: len(sessions), Page: page, PerPage: perPage}
+	if start := (page - 1) * perPage; start < len(sessions) {
+		end := start + perPage
+		if end > len(sessions) {
+			end = len(sessions)
+		}
+		result.Sessions = sessions[start:end]
+	}
+	return result, nil
+}
+
+// Revoke ends a session. Users can revoke their own sessions, admins the
+// sessions of every user of the tenant. Sessions the actor may not revoke
+// are reported as not found.
+func (s *SessionService) Revoke(ctx context.Context, actorID, sessionID string, ip net.IP) error {
+	token, err := s.repo.GetRefreshTokenByID(ctx, TenantFromContext(ctx), sessionID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ErrSessionNotFound
+		}
+		return fmt.Errorf("failed to get session: %w", err)
+	}
+
+	if token.UserID != actorID {
+		access, err := s.access.Access(ctx, actorID)
+		if err != nil {
+			return fmt.Errorf("failed to get user access: %w", err)
+		}
+		if !access.Can(PermissionAdmin) {
+			return ErrSessionNotFound
+		}
+	}
+
+	if err := s.repo.DeleteRefreshToken(ctx, token.ID); err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+
+	s.audit.Record(ctx, token.UserID, AuditSessionRevoked, ip, map[string]string{
+		"session_id": token.ID,
+		"revoked_by": actorID,
+	})
+	return nil
+}
+
+func optionalLocation(location models.GeoLocation) *models.GeoLocation {
+	if location.IsZero() {
+		return nil
+	}
+	return &location
+}
+
+// lastUsed is the time of the last refresh of the session, or of the login
+// when it was never refreshed.
+func lastUsed(token models.RefreshToken) time.Time {
+	if token.LastUsedAt != nil {
+		return *token.LastUsedAt
+	}
+	return token.CreatedAt
+}
+
+// lastIPOf is the IP of the last refresh of the session. Sessions opened
+// before last IPs were recorded fall back to the IP of the login.
+func lastIPOf(token models.RefreshToken) string {
+	if token.LastIP != "" {
+		return token.LastIP
+	}
+	return token.IP
+}
+
+// lastLocationOf is the location of lastIPOf.
+func lastLocationOf(token models.RefreshToken) models.GeoLocation {
+	if token.LastIP != "" {
+		return token.LastLocation
+	}
+	return token.Location
+}
@@ -0,0 +1,91 @@
+package services
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/auth-service/internal/models"
+	"github.com/auth-service/internal/repository"
+	"github.com/auth-service/internal/repository/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionService(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	auditLogger := NewAuditLogger(mockRepo)
+	sessionSvc := NewSessionService(mockRepo, NewRBACService(mockRepo, auditLogger, []string{"admin1"}), auditLogger)
+	ctx := context.Background()
+	ip := net.ParseIP("192.168.1.1")
+
+	mockRepo.EXPECT().SaveAuditEvent(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	now := time.Now()
+	earlier := now.Add(-time.Hour)
+	tokens := []models.RefreshToken{
+		{ID: "old", UserID: "user1", CreatedAt: now.Add(-48 * time.Hour), ExpiresAt: now.Add(time.Hour)},
+		{ID: "current", UserID: "user1", CreatedAt: now.Add(-24 * time.Hour), LastUsedAt: &now, ExpiresAt: now.Add(time.Hour)},
+		{ID: "laptop", UserID: "user1", CreatedAt: now.Add(-24 * time.Hour), LastUsedAt: &earlier, ExpiresAt: now.Add(time.Hour)},
+		{ID: "expired", UserID: "user1", CreatedAt: now.Add(-24 * time.Hour), ExpiresAt: now.Add(-time.Hour)},
+	}
+
+	t.Run("List sorts by last use and marks the current session", func(t *testing.T) {
+		mockRepo.EXPECT().GetRefreshTokensByUser(ctx, DefaultTenant, "user1").Return(tokens, nil)
+
+		page, err := sessionSvc.List(ctx, "user1", "current", 1, 2)
+		require.NoError(t, err)
+		assert.Equal(t, 3, page.Total)
+		require.Len(t, page.Sessions, 2)
+		assert.Equal(t, "current", page.Sessions[0].ID)
+		assert.True(t, page.Sessions[0].Current)
+		assert.Equal(t, "laptop", page.Sessions[1].ID)
+		assert.False(t, page.Sessions[1].Current)
+	})
+
+	t.Run("List past the last page", func(t *testing.T) {
+		mockRepo.EXPECT().GetRefreshTokensByUser(ctx, DefaultTenant, "user1").Return(tokens, nil)
+
+		page, err := sessionSvc.List(ctx, "user1", "", 3, 2)
+		require.NoError(t, err)
+		assert.Empty(t, page.Sessions)
+		assert.NotNil(t, page.Sessions)
+	})
+
+	t.Run("Owner revokes a session", func(t *testing.T) {
+		mockRepo.EXPECT().GetRefreshTokenByID(ctx, DefaultTenant, "laptop").Return(&tokens[2], nil)
+		mockRepo.EXPECT().DeleteRefreshToken(ctx, "laptop").Return(nil)
+
+		require.NoError(t, sessionSvc.Revoke(ctx, "user1", "laptop", ip))
+	})
+
+	t.Run("Session of another user", func(t *testing.T) {
+		mockRepo.EXPECT().GetRefreshTokenByID(ctx, DefaultTenant, "laptop").Return(&tokens[2], nil)
+		mockRepo.EXPECT().GetUserRoles(ctx, "user2").Return(nil, nil)
+		mockRepo.EXPECT().GetTokenCutoff(ctx, "user2").Return(time.Time{}, nil)
+
+		err := sessionSvc.Revoke(ctx, "user2", "laptop", ip)
+		assert.ErrorIs(t, err, ErrSessionNotFound)
+	})
+
+	t.Run("Admin revokes a session of another user", func(t *testing.T) {
+		mockRepo.EXPECT().GetRefreshTokenByID(ctx, DefaultTenant, "laptop").Return(&tokens[2], nil)
+		mockRepo.EXPECT().GetUserRoles(ctx, "admin1").Return(nil, nil)
+		mockRepo.EXPECT().GetTokenCutoff(ctx, "admin1").Return(time.Time{}, nil)
+		mockRepo.EXPECT().DeleteRefreshToken(ctx, "laptop").Return(nil)
+
+		require.NoError(t, sessionSvc.Revoke(ctx, "admin1", "laptop", ip))
+	})
+
+	t.Run("Unknown session", func(t *testing.T) {
+		mockRepo.EXPECT().GetRefreshTokenByID(ctx, DefaultTenant, "missing").Return(nil, repository.ErrNotFound)
+
+		err := sessionSvc.Revoke(ctx, "user1", "missing", ip)
+		assert.ErrorIs(t, err, ErrSessionNotFound)
+	})
+}
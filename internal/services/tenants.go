@@ -0,0 +1,149 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/auth-service/internal/repository"
+)
+
+const (
+	// DefaultTenant owns requests that name no tenant and the data created
+	// before tenants were configured.
+	DefaultTenant = "default"
+
+	// TenantHeader names the tenant of a request explicitly.
+	TenantHeader = "X-Tenant-ID"
+	// tenantPathPrefix names the tenant in the path: /t/<tenant>/auth/...
+	tenantPathPrefix = "/t/"
+)
+
+var ErrUnknownTenant = errors.New("unknown tenant")
+
+// Tenant is a customer hosted on the deployment. Zero token lifetimes fall
+// back to the service defaults.
+type Tenant struct {
+	ID              string
+	Hosts           []string
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+}
+
+type tenantContextKey struct{}
+
+// WithTenant returns a context that carries the tenant of the request.
+func WithTenant(ctx context.Context, tenantID string) context.Context {
+	return context.WithValue(ctx, tenantContextKey{}, tenantID)
+}
+
+// TenantFromContext returns the tenant of the request, or DefaultTenant.
+func TenantFromContext(ctx context.Context) string {
+	if tenantID, ok := ctx.Value(tenantContextKey{}).(string); ok && tenantID != "" {
+		return tenantID
+	}
+	return DefaultTenant
+}
+
+type tenantPathContextKey struct{}
+
+// WithTenantPath returns a context that carries the /t/<tenant> prefix the
+// request path started with.
+func WithTenantPath(ctx context.Context, prefix string) context.Context {
+	return context.WithValue(ctx, tenantPathContextKey{}, prefix)
+}
+
+// TenantPath returns path under the prefix the request named its tenant
+// with, for form actions and cookie paths of the pages served. Requests
+// that named their tenant by header or host get path as it is.
+func TenantPath(ctx context.Context, path string) string {
+	prefix, _ := ctx.Value(tenantPathContextKey{}).(string)
+	return prefix + path
+}
+
+// TenantURL returns baseURL with the path prefix of the tenant of the
+// request, so links sent to users lead back to their tenant.
+func TenantURL(ctx context.Context, baseURL string) string {
+	if tenantID := TenantFromContext(ctx); tenantID != DefaultTenant {
+		return baseURL + tenantPathPrefix + url.PathEscape(tenantID)
+	}
+	return baseURL
+}
+
+// Tenants resolves the tenant of a request from its path, header or host.
+type Tenants struct {
+	byID   map[string]*Tenant
+	byHost map[string]*Tenant
+	users  repository.UserRepository
+}
+
+func NewTenants(tenants []Tenant, users repository.UserRepository) *Tenants {
+	t := &Tenants{
+		byID:   map[string]*Tenant{DefaultTenant: {ID: DefaultTenant}},
+		byHost: make(map[string]*Tenant),
+		users:  users,
+	}
+	for i := range tenants {
+		tenant := &tenants[i]
+		t.byID[tenant.ID] = tenant
+		for _, host := range tenant.Hosts {
+			t.byHost[strings.ToLower(host)] = tenant
+		}
+	}
+	return t
+}
+
+func (t *Tenants) Get(id string) (*Tenant, bool) {
+	tenant, ok := t.byID[id]
+	return tenant, ok
+}
+
+// Resolve finds the tenant of a request. A /t/<tenant> path prefix wins over
+// the header, the header over the host; other requests belong to the default
+// tenant. The returned path has the tenant prefix removed.
+func (t *Tenants) Resolve(host, header, path string) (*Tenant, string, error) {
+	if rest, ok := strings.CutPrefix(path, tenantPathPrefix); ok {
+		id, rest, _ := strings.Cut(rest, "/")
+		tenant, ok := t.byID[id]
+		if !ok {
+			return nil, path, ErrUnknownTenant
+		}
+		return tenant, "/" + rest, nil
+	}
+
+	if header != "" {
+		tenant, ok := t.byID[header]
+		if !ok {
+			return nil, path, ErrUnknownTenant
+		}
+		return tenant, path, nil
+	}
+
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	if tenant, ok := t.byHost[strings.ToLower(host)]; ok {
+		return tenant, path, nil
+	}
+	return t.byID[DefaultTenant], path, nil
+}
+
+// OwnsUser reports whether the user belongs to the tenant of the request.
+// IDs without an account, such as those passed to /auth/tokens, belong to
+// the default tenant.
+func (t *Tenants) OwnsUser(ctx context.Context, userID string) (bool, error) {
+	tenantID := TenantFromContext(ctx)
+
+	user, err := t.users.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return tenantID == DefaultTenant, nil
+		}
+		return false, fmt.Errorf("failed to get user: %w", err)
+	}
+	return user.TenantID == tenantID, nil
+}
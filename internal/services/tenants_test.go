@@ -0,0 +1,78 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/auth-service/internal/models"
+	"github.com/auth-service/internal/repository"
+	"github.com/auth-service/internal/repository/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTenants(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	tenants := NewTenants([]Tenant{
+		{ID: "acme", Hosts: []string{"auth.acme.example"}, AccessTokenTTL: 5 * time.Minute},
+		{ID: "globex"},
+	}, mockRepo)
+
+	t.Run("Resolve", func(t *testing.T) {
+		tests := []struct {
+			name, host, header, path string
+			tenant, rest             string
+		}{
+			{"Default", "localhost:8081", "", "/auth/refresh", DefaultTenant, "/auth/refresh"},
+			{"Host", "Auth.Acme.Example:443", "", "/auth/refresh", "acme", "/auth/refresh"},
+			{"Header wins over host", "auth.acme.example", "globex", "/auth/refresh", "globex", "/auth/refresh"},
+			{"Path wins over header", "localhost", "acme", "/t/globex/auth/refresh", "globex", "/auth/refresh"},
+			{"Path without rest", "localhost", "", "/t/acme", "acme", "/"},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				tenant, rest, err := tenants.Resolve(tt.host, tt.header, tt.path)
+				require.NoError(t, err)
+				assert.Equal(t, tt.tenant, tenant.ID)
+				assert.Equal(t, tt.rest, rest)
+			})
+		}
+	})
+
+	t.Run("Unknown tenant", func(t *testing.T) {
+		_, _, err := tenants.Resolve("localhost", "initech", "/auth/refresh")
+		assert.ErrorIs(t, err, ErrUnknownTenant)
+		_, _, err = tenants.Resolve("localhost", "", "/t/initech/auth/refresh")
+		assert.ErrorIs(t, err, ErrUnknownTenant)
+	})
+
+	t.Run("TenantURL", func(t *testing.T) {
+		ctx := context.Background()
+		assert.Equal(t, "https://auth.example", TenantURL(ctx, "https://auth.example"))
+		assert.Equal(t, "https://auth.example/t/acme", TenantURL(WithTenant(ctx, "acme"), "https://auth.example"))
+	})
+
+	t.Run("OwnsUser", func(t *testing.T) {
+		acme := WithTenant(context.Background(), "acme")
+		mockRepo.EXPECT().GetUserByID(acme, "user1").Return(&models.User{ID: "user1", TenantID: "acme"}, nil)
+		mockRepo.EXPECT().GetUserByID(acme, "user2").Return(&models.User{ID: "user2", TenantID: "globex"}, nil)
+		mockRepo.EXPECT().GetUserByID(acme, "bare").Return(nil, repository.ErrNotFound)
+
+		owned, err := tenants.OwnsUser(acme, "user1")
+		require.NoError(t, err)
+		assert.True(t, owned)
+
+		owned, err = tenants.OwnsUser(acme, "user2")
+		require.NoError(t, err)
+		assert.False(t, owned)
+
+		owned, err = tenants.OwnsUser(acme, "bare")
+		require.NoError(t, err)
+		assert.False(t, owned)
+	})
+}
@@ -1,60 +1,176 @@
 package services
 
 import (
+	"context"
 	"crypto/rand"
 	"encoding/base64"
 	"errors"
 	"fmt"
 	"net"
+	"strings"
 	"time"
 
+	"github.com/auth-service/internal/repository"
 	"github.com/golang-jwt/jwt/v4"
 )
 
 var ErrInvalidToken = errors.New("invalid token")
 
+const (
+	DefaultAccessTokenTTL = 15 * time.Minute
+	// MFAChallengeTTL is how long the second factor of a login, or of a
+	// step-up, can be entered after the challenge.
+	MFAChallengeTTL = 5 * time.Minute
+
+	// mfaChallengeAudience marks tokens that only prove the first factor
+	// of a login. They are never accepted as access tokens.
+	mfaChallengeAudience = "mfa_challenge"
+)
+
 type TokenClaims struct {
-	UserID string `json:"user_id"`
-	IP     string `json:"ip"`
+	TenantID string   `json:"tid,omitempty"`
+	UserID   string   `json:"user_id"`
+	IP       string   `json:"ip"`
+	ClientID string   `json:"client_id,omitempty"`
+	Scope    string   `json:"scope,omitempty"`
+	Roles    []string `json:"roles,omitempty"`
+	// Permissions come from the roles assigned in the service.
+	Permissions []string `json:"permissions,omitempty"`
+	// OrgID is the active organization, OrgRole and Teams the membership of
+	// the user in it.
+	OrgID   string   `json:"org_id,omitempty"`
+	OrgRole string   `json:"org_role,omitempty"`
+	Teams   []string `json:"teams,omitempty"`
+	// SessionID is the refresh token session the token was issued with.
+	SessionID string `json:"sid,omitempty"`
+	// Actor is set on tokens an admin uses to act as the user.
+	Actor *ActorClaim `json:"act,omitempty"`
 	jwt.RegisteredClaims
 }
 
+// ActorClaim names the party acting on behalf of the subject (RFC 8693).
+type ActorClaim struct {
+	Subject string `json:"sub"`
+}
+
 type TokenService struct {
-	secretKey []byte
+	secretKey  []byte
+	tenantKeys map[string][]byte
+	denylist   repository.RevokedTokenRepository
 }
 
 func NewTokenService(secret string) *TokenService {
-	return &TokenService{secretKey: []byte(secret)}
+	return &TokenService{
+		secretKey:  []byte(secret),
+		tenantKeys: make(map[string][]byte),
+	}
+}
+
+// AddTenantKey makes tokens of the tenant be signed and verified with their
+// own secret. It has to be called before the service is used.
+func (s *TokenService) AddTenantKey(tenantID, secret string) {
+	s.tenantKeys[tenantID] = []byte(secret)
+}
+
+// SetDenylist makes IsRevoked check access tokens against the denylist. It
+// has to be called before the service is used.
+func (s *TokenService) SetDenylist(denylist repository.RevokedTokenRepository) {
+	s.denylist = denylist
+}
+
+// IsRevoked tells whether the access token was revoked before it expired,
+// on logout. Without a denylist no token is revoked.
+func (s *TokenService) IsRevoked(ctx context.Context, claims *TokenClaims) (bool, error) {
+	if s.denylist == nil || claims.ID == "" {
+		return false, nil
+	}
+	return s.denylist.IsAccessTokenRevoked(ctx, claims.ID)
+}
+
+func (s *TokenService) keyFor(tenantID string) []byte {
+	if key, ok := s.tenantKeys[tenantID]; ok {
+		return key
+	}
+	return s.secretKey
 }
 
 func (s *TokenService) GenerateAccessToken(userID string, ip net.IP) (string, error) {
-	claims := TokenClaims{
+	return s.SignAccessToken(TokenClaims{
 		UserID: userID,
 		IP:     ip.String(),
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(15 * time.Minute)),
-		},
+	}, DefaultAccessTokenTTL)
+}
+
+// SignAccessToken signs claims as an access token that is valid for ttl.
+func (s *TokenService) SignAccessToken(claims TokenClaims, ttl time.Duration) (string, error) {
+	if claims.ID == "" {
+		jti, err := generateSecureToken(16)
+		if err != nil {
+			return "", fmt.Errorf("failed to generate token id: %w", err)
+		}
+		claims.ID = strings.TrimRight(jti, "=")
 	}
+	now := time.Now()
+	claims.IssuedAt = jwt.NewNumericDate(now)
+	claims.ExpiresAt = jwt.NewNumericDate(now.Add(ttl))
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS512, claims)
-	return token.SignedString(s.secretKey)
+	return token.SignedString(s.keyFor(claims.TenantID))
 }
 
 func (s *TokenService) GenerateRefreshToken() (string, error) {
-	b := make([]byte, 32)
-	_, err := rand.Read(b)
+	return generateSecureToken(32)
+}
+
+func (s *TokenService) ParseAccessToken(tokenString string) (*TokenClaims, error) {
+	claims, err := s.parse(tokenString)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	return base64.URLEncoding.EncodeToString(b), nil
+	if claims.VerifyAudience(mfaChallengeAudience, true) {
+		return nil, ErrInvalidToken
+	}
+	return claims, nil
 }
 
-func (s *TokenService) ParseAccessToken(tokenString string) (*TokenClaims, error) {
+// SignMFAChallenge signs a token that lets the user finish a login with a
+// second factor within MFAChallengeTTL. Roles are kept for the token pair
+// issued then.
+func (s *TokenService) SignMFAChallenge(tenantID, userID string, roles []string) (string, error) {
+	return s.signMFAChallenge(TokenClaims{TenantID: tenantID, UserID: userID, Roles: roles})
+}
+
+// SignStepUpChallenge signs a challenge that lets the user keep the session
+// from the new ip once the second factor is entered from there.
+func (s *TokenService) SignStepUpChallenge(tenantID, userID, sessionID string, ip net.IP) (string, error) {
+	return s.signMFAChallenge(TokenClaims{TenantID: tenantID, UserID: userID, IP: ip.String(), SessionID: sessionID})
+}
+
+func (s *TokenService) signMFAChallenge(claims TokenClaims) (string, error) {
+	claims.Audience = jwt.ClaimStrings{mfaChallengeAudience}
+	return s.SignAccessToken(claims, MFAChallengeTTL)
+}
+
+// ParseMFAChallenge verifies a token signed by SignMFAChallenge.
+func (s *TokenService) ParseMFAChallenge(tokenString string) (*TokenClaims, error) {
+	claims, err := s.parse(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if !claims.VerifyAudience(mfaChallengeAudience, true) {
+		return nil, ErrInvalidToken
+	}
+	return claims, nil
+}
+
+func (s *TokenService) parse(tokenString string) (*TokenClaims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &TokenClaims{}, func(token *jwt.Token) (interface{}, error) {
 		if token.Method.Alg() != jwt.SigningMethodHS512.Alg() {
 			return nil, ErrInvalidToken
 		}
-		return s.secretKey, nil
+		// The tenant claim is not trusted yet, it only picks the key that
+		// has to verify it.
+		return s.keyFor(token.Claims.(*TokenClaims).TenantID), nil
 	})
 
 	if err != nil {
@@ -67,3 +183,12 @@ func (s *TokenService) ParseAccessToken(tokenString string) (*TokenClaims, error
 
 	return nil, ErrInvalidToken
 }
+
+func generateSecureToken(size int) (string, error) {
+	b := make([]byte, size)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+	return base64.URLEncoding.EncodeToString(b), nil
+}
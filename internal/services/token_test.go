@@ -3,6 +3,7 @@ package services_test
 import (
 	"net"
 	"testing"
+	"time"
 
 	"github.com/auth-service/internal/services"
 	"github.com/stretchr/testify/assert"
@@ -28,6 +29,52 @@ func TestTokenService(t *testing.T) {
 		assert.Equal(t, userIP.String(), claims.IP)
 	})
 
+	t.Run("Tenant signing keys", func(t *testing.T) {
+		tenantTokens := services.NewTokenService("test-secret")
+		tenantTokens.AddTenantKey("acme", "acme-secret")
+
+		token, err := tenantTokens.SignAccessToken(services.TokenClaims{TenantID: "acme", UserID: "user1"}, time.Minute)
+		require.NoError(t, err)
+
+		claims, err := tenantTokens.ParseAccessToken(token)
+		require.NoError(t, err)
+		assert.Equal(t, "acme", claims.TenantID)
+
+		// A token signed with the shared key cannot claim the tenant.
+		forged, err := ts.SignAccessToken(services.TokenClaims{TenantID: "acme", UserID: "user1"}, time.Minute)
+		require.NoError(t, err)
+		_, err = tenantTokens.ParseAccessToken(forged)
+		assert.Error(t, err)
+	})
+
+	t.Run("MFA challenges are not access tokens", func(t *testing.T) {
+		challenge, err := ts.SignMFAChallenge("default", "user1", nil)
+		require.NoError(t, err)
+
+		claims, err := ts.ParseMFAChallenge(challenge)
+		require.NoError(t, err)
+		assert.Equal(t, "user1", claims.UserID)
+
+		_, err = ts.ParseAccessToken(challenge)
+		assert.ErrorIs(t, err, services.ErrInvalidToken)
+
+		access, _ := ts.GenerateAccessToken("user1", userIP)
+		_, err = ts.ParseMFAChallenge(access)
+		assert.ErrorIs(t, err, services.ErrInvalidToken)
+	})
+
+	t.Run("Tokens get a unique ID", func(t *testing.T) {
+		first, _ := ts.GenerateAccessToken("user1", userIP)
+		second, _ := ts.GenerateAccessToken("user1", userIP)
+
+		firstClaims, err := ts.ParseAccessToken(first)
+		require.NoError(t, err)
+		secondClaims, err := ts.ParseAccessToken(second)
+		require.NoError(t, err)
+		assert.NotEmpty(t, firstClaims.ID)
+		assert.NotEqual(t, firstClaims.ID, secondClaims.ID)
+	})
+
 	t.Run("GenerateRefreshToken", func(t *testing.T) {
 		token1, err := ts.GenerateRefreshToken()
 		require.NoError(t, err)
@@ -0,0 +1,121 @@
+package services
+
+import (
+	"strings"
+)
+
+// Device types reported by ParseUserAgent.
+const (
+	DeviceDesktop = "desktop"
+	DeviceMobile  = "mobile"
+	DeviceTablet  = "tablet"
+	DeviceBot     = "bot"
+)
+
+// DeviceInfo is what a user agent tells about the device of a session.
+// Fields that cannot be told are empty.
+type DeviceInfo struct {
+	Device  string
+	OS      string
+	Browser string
+}
+
+// userAgentToken maps a product token of a user agent to a name. Tokens are
+// checked in order, so browsers built on Chrome come before Chrome itself.
+type userAgentToken struct {
+	token string
+	name  string
+}
+
+var (
+	browserTokens = []userAgentToken{
+		{"edg/", "Edge"},
+		{"edga/", "Edge"},
+		{"edgios/", "Edge"},
+		{"opr/", "Opera"},
+		{"yabrowser/", "Yandex Browser"},
+		{"samsungbrowser/", "Samsung Internet"},
+		{"firefox/", "Firefox"},
+		{"fxios/", "Firefox"},
+		{"crios/", "Chrome"},
+		{"chrome/", "Chrome"},
+		{"version/", "Safari"},
+		{"curl/", "curl"},
+		{"wget/", "Wget"},
+		{"okhttp/", "OkHttp"},
+		{"go-http-client/", "Go HTTP client"},
+		{"python-requests/", "Python Requests"},
+	}
+	osTokens = []userAgentToken{
+		{"windows phone", "Windows Phone"},
+		{"windows", "Windows"},
+		{"iphone", "iOS"},
+		{"ipad", "iPadOS"},
+		{"ipod", "iOS"},
+		{"android", "Android"},
+		{"cros", "ChromeOS"},
+		{"mac os x", "macOS"},
+		{"macintosh", "macOS"},
+		{"linux", "Linux"},
+	}
+	botTokens = []string{"bot", "crawler", "spider"}
+)
+
+// ParseUserAgent tells the device, OS and browser from a User-Agent header.
+// It knows the common browsers only, which is enough to describe a session
+// to its owner.
+func ParseUserAgent(userAgent string) DeviceInfo {
+	ua := strings.ToLower(userAgent)
+	if ua == "" {
+		return DeviceInfo{}
+	}
+
+	var info DeviceInfo
+	for _, t := range osTokens {
+		if strings.Contains(ua, t.token) {
+			info.OS = t.name
+			break
+		}
+	}
+	for _, t := range browserTokens {
+		if strings.Contains(ua, t.token) {
+			info.Browser = t.name
+			break
+		}
+	}
+
+	switch {
+	case containsAny(ua, botTokens):
+		info.Device = DeviceBot
+	case info.OS == "iPadOS" || strings.Contains(ua, "tablet") ||
+		(info.OS == "Android" && !strings.Contains(ua, "mobile")):
+		info.Device = DeviceTablet
+	case strings.Contains(ua, "mobi") || info.OS == "iOS" || info.OS == "Windows Phone":
+		info.Device = DeviceMobile
+	case info.OS != "":
+		info.Device = DeviceDesktop
+	}
+	return info
+}
+
+// String describes the device for notifications, "Chrome на Linux".
+func (d DeviceInfo) String() string {
+	switch {
+	case d.Browser != "" && d.OS != "":
+		return d.Browser + " на " + d.OS
+	case d.Browser != "":
+		return d.Browser
+	case d.OS != "":
+		return d.OS
+	}
+	return "неизвестное устройство"
+}
+
+func containsAny(s string, substrings []string) bool {
+	for _, sub := range substrings {
+		if strings.Contains(s, sub) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,72 @@
+package services_test
+
+import (
+	"testing"
+
+	"github.com/auth-service/internal/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseUserAgent(t *testing.T) {
+	tests := []struct {
+		name      string
+		userAgent string
+		want      services.DeviceInfo
+		described string
+	}{
+		{
+			name:      "Chrome on Linux",
+			userAgent: "Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36",
+			want:      services.DeviceInfo{Device: services.DeviceDesktop, OS: "Linux", Browser: "Chrome"},
+			described: "Chrome на Linux",
+		},
+		{
+			name:      "Edge on Windows",
+			userAgent: "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36 Edg/124.0.2478.51",
+			want:      services.DeviceInfo{Device: services.DeviceDesktop, OS: "Windows", Browser: "Edge"},
+			described: "Edge на Windows",
+		},
+		{
+			name:      "Safari on iPhone",
+			userAgent: "Mozilla/5.0 (iPhone; CPU iPhone OS 17_4 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.4 Mobile/15E148 Safari/604.1",
+			want:      services.DeviceInfo{Device: services.DeviceMobile, OS: "iOS", Browser: "Safari"},
+			described: "Safari на iOS",
+		},
+		{
+			name:      "Android tablet",
+			userAgent: "Mozilla/5.0 (Linux; Android 14; SM-X710) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36",
+			want:      services.DeviceInfo{Device: services.DeviceTablet, OS: "Android", Browser: "Chrome"},
+			described: "Chrome на Android",
+		},
+		{
+			name:      "Firefox on macOS",
+			userAgent: "Mozilla/5.0 (Macintosh; Intel Mac OS X 14.4; rv:125.0) Gecko/20100101 Firefox/125.0",
+			want:      services.DeviceInfo{Device: services.DeviceDesktop, OS: "macOS", Browser: "Firefox"},
+			described: "Firefox на macOS",
+		},
+		{
+			name:      "Bot",
+			userAgent: "Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)",
+			want:      services.DeviceInfo{Device: services.DeviceBot},
+			described: "неизвестное устройство",
+		},
+		{
+			name:      "Command line client",
+			userAgent: "curl/8.0",
+			want:      services.DeviceInfo{Browser: "curl"},
+			described: "curl",
+		},
+		{
+			name:      "Empty",
+			described: "неизвестное устройство",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := services.ParseUserAgent(tt.userAgent)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.described, got.String())
+		})
+	}
+}
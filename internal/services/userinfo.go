@@ -0,0 +1,61 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/auth-service/internal/repository"
+)
+
+const (
+	ScopeProfile = "profile"
+	ScopeEmail   = "email"
+)
+
+var ErrUserNotFound = errors.New("user not found")
+
+type UserInfoService struct {
+	repo repository.UserRepository
+}
+
+func NewUserInfoService(repo repository.UserRepository) *UserInfoService {
+	return &UserInfoService{repo: repo}
+}
+
+// UserInfo returns the OpenID Connect claims of the user that the granted
+// scope allows to release, OIDC Core section 5.4.
+func (s *UserInfoService) UserInfo(ctx context.Context, userID, scope string) (map[string]interface{}, error) {
+	user, err := s.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	claims := map[string]interface{}{"sub": user.ID}
+	scopes := strings.Fields(scope)
+	if hasString(scopes, ScopeProfile) {
+		if user.Name != "" {
+			claims["name"] = user.Name
+		}
+		claims["preferred_username"] = user.Email
+		if user.Locale != "" {
+			claims["locale"] = user.Locale
+		}
+		if user.Timezone != "" {
+			claims["zoneinfo"] = user.Timezone
+		}
+		if user.AvatarURL != "" {
+			claims["picture"] = user.AvatarURL
+		}
+		claims["updated_at"] = user.UpdatedAt.Unix()
+	}
+	if hasString(scopes, ScopeEmail) {
+		claims["email"] = user.Email
+		claims["email_verified"] = user.EmailVerified
+	}
+	return claims, nil
+}
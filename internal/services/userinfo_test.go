@@ -0,0 +1,70 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/auth-service/internal/models"
+	"github.com/auth-service/internal/repository"
+	"github.com/auth-service/internal/repository/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserInfoService(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	userInfoSvc := NewUserInfoService(mockRepo)
+	ctx := context.Background()
+
+	user := &models.User{
+		ID:            "user1",
+		Email:         "user@example.com",
+		Name:          "Иван Петров",
+		EmailVerified: true,
+		UpdatedAt:     time.Unix(1700000000, 0),
+	}
+
+	t.Run("openid only", func(t *testing.T) {
+		mockRepo.EXPECT().GetUserByID(ctx, "user1").Return(user, nil)
+
+		claims, err := userInfoSvc.UserInfo(ctx, "user1", "openid")
+		require.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{"sub": "user1"}, claims)
+	})
+
+	t.Run("profile and email", func(t *testing.T) {
+		mockRepo.EXPECT().GetUserByID(ctx, "user1").Return(user, nil)
+
+		claims, err := userInfoSvc.UserInfo(ctx, "user1", "openid profile email")
+		require.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{
+			"sub":                "user1",
+			"name":               "Иван Петров",
+			"preferred_username": "user@example.com",
+			"updated_at":         int64(1700000000),
+			"email":              "user@example.com",
+			"email_verified":     true,
+		}, claims)
+	})
+
+	t.Run("email only", func(t *testing.T) {
+		mockRepo.EXPECT().GetUserByID(ctx, "user1").Return(user, nil)
+
+		claims, err := userInfoSvc.UserInfo(ctx, "user1", "openid email")
+		require.NoError(t, err)
+		assert.NotContains(t, claims, "name")
+		assert.Equal(t, "user@example.com", claims["email"])
+	})
+
+	t.Run("Unknown user", func(t *testing.T) {
+		mockRepo.EXPECT().GetUserByID(ctx, "gone").Return(nil, repository.ErrNotFound)
+
+		_, err := userInfoSvc.UserInfo(ctx, "gone", "openid")
+		assert.ErrorIs(t, err, ErrUserNotFound)
+	})
+}
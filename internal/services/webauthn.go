@@ -0,0 +1,353 @@
+package services
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"time"
+
+	"github.com/auth-service/internal/models"
+	"github.com/auth-service/internal/repository"
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+)
+
+const (
+	AuditWebAuthnRegistered = "webauthn.credential_registered"
+	AuditWebAuthnLogin      = "webauthn.login"
+	AuditWebAuthnCloned     = "webauthn.clone_detected"
+
+	webauthnCeremonyRegistration = "registration"
+	webauthnCeremonyLogin        = "login"
+	webauthnSessionTTL           = 5 * time.Minute
+)
+
+var (
+	ErrWebAuthnSessionNotFound = errors.New("webauthn session not found or expired")
+	ErrWebAuthnNoCredentials   = errors.New("no webauthn credentials registered")
+	ErrWebAuthnVerification    = errors.New("webauthn verification failed")
+	ErrWebAuthnCloneDetected   = errors.New("webauthn authenticator may be cloned")
+)
+
+type WebAuthnService struct {
+	webAuthn *webauthn.WebAuthn
+	repo     repository.WebAuthnRepository
+	audit    *AuditLogger
+	notifier Notifier
+	lockout  *LockoutService
+}
+
+func NewWebAuthnService(
+	webAuthn *webauthn.WebAuthn,
+	repo repository.WebAuthnRepository,
+	audit *AuditLogger,
+	notifier Notifier,
+	lockout *LockoutService,
+) *WebAuthnService {
+	return &WebAuthnService{
+		webAuthn: webAuthn,
+		repo:     repo,
+		audit:    audit,
+		notifier: notifier,
+		lockout:  lockout,
+	}
+}
+
+// BeginRegistration starts a registration ceremony for an authenticated user
+// and returns the creation options together with the ceremony session ID.
+func (s *WebAuthnService) BeginRegistration(ctx context.Context, userID string) (*protocol.CredentialCreation, string, error) {
+	user, err := s.loadUser(ctx, userID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	exclusions := make([]protocol.CredentialDescriptor, 0, len(user.credentials))
+	for _, credential := range user.credentials {
+		exclusions = append(exclusions, credential.Descriptor())
+	}
+
+	creation, sessionData, err := s.webAuthn.BeginRegistration(user,
+		webauthn.WithExclusions(exclusions),
+		webauthn.WithResidentKeyRequirement(protocol.ResidentKeyRequirementPreferred),
+	)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to begin webauthn registration: %w", err)
+	}
+
+	sessionID, err := s.saveSession(ctx, userID, webauthnCeremonyRegistration, sessionData)
+	if err != nil {
+		return nil, "", err
+	}
+	return creation, sessionID, nil
+}
+
+func (s *WebAuthnService) FinishRegistration(ctx context.Context, userID, sessionID string, response []byte, ip net.IP) (*models.WebAuthnCredential, error) {
+	sessionData, err := s.takeSession(ctx, sessionID, webauthnCeremonyRegistration)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.loadUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	parsed, err := protocol.ParseCredentialCreationResponseBytes(response)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrWebAuthnVerification, err)
+	}
+
+	credential, err := s.webAuthn.CreateCredential(user, *sessionData, parsed)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrWebAuthnVerification, err)
+	}
+
+	transports := make([]string, 0, len(credential.Transport))
+	for _, transport := range credential.Transport {
+		transports = append(transports, string(transport))
+	}
+
+	stored := &models.WebAuthnCredential{
+		TenantID:        TenantFromContext(ctx),
+		UserID:          userID,
+		CredentialID:    credential.ID,
+		PublicKey:       credential.PublicKey,
+		AttestationType: credential.AttestationType,
+		AAGUID:          credential.Authenticator.AAGUID,
+		SignCount:       credential.Authenticator.SignCount,
+		Transports:      transports,
+		BackupEligible:  credential.Flags.BackupEligible,
+		BackupState:     credential.Flags.BackupState,
+	}
+	if err := s.repo.SaveWebAuthnCredential(ctx, stored); err != nil {
+		return nil, fmt.Errorf("failed to save webauthn credential: %w", err)
+	}
+
+	s.audit.Record(ctx, userID, AuditWebAuthnRegistered, ip, map[string]string{
+		"credential_id": base64.RawURLEncoding.EncodeToString(stored.CredentialID),
+	})
+	return stored, nil
+}
+
+// BeginLogin starts an assertion ceremony. With an empty userID the ceremony
+// is a discoverable (passkey) login and the user is taken from the response.
+func (s *WebAuthnService) BeginLogin(ctx context.Context, userID string) (*protocol.CredentialAssertion, string, error) {
+	var (
+		assertion   *protocol.CredentialAssertion
+		sessionData *webauthn.SessionData
+		err         error
+	)
+
+	if userID == "" {
+		assertion, sessionData, err = s.webAuthn.BeginDiscoverableLogin()
+	} else {
+		user, loadErr := s.loadUser(ctx, userID)
+		if loadErr != nil {
+			return nil, "", loadErr
+		}
+		if len(user.credentials) == 0 {
+			return nil, "", ErrWebAuthnNoCredentials
+		}
+		assertion, sessionData, err = s.webAuthn.BeginLogin(user)
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to begin webauthn login: %w", err)
+	}
+
+	sessionID, err := s.saveSession(ctx, userID, webauthnCeremonyLogin, sessionData)
+	if err != nil {
+		return nil, "", err
+	}
+	return assertion, sessionID, nil
+}
+
+// FinishLogin verifies the assertion and returns the ID of the authenticated
+// user. Failed assertions count towards the lockout of the user and the IP
+// like failed passwords.
+func (s *WebAuthnService) FinishLogin(ctx context.Context, sessionID string, response []byte, ip net.IP) (string, error) {
+	sessionData, err := s.takeSession(ctx, sessionID, webauthnCeremonyLogin)
+	if err != nil {
+		return "", err
+	}
+
+	// The user is named by the ceremony, or for passkeys by the user handle
+	// of the response. Without either only the IP is checked.
+	userID := string(sessionData.UserID)
+	parsed, parseErr := protocol.ParseCredentialRequestResponseBytes(response)
+	if parseErr == nil && userID == "" {
+		userID = string(parsed.Response.UserHandle)
+	}
+	if err := s.lockout.Check(ctx, userID, ip); err != nil {
+		return "", err
+	}
+	if parseErr != nil {
+		s.lockout.RegisterFailure(ctx, AttemptLogin, userID, ip)
+		return "", fmt.Errorf("%w: %v", ErrWebAuthnVerification, parseErr)
+	}
+
+	var (
+		user       *webauthnUser
+		credential *webauthn.Credential
+	)
+	if sessionData.UserID == nil {
+		var found webauthn.User
+		found, credential, err = s.webAuthn.ValidatePasskeyLogin(func(_, userHandle []byte) (webauthn.User, error) {
+			return s.loadUser(ctx, string(userHandle))
+		}, *sessionData, parsed)
+		if err == nil {
+			user = found.(*webauthnUser)
+		}
+	} else {
+		user, err = s.loadUser(ctx, string(sessionData.UserID))
+		if err != nil {
+			return "", err
+		}
+		credential, err = s.webAuthn.ValidateLogin(user, *sessionData, parsed)
+	}
+	if err != nil {
+		s.lockout.RegisterFailure(ctx, AttemptLogin, userID, ip)
+		return "", fmt.Errorf("%w: %v", ErrWebAuthnVerification, err)
+	}
+
+	stored := user.stored(credential.ID)
+	if stored == nil {
+		s.lockout.RegisterFailure(ctx, AttemptLogin, userID, ip)
+		return "", ErrWebAuthnVerification
+	}
+
+	if credential.Authenticator.CloneWarning {
+		s.audit.Record(ctx, user.id, AuditWebAuthnCloned, ip, map[string]string{
+			"credential_id": base64.RawURLEncoding.EncodeToString(credential.ID),
+		})
+		msg := fmt.Sprintf("Вход по ключу доступа отклонён: счётчик подписей ключа не совпадает, ключ мог быть скопирован (IP: %s)",
+			ip.String())
+		if err := s.notifier.SendSecurityAlert(user.id, msg); err != nil {
+			log.Printf("Failed to send webauthn clone alert to user %s: %v", user.id, err)
+		}
+		s.lockout.RegisterFailure(ctx, AttemptLogin, user.id, ip)
+		return "", ErrWebAuthnCloneDetected
+	}
+
+	if err := s.repo.UpdateWebAuthnCredentialUsage(ctx, stored.ID, credential.Authenticator.SignCount, credential.Flags.BackupState); err != nil {
+		return "", fmt.Errorf("failed to update webauthn credential: %w", err)
+	}
+
+	s.lockout.RegisterSecondFactorSuccess(ctx, user.id)
+	s.audit.Record(ctx, user.id, AuditWebAuthnLogin, ip, map[string]string{
+		"credential_id": base64.RawURLEncoding.EncodeToString(credential.ID),
+	})
+	return user.id, nil
+}
+
+// loadUser loads the user with the passkeys registered in the tenant of the
+// request, so a passkey cannot sign in to another tenant.
+func (s *WebAuthnService) loadUser(ctx context.Context, userID string) (*webauthnUser, error) {
+	stored, err := s.repo.GetWebAuthnCredentialsByUser(ctx, TenantFromContext(ctx), userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webauthn credentials: %w", err)
+	}
+
+	user := &webauthnUser{id: userID, records: stored}
+	for _, record := range stored {
+		transports := make([]protocol.AuthenticatorTransport, 0, len(record.Transports))
+		for _, transport := range record.Transports {
+			transports = append(transports, protocol.AuthenticatorTransport(transport))
+		}
+		user.credentials = append(user.credentials, webauthn.Credential{
+			ID:              record.CredentialID,
+			PublicKey:       record.PublicKey,
+			AttestationType: record.AttestationType,
+			Transport:       transports,
+			Flags: webauthn.CredentialFlags{
+				BackupEligible: record.BackupEligible,
+				BackupState:    record.BackupState,
+			},
+			Authenticator: webauthn.Authenticator{
+				AAGUID:    record.AAGUID,
+				SignCount: record.SignCount,
+			},
+		})
+	}
+	return user, nil
+}
+
+func (s *WebAuthnService) saveSession(ctx context.Context, userID, ceremony string, sessionData *webauthn.SessionData) (string, error) {
+	data, err := json.Marshal(sessionData)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode webauthn session: %w", err)
+	}
+
+	sessionID, err := generateSecureToken(32)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate webauthn session id: %w", err)
+	}
+
+	err = s.repo.SaveWebAuthnSession(ctx, &models.WebAuthnSession{
+		ID:        sessionID,
+		UserID:    userID,
+		Ceremony:  ceremony,
+		Data:      data,
+		ExpiresAt: time.Now().Add(webauthnSessionTTL),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to save webauthn session: %w", err)
+	}
+	return sessionID, nil
+}
+
+func (s *WebAuthnService) takeSession(ctx context.Context, sessionID, ceremony string) (*webauthn.SessionData, error) {
+	if sessionID == "" {
+		return nil, ErrWebAuthnSessionNotFound
+	}
+
+	session, err := s.repo.TakeWebAuthnSession(ctx, sessionID, ceremony)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrWebAuthnSessionNotFound
+		}
+		return nil, fmt.Errorf("failed to get webauthn session: %w", err)
+	}
+
+	var sessionData webauthn.SessionData
+	if err := json.Unmarshal(session.Data, &sessionData); err != nil {
+		return nil, fmt.Errorf("failed to decode webauthn session: %w", err)
+	}
+	return &sessionData, nil
+}
+
+// webauthnUser adapts our user ID and stored credentials to webauthn.User.
+// The user handle is the user ID itself.
+type webauthnUser struct {
+	id          string
+	records     []models.WebAuthnCredential
+	credentials []webauthn.Credential
+}
+
+func (u *webauthnUser) WebAuthnID() []byte {
+	return []byte(u.id)
+}
+
+func (u *webauthnUser) WebAuthnName() string {
+	return u.id
+}
+
+func (u *webauthnUser) WebAuthnDisplayName() string {
+	return u.id
+}
+
+func (u *webauthnUser) WebAuthnCredentials() []webauthn.Credential {
+	return u.credentials
+}
+
+func (u *webauthnUser) stored(credentialID []byte) *models.WebAuthnCredential {
+	for i := range u.records {
+		if string(u.records[i].CredentialID) == string(credentialID) {
+			return &u.records[i]
+		}
+	}
+	return nil
+}
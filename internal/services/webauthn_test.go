@@ -0,0 +1,353 @@
+package services
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/auth-service/internal/models"
+	"github.com/auth-service/internal/repository"
+	"github.com/auth-service/internal/repository/mocks"
+	"github.com/fxamacker/cbor/v2"
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testRPID   = "localhost"
+	testOrigin = "http://localhost:8081"
+)
+
+// softAuthenticator is a software ES256 authenticator that produces the same
+// attestation and assertion payloads a browser would send.
+type softAuthenticator struct {
+	key          *ecdsa.PrivateKey
+	credentialID []byte
+	userHandle   []byte
+	signCount    uint32
+	origin       string
+}
+
+func newSoftAuthenticator(t *testing.T) *softAuthenticator {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	credentialID := make([]byte, 16)
+	_, err = rand.Read(credentialID)
+	require.NoError(t, err)
+	return &softAuthenticator{key: key, credentialID: credentialID, origin: testOrigin}
+}
+
+func (a *softAuthenticator) authData(flags byte, attested []byte) []byte {
+	rpIDHash := sha256.Sum256([]byte(testRPID))
+	data := append([]byte{}, rpIDHash[:]...)
+	data = append(data, flags)
+	data = binary.BigEndian.AppendUint32(data, a.signCount)
+	return append(data, attested...)
+}
+
+func (a *softAuthenticator) clientData(t *testing.T, ceremony string, challenge protocol.URLEncodedBase64) []byte {
+	data, err := json.Marshal(map[string]string{
+		"type":      ceremony,
+		"challenge": base64.RawURLEncoding.EncodeToString(challenge),
+		"origin":    a.origin,
+	})
+	require.NoError(t, err)
+	return data
+}
+
+func (a *softAuthenticator) create(t *testing.T, options *protocol.CredentialCreation) []byte {
+	userID, ok := options.Response.User.ID.(protocol.URLEncodedBase64)
+	require.True(t, ok)
+	a.userHandle = userID
+
+	coseKey, err := cbor.Marshal(map[int]interface{}{
+		1:  2,
+		3:  -7,
+		-1: 1,
+		-2: a.key.PublicKey.X.FillBytes(make([]byte, 32)),
+		-3: a.key.PublicKey.Y.FillBytes(make([]byte, 32)),
+	})
+	require.NoError(t, err)
+
+	attested := make([]byte, 16)
+	attested = binary.BigEndian.AppendUint16(attested, uint16(len(a.credentialID)))
+	attested = append(attested, a.credentialID...)
+	attested = append(attested, coseKey...)
+
+	attestationObject, err := cbor.Marshal(map[string]interface{}{
+		"fmt":      "none",
+		"attStmt":  map[string]interface{}{},
+		"authData": a.authData(0x45, attested),
+	})
+	require.NoError(t, err)
+
+	return a.response(t, map[string]interface{}{
+		"clientDataJSON":    a.clientData(t, "webauthn.create", options.Response.Challenge),
+		"attestationObject": attestationObject,
+		"transports":        []string{"internal"},
+	})
+}
+
+func (a *softAuthenticator) get(t *testing.T, options *protocol.CredentialAssertion) []byte {
+	a.signCount++
+	authData := a.authData(0x05, nil)
+	clientData := a.clientData(t, "webauthn.get", options.Response.Challenge)
+
+	clientDataHash := sha256.Sum256(clientData)
+	digest := sha256.Sum256(append(append([]byte{}, authData...), clientDataHash[:]...))
+	signature, err := ecdsa.SignASN1(rand.Reader, a.key, digest[:])
+	require.NoError(t, err)
+
+	return a.response(t, map[string]interface{}{
+		"clientDataJSON":    clientData,
+		"authenticatorData": authData,
+		"signature":         signature,
+		"userHandle":        a.userHandle,
+	})
+}
+
+func (a *softAuthenticator) response(t *testing.T, response map[string]interface{}) []byte {
+	encoded := make(map[string]interface{}, len(response))
+	for key, value := range response {
+		if b, ok := value.([]byte); ok {
+			encoded[key] = base64.RawURLEncoding.EncodeToString(b)
+		} else {
+			encoded[key] = value
+		}
+	}
+
+	id := base64.RawURLEncoding.EncodeToString(a.credentialID)
+	data, err := json.Marshal(map[string]interface{}{
+		"id":       id,
+		"rawId":    id,
+		"type":     "public-key",
+		"response": encoded,
+	})
+	require.NoError(t, err)
+	return data
+}
+
+func TestWebAuthnService(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRepository(ctrl)
+	mockNotifier := NewMockNotifier(ctrl)
+	webAuthn, err := webauthn.New(&webauthn.Config{
+		RPID:          testRPID,
+		RPDisplayName: "Auth Service",
+		RPOrigins:     []string{testOrigin},
+	})
+	require.NoError(t, err)
+
+	audit := NewAuditLogger(mockRepo)
+	lockout := NewLockoutService(mockRepo, LockoutPolicy{LockThreshold: 3, LockDuration: time.Minute, Window: time.Hour}, audit, mockNotifier)
+	svc := NewWebAuthnService(webAuthn, mockRepo, audit, mockNotifier, lockout)
+	ctx := context.Background()
+	userIP := net.ParseIP("192.168.1.1")
+
+	failures := make(map[string]*models.AuthFailure)
+	mockRepo.EXPECT().
+		GetAuthFailure(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, scope, key string) (*models.AuthFailure, error) {
+			if failure, ok := failures[scope+":"+key]; ok {
+				copied := *failure
+				return &copied, nil
+			}
+			return &models.AuthFailure{}, nil
+		}).AnyTimes()
+	mockRepo.EXPECT().
+		RecordAuthFailure(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, scope, key string, _ time.Duration) (*models.AuthFailure, error) {
+			failure, ok := failures[scope+":"+key]
+			if !ok {
+				failure = &models.AuthFailure{Scope: scope, Key: key}
+				failures[scope+":"+key] = failure
+			}
+			failure.Failures++
+			failure.LastFailedAt = time.Now()
+			copied := *failure
+			return &copied, nil
+		}).AnyTimes()
+	mockRepo.EXPECT().
+		LockAuthFailure(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, scope, key string, until time.Time) error {
+			failures[scope+":"+key].LockedUntil = &until
+			return nil
+		}).AnyTimes()
+	mockRepo.EXPECT().
+		ClearAuthFailures(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, scope, key string) error {
+			delete(failures, scope+":"+key)
+			return nil
+		}).AnyTimes()
+
+	sessions := make(map[string]*models.WebAuthnSession)
+	credentials := make(map[string][]models.WebAuthnCredential)
+
+	mockRepo.EXPECT().SaveAuditEvent(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	mockRepo.EXPECT().
+		SaveWebAuthnSession(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, session *models.WebAuthnSession) error {
+			sessions[session.ID] = session
+			return nil
+		}).AnyTimes()
+	mockRepo.EXPECT().
+		TakeWebAuthnSession(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, id, ceremony string) (*models.WebAuthnSession, error) {
+			session, ok := sessions[id]
+			if !ok || session.Ceremony != ceremony {
+				return nil, repository.ErrNotFound
+			}
+			delete(sessions, id)
+			return session, nil
+		}).AnyTimes()
+	mockRepo.EXPECT().
+		SaveWebAuthnCredential(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, credential *models.WebAuthnCredential) error {
+			credential.ID = base64.RawURLEncoding.EncodeToString(credential.CredentialID)
+			credentials[credential.UserID] = append(credentials[credential.UserID], *credential)
+			return nil
+		}).AnyTimes()
+	mockRepo.EXPECT().
+		GetWebAuthnCredentialsByUser(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, tenantID, userID string) ([]models.WebAuthnCredential, error) {
+			var found []models.WebAuthnCredential
+			for _, credential := range credentials[userID] {
+				if credential.TenantID == tenantID {
+					found = append(found, credential)
+				}
+			}
+			return found, nil
+		}).AnyTimes()
+	mockRepo.EXPECT().
+		UpdateWebAuthnCredentialUsage(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, id string, signCount uint32, _ bool) error {
+			for userID, stored := range credentials {
+				for i := range stored {
+					if stored[i].ID == id {
+						credentials[userID][i].SignCount = signCount
+					}
+				}
+			}
+			return nil
+		}).AnyTimes()
+
+	authenticator := newSoftAuthenticator(t)
+
+	register := func(t *testing.T, a *softAuthenticator, userID string) *models.WebAuthnCredential {
+		options, sessionID, err := svc.BeginRegistration(ctx, userID)
+		require.NoError(t, err)
+		credential, err := svc.FinishRegistration(ctx, userID, sessionID, a.create(t, options), userIP)
+		require.NoError(t, err)
+		return credential
+	}
+
+	t.Run("Registration", func(t *testing.T) {
+		credential := register(t, authenticator, "user1")
+
+		assert.Equal(t, "user1", credential.UserID)
+		assert.Equal(t, authenticator.credentialID, credential.CredentialID)
+		assert.Equal(t, []string{"internal"}, credential.Transports)
+		assert.NotEmpty(t, credential.PublicKey)
+	})
+
+	t.Run("Login", func(t *testing.T) {
+		options, sessionID, err := svc.BeginLogin(ctx, "user1")
+		require.NoError(t, err)
+
+		userID, err := svc.FinishLogin(ctx, sessionID, authenticator.get(t, options), userIP)
+		require.NoError(t, err)
+		assert.Equal(t, "user1", userID)
+		assert.Equal(t, authenticator.signCount, credentials["user1"][0].SignCount)
+	})
+
+	t.Run("Discoverable login", func(t *testing.T) {
+		options, sessionID, err := svc.BeginLogin(ctx, "")
+		require.NoError(t, err)
+		assert.Empty(t, options.Response.AllowedCredentials)
+
+		userID, err := svc.FinishLogin(ctx, sessionID, authenticator.get(t, options), userIP)
+		require.NoError(t, err)
+		assert.Equal(t, "user1", userID)
+	})
+
+	t.Run("Passkey of another tenant", func(t *testing.T) {
+		otherTenant := WithTenant(ctx, "acme")
+		options, sessionID, err := svc.BeginLogin(otherTenant, "")
+		require.NoError(t, err)
+
+		_, err = svc.FinishLogin(otherTenant, sessionID, authenticator.get(t, options), userIP)
+		assert.ErrorIs(t, err, ErrWebAuthnVerification)
+	})
+
+	t.Run("Session cannot be replayed", func(t *testing.T) {
+		options, sessionID, err := svc.BeginLogin(ctx, "user1")
+		require.NoError(t, err)
+		response := authenticator.get(t, options)
+
+		_, err = svc.FinishLogin(ctx, sessionID, response, userIP)
+		require.NoError(t, err)
+		_, err = svc.FinishLogin(ctx, sessionID, response, userIP)
+		assert.ErrorIs(t, err, ErrWebAuthnSessionNotFound)
+	})
+
+	t.Run("Wrong origin", func(t *testing.T) {
+		options, sessionID, err := svc.BeginLogin(ctx, "user1")
+		require.NoError(t, err)
+
+		phished := *authenticator
+		phished.origin = "https://evil.example"
+		_, err = svc.FinishLogin(ctx, sessionID, phished.get(t, options), userIP)
+		assert.ErrorIs(t, err, ErrWebAuthnVerification)
+	})
+
+	t.Run("Cloned authenticator", func(t *testing.T) {
+		options, sessionID, err := svc.BeginLogin(ctx, "user1")
+		require.NoError(t, err)
+
+		clone := *authenticator
+		clone.signCount = 0
+		mockNotifier.EXPECT().SendSecurityAlert("user1", gomock.Any()).Return(nil)
+
+		_, err = svc.FinishLogin(ctx, sessionID, clone.get(t, options), userIP)
+		assert.ErrorIs(t, err, ErrWebAuthnCloneDetected)
+	})
+
+	t.Run("No credentials", func(t *testing.T) {
+		_, _, err := svc.BeginLogin(ctx, "user-without-passkeys")
+		assert.ErrorIs(t, err, ErrWebAuthnNoCredentials)
+	})
+
+	t.Run("Failed assertions lock the account", func(t *testing.T) {
+		clear(failures)
+		phished := *authenticator
+		phished.origin = "https://evil.example"
+		mockNotifier.EXPECT().SendSecurityAlert("user1", gomock.Any()).Return(nil)
+
+		for i := 0; i < 3; i++ {
+			options, sessionID, err := svc.BeginLogin(ctx, "")
+			require.NoError(t, err)
+			_, err = svc.FinishLogin(ctx, sessionID, phished.get(t, options), userIP)
+			assert.ErrorIs(t, err, ErrWebAuthnVerification)
+		}
+
+		options, sessionID, err := svc.BeginLogin(ctx, "")
+		require.NoError(t, err)
+		_, err = svc.FinishLogin(ctx, sessionID, authenticator.get(t, options), userIP)
+		var locked *LockedError
+		require.ErrorAs(t, err, &locked)
+		assert.Greater(t, locked.RetryAfter, time.Duration(0))
+	})
+}
@@ -2,12 +2,15 @@ package main
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/rsa"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
 	"runtime/debug"
+	"strings"
 	"syscall"
 	"time"
 
@@ -17,6 +20,7 @@ import (
 	"github.com/auth-service/internal/repository"
 	"github.com/auth-service/internal/services"
 	"github.com/gin-gonic/gin"
+	"github.com/go-webauthn/webauthn/webauthn"
 	_ "github.com/lib/pq"
 )
 
@@ -33,19 +37,183 @@ func main() {
 	defer closeResource(repo.Close, "DB connection")
 
 	tokenService := services.NewTokenService(cfg.JWTSecret)
-	emailNotifier := services.NewEmailNotifier()
-	authService := services.NewAuthService(repo, tokenService, emailNotifier)
+	tokenService.SetDenylist(repo)
+	tenants := services.NewTenants(tenantsFromConfig(cfg), repo)
+	for _, tenant := range cfg.Tenants {
+		if tenant.JWTSecret != "" {
+			tokenService.AddTenantKey(tenant.ID, tenant.JWTSecret)
+		}
+	}
+	emailNotifier := services.NewAlertRecorder(services.NewEmailNotifier(), repo)
+	auditLogger := services.NewAuditLogger(repo)
+	lockoutService := services.NewLockoutService(repo, services.LockoutPolicy{
+		DelayThreshold:  cfg.Lockout.DelayThreshold,
+		BaseDelay:       cfg.Lockout.BaseDelay,
+		MaxDelay:        cfg.Lockout.MaxDelay,
+		LockThreshold:   cfg.Lockout.LockThreshold,
+		LockDuration:    cfg.Lockout.LockDuration,
+		IPLockThreshold: cfg.Lockout.IPLockThreshold,
+		Window:          cfg.Lockout.Window,
+	}, auditLogger, emailNotifier)
+	rbacService := services.NewRBACService(repo, auditLogger, cfg.AdminUserIDs)
+	orgService := services.NewOrganizationService(repo, emailNotifier, auditLogger)
+	sessionPolicy, err := services.ParseSessionLimitPolicy(cfg.Sessions.LimitPolicy)
+	if err != nil {
+		log.Fatalf("Invalid sessions config: %v", err)
+	}
+	var geoIP *services.GeoIP
+	if cfg.GeoIP.CityDB != "" || cfg.GeoIP.ASNDB != "" {
+		geoIP, err = services.NewGeoIP(cfg.GeoIP.CityDB, cfg.GeoIP.ASNDB)
+		if err != nil {
+			log.Fatalf("Failed to load GeoIP databases: %v", err)
+		}
+	}
+	ipChangeMode, err := services.ParseIPChangeMode(cfg.IPChange.Policy)
+	if err != nil {
+		log.Fatalf("Invalid IP change config: %v", err)
+	}
+	allowedNetworks, err := services.ParseNetworks(cfg.IPChange.AllowedNetworks)
+	if err != nil {
+		log.Fatalf("Invalid IP change config: %v", err)
+	}
+	authService := services.NewAuthService(repo, tokenService, emailNotifier,
+		services.WithLockout(lockoutService), services.WithAccess(rbacService), services.WithTenants(tenants),
+		services.WithOrganizations(orgService),
+		services.WithSessionLimits(services.SessionLimits{
+			MaxPerUser: cfg.Sessions.MaxPerUser,
+			Policy:     sessionPolicy,
+		}, auditLogger),
+		services.WithIdleTimeout(cfg.Sessions.IdleTimeout),
+		services.WithGeoIP(geoIP),
+		services.WithIPChangePolicy(services.IPChangePolicy{
+			Mode:            ipChangeMode,
+			SameSubnet:      cfg.IPChange.SameSubnet,
+			SameASN:         cfg.IPChange.SameASN,
+			SameCountry:     cfg.IPChange.SameCountry,
+			AllowedNetworks: allowedNetworks,
+		}, auditLogger))
+	mfaService := services.NewMFAService(repo, tokenService, auditLogger, emailNotifier, lockoutService)
+
+	webAuthn, err := webauthn.New(&webauthn.Config{
+		RPID:          cfg.WebAuthn.RPID,
+		RPDisplayName: cfg.WebAuthn.RPDisplayName,
+		RPOrigins:     cfg.WebAuthn.RPOrigins,
+	})
+	if err != nil {
+		log.Fatalf("Failed to configure WebAuthn: %v", err)
+	}
+	webAuthnService := services.NewWebAuthnService(webAuthn, repo, auditLogger, emailNotifier, lockoutService)
+	magicLinkService := services.NewMagicLinkService(
+		repo, cfg.JWTSecret, cfg.PublicURL, cfg.MagicLinkTTL, emailNotifier, auditLogger, lockoutService,
+	)
+	clientService := services.NewClientService(repo, auditLogger)
+	signingKey, err := loadSigningKey(cfg.OIDC.SigningKeyFile)
+	if err != nil {
+		log.Fatalf("Failed to load ID token signing key: %v", err)
+	}
+	idTokenService, err := services.NewIDTokenService(signingKey, cfg.PublicURL)
+	if err != nil {
+		log.Fatalf("Failed to configure ID tokens: %v", err)
+	}
+	oauthService := services.NewOAuthService(clientService, tokenService, authService, repo, idTokenService, auditLogger, cfg.PublicURL)
+	realms := services.NewRealms(services.RealmLocal, services.NewLocalAuthenticator(repo, lockoutService, auditLogger))
+	if cfg.LDAP.URL != "" {
+		realms.Add(services.RealmLDAP, services.NewLDAPAuthenticator(services.LDAPConfig{
+			URL:          cfg.LDAP.URL,
+			StartTLS:     cfg.LDAP.StartTLS,
+			BindDN:       cfg.LDAP.BindDN,
+			BindPassword: cfg.LDAP.BindPassword,
+			UserBaseDN:   cfg.LDAP.UserBaseDN,
+			UserFilter:   cfg.LDAP.UserFilter,
+			GroupBaseDN:  cfg.LDAP.GroupBaseDN,
+			GroupFilter:  cfg.LDAP.GroupFilter,
+			IDAttribute:  cfg.LDAP.IDAttribute,
+			GroupRoles:   cfg.LDAP.GroupRoles,
+			Timeout:      cfg.LDAP.Timeout,
+		}, repo, lockoutService, auditLogger), cfg.LDAP.Domains)
+	}
+	userInfoService := services.NewUserInfoService(repo)
+	profileService := services.NewProfileService(repo, cfg.PublicURL, emailNotifier, auditLogger)
+	accountService := services.NewAccountService(repo, auditLogger, cfg.AccountDeletionGrace)
+	apiKeyService := services.NewAPIKeyService(repo, auditLogger)
+	sessionService := services.NewSessionService(repo, rbacService, auditLogger)
+	impersonationService := services.NewImpersonationService(repo, authService, rbacService, emailNotifier, auditLogger)
+	federationService := services.NewFederationService(
+		repo, federationProviders(cfg), cfg.PublicURL, &http.Client{Timeout: 10 * time.Second}, auditLogger,
+	)
+
 	authHandler := handlers.NewAuthHandler(authService, emailNotifier)
+	mfaHandler := handlers.NewMFAHandler(mfaService, authService)
+	webAuthnHandler := handlers.NewWebAuthnHandler(webAuthnService, authService)
+	magicLinkHandler := handlers.NewMagicLinkHandler(
+		magicLinkService, authService, cfg.MagicLinkTTL, strings.HasPrefix(cfg.PublicURL, "https://"),
+	)
+	adminHandler := handlers.NewAdminHandler(lockoutService)
+	clientHandler := handlers.NewClientHandler(clientService)
+	oauthHandler := handlers.NewOAuthHandler(oauthService)
+	authorizeHandler := handlers.NewAuthorizeHandler(oauthService, realms, strings.HasPrefix(cfg.PublicURL, "https://"))
+	deviceHandler := handlers.NewDeviceHandler(oauthService, realms, strings.HasPrefix(cfg.PublicURL, "https://"))
+	userHandler := handlers.NewUserHandler(profileService)
+	loginHandler := handlers.NewLoginHandler(realms, authService, mfaService)
+	oidcHandler := handlers.NewOIDCHandler(userInfoService, cfg.PublicURL, idTokenService.KeySet())
+	federationHandler := handlers.NewFederationHandler(federationService, authService, strings.HasPrefix(cfg.PublicURL, "https://"))
+	apiKeyHandler := handlers.NewAPIKeyHandler(apiKeyService)
+	roleHandler := handlers.NewRoleHandler(rbacService)
+	orgHandler := handlers.NewOrganizationHandler(orgService, authService)
+	accountHandler := handlers.NewAccountHandler(accountService)
+	impersonationHandler := handlers.NewImpersonationHandler(impersonationService)
+	sessionHandler := handlers.NewSessionHandler(sessionService)
 
-	router := setupRouter(authHandler, tokenService)
+	jobs := newJobManager(cfg.Cleanup, services.NewCleanupService(repo, cfg.Cleanup.BatchSize), authService, accountService)
+	if geoIP != nil {
+		jobs.Add(services.Job{Name: "geoip_reload", Interval: cfg.GeoIP.ReloadInterval, Run: geoIP.Reload})
+	}
+	jobHandler := handlers.NewJobHandler(jobs)
+
+	router := setupRouter(authHandler, mfaHandler, webAuthnHandler, magicLinkHandler, adminHandler,
+		clientHandler, oauthHandler, authorizeHandler, deviceHandler, userHandler, oidcHandler, federationHandler, loginHandler, apiKeyHandler, roleHandler,
+		orgHandler, accountHandler, impersonationHandler, sessionHandler, jobHandler, tokenService, apiKeyService, rbacService, tenants)
+	if cfg.DevTokens {
+		// Unauthenticated token minting is only for local development.
+		log.Println("DEV_TOKENS is enabled: GET /auth/tokens issues tokens without authentication")
+		router.GET("/auth/tokens", authHandler.GenerateTokens)
+	}
 	srv := &http.Server{
 		Addr:    ":" + cfg.ServerPort,
-		Handler: withPanicRecovery(router),
+		Handler: withPanicRecovery(middleware.ResolveTenant(tenants, router)),
 	}
 
+	jobs.Start(context.Background())
 	startServer(srv, cfg.ServerPort)
 	waitForShutdownSignal()
 	shutdownServer(srv, 5*time.Second)
+	stopJobs(jobs, 5*time.Second)
+}
+
+// newJobManager sets up the background cleanup jobs.
+func newJobManager(
+	cfg config.CleanupConfig,
+	cleanupService *services.CleanupService,
+	authService *services.AuthService,
+	accountService *services.AccountService,
+) *services.JobManager {
+	jobs := services.NewJobManager()
+	jobs.Add(services.Job{Name: "expired_tokens", Interval: cfg.TokensInterval, Run: cleanupService.PurgeExpiredTokens})
+	jobs.Add(services.Job{Name: "idle_sessions", Interval: cfg.TokensInterval, Run: authService.SweepIdleSessions})
+	jobs.Add(services.Job{Name: "stale_codes", Interval: cfg.CodesInterval, Run: cleanupService.PurgeStaleCodes})
+	jobs.Add(services.Job{Name: "deleted_accounts", Interval: cfg.AccountsInterval, Run: accountService.PurgeDue})
+	return jobs
+}
+
+func stopJobs(jobs *services.JobManager, timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if err := jobs.Stop(ctx); err != nil {
+		log.Printf("Background jobs did not stop in time: %v", err)
+		return
+	}
+	log.Println("Background jobs stopped")
 }
 
 func initRepository(cfg *config.Config, maxRetries int) (repository.Repository, error) {
@@ -64,20 +232,223 @@ func initRepository(cfg *config.Config, maxRetries int) (repository.Repository,
 	return nil, fmt.Errorf("failed to connect to DB after %d attempts: %v", maxRetries, err)
 }
 
-func setupRouter(authHandler *handlers.AuthHandler, tokenService *services.TokenService) *gin.Engine {
+// loadSigningKey reads the ID token signing key, or generates a temporary
+// one when no key file is configured.
+func loadSigningKey(path string) (*rsa.PrivateKey, error) {
+	if path != "" {
+		return services.LoadSigningKey(path)
+	}
+
+	log.Println("WARNING: OIDC_SIGNING_KEY_FILE is not set, ID tokens are signed with a temporary key")
+	return rsa.GenerateKey(rand.Reader, 2048)
+}
+
+func tenantsFromConfig(cfg *config.Config) []services.Tenant {
+	tenants := make([]services.Tenant, 0, len(cfg.Tenants))
+	for _, t := range cfg.Tenants {
+		tenants = append(tenants, services.Tenant{
+			ID:              t.ID,
+			Hosts:           t.Hosts,
+			AccessTokenTTL:  t.AccessTokenTTL,
+			RefreshTokenTTL: t.RefreshTokenTTL,
+		})
+	}
+	return tenants
+}
+
+func federationProviders(cfg *config.Config) []services.FederationProvider {
+	providers := make([]services.FederationProvider, 0, len(cfg.FederatedProviders))
+	for _, p := range cfg.FederatedProviders {
+		providers = append(providers, services.FederationProvider{
+			Name:         p.Name,
+			Issuer:       p.Issuer,
+			ClientID:     p.ClientID,
+			ClientSecret: p.ClientSecret,
+			Scopes:       p.Scopes,
+			TrustEmail:   p.TrustEmail,
+		})
+	}
+	return providers
+}
+
+func setupRouter(
+	authHandler *handlers.AuthHandler,
+	mfaHandler *handlers.MFAHandler,
+	webAuthnHandler *handlers.WebAuthnHandler,
+	magicLinkHandler *handlers.MagicLinkHandler,
+	adminHandler *handlers.AdminHandler,
+	clientHandler *handlers.ClientHandler,
+	oauthHandler *handlers.OAuthHandler,
+	authorizeHandler *handlers.AuthorizeHandler,
+	deviceHandler *handlers.DeviceHandler,
+	userHandler *handlers.UserHandler,
+	oidcHandler *handlers.OIDCHandler,
+	federationHandler *handlers.FederationHandler,
+	loginHandler *handlers.LoginHandler,
+	apiKeyHandler *handlers.APIKeyHandler,
+	roleHandler *handlers.RoleHandler,
+	orgHandler *handlers.OrganizationHandler,
+	accountHandler *handlers.AccountHandler,
+	impersonationHandler *handlers.ImpersonationHandler,
+	sessionHandler *handlers.SessionHandler,
+	jobHandler *handlers.JobHandler,
+	tokenService *services.TokenService,
+	apiKeyService services.APIKeyServiceInterface,
+	accessProvider services.AccessProvider,
+	tenants services.TenantMembership,
+) *gin.Engine {
 	router := gin.Default()
+	router.Use(middleware.CaptureDevice())
 
 	authGroup := router.Group("/auth")
 	{
-		authGroup.GET("/tokens", authHandler.GenerateTokens)
+		authGroup.POST("/login", loginHandler.Login)
 		authGroup.POST("/refresh", authHandler.RefreshTokens)
-		authGroup.POST("/logout", authHandler.Logout)
+		authGroup.POST("/mfa/recovery", mfaHandler.VerifyRecoveryCode)
+		authGroup.POST("/webauthn/login/begin", webAuthnHandler.BeginLogin)
+		authGroup.POST("/webauthn/login/finish", webAuthnHandler.FinishLogin)
+		authGroup.POST("/magic-link", magicLinkHandler.RequestLink)
+		authGroup.GET("/magic-link/callback", magicLinkHandler.Callback)
+		authGroup.POST("/magic-link/verify", magicLinkHandler.VerifyCode)
+		authGroup.GET("/federated/:provider", federationHandler.Begin)
+		authGroup.GET("/federated/:provider/callback", federationHandler.Callback)
+		authGroup.GET("/email/confirm", userHandler.ConfirmEmail)
+	}
+
+	oauthGroup := router.Group("/oauth")
+	{
+		oauthGroup.GET("/authorize", authorizeHandler.Authorize)
+		oauthGroup.POST("/authorize", authorizeHandler.Decide)
+		oauthGroup.POST("/token", oauthHandler.Token)
+		oauthGroup.POST("/device_authorization", oauthHandler.DeviceAuthorization)
+		oauthGroup.GET("/device", deviceHandler.Verify)
+		oauthGroup.POST("/device", deviceHandler.Decide)
+	}
+
+	router.GET("/.well-known/openid-configuration", oidcHandler.Discovery)
+	router.GET("/.well-known/jwks.json", oidcHandler.JWKS)
+
+	userInfo := router.Group("/userinfo")
+	userInfo.Use(middleware.JWTValidator(tokenService), middleware.RejectStaleTokens(accessProvider))
+	{
+		userInfo.GET("", oidcHandler.UserInfo)
+		userInfo.POST("", oidcHandler.UserInfo)
+	}
+
+	webAuthnRegister := router.Group("/auth/webauthn/register")
+	webAuthnRegister.Use(middleware.JWTValidator(tokenService), middleware.RejectStaleTokens(accessProvider),
+		middleware.RejectImpersonation(), middleware.RejectClientTokens())
+	{
+		webAuthnRegister.POST("/begin", webAuthnHandler.BeginRegistration)
+		webAuthnRegister.POST("/finish", webAuthnHandler.FinishRegistration)
+	}
+
+	// Logging out needs an access token, stale ones included: it only ends
+	// sessions.
+	logout := router.Group("/auth")
+	logout.Use(middleware.JWTValidator(tokenService))
+	{
+		logout.POST("/logout", authHandler.Logout)
+		logout.POST("/logout-all", middleware.RejectImpersonation(), middleware.RejectClientTokens(), authHandler.LogoutAll)
+	}
+
+	// Keys are managed with an access token only. Keys cannot reach the
+	// routes that issue or change credentials either, so a leaked key cannot
+	// be turned into a session. The same goes for tokens of OAuth clients.
+	apiKeys := router.Group("/api/keys")
+	apiKeys.Use(middleware.JWTValidator(tokenService), middleware.RejectStaleTokens(accessProvider),
+		middleware.RejectImpersonation(), middleware.RejectClientTokens())
+	{
+		apiKeys.POST("", apiKeyHandler.CreateKey)
+		apiKeys.GET("", apiKeyHandler.ListKeys)
+		apiKeys.DELETE("/:id", apiKeyHandler.RevokeKey)
+	}
+
+	// Switching organizations issues a refreshable pair, so impersonation
+	// tokens are kept out of the whole group.
+	orgs := router.Group("/api/orgs")
+	orgs.Use(middleware.JWTValidator(tokenService), middleware.RejectStaleTokens(accessProvider), middleware.RejectImpersonation())
+	{
+		orgs.POST("", orgHandler.CreateOrganization)
+		orgs.GET("", orgHandler.ListOrganizations)
+		orgs.POST("/:org_id/switch", orgHandler.Switch)
+		orgs.GET("/:org_id/members", orgHandler.ListMembers)
+		orgs.POST("/:org_id/invitations", orgHandler.Invite)
+		orgs.PUT("/:org_id/members/:user_id", orgHandler.ChangeMemberRole)
+		orgs.DELETE("/:org_id/members/:user_id", orgHandler.RemoveMember)
+		orgs.GET("/:org_id/teams", orgHandler.ListTeams)
+		orgs.POST("/:org_id/teams", orgHandler.CreateTeam)
+		orgs.PUT("/:org_id/teams/:team_id/members/:user_id", orgHandler.AddTeamMember)
+		orgs.DELETE("/:org_id/teams/:team_id/members/:user_id", orgHandler.RemoveTeamMember)
 	}
+	router.POST("/api/invitations/accept",
+		middleware.JWTValidator(tokenService), middleware.RejectStaleTokens(accessProvider), orgHandler.AcceptInvitation)
 
+	// Changing, exporting and deleting the account need an access token of
+	// the user, API keys and OAuth clients cannot do any of it.
+	account := router.Group("/api/me")
+	account.Use(middleware.JWTValidator(tokenService), middleware.RejectStaleTokens(accessProvider),
+		middleware.RejectImpersonation(), middleware.RejectClientTokens())
+	{
+		account.PATCH("", userHandler.UpdateProfile)
+		account.POST("/export", accountHandler.Export)
+		account.DELETE("", accountHandler.Delete)
+		account.POST("/cancel-deletion", accountHandler.CancelDeletion)
+	}
+
+	sessions := router.Group("/api/sessions")
+	sessions.Use(middleware.JWTValidator(tokenService), middleware.RejectStaleTokens(accessProvider))
+	{
+		sessions.GET("", sessionHandler.ListSessions)
+		sessions.DELETE("/:id", middleware.RejectImpersonation(), sessionHandler.RevokeSession)
+	}
+
+	mfa := router.Group("/api/mfa")
+	mfa.Use(middleware.JWTValidator(tokenService), middleware.RejectStaleTokens(accessProvider),
+		middleware.RejectImpersonation(), middleware.RejectClientTokens())
+	{
+		mfa.POST("/recovery-codes", mfaHandler.GenerateRecoveryCodes)
+	}
+
+	// The routes that take API keys as well as access tokens.
 	protected := router.Group("/api")
-	protected.Use(middleware.JWTValidator(tokenService))
+	protected.Use(middleware.CredentialsValidator(tokenService, apiKeyService), middleware.RejectStaleTokens(accessProvider))
+	{
+		protected.GET("/me", middleware.RequireAPIKeyScope(services.ScopeProfileRead), userHandler.GetProfile)
+		// /api/user predates /api/me and is kept for existing clients.
+		protected.GET("/user", middleware.RequireAPIKeyScope(services.ScopeProfileRead), userHandler.GetProfile)
+	}
+
+	admin := router.Group("/admin")
+	admin.Use(middleware.JWTValidator(tokenService), middleware.RejectImpersonation(), middleware.RequirePermission(accessProvider, services.PermissionAdmin))
+	{
+		admin.GET("/roles", roleHandler.ListRoles)
+		admin.POST("/roles", roleHandler.CreateRole)
+		admin.PUT("/roles/:id", roleHandler.UpdateRole)
+		admin.DELETE("/roles/:id", roleHandler.DeleteRole)
+		admin.GET("/permissions", roleHandler.ListPermissions)
+		admin.GET("/jobs", jobHandler.ListJobs)
+	}
+
+	adminUsers := admin.Group("/users/:id", middleware.RequireTenantUser(tenants))
+	{
+		adminUsers.GET("/roles", roleHandler.GetUserRoles)
+		adminUsers.PUT("/roles", roleHandler.SetUserRoles)
+		adminUsers.POST("/unlock", adminHandler.UnlockUser)
+	}
+
+	// Impersonation needs its own permission rather than admin.
+	router.POST("/admin/users/:id/impersonate",
+		middleware.JWTValidator(tokenService), middleware.RejectImpersonation(),
+		middleware.RequirePermission(accessProvider, services.PermissionImpersonate),
+		middleware.RequireTenantUser(tenants), impersonationHandler.Impersonate)
+
+	// OAuth clients are shared by all tenants.
+	adminClients := admin.Group("/clients", middleware.RequireTenant(services.DefaultTenant))
 	{
-		protected.GET("/user", authHandler.GetUserData)
+		adminClients.POST("", clientHandler.CreateClient)
+		adminClients.POST("/:client_id/secret", clientHandler.RotateSecret)
+		adminClients.POST("/:client_id/disable", clientHandler.DisableClient)
 	}
 
 	return router
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS auth_failures (
+    scope VARCHAR(16) NOT NULL,
+    key VARCHAR(64) NOT NULL,
+    failures INT NOT NULL DEFAULT 0,
+    last_failed_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    locked_until TIMESTAMP,
+    PRIMARY KEY (scope, key)
+);
@@ -0,0 +1,6 @@
+-- Access tokens issued before not_before are rejected, which makes the
+-- client refresh them and pick up changed roles.
+CREATE TABLE IF NOT EXISTS token_cutoffs (
+    user_id VARCHAR(36) PRIMARY KEY,
+    not_before TIMESTAMP NOT NULL
+);
@@ -0,0 +1,3 @@
+INSERT INTO permissions (name, description) VALUES
+    ('users:impersonate', 'Вход в аккаунт пользователя от его имени для поддержки')
+ON CONFLICT (name) DO NOTHING;
@@ -0,0 +1,4 @@
+-- A refresh token row is a session: refreshing rotates the token in place,
+-- so the id and created_at of the session stay the same.
+ALTER TABLE refresh_tokens ADD COLUMN IF NOT EXISTS user_agent TEXT NOT NULL DEFAULT '';
+ALTER TABLE refresh_tokens ADD COLUMN IF NOT EXISTS last_used_at TIMESTAMP;
@@ -0,0 +1,2 @@
+-- max_sessions caps the sessions a user can have with the client, 0 means no cap.
+ALTER TABLE oauth_clients ADD COLUMN IF NOT EXISTS max_sessions INTEGER NOT NULL DEFAULT 0;
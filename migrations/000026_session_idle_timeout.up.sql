@@ -0,0 +1,4 @@
+-- idle_timeout is in seconds, 0 means the global idle timeout. Sessions keep
+-- the timeout of the client they were opened with.
+ALTER TABLE oauth_clients ADD COLUMN IF NOT EXISTS idle_timeout INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE refresh_tokens ADD COLUMN IF NOT EXISTS idle_timeout INTEGER NOT NULL DEFAULT 0;
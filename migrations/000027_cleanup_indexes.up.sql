@@ -0,0 +1,5 @@
+-- The cleanup jobs delete rows by their expiry.
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_expires_at ON refresh_tokens(expires_at);
+CREATE INDEX IF NOT EXISTS idx_login_codes_expires_at ON login_codes(expires_at);
+CREATE INDEX IF NOT EXISTS idx_webauthn_sessions_expires_at ON webauthn_sessions(expires_at);
+CREATE INDEX IF NOT EXISTS idx_email_changes_expires_at ON email_changes(expires_at);
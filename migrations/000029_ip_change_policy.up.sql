@@ -0,0 +1,4 @@
+-- ip_change_policy is allow, notify, step_up or deny, empty means the global
+-- policy. Sessions keep the policy of the client they were opened with.
+ALTER TABLE oauth_clients ADD COLUMN IF NOT EXISTS ip_change_policy VARCHAR(16) NOT NULL DEFAULT '';
+ALTER TABLE refresh_tokens ADD COLUMN IF NOT EXISTS ip_change_policy VARCHAR(16) NOT NULL DEFAULT '';
@@ -0,0 +1,13 @@
+-- One-time codes and passkeys can only be redeemed in the tenant they were
+-- issued in. Existing rows take the tenant of their user.
+ALTER TABLE login_codes ADD COLUMN IF NOT EXISTS tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+ALTER TABLE authorization_codes ADD COLUMN IF NOT EXISTS tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+ALTER TABLE device_codes ADD COLUMN IF NOT EXISTS tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+ALTER TABLE mfa_recovery_codes ADD COLUMN IF NOT EXISTS tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+ALTER TABLE webauthn_credentials ADD COLUMN IF NOT EXISTS tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+
+UPDATE login_codes c SET tenant_id = u.tenant_id FROM users u WHERE u.id = c.user_id;
+UPDATE authorization_codes c SET tenant_id = u.tenant_id FROM users u WHERE u.id = c.user_id;
+UPDATE device_codes c SET tenant_id = u.tenant_id FROM users u WHERE u.id = c.user_id;
+UPDATE mfa_recovery_codes c SET tenant_id = u.tenant_id FROM users u WHERE u.id = c.user_id;
+UPDATE webauthn_credentials c SET tenant_id = u.tenant_id FROM users u WHERE u.id = c.user_id;
@@ -0,0 +1,4 @@
+-- Lifetimes are in seconds, 0 means the tenant or global default. Sessions
+-- keep the lifetimes of the client they were opened with across refreshes.
+ALTER TABLE refresh_tokens ADD COLUMN IF NOT EXISTS access_token_ttl INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE refresh_tokens ADD COLUMN IF NOT EXISTS refresh_token_ttl INTEGER NOT NULL DEFAULT 0;
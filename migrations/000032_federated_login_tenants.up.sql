@@ -0,0 +1,2 @@
+-- A federated login completes only in the tenant it was started in.
+ALTER TABLE federated_logins ADD COLUMN IF NOT EXISTS tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';